@@ -0,0 +1,158 @@
+// firma-ca genera una CA local autofirmada y certificados PKCS#12 de usuario para probar la
+// firma digital de formularios sin una entidad certificadora real.
+//
+//	go run ./cmd/firma-ca -dir certs-dev
+//	go run ./cmd/firma-ca -dir certs-dev -nombre "Juan Pérez" -ci 1234567 -password secreto
+//
+// El servidor confía en la CA configurando FIRMA_CA_CERTS=certs-dev/ca.crt.
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func main() {
+	dir := flag.String("dir", "certs-dev", "directorio de la CA y los certificados")
+	nombre := flag.String("nombre", "", "nombre completo del firmante; vacío solo prepara la CA")
+	ci := flag.String("ci", "", "carnet de identidad del firmante")
+	password := flag.String("password", "", "contraseña del archivo .p12")
+	dias := flag.Int("dias", 365, "días de vigencia del certificado de usuario")
+	flag.Parse()
+
+	if err := os.MkdirAll(*dir, 0700); err != nil {
+		log.Fatalf("No se pudo crear el directorio: %v", err)
+	}
+
+	caCert, caKey, err := cargarOCrearCA(*dir)
+	if err != nil {
+		log.Fatalf("Error con la CA: %v", err)
+	}
+	fmt.Printf("CA: %s (%s)\n", caCert.Subject.CommonName, filepath.Join(*dir, "ca.crt"))
+
+	if *nombre == "" {
+		return
+	}
+	if *password == "" {
+		log.Fatal("Indique -password para proteger el certificado del usuario")
+	}
+
+	p12, err := emitirUsuario(caCert, caKey, *nombre, *ci, *password, *dias)
+	if err != nil {
+		log.Fatalf("Error emitiendo el certificado: %v", err)
+	}
+	archivo := filepath.Join(*dir, nombreArchivo(*nombre)+".p12")
+	if err := os.WriteFile(archivo, p12, 0600); err != nil {
+		log.Fatalf("No se pudo guardar el certificado: %v", err)
+	}
+	fmt.Printf("Certificado de %s: %s\n", *nombre, archivo)
+}
+
+func cargarOCrearCA(dir string) (*x509.Certificate, *ecdsa.PrivateKey, error) {
+	certPath, keyPath := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+
+	if certPEM, err := os.ReadFile(certPath); err == nil {
+		keyPEM, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		certBlock, keyBlock := decodePEM(certPEM), decodePEM(keyPEM)
+		if certBlock == nil || keyBlock == nil {
+			return nil, nil, fmt.Errorf("ca.crt o ca.key no son PEM válidos")
+		}
+		cert, err := x509.ParseCertificate(certBlock)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := x509.ParseECPrivateKey(keyBlock)
+		if err != nil {
+			return nil, nil, err
+		}
+		return cert, key, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial(),
+		Subject:               pkix.Name{CommonName: "CA Local de Pruebas - Sistema de Pasajes", Organization: []string{"Pruebas"}, Country: []string{"BO"}},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		return nil, nil, err
+	}
+	if err := os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		return nil, nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	return cert, key, err
+}
+
+func emitirUsuario(caCert *x509.Certificate, caKey *ecdsa.PrivateKey, nombre, ci, password string, dias int) ([]byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: serial(),
+		Subject:      pkix.Name{CommonName: nombre, SerialNumber: ci, Organization: []string{"Pruebas"}, Country: []string{"BO"}},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().AddDate(0, 0, dias),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageContentCommitment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageEmailProtection},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return pkcs12.Modern.Encode(key, cert, []*x509.Certificate{caCert}, password)
+}
+
+func decodePEM(data []byte) []byte {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil
+	}
+	return block.Bytes
+}
+
+func serial() *big.Int {
+	n, _ := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	return n
+}
+
+func nombreArchivo(nombre string) string {
+	return strings.ToLower(strings.Join(strings.Fields(nombre), "-"))
+}
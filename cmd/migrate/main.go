@@ -50,6 +50,26 @@ func main() {
 		&models.EstadoCupoDerecho{},
 		&models.CupoDerecho{},
 		&models.CupoDerechoItem{},
+		&models.PoliticaCupo{},
+		&models.PoliticaCupoAjuste{},
+		&models.TransferenciaCupo{},
+		&models.MovimientoCupo{},
+		&models.LicenciaSenador{},
+		&models.CalendarioFeed{},
+		&models.CertificadoFirma{},
+		&models.Adjunto{},
+		&models.AdjuntoVersion{},
+		&models.DesviacionTarifa{},
+		&models.TipoCambio{},
+		&models.Feriado{},
+		&models.ReglaDescargo{},
+		&models.ZonaViatico{},
+		&models.CategoriaViatico{},
+		&models.Viatico{},
+		&models.Anticipo{},
+		&models.RecordatorioDescargo{},
+		&models.ExtractoBancario{},
+		&models.MovimientoBancario{},
 
 		// Operaciones Principales
 		&models.Solicitud{},
@@ -73,5 +93,19 @@ func main() {
 		log.Fatalf("Error durante la migración: %v", err)
 	}
 
+	// Completa el número de billete normalizado de los registros anteriores a la columna;
+	// los nuevos lo calculan al guardarse.
+	for _, tabla := range []struct{ nombre, columna string }{
+		{"pasajes", "numero_billete"},
+		{"descargo_tramos", "billete"},
+		{"open_tickets", "numero_billete"},
+	} {
+		err := configs.DB.Exec("UPDATE " + tabla.nombre + " SET billete_normalizado = UPPER(REGEXP_REPLACE(" + tabla.columna +
+			", '[^0-9A-Za-z]', '', 'g')) WHERE COALESCE(billete_normalizado, '') = '' AND " + tabla.columna + " <> ''").Error
+		if err != nil {
+			log.Fatalf("Error normalizando billetes de %s: %v", tabla.nombre, err)
+		}
+	}
+
 	log.Println("Migración completada exitosamente.")
 }
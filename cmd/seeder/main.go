@@ -25,6 +25,9 @@ func main() {
 	seedConfig()
 	seedGeneros()
 	seedCodigoSecuencia()
+	seedFeriados()
+	seedReglasDescargo()
+	seedZonasViatico()
 }
 
 func seedCodigoSecuencia() {
@@ -218,6 +221,14 @@ func seedConfig() {
 		{Clave: "BANCO_CUENTA_DEVOLUCION", Valor: "10000005588211", Tipo: "STRING"},
 		{Clave: "BANCO_NOMBRE_DEVOLUCION", Valor: "BANCO UNIÓN S.A.", Tipo: "STRING"},
 		{Clave: "SEDES_AUTORIZADAS", Valor: "LPB", Tipo: "STRING"},
+		{Clave: "TARIFA_TOLERANCIA_PORCENTAJE", Valor: "10", Tipo: "FLOAT"},
+		{Clave: "BILLETE_DUPLICADO_MODO", Valor: "BLOQUEAR", Tipo: "STRING"},
+		{Clave: "REEMBOLSO_PLAZO_DIAS", Valor: "10", Tipo: "INT"},
+		{Clave: "AGENCIA_PLAZO_EMISION_HORAS", Valor: "24", Tipo: "INT"},
+		{Clave: "DESCARGO_RECORDATORIO_ETAPAS", Valor: "-5,0,3,10", Tipo: "STRING"},
+		{Clave: "DESCARGO_ESCALAR_ENCARGADO_DIAS", Valor: "3", Tipo: "INT"},
+		{Clave: "DESCARGO_ESCALAR_RESPONSABLE_DIAS", Valor: "10", Tipo: "INT"},
+		{Clave: "MOROSIDAD_BLOQUEO_MODO", Valor: "AUTORIZAR", Tipo: "STRING"},
 	}
 
 	for _, cf := range confList {
@@ -442,6 +453,7 @@ func seedEstadosPasaje() {
 		{Codigo: "REGISTRADO", Nombre: "Registrado", Color: "#8B5CF6", Icon: "ph ph-paper-plane", Descripcion: "Pasaje registrado en el sistema pero no emitido"},
 		{Codigo: "EMITIDO", Nombre: "Emitido", Color: "#10B981", Icon: "ph ph-airplane-takeoff", Descripcion: "Pasaje emitido correctamente"},
 		{Codigo: "FINALIZADO", Nombre: "Finalizado", Color: "#374151", Icon: "ph ph-airplane-landing", Descripcion: "Pasaje procesado (viaje realizado o crédito generado)"},
+		{Codigo: "REEMITIDO", Nombre: "Reemitido", Color: "#D97706", Icon: "ph ph-arrows-clockwise", Descripcion: "Billete reemplazado por una reemisión (cambio de fecha, ruta o vuelo)"},
 	}
 
 	for _, e := range estados {
@@ -490,3 +502,56 @@ func seedEstadosSolicitudItem() {
 		}
 	}
 }
+
+func seedFeriados() {
+	fmt.Println("Sincronizando Feriados...")
+	depto := func(codigo string) *string { return &codigo }
+	feriados := []models.Feriado{
+		{Nombre: "Año Nuevo", Tipo: models.FeriadoFijo, Mes: 1, Dia: 1, TrasladarDomingo: true},
+		{Nombre: "Día del Estado Plurinacional", Tipo: models.FeriadoFijo, Mes: 1, Dia: 22, TrasladarDomingo: true},
+		{Nombre: "Carnaval (lunes)", Tipo: models.FeriadoMovil, DiasDesdePascua: -48},
+		{Nombre: "Carnaval (martes)", Tipo: models.FeriadoMovil, DiasDesdePascua: -47},
+		{Nombre: "Viernes Santo", Tipo: models.FeriadoMovil, DiasDesdePascua: -2},
+		{Nombre: "Día del Trabajo", Tipo: models.FeriadoFijo, Mes: 5, Dia: 1, TrasladarDomingo: true},
+		{Nombre: "Corpus Christi", Tipo: models.FeriadoMovil, DiasDesdePascua: 60},
+		{Nombre: "Año Nuevo Andino Amazónico", Tipo: models.FeriadoFijo, Mes: 6, Dia: 21, TrasladarDomingo: true},
+		{Nombre: "Día de la Independencia", Tipo: models.FeriadoFijo, Mes: 8, Dia: 6, TrasladarDomingo: true},
+		{Nombre: "Todos Santos", Tipo: models.FeriadoFijo, Mes: 11, Dia: 2, TrasladarDomingo: true},
+		{Nombre: "Navidad", Tipo: models.FeriadoFijo, Mes: 12, Dia: 25, TrasladarDomingo: true},
+
+		{Nombre: "Efeméride de Oruro", Tipo: models.FeriadoFijo, Mes: 2, Dia: 10, DepartamentoCodigo: depto("OR")},
+		{Nombre: "Efeméride de Tarija", Tipo: models.FeriadoFijo, Mes: 4, Dia: 15, DepartamentoCodigo: depto("TJ")},
+		{Nombre: "Efeméride de Chuquisaca", Tipo: models.FeriadoFijo, Mes: 5, Dia: 25, DepartamentoCodigo: depto("CH")},
+		{Nombre: "Efeméride de La Paz", Tipo: models.FeriadoFijo, Mes: 7, Dia: 16, DepartamentoCodigo: depto("LP")},
+		{Nombre: "Efeméride de Cochabamba", Tipo: models.FeriadoFijo, Mes: 9, Dia: 14, DepartamentoCodigo: depto("CB")},
+		{Nombre: "Efeméride de Santa Cruz", Tipo: models.FeriadoFijo, Mes: 9, Dia: 24, DepartamentoCodigo: depto("SC")},
+		{Nombre: "Efeméride de Pando", Tipo: models.FeriadoFijo, Mes: 10, Dia: 11, DepartamentoCodigo: depto("PA")},
+		{Nombre: "Efeméride de Potosí", Tipo: models.FeriadoFijo, Mes: 11, Dia: 10, DepartamentoCodigo: depto("PT")},
+		{Nombre: "Efeméride del Beni", Tipo: models.FeriadoFijo, Mes: 11, Dia: 18, DepartamentoCodigo: depto("BE")},
+	}
+
+	for _, f := range feriados {
+		f.Activo = true
+		configs.DB.Where("nombre = ?", f.Nombre).FirstOrCreate(&f)
+	}
+}
+
+// seedReglasDescargo solo siembra una base vacía para no reponer reglas que el administrador eliminó.
+func seedReglasDescargo() {
+	fmt.Println("Sincronizando Reglas de Descargo...")
+	var count int64
+	configs.DB.Model(&models.ReglaDescargo{}).Count(&count)
+	if count > 0 {
+		return
+	}
+	for _, r := range models.ReglasDescargoPorDefecto() {
+		configs.DB.Omit("Concepto", "TipoSolicitud").Create(&r)
+	}
+}
+
+func seedZonasViatico() {
+	fmt.Println("Sincronizando Zonas de Viático...")
+	for _, nombre := range []string{"CIUDAD CAPITAL", "PROVINCIA", "SUDAMERICA", "RESTO DEL MUNDO"} {
+		configs.DB.Where("nombre = ?", nombre).FirstOrCreate(&models.ZonaViatico{Nombre: nombre})
+	}
+}
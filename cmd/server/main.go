@@ -68,14 +68,52 @@ func main() {
 		slog.Error("[Scheduler] Error al programar alertas", "error", err)
 	}
 
+	cupoPeriodoService := container.CupoPeriodoService
+
+	// "0 6 25 * *" means: At 06:00 on day 25 of every month (genera el mes siguiente)
+	_, err = c.AddFunc("0 6 25 * *", func() {
+		slog.Info("[Scheduler] Generando cupos de derecho del siguiente periodo (día 25 06:00)...")
+		workerPool.Submit(&services.GeneracionCuposJob{Service: cupoPeriodoService})
+	})
+	if err != nil {
+		slog.Error("[Scheduler] Error al programar generación de cupos", "error", err)
+	}
+
+	// "10 0 1 * *" means: At 00:10 on day 1 (cambio de periodo: completa el mes que inicia)
+	_, err = c.AddFunc("10 0 1 * *", func() {
+		slog.Info("[Scheduler] Verificando cupos de derecho del periodo que inicia (día 1 00:10)...")
+		workerPool.Submit(&services.GeneracionCuposJob{Service: cupoPeriodoService, PeriodoActual: true})
+	})
+	if err != nil {
+		slog.Error("[Scheduler] Error al programar cambio de periodo de cupos", "error", err)
+	}
+
+	licenciaService := container.LicenciaService
+
+	// "30 0 * * *" means: At 00:30 every day (inicio/fin de licencias de titulares)
+	_, err = c.AddFunc("30 0 * * *", func() {
+		slog.Info("[Scheduler] Procesando licencias de senadores (diario 00:30)...")
+		workerPool.Submit(&services.LicenciasJob{Service: licenciaService})
+	})
+	if err != nil {
+		slog.Error("[Scheduler] Error al programar licencias", "error", err)
+	}
+
 	c.Start()
-	slog.Info("[Scheduler] Programador iniciado: Alertas diarias Mon-Fri 09:00 America/La_Paz.")
+	slog.Info("[Scheduler] Programador iniciado: Alertas diarias Mon-Fri 09:00 y cupos mensuales día 25 06:00 America/La_Paz.")
 
 	itinerarioService := container.TipoItinerarioService
 	if err := itinerarioService.EnsureDefaults(context.Background()); err != nil {
 		slog.Error("Error seeding itineraries", "error", err)
 	}
 
+	if err := container.FeriadoService.CargarCalendario(context.Background()); err != nil {
+		slog.Error("Error cargando calendario de feriados", "error", err)
+	}
+	if err := container.ReglaDescargoService.CargarReglas(context.Background()); err != nil {
+		slog.Error("Error cargando reglas de descargo", "error", err)
+	}
+
 	isDev := viper.GetString("ENV") != "production"
 	if !isDev {
 		gin.SetMode(gin.ReleaseMode)
@@ -38,11 +38,16 @@ type Container struct {
 	NotificationService     *services.NotificationService
 	EmailService            *services.EmailService
 	AlertaService           *services.AlertaService
+	FeriadoService          *services.FeriadoService
+	ReglaDescargoService    *services.ReglaDescargoService
+	ViaticoService          *services.ViaticoService
 	ConceptoService         *services.ConceptoService
 	EstadoPasajeService     *services.EstadoPasajeService
 	AuditService            *services.AuditService
 	PushService             *services.PushService
 	OpenTicketService       *services.OpenTicketService
+	CupoPeriodoService      *services.CupoPeriodoService
+	LicenciaService         *services.LicenciaService
 
 	// Controllers
 	CupoController             *controllers.CupoController
@@ -72,6 +77,18 @@ type Container struct {
 	OpenTicketController       *controllers.OpenTicketController
 	ReportController           *controllers.ReportController
 	DestinoController          *controllers.DestinoController
+	PoliticaCupoController     *controllers.PoliticaCupoController
+	ConciliacionCupoController *controllers.ConciliacionCupoController
+	LicenciaController         *controllers.LicenciaController
+	CalendarioController       *controllers.CalendarioController
+	TipoCambioController       *controllers.TipoCambioController
+	ReembolsoController        *controllers.ReembolsoController
+	FeriadoController          *controllers.FeriadoController
+	ReglaDescargoController    *controllers.ReglaDescargoController
+	ViaticoController          *controllers.ViaticoController
+	AnticipoController         *controllers.AnticipoController
+	FirmaController            *controllers.FirmaController
+	AdjuntoController          *controllers.AdjuntoController
 }
 
 // NewContainer initializes the graph of dependencies
@@ -107,18 +124,39 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 	auditRepo := repositories.NewAuditRepository(db)
 	pushRepo := repositories.NewPushRepository(db)
 	openTicketRepo := repositories.NewOpenTicketRepository(db)
+	politicaCupoRepo := repositories.NewPoliticaCupoRepository(db)
+	transferenciaCupoRepo := repositories.NewTransferenciaCupoRepository(db)
+	movimientoCupoRepo := repositories.NewMovimientoCupoRepository(db)
+	licenciaRepo := repositories.NewLicenciaSenadorRepository(db)
+	calendarioFeedRepo := repositories.NewCalendarioFeedRepository(db)
+	desviacionTarifaRepo := repositories.NewDesviacionTarifaRepository(db)
+	tipoCambioRepo := repositories.NewTipoCambioRepository(db)
+	feriadoRepo := repositories.NewFeriadoRepository(db)
+	reglaDescargoRepo := repositories.NewReglaDescargoRepository(db)
+	viaticoRepo := repositories.NewViaticoRepository(db)
+	anticipoRepo := repositories.NewAnticipoRepository(db)
+	recordatorioDescargoRepo := repositories.NewRecordatorioDescargoRepository(db)
+	billeteRepo := repositories.NewBilleteRepository(db)
+	extractoBancarioRepo := repositories.NewExtractoBancarioRepository(db)
+	certificadoFirmaRepo := repositories.NewCertificadoFirmaRepository(db)
+	adjuntoRepo := repositories.NewAdjuntoRepository(db)
 
 	emailService := services.NewEmailService()
 	auditService := services.NewAuditService(auditRepo)
 	pushService := services.NewPushService(pushRepo)
 	notifService := services.NewNotificationService(notifRepo, userRepo, pushService)
 	configService := services.NewConfiguracionService(configRepo)
+	billeteService := services.NewBilleteService(billeteRepo, configService, auditService)
 	peopleService := services.NewPeopleService(peopleRepo)
 	estadoPasajeService := services.NewEstadoPasajeService(estadoPasajeRepo)
 	openTicketService := services.NewOpenTicketService(openTicketRepo, solicitudRepo, userRepo, pasajeRepo)
+	conflictoService := services.NewConflictoViajeService(solicitudItemRepo, pasajeRepo, auditService)
+	morosidadService := services.NewMorosidadService(solicitudRepo, configService, auditService)
+	cupoLedgerService := services.NewCupoLedgerService(movimientoCupoRepo, cupoRepo, itemRepo, auditService)
+	adjuntoService := services.NewAdjuntoService(adjuntoRepo, auditService)
 
-	reportService := services.NewReportService(solicitudRepo, aerolineaRepo, pasajeRepo, agenciaRepo, cupoRepo, openTicketRepo, configService)
-	cupoService := services.NewCupoService(cupoRepo, userRepo, itemRepo, solicitudRepo)
+	reportService := services.NewReportService(solicitudRepo, aerolineaRepo, pasajeRepo, agenciaRepo, cupoRepo, openTicketRepo, configService, transferenciaCupoRepo, desviacionTarifaRepo, billeteRepo)
+	cupoService := services.NewCupoService(cupoRepo, userRepo, itemRepo, solicitudRepo, politicaCupoRepo, transferenciaCupoRepo, notifService, auditService, cupoLedgerService)
 	userService := services.NewUsuarioService(userRepo, peopleRepo, deptoRepo, mongoUserRepo, rolRepo, destinoRepo, cargoRepo, oficinaRepo)
 
 	solicitudService := services.NewSolicitudService(
@@ -128,6 +166,9 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		emailService,
 		auditService,
 		openTicketRepo,
+		conflictoService,
+		cupoLedgerService,
+		morosidadService,
 	)
 	rolService := services.NewRolService(rolRepo)
 	destinoService := services.NewDestinoService(destinoRepo)
@@ -145,6 +186,7 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		destinoService,
 		openTicketRepo,
 		configService,
+		cupoService,
 	)
 	solicitudOficialService := services.NewSolicitudOficialService(
 		solicitudRepo,
@@ -157,9 +199,10 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 	)
 
 	compensacionService := services.NewCompensacionService(compensacionRepo, catCompensacionRepo)
-	descargoService := services.NewDescargoService(descargoRepo, pasajeRepo, openTicketService, solicitudService, userService, auditService)
-	descargoDerechoService := services.NewDescargoDerechoService(descargoRepo, rutaRepo, descargoService, solicitudService, auditService, pasajeRepo)
-	descargoOficialService := services.NewDescargoOficialService(descargoRepo, rutaRepo, descargoService, solicitudService, auditService, pasajeRepo)
+	anticipoService := services.NewAnticipoService(anticipoRepo, solicitudRepo, descargoRepo, auditService)
+	descargoService := services.NewDescargoService(descargoRepo, pasajeRepo, openTicketService, solicitudService, userService, auditService, billeteService, anticipoService)
+	descargoDerechoService := services.NewDescargoDerechoService(descargoRepo, rutaRepo, descargoService, solicitudService, auditService, pasajeRepo, adjuntoService)
+	descargoOficialService := services.NewDescargoOficialService(descargoRepo, rutaRepo, descargoService, solicitudService, auditService, pasajeRepo, adjuntoService)
 	organigramaService := services.NewOrganigramaService(cargoRepo, oficinaRepo)
 	tipoSolicitudService := services.NewTipoSolicitudService(tipoSolicitudRepo)
 	ambitoService := services.NewAmbitoService(ambitoRepo)
@@ -177,6 +220,13 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		generoRepo,
 	)
 
+	tarifaService := services.NewTarifaService(desviacionTarifaRepo, rutaRepo, configService, auditService)
+	tipoCambioService := services.NewTipoCambioService(tipoCambioRepo, configService, auditService)
+	viaticoService := services.NewViaticoService(viaticoRepo, descargoRepo, tipoCambioService, configService, auditService)
+	reembolsoService := services.NewReembolsoService(extractoBancarioRepo, configService, auditService)
+	feriadoService := services.NewFeriadoService(feriadoRepo, auditService)
+	reglaDescargoService := services.NewReglaDescargoService(reglaDescargoRepo, tipoSolicitudRepo, auditService)
+
 	pasajeService := services.NewPasajeService(
 		pasajeRepo,
 		solicitudRepo,
@@ -184,9 +234,19 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		rutaRepo,
 		emailService,
 		auditService,
+		tarifaService,
+		tipoCambioService,
+		billeteService,
+		morosidadService,
+		adjuntoService,
 	)
 
-	alertaService := services.NewAlertaService(solicitudRepo, descargoRepo, emailService)
+	alertaService := services.NewAlertaService(solicitudRepo, descargoRepo, recordatorioDescargoRepo, userRepo, configService, emailService)
+	cupoPeriodoService := services.NewCupoPeriodoService(cupoService, cupoRepo, userRepo, notifService)
+	politicaCupoService := services.NewPoliticaCupoService(politicaCupoRepo, cupoService, auditService)
+	licenciaService := services.NewLicenciaService(licenciaRepo, userRepo, cupoService, auditService, notifService)
+	calendarioService := services.NewCalendarioService(calendarioFeedRepo, solicitudRepo, itemRepo)
+	firmaService := services.NewFirmaService(certificadoFirmaRepo, auditService)
 
 	cupoCtrl := controllers.NewCupoController(cupoService, userService)
 
@@ -202,6 +262,7 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		aerolineaService,
 		descargoService,
 		openTicketService,
+		firmaService,
 	)
 
 	solicitudOficialCtrl := controllers.NewSolicitudOficialController(
@@ -215,6 +276,8 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		reportService,
 		peopleService,
 		descargoService,
+		anticipoService,
+		firmaService,
 	)
 
 	solicitudCtrl := controllers.NewSolicitudController(solicitudService, userService)
@@ -231,6 +294,7 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		reportService,
 		peopleService,
 		configService,
+		firmaService,
 	)
 
 	descargoOficialCtrl := controllers.NewDescargoOficialController(
@@ -241,11 +305,15 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		reportService,
 		peopleService,
 		configService,
+		viaticoService,
+		anticipoService,
+		firmaService,
 	)
 
 	authCtrl := controllers.NewAuthController(authService)
-	pasajeCtrl := controllers.NewPasajeController(agenciaService, rutaService, solicitudService, pasajeService, aerolineaService)
-	perfilCtrl := controllers.NewPerfilController(destinoService)
+	eticketService := services.NewETicketService(solicitudItemRepo, aerolineaRepo, destinoRepo)
+	pasajeCtrl := controllers.NewPasajeController(agenciaService, rutaService, solicitudService, pasajeService, aerolineaService, eticketService)
+	perfilCtrl := controllers.NewPerfilController(destinoService, calendarioService, firmaService)
 	catalogoCtrl := controllers.NewCatalogoController(tipoSolicitudService, destinoService, userService)
 
 	aerolineaCtrl := controllers.NewAerolineaController(aerolineaService)
@@ -260,6 +328,18 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 	openTicketCtrl := controllers.NewOpenTicketController(openTicketService, solicitudService)
 	reportCtrl := controllers.NewReportController(reportService, aerolineaService, agenciaService)
 	destinoCtrl := controllers.NewDestinoController(destinoService, ambitoRepo, deptoRepo)
+	politicaCupoCtrl := controllers.NewPoliticaCupoController(politicaCupoService, deptoRepo)
+	conciliacionCupoCtrl := controllers.NewConciliacionCupoController(cupoLedgerService)
+	licenciaCtrl := controllers.NewLicenciaController(licenciaService, userService)
+	calendarioCtrl := controllers.NewCalendarioController(calendarioService)
+	tipoCambioCtrl := controllers.NewTipoCambioController(tipoCambioService)
+	reembolsoCtrl := controllers.NewReembolsoController(reembolsoService)
+	feriadoCtrl := controllers.NewFeriadoController(feriadoService, deptoRepo)
+	reglaDescargoCtrl := controllers.NewReglaDescargoController(reglaDescargoService, conceptoRepo)
+	viaticoCtrl := controllers.NewViaticoController(viaticoService, ambitoService)
+	anticipoCtrl := controllers.NewAnticipoController(anticipoService)
+	firmaCtrl := controllers.NewFirmaController(firmaService)
+	adjuntoCtrl := controllers.NewAdjuntoController(adjuntoService)
 
 	return &Container{
 		// Services
@@ -289,11 +369,16 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		NotificationService:     notifService,
 		EmailService:            emailService,
 		AlertaService:           alertaService,
+		FeriadoService:          feriadoService,
+		ReglaDescargoService:    reglaDescargoService,
+		ViaticoService:          viaticoService,
 		ConceptoService:         conceptoService,
 		EstadoPasajeService:     estadoPasajeService,
 		AuditService:            auditService,
 		PushService:             pushService,
 		OpenTicketService:       openTicketService,
+		CupoPeriodoService:      cupoPeriodoService,
+		LicenciaService:         licenciaService,
 
 		// Controllers
 		CupoController:             cupoCtrl,
@@ -323,5 +408,17 @@ func NewContainer(db *gorm.DB, mongoRRHH *mongo.Database, mongoChat *mongo.Datab
 		OpenTicketController:       openTicketCtrl,
 		ReportController:           reportCtrl,
 		DestinoController:          destinoCtrl,
+		PoliticaCupoController:     politicaCupoCtrl,
+		ConciliacionCupoController: conciliacionCupoCtrl,
+		LicenciaController:         licenciaCtrl,
+		CalendarioController:       calendarioCtrl,
+		TipoCambioController:       tipoCambioCtrl,
+		ReembolsoController:        reembolsoCtrl,
+		FeriadoController:          feriadoCtrl,
+		ReglaDescargoController:    reglaDescargoCtrl,
+		ViaticoController:          viaticoCtrl,
+		AnticipoController:         anticipoCtrl,
+		FirmaController:            firmaCtrl,
+		AdjuntoController:          adjuntoCtrl,
 	}
 }
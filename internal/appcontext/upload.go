@@ -0,0 +1,32 @@
+package appcontext
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+)
+
+const uploadsKey contextKey = "uploads"
+
+// subidas relaciona la ruta guardada de cada archivo subido en la petición con su nombre original.
+type subidas map[string]string
+
+// SetUploadedFile registra el nombre original de un archivo guardado durante la petición para
+// que los servicios lo conserven en el historial del adjunto.
+func SetUploadedFile(c *gin.Context, path, originalName string) {
+	if s, ok := c.Request.Context().Value(uploadsKey).(subidas); ok {
+		s[path] = originalName
+		return
+	}
+	ctx := context.WithValue(c.Request.Context(), uploadsKey, subidas{path: originalName})
+	c.Request = c.Request.WithContext(ctx)
+}
+
+// GetUploadedFileName devuelve el nombre original del archivo si se subió en esta petición.
+func GetUploadedFileName(ctx context.Context, path string) (string, bool) {
+	if s, ok := ctx.Value(uploadsKey).(subidas); ok {
+		name, found := s[path]
+		return name, found
+	}
+	return "", false
+}
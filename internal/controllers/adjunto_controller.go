@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AdjuntoController struct {
+	service *services.AdjuntoService
+}
+
+func NewAdjuntoController(service *services.AdjuntoService) *AdjuntoController {
+	return &AdjuntoController{service: service}
+}
+
+func (ctrl *AdjuntoController) Historial(c *gin.Context) {
+	adjunto, err := ctrl.service.GetHistorial(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "Adjunto no encontrado")
+		return
+	}
+
+	authUser := appcontext.AuthUser(c)
+	utils.Render(c, "components/modal_historial_adjunto", gin.H{
+		"Adjunto":        adjunto,
+		"PuedeRestaurar": authUser != nil && authUser.IsAdminOrResponsable(),
+	})
+}
+
+// Restaurar vuelve a poner vigente una versión anterior; solo administradores y responsables.
+func (ctrl *AdjuntoController) Restaurar(c *gin.Context) {
+	returnURL := c.Request.Referer()
+
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil || !authUser.IsAdminOrResponsable() {
+		utils.SetErrorMessage(c, "No tiene permiso para restaurar versiones de adjuntos")
+		c.Redirect(http.StatusFound, returnURL)
+		return
+	}
+
+	var req dtos.RestaurarAdjuntoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Versión inválida")
+		c.Redirect(http.StatusFound, returnURL)
+		return
+	}
+
+	adjunto, err := ctrl.service.Restaurar(c.Request.Context(), c.Param("id"), req.Version)
+	if err != nil {
+		utils.SetErrorMessage(c, "No se pudo restaurar la versión: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, fmt.Sprintf("%s restaurado a la versión %d", adjunto.GetEtiqueta(), req.Version))
+	}
+	c.Redirect(http.StatusFound, returnURL)
+}
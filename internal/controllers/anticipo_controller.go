@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnticipoController struct {
+	service *services.AnticipoService
+}
+
+func NewAnticipoController(service *services.AnticipoService) *AnticipoController {
+	return &AnticipoController{service: service}
+}
+
+func (ctrl *AnticipoController) Index(c *gin.Context) {
+	estado := c.DefaultQuery("estado", models.EstadoAnticipoSolicitado)
+	if estado == "TODOS" {
+		estado = ""
+	}
+	anticipos, _ := ctrl.service.GetByEstado(c.Request.Context(), estado)
+
+	utils.Render(c, "admin/anticipos", gin.H{
+		"Title":     "Anticipos de Comisión",
+		"Anticipos": anticipos,
+		"Estado":    c.DefaultQuery("estado", models.EstadoAnticipoSolicitado),
+	})
+}
+
+func (ctrl *AnticipoController) Solicitar(c *gin.Context) {
+	solicitudID := c.Param("id")
+	redirect := "/solicitudes/oficial/" + solicitudID + "/detalle"
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	var req dtos.SolicitarAnticipoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Indique el monto y el concepto del anticipo")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	if err := ctrl.service.Solicitar(c.Request.Context(), solicitudID, req, authUser); err != nil {
+		utils.SetErrorMessage(c, "No se pudo solicitar el anticipo: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Anticipo solicitado")
+	}
+	c.Redirect(http.StatusFound, redirect)
+}
+
+func (ctrl *AnticipoController) Aprobar(c *gin.Context) {
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil || !authUser.IsAdminOrResponsable() {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+	anticipo, err := ctrl.service.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/admin/anticipos")
+		return
+	}
+	redirect := "/solicitudes/oficial/" + anticipo.SolicitudID + "/detalle"
+
+	var req dtos.AprobarAnticipoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Indique el monto aprobado")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	if err := ctrl.service.Aprobar(c.Request.Context(), anticipo.ID, req, authUser); err != nil {
+		utils.SetErrorMessage(c, "No se pudo aprobar el anticipo: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, fmt.Sprintf("Anticipo aprobado por Bs %.2f", utils.ParseFloat(req.MontoAprobado)))
+	}
+	c.Redirect(http.StatusFound, redirect)
+}
+
+func (ctrl *AnticipoController) Rechazar(c *gin.Context) {
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil || !authUser.IsAdminOrResponsable() {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+	anticipo, err := ctrl.service.GetByID(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/admin/anticipos")
+		return
+	}
+	redirect := "/solicitudes/oficial/" + anticipo.SolicitudID + "/detalle"
+
+	var req dtos.RechazarAnticipoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Indique el motivo del rechazo")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	if err := ctrl.service.Rechazar(c.Request.Context(), anticipo.ID, req, authUser); err != nil {
+		utils.SetErrorMessage(c, "No se pudo rechazar el anticipo: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Anticipo rechazado")
+	}
+	c.Redirect(http.StatusFound, redirect)
+}
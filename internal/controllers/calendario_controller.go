@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CalendarioController struct {
+	service *services.CalendarioService
+}
+
+func NewCalendarioController(service *services.CalendarioService) *CalendarioController {
+	return &CalendarioController{service: service}
+}
+
+// Feed sirve el calendario ICS. Es público: el token de la URL es la única credencial, ya que
+// las aplicaciones de calendario no manejan la sesión del sistema.
+func (ctrl *CalendarioController) Feed(c *gin.Context) {
+	token := strings.TrimSuffix(c.Param("token"), ".ics")
+	data, err := ctrl.service.GenerarICS(c.Request.Context(), token)
+	if err != nil {
+		c.Status(http.StatusNotFound)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
+	c.Header("Content-Disposition", "inline; filename=\"pasajes.ics\"")
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", data)
+}
+
+func (ctrl *CalendarioController) Regenerar(c *gin.Context) {
+	user := appcontext.AuthUser(c)
+	if _, err := ctrl.service.RegenerarToken(c.Request.Context(), user.ID); err != nil {
+		utils.SetErrorMessage(c, "No se pudo regenerar el enlace del calendario: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Enlace del calendario regenerado. Actualice la suscripción en sus dispositivos.")
+	}
+	c.Redirect(http.StatusFound, "/perfil")
+}
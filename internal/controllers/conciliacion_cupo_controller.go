@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConciliacionCupoController struct {
+	ledger *services.CupoLedgerService
+}
+
+func NewConciliacionCupoController(ledger *services.CupoLedgerService) *ConciliacionCupoController {
+	return &ConciliacionCupoController{ledger: ledger}
+}
+
+func (ctrl *ConciliacionCupoController) Index(c *gin.Context) {
+	gestion, _ := strconv.Atoi(c.Query("gestion"))
+	if gestion <= 0 {
+		gestion = time.Now().Year()
+	}
+
+	periodos, err := ctrl.ledger.Conciliar(c.Request.Context(), gestion)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error al conciliar cupos: "+err.Error())
+	}
+
+	utils.Render(c, "admin/conciliacion_cupos", gin.H{
+		"Title":    "Conciliación de Cupos",
+		"Gestion":  gestion,
+		"Periodos": periodos,
+	})
+}
+
+func (ctrl *ConciliacionCupoController) Corregir(c *gin.Context) {
+	gestion, _ := strconv.Atoi(c.PostForm("gestion"))
+	mes, _ := strconv.Atoi(c.PostForm("mes"))
+	targetURL := fmt.Sprintf("/admin/cupos/conciliacion?gestion=%d", gestion)
+
+	if gestion <= 0 || mes < 1 || mes > 12 {
+		utils.SetErrorMessage(c, "Periodo inválido")
+		c.Redirect(http.StatusFound, targetURL)
+		return
+	}
+
+	corregidos, err := ctrl.ledger.CorregirPeriodo(c.Request.Context(), gestion, mes)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error al corregir el periodo: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, fmt.Sprintf("%s %d: %d cupo(s) conciliado(s)", utils.GetMonthName(mes), gestion, corregidos))
+	}
+	c.Redirect(http.StatusFound, targetURL)
+}
@@ -118,7 +118,7 @@ func (ctrl *CupoController) TomarCupo(c *gin.Context) {
 	req.Motivo = "Tomado por el propio suplente"
 
 	targetUserID := authUser.ID
-	err := ctrl.service.TransferirCupoDerecho(c.Request.Context(), req.ItemID, targetUserID, req.Motivo)
+	err := ctrl.service.TransferirCupoDerecho(c.Request.Context(), req.ItemID, targetUserID, req.Motivo, authUser)
 	if err != nil {
 		c.String(http.StatusInternalServerError, "Error: "+err.Error())
 		return
@@ -160,7 +160,7 @@ func (ctrl *CupoController) AsignarCupo(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.service.TransferirCupoDerecho(c.Request.Context(), req.ItemID, targetUserID, req.Motivo)
+	err = ctrl.service.TransferirCupoDerecho(c.Request.Context(), req.ItemID, targetUserID, req.Motivo, authUser)
 	if err != nil {
 		c.String(http.StatusInternalServerError, "Error: "+err.Error())
 		return
@@ -187,7 +187,7 @@ func (ctrl *CupoController) Transferir(c *gin.Context) {
 	}
 
 	targetUserID := req.TargetUserID
-	err := ctrl.service.TransferirCupoDerecho(c.Request.Context(), req.ItemID, targetUserID, req.Motivo)
+	err := ctrl.service.TransferirCupoDerecho(c.Request.Context(), req.ItemID, targetUserID, req.Motivo, authUser)
 	if err != nil {
 		log.Printf("Error transfiriendo cupo derecho: %v\n", err)
 	}
@@ -200,6 +200,69 @@ func (ctrl *CupoController) Transferir(c *gin.Context) {
 	c.Redirect(http.StatusFound, targetURL)
 }
 
+func (ctrl *CupoController) SolicitarTransferencia(c *gin.Context) {
+	var req dtos.TransferirCupoDerechoItemRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos")
+		c.Redirect(http.StatusFound, c.Request.Referer())
+		return
+	}
+
+	targetURL := c.Request.Referer()
+	if req.ReturnURL != "" {
+		targetURL = req.ReturnURL
+	}
+
+	authUser := appcontext.AuthUser(c)
+	if _, err := ctrl.service.SolicitarTransferencia(c.Request.Context(), req.ItemID, req.TargetUserID, req.Motivo, authUser); err != nil {
+		utils.SetErrorMessage(c, err.Error())
+		c.Redirect(http.StatusFound, targetURL)
+		return
+	}
+
+	utils.SetSuccessMessage(c, "Solicitud de transferencia registrada. Queda pendiente de aprobación.")
+	c.Redirect(http.StatusFound, targetURL)
+}
+
+func (ctrl *CupoController) Transferencias(c *gin.Context) {
+	pendientes, _ := ctrl.service.GetTransferenciasPendientes(c.Request.Context())
+	recientes, _ := ctrl.service.GetTransferenciasRecientes(c.Request.Context(), 50)
+
+	utils.Render(c, "admin/transferencias_cupo", gin.H{
+		"Title":      "Transferencias de Cupo",
+		"Pendientes": pendientes,
+		"Recientes":  recientes,
+	})
+}
+
+func (ctrl *CupoController) AprobarTransferencia(c *gin.Context) {
+	if err := ctrl.service.AprobarTransferencia(c.Request.Context(), c.Param("id"), appcontext.AuthUser(c)); err != nil {
+		utils.SetErrorMessage(c, "No se pudo aprobar la transferencia: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Transferencia aprobada")
+	}
+	c.Redirect(http.StatusFound, "/admin/cupos/transferencias")
+}
+
+func (ctrl *CupoController) RechazarTransferencia(c *gin.Context) {
+	observacion := strings.TrimSpace(c.GetHeader("HX-Prompt"))
+	if observacion == "" {
+		observacion = strings.TrimSpace(c.PostForm("observacion"))
+	}
+	if observacion == "" {
+		utils.SetErrorMessage(c, "Debe indicar el motivo del rechazo")
+		c.Redirect(http.StatusFound, "/admin/cupos/transferencias")
+		return
+	}
+
+	if err := ctrl.service.RechazarTransferencia(c.Request.Context(), c.Param("id"), observacion, appcontext.AuthUser(c)); err != nil {
+		utils.SetErrorMessage(c, "No se pudo rechazar la transferencia: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Transferencia rechazada")
+	}
+	c.Redirect(http.StatusFound, "/admin/cupos/transferencias")
+}
+
 func (ctrl *CupoController) RevertirTransferencia(c *gin.Context) {
 	itemID := c.Param("id")
 	gestion := c.Query("gestion")
@@ -218,7 +281,7 @@ func (ctrl *CupoController) RevertirTransferencia(c *gin.Context) {
 		return
 	}
 
-	err = ctrl.service.RevertirTransferencia(c.Request.Context(), itemID)
+	err = ctrl.service.RevertirTransferencia(c.Request.Context(), itemID, authUser)
 	if err != nil {
 		fmt.Printf("Error revirtiendo transferencia: %v\n", err)
 		if c.GetHeader("HX-Request") == "true" {
@@ -305,12 +368,16 @@ func (ctrl *CupoController) GetTransferModal(c *gin.Context) {
 	}
 
 	authUser := appcontext.AuthUser(c)
-	if !authUser.IsAdminOrResponsable() && authUser.ID != item.SenTitularID {
+	senador, err := ctrl.userService.GetByID(c.Request.Context(), item.SenTitularID)
+	if err != nil || senador == nil {
+		c.String(http.StatusNotFound, "Titular del derecho no encontrado")
+		return
+	}
+	if !authUser.IsAdminOrResponsable() && authUser.ID != item.SenTitularID && !senador.IsManagedBy(authUser) {
 		c.String(http.StatusForbidden, "No tiene permiso para transferir este derecho")
 		return
 	}
 
-	senador, _ := ctrl.userService.GetByID(c.Request.Context(), item.SenTitularID)
 	suplente, _ := ctrl.userService.GetSuplenteByTitularID(c.Request.Context(), item.SenTitularID)
 
 	var candidates []models.Usuario
@@ -328,13 +395,14 @@ func (ctrl *CupoController) GetTransferModal(c *gin.Context) {
 	}
 
 	utils.Render(c, "admin/components/modal_transferir_derecho", gin.H{
-		"Item":       item,
-		"Senador":    senador,
-		"Candidates": candidates,
-		"Gestion":    gestion,
-		"Mes":        mesStr,
-		"MesName":    mesName,
-		"ReturnURL":  c.Request.Referer(),
+		"Item":        item,
+		"Senador":     senador,
+		"Candidates":  candidates,
+		"Gestion":     gestion,
+		"Mes":         mesStr,
+		"MesName":     mesName,
+		"ReturnURL":   c.Request.Referer(),
+		"EsSolicitud": !authUser.IsAdminOrResponsable(),
 	})
 }
 
@@ -376,6 +444,8 @@ func (ctrl *CupoController) DerechoByYear(c *gin.Context) {
 	items, _ := ctrl.service.GetCuposDerechoByUsuarioAndGestion(c.Request.Context(), idParaCupos, gestion)
 
 	monthGroups := ctrl.service.BuildMonthGroups(items, targetUser, authUser)
+	ctrl.service.ApplyPoliticas(c.Request.Context(), monthGroups, gestion)
+	ctrl.service.ApplyTransferenciasPendientes(c.Request.Context(), monthGroups)
 	displayMonths := ctrl.service.GetDisplayMonths(monthGroups, gestion)
 
 	utils.Render(c, "cupo/derecho", gin.H{
@@ -431,6 +501,8 @@ func (ctrl *CupoController) DerechoByMonth(c *gin.Context) {
 	}
 
 	monthGroups := ctrl.service.BuildMonthGroups(items, targetUser, authUser)
+	ctrl.service.ApplyPoliticas(c.Request.Context(), monthGroups, gestion)
+	ctrl.service.ApplyTransferenciasPendientes(c.Request.Context(), monthGroups)
 
 	var displayMonths []*services.MonthGroup
 	for _, mg := range monthGroups {
@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -25,6 +27,7 @@ type DescargoDerechoController struct {
 	reportService          *services.ReportService
 	peopleService          *services.PeopleService
 	configService          *services.ConfiguracionService
+	firmaService           *services.FirmaService
 }
 
 func NewDescargoDerechoController(
@@ -34,6 +37,7 @@ func NewDescargoDerechoController(
 	reportService *services.ReportService,
 	peopleService *services.PeopleService,
 	configService *services.ConfiguracionService,
+	firmaService *services.FirmaService,
 ) *DescargoDerechoController {
 	return &DescargoDerechoController{
 		descargoService:        descargoService,
@@ -42,6 +46,7 @@ func NewDescargoDerechoController(
 		reportService:          reportService,
 		peopleService:          peopleService,
 		configService:          configService,
+		firmaService:           firmaService,
 	}
 }
 
@@ -203,6 +208,9 @@ func (ctrl *DescargoDerechoController) Update(c *gin.Context) {
 		return
 	}
 
+	if utils.FlashUploadErrors(c) {
+		utils.SetErrorMessage(c, "Los cambios se guardaron, pero algunos archivos fueron rechazados y se conservó el anterior. Revise los campos marcados.")
+	}
 	c.Redirect(http.StatusFound, "/descargos/derecho/"+id+"/editar")
 }
 
@@ -247,6 +255,11 @@ func (ctrl *DescargoDerechoController) UpdateUtilizacion(c *gin.Context) {
 		return
 	}
 
+	if utils.FlashUploadErrors(c) {
+		utils.SetErrorMessage(c, "Los cambios se guardaron, pero algunos archivos fueron rechazados y se conservó el anterior. Revise los campos marcados.")
+		c.Redirect(http.StatusFound, "/descargos/derecho/"+id+"/completar")
+		return
+	}
 	c.Redirect(http.StatusFound, "/descargos/derecho/"+id+"/completar?success=TramosActualizados")
 }
 
@@ -275,6 +288,48 @@ func (ctrl *DescargoDerechoController) Print(c *gin.Context) {
 	c.Writer.Write(pdfReader)
 }
 
+// Firmar genera el PV-05 con sus adjuntos y lo devuelve firmado digitalmente con el certificado
+// del usuario.
+func (ctrl *DescargoDerechoController) Firmar(c *gin.Context) {
+	id := c.Param("id")
+	redirect := "/descargos/derecho/" + id
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	descargo, err := ctrl.descargoService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.String(http.StatusNotFound, "Descargo no encontrado")
+		return
+	}
+	if !descargo.CanFirmar(authUser) {
+		c.String(http.StatusForbidden, "No tiene permiso para firmar este formulario")
+		return
+	}
+
+	var req dtos.FirmarDocumentoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Ingrese la contraseña de su certificado")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	personaView, _ := ctrl.peopleService.GetSenatorDataByCI(c.Request.Context(), descargo.Solicitud.Usuario.CI)
+	data, err := ctrl.firmaService.FirmarFormulario(c.Request.Context(), authUser, req.Password, "PV-05 "+descargo.Codigo, func(ctx context.Context) ([]byte, error) {
+		return ctrl.reportService.GeneratePV05Complete(ctx, descargo, personaView)
+	})
+	if err != nil {
+		utils.SetErrorMessage(c, "No se pudo firmar el formulario: "+err.Error())
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"FORM-PV05-%s-firmado.pdf\"", descargo.ID))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
 func (ctrl *DescargoDerechoController) PrintOpenTicket(c *gin.Context) {
 	id := c.Param("id")
 	descargo, err := ctrl.descargoService.GetByID(c.Request.Context(), id)
@@ -420,6 +475,32 @@ func (ctrl *DescargoDerechoController) Approve(c *gin.Context) {
 	c.Redirect(http.StatusFound, "/descargos/derecho/"+id)
 }
 
+// VerificarPases vuelve a leer los códigos de barras de los pases de abordar antes de aprobar.
+func (ctrl *DescargoDerechoController) VerificarPases(c *gin.Context) {
+	id := c.Param("id")
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil || !authUser.IsAdminOrResponsable() {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	descargo, err := ctrl.descargoService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/descargos/derecho")
+		return
+	}
+
+	if err := ctrl.descargoService.VerificarPasesAbordo(c.Request.Context(), descargo); err != nil {
+		log.Printf("Error verificando pases de abordar: %v", err)
+		utils.SetErrorMessage(c, "Error al verificar los pases de abordar: "+err.Error())
+	} else if n := len(descargo.GetTramosConDiscrepanciaPase()); n > 0 {
+		utils.SetErrorMessage(c, fmt.Sprintf("Atención: %d pase(s) de abordar no coinciden con lo declarado", n))
+	} else {
+		utils.SetSuccessMessage(c, "Pases de abordar verificados")
+	}
+	c.Redirect(http.StatusFound, "/descargos/derecho/"+id)
+}
+
 func (ctrl *DescargoDerechoController) Reject(ctx *gin.Context) {
 	id := ctx.Param("id")
 	authUser := appcontext.AuthUser(ctx)
@@ -578,7 +659,12 @@ func (ctrl *DescargoDerechoController) UploadSingle(c *gin.Context) {
 	}
 
 	timestamp := time.Now().UnixNano()
-	savedPath, err := utils.SaveUploadedFile(c, file, "uploads/pases_abordo", fmt.Sprintf("fast_upload_%d_", timestamp))
+	savedPath, err := utils.SaveUploadedFile(c, file, "uploads/pases_abordo", fmt.Sprintf("fast_upload_%d_", timestamp), utils.UploadPDF)
+	var rechazo *utils.UploadError
+	if errors.As(err, &rechazo) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": rechazo.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Error al guardar el archivo: " + err.Error()})
 		return
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -21,6 +22,9 @@ type DescargoOficialController struct {
 	reportService          *services.ReportService
 	peopleService          *services.PeopleService
 	configService          *services.ConfiguracionService
+	viaticoService         *services.ViaticoService
+	anticipoService        *services.AnticipoService
+	firmaService           *services.FirmaService
 }
 
 func NewDescargoOficialController(
@@ -31,6 +35,9 @@ func NewDescargoOficialController(
 	reportService *services.ReportService,
 	peopleService *services.PeopleService,
 	configService *services.ConfiguracionService,
+	viaticoService *services.ViaticoService,
+	anticipoService *services.AnticipoService,
+	firmaService *services.FirmaService,
 ) *DescargoOficialController {
 	return &DescargoOficialController{
 		descargoService:        descargoService,
@@ -39,6 +46,9 @@ func NewDescargoOficialController(
 		reportService:          reportService,
 		peopleService:          peopleService,
 		configService:          configService,
+		viaticoService:         viaticoService,
+		anticipoService:        anticipoService,
+		firmaService:           firmaService,
 	}
 }
 
@@ -89,6 +99,13 @@ func (ctrl *DescargoOficialController) Show(c *gin.Context) {
 		descargo.Solicitud.HydratePermissions(authUser)
 	}
 
+	puedeCalcularViatico := authUser != nil && authUser.IsAdminOrResponsable() && descargo.Oficial != nil && descargo.PermiteLiquidarViatico()
+	var zonasViatico []models.ZonaViatico
+	if puedeCalcularViatico {
+		zonasViatico, _ = ctrl.viaticoService.GetZonas(c.Request.Context())
+	}
+	anticipo, _ := ctrl.anticipoService.GetParaDescargo(c.Request.Context(), descargo)
+
 	utils.Render(c, "descargo/oficial/show", gin.H{
 		"Title":                     "Detalle de Descargo (Oficial)",
 		"Descargo":                  descargo,
@@ -99,6 +116,9 @@ func (ctrl *DescargoOficialController) Show(c *gin.Context) {
 		"BancoCuenta":               bancoCuenta,
 		"BancoNombre":               bancoNombre,
 		"User":                      authUser,
+		"ZonasViatico":              zonasViatico,
+		"PuedeCalcularViatico":      puedeCalcularViatico,
+		"Anticipo":                  anticipo,
 	})
 }
 
@@ -122,6 +142,7 @@ func (ctrl *DescargoOficialController) Edit(c *gin.Context) {
 
 	tramosIdaOrig, tramosIdaRepro, tramosVueltaOrig, tramosVueltaRepro := ctrl.descargoOficialService.ClassifyTramos(descargo)
 	bancoCuenta, bancoNombre := ctrl.configService.GetBankDefaults(c.Request.Context())
+	anticipo, _ := ctrl.anticipoService.GetParaDescargo(c.Request.Context(), descargo)
 
 	utils.Render(c, "descargo/oficial/edit", gin.H{
 		"Title":                     "Editar Descargo (Oficial)",
@@ -134,6 +155,7 @@ func (ctrl *DescargoOficialController) Edit(c *gin.Context) {
 		"BancoCuenta":               bancoCuenta,
 		"BancoNombre":               bancoNombre,
 		"User":                      authUser,
+		"Anticipo":                  anticipo,
 	})
 }
 
@@ -170,14 +192,19 @@ func (ctrl *DescargoOficialController) Update(c *gin.Context) {
 	anexoPaths := utils.ExtractDescargoAnexos(c, id)
 	boletasPaths := utils.ExtractPasajeBoletas(c, req.LiquidacionPasajeID)
 	memoPath := utils.ExtractMemorandumFile(c, id)
+	boletaDepositoPath := utils.ExtractBoletaDepositoFile(c, id)
 
-	if err := ctrl.descargoOficialService.UpdateOficial(c.Request.Context(), id, req, authUser.ID, pasesAbordoPaths, terrestrePaths, anexoPaths, boletasPaths, memoPath); err != nil {
+	if err := ctrl.descargoOficialService.UpdateOficial(c.Request.Context(), id, req, authUser.ID, pasesAbordoPaths, terrestrePaths, anexoPaths, boletasPaths, memoPath, boletaDepositoPath); err != nil {
 		utils.SetErrorMessage(c, "Error al actualizar el descargo oficial: "+err.Error())
 		c.Redirect(http.StatusFound, "/descargos/oficial/"+id+"/editar")
 		return
 	}
 
-	utils.SetSuccessMessage(c, "Descargo oficial actualizado correctamente")
+	if utils.FlashUploadErrors(c) {
+		utils.SetErrorMessage(c, "Los cambios se guardaron, pero algunos archivos fueron rechazados y se conservó el anterior. Revise los campos marcados.")
+	} else {
+		utils.SetSuccessMessage(c, "Descargo oficial actualizado correctamente")
+	}
 	c.Redirect(http.StatusFound, "/descargos/oficial/"+id+"/editar")
 }
 
@@ -207,6 +234,48 @@ func (ctrl *DescargoOficialController) Print(c *gin.Context) {
 	c.Writer.Write(pdf)
 }
 
+// Firmar genera el PV-06 con sus adjuntos y lo devuelve firmado digitalmente con el certificado
+// del usuario.
+func (ctrl *DescargoOficialController) Firmar(c *gin.Context) {
+	id := c.Param("id")
+	redirect := "/descargos/oficial/" + id
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	descargo, err := ctrl.descargoService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.String(http.StatusNotFound, "Descargo no encontrado")
+		return
+	}
+	if !descargo.CanFirmar(authUser) {
+		c.String(http.StatusForbidden, "No tiene permiso para firmar este formulario")
+		return
+	}
+
+	var req dtos.FirmarDocumentoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Ingrese la contraseña de su certificado")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	personaView, _ := ctrl.peopleService.GetSenatorDataByCI(c.Request.Context(), descargo.Solicitud.Usuario.CI)
+	data, err := ctrl.firmaService.FirmarFormulario(c.Request.Context(), authUser, req.Password, "PV-06 "+descargo.Codigo, func(ctx context.Context) ([]byte, error) {
+		return ctrl.reportService.GeneratePV06Complete(ctx, descargo, personaView)
+	})
+	if err != nil {
+		utils.SetErrorMessage(c, "No se pudo firmar el formulario: "+err.Error())
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"FORM-PV06-%s-firmado.pdf\"", descargo.ID))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
 func (ctrl *DescargoOficialController) Preview(c *gin.Context) {
 	id := c.Param("id")
 	c.HTML(http.StatusOK, "solicitud/components/modal_preview_archivo", gin.H{
@@ -235,6 +304,7 @@ func (ctrl *DescargoOficialController) Submit(c *gin.Context) {
 
 	if err := ctrl.descargoService.Submit(c.Request.Context(), id, authUser.ID); err != nil {
 		log.Printf("Error enviando descargo oficial: %v", err)
+		utils.SetErrorMessage(c, "No se pudo enviar el descargo: "+err.Error())
 		c.Redirect(http.StatusFound, "/descargos/oficial/"+id+"?error=ErrorEnvio")
 		return
 	}
@@ -261,10 +331,37 @@ func (ctrl *DescargoOficialController) Approve(c *gin.Context) {
 
 	if err := ctrl.descargoService.Approve(c.Request.Context(), id, authUser.ID); err != nil {
 		log.Printf("Error aprobando descargo oficial: %v", err)
-		c.Redirect(http.StatusFound, "/descargos/oficial/"+id+"?error=ErrorAprobacion")
+		utils.SetErrorMessage(c, "No se pudo aprobar el descargo: "+err.Error())
+		c.Redirect(http.StatusFound, "/descargos/oficial/"+id)
+		return
+	}
+
+	c.Redirect(http.StatusFound, "/descargos/oficial/"+id)
+}
+
+// VerificarPases vuelve a leer los códigos de barras de los pases de abordar antes de aprobar.
+func (ctrl *DescargoOficialController) VerificarPases(c *gin.Context) {
+	id := c.Param("id")
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil || !authUser.IsAdminOrResponsable() {
+		c.Redirect(http.StatusFound, "/auth/login")
 		return
 	}
 
+	descargo, err := ctrl.descargoService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/descargos/oficial")
+		return
+	}
+
+	if err := ctrl.descargoService.VerificarPasesAbordo(c.Request.Context(), descargo); err != nil {
+		log.Printf("Error verificando pases de abordar: %v", err)
+		utils.SetErrorMessage(c, "Error al verificar los pases de abordar: "+err.Error())
+	} else if n := len(descargo.GetTramosConDiscrepanciaPase()); n > 0 {
+		utils.SetErrorMessage(c, fmt.Sprintf("Atención: %d pase(s) de abordar no coinciden con lo declarado", n))
+	} else {
+		utils.SetSuccessMessage(c, "Pases de abordar verificados")
+	}
 	c.Redirect(http.StatusFound, "/descargos/oficial/"+id)
 }
 
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeriadoController struct {
+	service   *services.FeriadoService
+	deptoRepo *repositories.DepartamentoRepository
+}
+
+func NewFeriadoController(service *services.FeriadoService, deptoRepo *repositories.DepartamentoRepository) *FeriadoController {
+	return &FeriadoController{service: service, deptoRepo: deptoRepo}
+}
+
+func (ctrl *FeriadoController) Index(c *gin.Context) {
+	anio := utils.StrToInt(c.Query("anio"), time.Now().Year())
+	feriados, _ := ctrl.service.GetAll(c.Request.Context())
+	calendario, _ := ctrl.service.GetCalendario(c.Request.Context(), anio)
+	departamentos, _ := ctrl.deptoRepo.FindAll(c.Request.Context())
+
+	utils.Render(c, "admin/feriados", gin.H{
+		"Title":         "Feriados",
+		"Feriados":      feriados,
+		"Calendario":    calendario,
+		"Departamentos": departamentos,
+		"Anio":          anio,
+		"Meses":         utils.GetMonthNames()[1:],
+		"PlazoDias":     models.PlazoDescargoDiasHabiles,
+	})
+}
+
+func (ctrl *FeriadoController) Store(c *gin.Context) {
+	var req dtos.CreateFeriadoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos: nombre y tipo son obligatorios")
+		c.Redirect(http.StatusFound, "/admin/feriados")
+		return
+	}
+
+	if err := ctrl.service.Create(c.Request.Context(), req, appcontext.AuthUser(c)); err != nil {
+		utils.SetErrorMessage(c, err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Feriado registrado")
+	}
+	c.Redirect(http.StatusFound, "/admin/feriados")
+}
+
+func (ctrl *FeriadoController) Toggle(c *gin.Context) {
+	activo := c.PostForm("activo") == "true"
+	if err := ctrl.service.SetActivo(c.Request.Context(), c.Param("id"), activo); err != nil {
+		utils.SetErrorMessage(c, "Error al actualizar: "+err.Error())
+	} else if activo {
+		utils.SetSuccessMessage(c, "Feriado activado")
+	} else {
+		utils.SetSuccessMessage(c, "Feriado desactivado")
+	}
+	c.Redirect(http.StatusFound, "/admin/feriados")
+}
+
+func (ctrl *FeriadoController) Delete(c *gin.Context) {
+	if err := ctrl.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		utils.SetErrorMessage(c, "Error al eliminar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Feriado eliminado")
+	}
+	c.Redirect(http.StatusFound, "/admin/feriados")
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxCertificadoBytes      = 64 << 10
+	maxDocumentoFirmadoBytes = 30 << 20
+)
+
+type FirmaController struct {
+	service *services.FirmaService
+}
+
+func NewFirmaController(service *services.FirmaService) *FirmaController {
+	return &FirmaController{service: service}
+}
+
+func (ctrl *FirmaController) RegistrarCertificado(c *gin.Context) {
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	var req dtos.RegistrarCertificadoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Ingrese la contraseña del certificado")
+		c.Redirect(http.StatusFound, "/perfil")
+		return
+	}
+	data, err := leerArchivoSubido(c, "archivo_certificado", maxCertificadoBytes)
+	if err != nil {
+		utils.SetErrorMessage(c, "Seleccione el archivo .p12 o .pfx de su certificado")
+		c.Redirect(http.StatusFound, "/perfil")
+		return
+	}
+
+	cert, err := ctrl.service.RegistrarCertificado(c.Request.Context(), authUser.ID, data, req.Password)
+	if err != nil {
+		utils.SetErrorMessage(c, "No se pudo registrar el certificado: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Certificado de "+cert.Sujeto+" registrado para firma digital")
+	}
+	c.Redirect(http.StatusFound, "/perfil")
+}
+
+func (ctrl *FirmaController) EliminarCertificado(c *gin.Context) {
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	if err := ctrl.service.EliminarCertificado(c.Request.Context(), authUser.ID); err != nil {
+		utils.SetErrorMessage(c, "No se pudo eliminar el certificado: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Certificado eliminado")
+	}
+	c.Redirect(http.StatusFound, "/perfil")
+}
+
+func (ctrl *FirmaController) Verificar(c *gin.Context) {
+	utils.Render(c, "firma/verificar", gin.H{
+		"Title": "Verificar Firmas",
+	})
+}
+
+// VerificarDocumento valida las firmas de un PDF cargado; el archivo no se guarda.
+func (ctrl *FirmaController) VerificarDocumento(c *gin.Context) {
+	data := gin.H{"Title": "Verificar Firmas"}
+
+	file, err := c.FormFile("documento")
+	if err != nil {
+		data["Error"] = "Seleccione el PDF firmado que desea verificar"
+		utils.Render(c, "firma/verificar", data)
+		return
+	}
+	data["Archivo"] = file.Filename
+
+	// Los documentos firmados pesan más que un adjunto común: se amplía solo el tamaño.
+	regla := utils.UploadPDF
+	regla.MaxBytes = maxDocumentoFirmadoBytes
+	contenido, err := utils.ReadUploadedFile(c, file, regla)
+	if err != nil {
+		var rechazo *utils.UploadError
+		if errors.As(err, &rechazo) {
+			data["Error"] = "No se pudo verificar el archivo: " + rechazo.Reason
+		} else {
+			data["Error"] = "No se pudo leer el archivo"
+		}
+		utils.Render(c, "firma/verificar", data)
+		return
+	}
+
+	resultados, err := ctrl.service.Verificar(contenido)
+	if err != nil {
+		data["Error"] = err.Error()
+	}
+	data["Resultados"] = resultados
+	utils.Render(c, "firma/verificar", data)
+}
+
+func leerArchivoSubido(c *gin.Context, campo string, max int64) ([]byte, error) {
+	file, err := c.FormFile(campo)
+	if err != nil {
+		return nil, err
+	}
+	if file.Size > max {
+		return nil, errors.New("el archivo supera el tamaño permitido")
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(io.LimitReader(f, max))
+}
@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LicenciaController struct {
+	service     *services.LicenciaService
+	userService *services.UsuarioService
+}
+
+func NewLicenciaController(service *services.LicenciaService, userService *services.UsuarioService) *LicenciaController {
+	return &LicenciaController{
+		service:     service,
+		userService: userService,
+	}
+}
+
+func (ctrl *LicenciaController) Index(c *gin.Context) {
+	licencias, _ := ctrl.service.GetAll(c.Request.Context())
+	senadores, _ := ctrl.userService.GetByRoleType(c.Request.Context(), models.RolSenador)
+
+	utils.Render(c, "admin/licencias", gin.H{
+		"Title":     "Licencias de Senadores",
+		"Licencias": licencias,
+		"Titulares": senadores,
+	})
+}
+
+func (ctrl *LicenciaController) Store(c *gin.Context) {
+	var req dtos.CreateLicenciaRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos: titular y fechas son obligatorios")
+		c.Redirect(http.StatusFound, "/admin/licencias")
+		return
+	}
+
+	lic, err := ctrl.service.Crear(c.Request.Context(), req, appcontext.AuthUser(c))
+	if err != nil && lic == nil {
+		utils.SetErrorMessage(c, err.Error())
+		c.Redirect(http.StatusFound, "/admin/licencias")
+		return
+	}
+	if err != nil {
+		utils.SetErrorMessage(c, "Licencia registrada, pero no se pudieron asignar todos los cupos: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Licencia registrada")
+	}
+	c.Redirect(http.StatusFound, "/admin/licencias")
+}
+
+func (ctrl *LicenciaController) Anular(c *gin.Context) {
+	if err := ctrl.service.Anular(c.Request.Context(), c.Param("id")); err != nil {
+		utils.SetErrorMessage(c, "Error al anular la licencia: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Licencia anulada. Los cupos no usados volvieron al titular.")
+	}
+	c.Redirect(http.StatusFound, "/admin/licencias")
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -19,6 +20,7 @@ type PasajeController struct {
 	solicitudService *services.SolicitudService
 	pasajeService    *services.PasajeService
 	aerolineaService *services.AerolineaService
+	eticketService   *services.ETicketService
 }
 
 func NewPasajeController(
@@ -27,6 +29,7 @@ func NewPasajeController(
 	solicitudService *services.SolicitudService,
 	pasajeService *services.PasajeService,
 	aerolineaService *services.AerolineaService,
+	eticketService *services.ETicketService,
 ) *PasajeController {
 	return &PasajeController{
 		agenciaService:   agenciaService,
@@ -34,6 +37,7 @@ func NewPasajeController(
 		solicitudService: solicitudService,
 		pasajeService:    pasajeService,
 		aerolineaService: aerolineaService,
+		eticketService:   eticketService,
 	}
 }
 
@@ -58,12 +62,18 @@ func (ctrl *PasajeController) Store(c *gin.Context) {
 		return
 	}
 
-	var filePath string
-	if file, err := c.FormFile("archivo"); err == nil {
-		filePath, _ = utils.SaveUploadedFile(c, file, "uploads/pasajes", "pasaje_"+solicitudID+"_new_")
+	filePath := utils.SaveFormFile(c, "archivo", "uploads/pasajes", "pasaje_"+solicitudID+"_new_", utils.UploadPDF)
+	if rechazo := utils.UploadErrorSummary(c); rechazo != "" {
+		if isHTMX {
+			ctrl.renderCreateModalWithError(c, solicitudID, req, rechazo)
+			return
+		}
+		utils.SetErrorMessage(c, rechazo)
+		c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+		return
 	}
 
-	if _, err := ctrl.pasajeService.Create(c.Request.Context(), solicitudID, req, filePath); err != nil {
+	if pasaje, err := ctrl.pasajeService.Create(c.Request.Context(), solicitudID, req, filePath); err != nil {
 		if isHTMX {
 			ctrl.renderCreateModalWithError(c, solicitudID, req, "Error al guardar: "+err.Error())
 			return
@@ -71,6 +81,9 @@ func (ctrl *PasajeController) Store(c *gin.Context) {
 		utils.SetErrorMessage(c, "Error: "+err.Error())
 	} else {
 		utils.SetSuccessMessage(c, "Pasaje registrado correctamente")
+		if pasaje.AvisoBillete != "" {
+			utils.SetErrorMessage(c, "Atención: "+pasaje.AvisoBillete)
+		}
 	}
 
 	solicitud, _ := ctrl.solicitudService.GetByID(c.Request.Context(), solicitudID)
@@ -122,16 +135,50 @@ func (ctrl *PasajeController) UpdateStatus(c *gin.Context) {
 	var ticketPath, pasePath string
 	switch req.Status {
 	case "EMITIDO":
-		if file, err := c.FormFile("archivo_ticket"); err == nil {
-			ticketPath, _ = utils.SaveUploadedFile(c, file, "uploads/pasajes", "pasaje_"+req.ID+"_")
-		}
+		ticketPath = utils.SaveFormFile(c, "archivo_ticket", "uploads/pasajes", "pasaje_"+req.ID+"_", utils.UploadPDF)
 	case "USADO":
-		if file, err := c.FormFile("archivo_pase_abordo"); err == nil {
-			pasePath, _ = utils.SaveUploadedFile(c, file, "uploads/pases_abordo", "pase_"+req.ID+"_")
+		pasePath = utils.SaveFormFile(c, "archivo_pase_abordo", "uploads/pases_abordo", "pase_"+req.ID+"_", utils.UploadDocument)
+	}
+	if rechazo := utils.UploadErrorSummary(c); rechazo != "" {
+		if c.GetHeader("X-Requested-With") == "XMLHttpRequest" || c.GetHeader("HX-Request") == "true" {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": rechazo})
+		} else {
+			utils.SetErrorMessage(c, rechazo)
+			c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
 		}
+		return
 	}
 
-	if err := ctrl.pasajeService.UpdateStatus(c.Request.Context(), req.ID, req.Status, ticketPath, pasePath); err != nil {
+	authUser := appcontext.AuthUser(c)
+	if err := ctrl.pasajeService.UpdateStatus(c.Request.Context(), req.ID, req.Status, ticketPath, pasePath, authUser, req.JustificacionMorosidad); err != nil {
+		var errMorosidad *services.ErrMorosidadDescargo
+		if errors.As(err, &errMorosidad) {
+			if c.GetHeader("X-Requested-With") == "XMLHttpRequest" || c.GetHeader("HX-Request") == "true" {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":           errMorosidad.Error(),
+					"morosidad":       true,
+					"puede_autorizar": errMorosidad.PuedeAutorizar && authUser != nil && authUser.IsAdmin(),
+				})
+			} else {
+				utils.SetErrorMessage(c, errMorosidad.Error())
+				c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+			}
+			return
+		}
+		var errTarifa *services.ErrTarifaExcedida
+		if errors.As(err, &errTarifa) {
+			if c.GetHeader("X-Requested-With") == "XMLHttpRequest" || c.GetHeader("HX-Request") == "true" {
+				c.JSON(http.StatusConflict, gin.H{
+					"error":                 errTarifa.Error(),
+					"requiere_autorizacion": true,
+					"puede_autorizar":       authUser != nil && authUser.IsAdminOrResponsable(),
+				})
+			} else {
+				utils.SetErrorMessage(c, errTarifa.Error())
+				c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+			}
+			return
+		}
 		if c.GetHeader("HX-Request") == "true" {
 			if req.Status == "EMITIDO" {
 
@@ -171,6 +218,15 @@ func (ctrl *PasajeController) UpdateStatus(c *gin.Context) {
 	}
 }
 
+func (ctrl *PasajeController) AutorizarTarifa(c *gin.Context) {
+	err := ctrl.pasajeService.AutorizarSobreprecio(c.Request.Context(), c.Param("id"), c.PostForm("justificacion"), appcontext.AuthUser(c))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Sobreprecio autorizado"})
+}
+
 func (ctrl *PasajeController) Devolver(c *gin.Context) {
 	var req dtos.DevolverPasajeRequest
 	if err := c.ShouldBind(&req); err != nil {
@@ -188,6 +244,42 @@ func (ctrl *PasajeController) Devolver(c *gin.Context) {
 	c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
 }
 
+func (ctrl *PasajeController) Reemitir(c *gin.Context) {
+	var req dtos.ReemitirPasajeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos para la reemisión")
+		c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+		return
+	}
+
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil || !authUser.IsAdminOrResponsable() {
+		utils.SetErrorMessage(c, "No tiene permisos para reemitir pasajes")
+		c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+		return
+	}
+
+	filePath := utils.SaveFormFile(c, "archivo", "uploads/pasajes", "pasaje_"+req.PasajeID+"_reemision_", utils.UploadPDF)
+	if rechazo := utils.UploadErrorSummary(c); rechazo != "" {
+		utils.SetErrorMessage(c, rechazo)
+		c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+		return
+	}
+
+	nuevo, err := ctrl.pasajeService.Reemitir(c.Request.Context(), req, filePath, authUser)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error: "+err.Error())
+		c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+		return
+	}
+
+	utils.SetSuccessMessage(c, "Pasaje reemitido: nuevo billete "+nuevo.NumeroBillete)
+	if nuevo.AvisoBillete != "" {
+		utils.SetErrorMessage(c, "Atención: "+nuevo.AvisoBillete)
+	}
+	c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+}
+
 func (ctrl *PasajeController) Update(c *gin.Context) {
 	var req dtos.UpdatePasajeRequest
 	isHTMX := c.GetHeader("HX-Request") == "true"
@@ -201,15 +293,19 @@ func (ctrl *PasajeController) Update(c *gin.Context) {
 		return
 	}
 
-	var filePath, pasePath string
-	if file, err := c.FormFile("archivo"); err == nil {
-		filePath, _ = utils.SaveUploadedFile(c, file, "uploads/pasajes", "pasaje_"+req.ID+"_edit_")
-	}
-	if file, err := c.FormFile("archivo_pase_abordo"); err == nil {
-		pasePath, _ = utils.SaveUploadedFile(c, file, "uploads/pases_abordo", "pase_"+req.ID+"_edit_")
+	filePath := utils.SaveFormFile(c, "archivo", "uploads/pasajes", "pasaje_"+req.ID+"_edit_", utils.UploadPDF)
+	pasePath := utils.SaveFormFile(c, "archivo_pase_abordo", "uploads/pases_abordo", "pase_"+req.ID+"_edit_", utils.UploadDocument)
+	if rechazo := utils.UploadErrorSummary(c); rechazo != "" {
+		if isHTMX {
+			ctrl.renderEditModalWithError(c, req.ID, req, rechazo)
+			return
+		}
+		utils.SetErrorMessage(c, rechazo)
+		c.Redirect(http.StatusFound, c.Request.Header.Get("Referer"))
+		return
 	}
 
-	if err := ctrl.pasajeService.UpdateFromRequest(c.Request.Context(), req, filePath, pasePath); err != nil {
+	if pasaje, err := ctrl.pasajeService.UpdateFromRequest(c.Request.Context(), req, filePath, pasePath); err != nil {
 		if isHTMX {
 			ctrl.renderEditModalWithError(c, req.ID, req, "Error: "+err.Error())
 			return
@@ -217,6 +313,9 @@ func (ctrl *PasajeController) Update(c *gin.Context) {
 		utils.SetErrorMessage(c, "Error: "+err.Error())
 	} else {
 		utils.SetSuccessMessage(c, "Datos del pasaje actualizados")
+		if pasaje.AvisoBillete != "" {
+			utils.SetErrorMessage(c, "Atención: "+pasaje.AvisoBillete)
+		}
 	}
 
 	if isHTMX {
@@ -367,6 +466,29 @@ func (ctrl *PasajeController) GetDevolverModal(c *gin.Context) {
 	})
 }
 
+func (ctrl *PasajeController) GetReemitirModal(c *gin.Context) {
+	id := c.Param("id")
+	pasaje, err := ctrl.pasajeService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.String(http.StatusNotFound, "Pasaje no encontrado")
+		return
+	}
+
+	aerolineas, _ := ctrl.aerolineaService.GetAllActive(c.Request.Context())
+	rutas, _ := ctrl.rutaService.GetAll(c.Request.Context())
+
+	authUser := appcontext.AuthUser(c)
+	pasaje.HydratePermissions(authUser)
+
+	utils.Render(c, "solicitud/components/modal_reemitir_pasaje", gin.H{
+		"Pasaje":     pasaje,
+		"Aerolineas": aerolineas,
+		"Rutas":      rutas,
+		"Morosidad":  ctrl.pasajeService.GetMorosidad(c.Request.Context(), pasaje),
+		"IsAdmin":    authUser != nil && authUser.IsAdmin(),
+	})
+}
+
 func (ctrl *PasajeController) GetUsadoModal(c *gin.Context) {
 	id := c.Param("id")
 	pasaje, err := ctrl.pasajeService.GetByID(c.Request.Context(), id)
@@ -425,9 +547,18 @@ func (ctrl *PasajeController) UpdateServicio(c *gin.Context) {
 		return
 	}
 
-	var filePath string
-	if file, err := c.FormFile("servicio_archivo"); err == nil {
-		filePath, _ = utils.SaveUploadedFile(c, file, "uploads/servicios", "servicio_"+req.ID+"_")
+	filePath := utils.SaveFormFile(c, "servicio_archivo", "uploads/servicios", "servicio_"+req.ID+"_", utils.UploadDocument)
+	if rechazo := utils.UploadErrorSummary(c); rechazo != "" {
+		pasaje, err := ctrl.pasajeService.GetByID(c.Request.Context(), req.ID)
+		if err != nil {
+			c.String(http.StatusNotFound, "Pasaje no encontrado")
+			return
+		}
+		utils.Render(c, "solicitud/components/modal_servicio_pasaje", gin.H{
+			"Pasaje":       pasaje,
+			"ErrorMessage": rechazo,
+		})
+		return
 	}
 
 	if err := ctrl.pasajeService.UpdateServicioEmision(c.Request.Context(), req, filePath); err != nil {
@@ -452,6 +583,33 @@ func (ctrl *PasajeController) GetCargosModal(c *gin.Context) {
 	})
 }
 
+// ExtraerETicket lee el PDF del billete y devuelve los datos para precargar el modal de pasaje.
+func (ctrl *PasajeController) ExtraerETicket(c *gin.Context) {
+	file, err := c.FormFile("archivo")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "No se recibió ningún archivo"})
+		return
+	}
+
+	data, err := utils.ReadUploadedFile(c, file, utils.UploadPDF)
+	if err != nil {
+		var rechazo *utils.UploadError
+		if errors.As(err, &rechazo) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": rechazo.Reason})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "No se pudo leer el archivo"})
+		return
+	}
+
+	res, err := ctrl.eticketService.Extraer(c.Request.Context(), data, c.PostForm("solicitud_item_id"))
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
 func (ctrl *PasajeController) StoreCargo(c *gin.Context) {
 	id := c.Param("id")
 	var req dtos.CreatePasajeCargoRequest
@@ -461,9 +619,18 @@ func (ctrl *PasajeController) StoreCargo(c *gin.Context) {
 	}
 	req.PasajeID = id
 
-	var filePath string
-	if file, err := c.FormFile("archivo"); err == nil {
-		filePath, _ = utils.SaveUploadedFile(c, file, "uploads/pasajes/cargos", "cargo_"+id+"_")
+	filePath := utils.SaveFormFile(c, "archivo", "uploads/pasajes/cargos", "cargo_"+id+"_", utils.UploadPDF)
+	if rechazo := utils.UploadErrorSummary(c); rechazo != "" {
+		pasaje, err := ctrl.pasajeService.GetByID(c.Request.Context(), id)
+		if err != nil {
+			c.String(http.StatusNotFound, "Pasaje no encontrado")
+			return
+		}
+		utils.Render(c, "solicitud/components/modal_cargos_pasaje", gin.H{
+			"Pasaje":       pasaje,
+			"ErrorMessage": rechazo,
+		})
+		return
 	}
 
 	if err := ctrl.pasajeService.CreateCargo(c.Request.Context(), req, filePath); err != nil {
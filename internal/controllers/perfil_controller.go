@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"sistema-pasajes/internal/appcontext"
 	"sistema-pasajes/internal/services"
 	"sistema-pasajes/internal/utils"
 
@@ -8,21 +9,33 @@ import (
 )
 
 type PerfilController struct {
-	destinoService *services.DestinoService
+	destinoService    *services.DestinoService
+	calendarioService *services.CalendarioService
+	firmaService      *services.FirmaService
 }
 
-func NewPerfilController(destinoService *services.DestinoService) *PerfilController {
+func NewPerfilController(destinoService *services.DestinoService, calendarioService *services.CalendarioService, firmaService *services.FirmaService) *PerfilController {
 	return &PerfilController{
-		destinoService: destinoService,
+		destinoService:    destinoService,
+		calendarioService: calendarioService,
+		firmaService:      firmaService,
 	}
 }
 
 func (ctrl *PerfilController) Show(c *gin.Context) {
 	destinos, _ := ctrl.destinoService.GetAll(c.Request.Context())
 
+	calendarioURL := ""
+	if feed, err := ctrl.calendarioService.GetOrCreateFeed(c.Request.Context(), appcontext.AuthUser(c).ID); err == nil {
+		calendarioURL = ctrl.calendarioService.GetFeedURL(feed)
+	}
+	certificado, _ := ctrl.firmaService.GetCertificado(c.Request.Context(), appcontext.AuthUser(c).ID)
+
 	utils.Render(c, "auth/profile", gin.H{
-		"Title":    "Mi Perfil",
-		"Destinos": destinos,
-		"Success":  c.Query("success"),
+		"Title":         "Mi Perfil",
+		"Destinos":      destinos,
+		"Success":       c.Query("success"),
+		"CalendarioURL": calendarioURL,
+		"Certificado":   certificado,
 	})
 }
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PoliticaCupoController struct {
+	service   *services.PoliticaCupoService
+	deptoRepo *repositories.DepartamentoRepository
+}
+
+func NewPoliticaCupoController(service *services.PoliticaCupoService, deptoRepo *repositories.DepartamentoRepository) *PoliticaCupoController {
+	return &PoliticaCupoController{
+		service:   service,
+		deptoRepo: deptoRepo,
+	}
+}
+
+func (ctrl *PoliticaCupoController) Index(c *gin.Context) {
+	ctx := c.Request.Context()
+	politicas, _ := ctrl.service.GetAll(ctx)
+	departamentos, _ := ctrl.deptoRepo.FindAll(ctx)
+
+	// Permite precargar el formulario con una versión existente para derivar la siguiente.
+	base := models.PoliticaCupoDefault()
+	if id := c.Query("base"); id != "" {
+		if p, err := ctrl.service.GetByID(ctx, id); err == nil {
+			base = p
+		}
+	}
+
+	utils.Render(c, "admin/politicas_cupo", gin.H{
+		"Title":         "Políticas de Cupo",
+		"Politicas":     politicas,
+		"Vigente":       ctrl.service.GetVigenteActual(ctx),
+		"Base":          base,
+		"Departamentos": departamentos,
+		"TiposSenador":  []string{models.TipoSenadorTitular, models.TipoSenadorSuplente},
+	})
+}
+
+func (ctrl *PoliticaCupoController) Store(c *gin.Context) {
+	var req dtos.CreatePoliticaCupoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos: nombre y vigencia son obligatorios")
+		c.Redirect(http.StatusFound, "/admin/cupos/politicas")
+		return
+	}
+
+	politica, err := ctrl.service.CrearVersion(c.Request.Context(), req, appcontext.AuthUser(c))
+	if err != nil {
+		utils.SetErrorMessage(c, err.Error())
+		c.Redirect(http.StatusFound, "/admin/cupos/politicas")
+		return
+	}
+
+	utils.SetSuccessMessage(c, "Política registrada: "+politica.GetLabel())
+	c.Redirect(http.StatusFound, "/admin/cupos/politicas")
+}
+
+func (ctrl *PoliticaCupoController) Toggle(c *gin.Context) {
+	activa := c.PostForm("activa") == "true"
+	if err := ctrl.service.SetActiva(c.Request.Context(), c.Param("id"), activa); err != nil {
+		utils.SetErrorMessage(c, "Error al actualizar la política: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Política actualizada")
+	}
+	c.Redirect(http.StatusFound, "/admin/cupos/politicas")
+}
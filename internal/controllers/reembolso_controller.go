@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReembolsoController struct {
+	service *services.ReembolsoService
+}
+
+func NewReembolsoController(service *services.ReembolsoService) *ReembolsoController {
+	return &ReembolsoController{service: service}
+}
+
+func (ctrl *ReembolsoController) Index(c *gin.Context) {
+	ctx := c.Request.Context()
+	viajeros, _ := ctrl.service.GetResumenPorViajero(ctx)
+	movimientos, _ := ctrl.service.GetMovimientosSinConciliar(ctx)
+	extractos, _ := ctrl.service.GetExtractos(ctx)
+
+	vencidos := 0
+	for _, v := range viajeros {
+		vencidos += v.Vencidos
+	}
+
+	utils.Render(c, "admin/reembolsos", gin.H{
+		"Title":       "Reembolsos",
+		"Viajeros":    viajeros,
+		"Movimientos": movimientos,
+		"Extractos":   extractos,
+		"Vencidos":    vencidos,
+		"PlazoDias":   ctrl.service.GetPlazoDias(ctx),
+	})
+}
+
+func (ctrl *ReembolsoController) Importar(c *gin.Context) {
+	file, err := c.FormFile("extracto")
+	if err != nil {
+		utils.SetErrorMessage(c, "Seleccione el archivo del extracto (CSV o xlsx)")
+		c.Redirect(http.StatusFound, "/admin/reembolsos")
+		return
+	}
+
+	path, err := utils.SaveUploadedFile(c, file, "uploads/extractos", "extracto_"+utils.FormatDateFilename()+"_", utils.UploadStatement)
+	var rechazo *utils.UploadError
+	if errors.As(err, &rechazo) {
+		utils.SetErrorMessage(c, "Extracto rechazado: "+rechazo.Error())
+		c.Redirect(http.StatusFound, "/admin/reembolsos")
+		return
+	}
+	if err != nil {
+		utils.SetErrorMessage(c, "Error al guardar el archivo: "+err.Error())
+		c.Redirect(http.StatusFound, "/admin/reembolsos")
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error al leer el archivo: "+err.Error())
+		c.Redirect(http.StatusFound, "/admin/reembolsos")
+		return
+	}
+
+	extracto, verificados, err := ctrl.service.ImportarExtracto(c.Request.Context(), file.Filename, data, path, appcontext.AuthUser(c).ID)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error al importar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, fmt.Sprintf("Extracto importado: %d abonos nuevos, %d duplicados, %d reembolsos verificados",
+			extracto.Movimientos, extracto.Duplicados, verificados))
+	}
+	c.Redirect(http.StatusFound, "/admin/reembolsos")
+}
+
+func (ctrl *ReembolsoController) Conciliar(c *gin.Context) {
+	verificados, err := ctrl.service.Conciliar(c.Request.Context())
+	if err != nil {
+		utils.SetErrorMessage(c, "Error al conciliar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, fmt.Sprintf("Conciliación completada: %d reembolsos verificados", verificados))
+	}
+	c.Redirect(http.StatusFound, "/admin/reembolsos")
+}
+
+func (ctrl *ReembolsoController) Vincular(c *gin.Context) {
+	movimientoID := c.PostForm("movimiento_id")
+	pasajeID := c.PostForm("pasaje_id")
+	if movimientoID == "" || pasajeID == "" {
+		utils.SetErrorMessage(c, "Seleccione el movimiento y el reembolso a vincular")
+	} else if err := ctrl.service.VincularManual(c.Request.Context(), movimientoID, pasajeID); err != nil {
+		utils.SetErrorMessage(c, "Error al vincular: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Reembolso verificado manualmente")
+	}
+	c.Redirect(http.StatusFound, "/admin/reembolsos")
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReglaDescargoController struct {
+	service      *services.ReglaDescargoService
+	conceptoRepo *repositories.ConceptoViajeRepository
+}
+
+func NewReglaDescargoController(service *services.ReglaDescargoService, conceptoRepo *repositories.ConceptoViajeRepository) *ReglaDescargoController {
+	return &ReglaDescargoController{service: service, conceptoRepo: conceptoRepo}
+}
+
+func (ctrl *ReglaDescargoController) Index(c *gin.Context) {
+	reglas, _ := ctrl.service.GetAll(c.Request.Context())
+	conceptos, _ := ctrl.conceptoRepo.FindConceptos(c.Request.Context())
+
+	utils.Render(c, "admin/reglas_descargo", gin.H{
+		"Title":      "Reglas de Descargo",
+		"Reglas":     reglas,
+		"Conceptos":  conceptos,
+		"Requisitos": models.CatalogoRequisitosDescargo,
+		"PorDefecto": len(reglas) == 0,
+	})
+}
+
+func (ctrl *ReglaDescargoController) Store(c *gin.Context) {
+	var req dtos.CreateReglaDescargoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos: el requisito es obligatorio")
+		c.Redirect(http.StatusFound, "/admin/reglas-descargo")
+		return
+	}
+
+	if err := ctrl.service.Create(c.Request.Context(), req, appcontext.AuthUser(c)); err != nil {
+		utils.SetErrorMessage(c, err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Regla registrada")
+	}
+	c.Redirect(http.StatusFound, "/admin/reglas-descargo")
+}
+
+func (ctrl *ReglaDescargoController) Update(c *gin.Context) {
+	var req dtos.UpdateReglaDescargoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos: la etiqueta es obligatoria")
+		c.Redirect(http.StatusFound, "/admin/reglas-descargo")
+		return
+	}
+
+	if err := ctrl.service.Update(c.Request.Context(), c.Param("id"), req); err != nil {
+		utils.SetErrorMessage(c, "Error al actualizar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Regla actualizada")
+	}
+	c.Redirect(http.StatusFound, "/admin/reglas-descargo")
+}
+
+func (ctrl *ReglaDescargoController) Toggle(c *gin.Context) {
+	activo := c.PostForm("activo") == "true"
+	if err := ctrl.service.SetActivo(c.Request.Context(), c.Param("id"), activo); err != nil {
+		utils.SetErrorMessage(c, "Error al actualizar: "+err.Error())
+	} else if activo {
+		utils.SetSuccessMessage(c, "Regla activada")
+	} else {
+		utils.SetSuccessMessage(c, "Regla desactivada")
+	}
+	c.Redirect(http.StatusFound, "/admin/reglas-descargo")
+}
+
+func (ctrl *ReglaDescargoController) Delete(c *gin.Context) {
+	if err := ctrl.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		utils.SetErrorMessage(c, "Error al eliminar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Regla eliminada")
+	}
+	c.Redirect(http.StatusFound, "/admin/reglas-descargo")
+}
@@ -113,6 +113,71 @@ func (ctrl *ReportController) DownloadEstadisticasAerolineaExcel(c *gin.Context)
 	_ = f.Write(c.Writer)
 }
 
+func (ctrl *ReportController) DownloadSobrepreciosExcel(c *gin.Context) {
+	var filter dtos.ReportFilterRequest
+	_ = c.ShouldBindQuery(&filter)
+
+	f, err := ctrl.reportService.GenerateSobrepreciosExcel(c.Request.Context(), filter)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error generando reporte: "+err.Error())
+		c.Redirect(http.StatusFound, "/admin/reports")
+		return
+	}
+
+	fileName := fmt.Sprintf("Sobreprecios_Tarifas_%s.xlsx", utils.FormatDateFilename())
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	_ = f.Write(c.Writer)
+}
+
+func (ctrl *ReportController) DownloadColisionesBilleteExcel(c *gin.Context) {
+	f, err := ctrl.reportService.GenerateColisionesBilleteExcel(c.Request.Context())
+	if err != nil {
+		utils.SetErrorMessage(c, "Error generando reporte: "+err.Error())
+		c.Redirect(http.StatusFound, "/admin/reports")
+		return
+	}
+
+	fileName := fmt.Sprintf("Colisiones_Billetes_%s.xlsx", utils.FormatDateFilename())
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	_ = f.Write(c.Writer)
+}
+
+// Agencias muestra el tiempo de emisión y la calidad de servicio de cada agencia en la gestión.
+func (ctrl *ReportController) Agencias(c *gin.Context) {
+	anio := utils.StrToInt(c.Query("anio"), time.Now().Year())
+
+	scorecards, err := ctrl.reportService.GetScorecardAgencias(c.Request.Context(), anio)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error calculando métricas de agencias: "+err.Error())
+	}
+
+	utils.Render(c, "admin/reports/agencias", gin.H{
+		"Title":      "Servicio de Agencias",
+		"Anio":       anio,
+		"Anios":      []int{time.Now().Year(), time.Now().Year() - 1, time.Now().Year() - 2},
+		"PlazoHoras": ctrl.reportService.GetPlazoEmisionHoras(c.Request.Context()),
+		"Scorecards": scorecards,
+	})
+}
+
+func (ctrl *ReportController) DownloadAgenciasExcel(c *gin.Context) {
+	anio := utils.StrToInt(c.Query("anio"), time.Now().Year())
+
+	f, err := ctrl.reportService.GenerateScorecardAgenciasExcel(c.Request.Context(), anio)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error generando reporte: "+err.Error())
+		c.Redirect(http.StatusFound, "/admin/reports/agencias")
+		return
+	}
+
+	fileName := fmt.Sprintf("Servicio_Agencias_%d.xlsx", anio)
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	_ = f.Write(c.Writer)
+}
+
 func (ctrl *ReportController) DownloadOficialesExcel(c *gin.Context) {
 	var filter dtos.ReportFilterRequest
 	if err := c.ShouldBindQuery(&filter); err != nil {
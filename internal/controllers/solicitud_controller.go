@@ -416,3 +416,12 @@ func (ctrl *SolicitudController) TableOpenTicketDescargo(c *gin.Context) {
 		"LinkBase": "/solicitudes/con-open-ticket",
 	})
 }
+
+// justificacionConflicto obtiene la justificación de un administrador para autorizar conflictos de fechas,
+// ya sea desde el formulario o desde el prompt de HTMX (hx-prompt).
+func justificacionConflicto(c *gin.Context) string {
+	if j := strings.TrimSpace(c.PostForm("justificacion_conflicto")); j != "" {
+		return j
+	}
+	return strings.TrimSpace(c.GetHeader("HX-Prompt"))
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"sistema-pasajes/internal/appcontext"
@@ -24,6 +25,7 @@ type SolicitudDerechoController struct {
 	aerolineaService        *services.AerolineaService
 	descargoService         *services.DescargoService
 	openTicketService       *services.OpenTicketService
+	firmaService            *services.FirmaService
 }
 
 func NewSolicitudDerechoController(
@@ -38,6 +40,7 @@ func NewSolicitudDerechoController(
 	aerolineaService *services.AerolineaService,
 	descargoService *services.DescargoService,
 	openTicketService *services.OpenTicketService,
+	firmaService *services.FirmaService,
 ) *SolicitudDerechoController {
 	return &SolicitudDerechoController{
 		solicitudService:        solicitudService,
@@ -51,6 +54,7 @@ func NewSolicitudDerechoController(
 		aerolineaService:        aerolineaService,
 		descargoService:         descargoService,
 		openTicketService:       openTicketService,
+		firmaService:            firmaService,
 	}
 }
 
@@ -110,6 +114,7 @@ func (ctrl *SolicitudDerechoController) GetCreateModal(c *gin.Context) {
 		"CanManageSystem":     authUser.IsAdminOrResponsable(),
 		"AuthUser":            authUser,
 		"Destinos":            destinos,
+		"Morosidad":           ctrl.solicitudService.GetMorosidad(c.Request.Context(), targetUser.ID),
 	})
 }
 
@@ -271,13 +276,14 @@ func (ctrl *SolicitudDerechoController) Show(c *gin.Context) {
 		"ShowNextSteps": showNextSteps,
 		"StatusCard":    statusCard,
 		"Aerolineas":    aerolineas,
+		"Conflictos":    ctrl.solicitudService.GetConflictos(c.Request.Context(), solicitud),
 	})
 }
 
 func (ctrl *SolicitudDerechoController) Approve(c *gin.Context) {
 	id := c.Param("id")
 	authUser := appcontext.AuthUser(c)
-	if err := ctrl.solicitudService.Approve(c.Request.Context(), id, authUser); err != nil {
+	if err := ctrl.solicitudService.Approve(c.Request.Context(), id, authUser, justificacionConflicto(c)); err != nil {
 		c.String(http.StatusForbidden, err.Error())
 		return
 	}
@@ -352,6 +358,48 @@ func (ctrl *SolicitudDerechoController) Print(c *gin.Context) {
 	pdf.Output(c.Writer)
 }
 
+// Firmar genera el PV-01 y lo devuelve firmado digitalmente con el certificado del usuario.
+func (ctrl *SolicitudDerechoController) Firmar(c *gin.Context) {
+	id := c.Param("id")
+	redirect := "/solicitudes/derecho/" + id + "/detalle"
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	solicitud, err := ctrl.solicitudService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.String(http.StatusNotFound, "Solicitud no encontrada")
+		return
+	}
+	if !solicitud.CanFirmar(authUser) {
+		c.String(http.StatusForbidden, "No tiene permiso para firmar este formulario")
+		return
+	}
+
+	var req dtos.FirmarDocumentoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Ingrese la contraseña de su certificado")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	personaView, _ := ctrl.peopleService.GetSenatorDataByCI(c.Request.Context(), solicitud.Usuario.CI)
+	mode := c.PostForm("mode")
+	data, err := ctrl.firmaService.FirmarFormulario(c.Request.Context(), authUser, req.Password, "PV-01 "+solicitud.Codigo, func(ctx context.Context) ([]byte, error) {
+		return ctrl.reportService.Bytes(ctrl.reportService.GeneratePV01(ctx, solicitud, personaView, mode))
+	})
+	if err != nil {
+		utils.SetErrorMessage(c, "No se pudo firmar el formulario: "+err.Error())
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"FORM-PV01-%s-firmado.pdf\"", solicitud.ID))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
 func (ctrl *SolicitudDerechoController) Destroy(c *gin.Context) {
 	id := c.Param("id")
 	authUser := appcontext.AuthUser(c)
@@ -371,7 +419,7 @@ func (ctrl *SolicitudDerechoController) ApproveItem(c *gin.Context) {
 	id := c.Param("id")
 	itemID := c.Param("item_id")
 	authUser := appcontext.AuthUser(c)
-	if err := ctrl.solicitudService.ApproveItem(c.Request.Context(), id, itemID, authUser); err != nil {
+	if err := ctrl.solicitudService.ApproveItem(c.Request.Context(), id, itemID, authUser, justificacionConflicto(c)); err != nil {
 		c.String(http.StatusForbidden, err.Error())
 		return
 	}
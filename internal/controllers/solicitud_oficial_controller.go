@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -26,6 +27,8 @@ type SolicitudOficialController struct {
 	reportService           *services.ReportService
 	peopleService           *services.PeopleService
 	descargoService         *services.DescargoService
+	anticipoService         *services.AnticipoService
+	firmaService            *services.FirmaService
 }
 
 func NewSolicitudOficialController(
@@ -39,6 +42,8 @@ func NewSolicitudOficialController(
 	reportService *services.ReportService,
 	peopleService *services.PeopleService,
 	descargoService *services.DescargoService,
+	anticipoService *services.AnticipoService,
+	firmaService *services.FirmaService,
 ) *SolicitudOficialController {
 	return &SolicitudOficialController{
 		solicitudService:        solicitudService,
@@ -51,6 +56,8 @@ func NewSolicitudOficialController(
 		reportService:           reportService,
 		peopleService:           peopleService,
 		descargoService:         descargoService,
+		anticipoService:         anticipoService,
+		firmaService:            firmaService,
 	}
 }
 
@@ -86,6 +93,7 @@ func (ctrl *SolicitudOficialController) GetCreateModal(c *gin.Context) {
 		"Destinos":    destinos,
 		"Tipos":       tipos,
 		"DefaultDate": dateIda,
+		"Morosidad":   ctrl.solicitudService.GetMorosidad(c.Request.Context(), targetUser.ID),
 	})
 }
 
@@ -151,11 +159,19 @@ func (ctrl *SolicitudOficialController) Show(c *gin.Context) {
 	// Descargo PV-05/06: si ya existe, pasamos ID y Estado para enlaces directos
 	var descargoID string
 	var descargoEstado string
+	descargoAbierto := true
 	if descargo, _ := ctrl.descargoService.GetBySolicitudID(c.Request.Context(), id); descargo != nil && descargo.ID != "" {
 		descargoID = descargo.ID
 		descargoEstado = string(descargo.Estado)
+		descargoAbierto = descargo.IsEditable()
 	}
 
+	// Anticipo: se puede pedir (o volver a pedir tras un rechazo) mientras el descargo no se presente
+	anticipo, _ := ctrl.anticipoService.GetBySolicitudID(c.Request.Context(), id)
+	estado := solicitud.GetEstado()
+	puedeSolicitarAnticipo := descargoAbierto && estado != "RECHAZADO" && estado != "FINALIZADO" &&
+		(anticipo == nil || anticipo.Estado == models.EstadoAnticipoRechazado)
+
 	// Cargar usuarios de auditoría si existen
 	var updatedByUser, createdByUser *models.Usuario
 	if solicitud.UpdatedBy != nil {
@@ -167,21 +183,24 @@ func (ctrl *SolicitudOficialController) Show(c *gin.Context) {
 	solicitud.HydrateAuditUsers(updatedByUser, createdByUser)
 
 	utils.Render(c, "solicitud/oficial/show", gin.H{
-		"Title":          "Solicitud de Comisión Oficial " + solicitud.Codigo,
-		"Solicitud":      solicitud,
-		"Steps":          steps,
-		"ShowNextSteps":  showNextSteps,
-		"StatusCard":     statusCard,
-		"Aerolineas":     aerolineas,
-		"DescargoID":     descargoID,
-		"DescargoEstado": descargoEstado,
+		"Title":                  "Solicitud de Comisión Oficial " + solicitud.Codigo,
+		"Solicitud":              solicitud,
+		"Steps":                  steps,
+		"ShowNextSteps":          showNextSteps,
+		"StatusCard":             statusCard,
+		"Aerolineas":             aerolineas,
+		"DescargoID":             descargoID,
+		"DescargoEstado":         descargoEstado,
+		"Conflictos":             ctrl.solicitudService.GetConflictos(c.Request.Context(), solicitud),
+		"Anticipo":               anticipo,
+		"PuedeSolicitarAnticipo": puedeSolicitarAnticipo,
 	})
 }
 
 func (ctrl *SolicitudOficialController) Approve(c *gin.Context) {
 	id := c.Param("id")
 	authUser := appcontext.AuthUser(c)
-	if err := ctrl.solicitudService.Approve(c.Request.Context(), id, authUser); err != nil {
+	if err := ctrl.solicitudService.Approve(c.Request.Context(), id, authUser, justificacionConflicto(c)); err != nil {
 		utils.SetErrorMessage(c, err.Error())
 		c.Redirect(http.StatusFound, "/solicitudes/oficial/"+id+"/detalle")
 		return
@@ -230,7 +249,7 @@ func (ctrl *SolicitudOficialController) ApproveItem(c *gin.Context) {
 	id := c.Param("id")
 	itemID := c.Param("item_id")
 	authUser := appcontext.AuthUser(c)
-	if err := ctrl.solicitudService.ApproveItem(c.Request.Context(), id, itemID, authUser); err != nil {
+	if err := ctrl.solicitudService.ApproveItem(c.Request.Context(), id, itemID, authUser, justificacionConflicto(c)); err != nil {
 		utils.SetErrorMessage(c, err.Error())
 		c.Redirect(http.StatusFound, "/solicitudes/oficial/"+id+"/detalle")
 		return
@@ -292,6 +311,47 @@ func (ctrl *SolicitudOficialController) Print(c *gin.Context) {
 	pdf.Output(c.Writer)
 }
 
+// Firmar genera el PV-02 y lo devuelve firmado digitalmente con el certificado del usuario.
+func (ctrl *SolicitudOficialController) Firmar(c *gin.Context) {
+	id := c.Param("id")
+	redirect := "/solicitudes/oficial/" + id + "/detalle"
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	solicitud, err := ctrl.solicitudService.GetByID(c.Request.Context(), id)
+	if err != nil {
+		c.String(http.StatusNotFound, "Solicitud no encontrada")
+		return
+	}
+	if !solicitud.CanFirmar(authUser) {
+		c.String(http.StatusForbidden, "No tiene permiso para firmar este formulario")
+		return
+	}
+
+	var req dtos.FirmarDocumentoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Ingrese la contraseña de su certificado")
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	personaView, _ := ctrl.peopleService.GetSenatorDataByCI(c.Request.Context(), solicitud.Usuario.CI)
+	data, err := ctrl.firmaService.FirmarFormulario(c.Request.Context(), authUser, req.Password, "PV-02 "+solicitud.Codigo, func(ctx context.Context) ([]byte, error) {
+		return ctrl.reportService.Bytes(ctrl.reportService.GeneratePV02(ctx, solicitud, personaView))
+	})
+	if err != nil {
+		utils.SetErrorMessage(c, "No se pudo firmar el formulario: "+err.Error())
+		c.Redirect(http.StatusFound, redirect)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"FORM-PV02-%s-firmado.pdf\"", solicitud.ID))
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
 func (ctrl *SolicitudOficialController) GetEditModal(c *gin.Context) {
 	id := c.Param("id")
 	solicitud, err := ctrl.solicitudService.GetByID(c.Request.Context(), id)
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TipoCambioController struct {
+	service *services.TipoCambioService
+}
+
+func NewTipoCambioController(service *services.TipoCambioService) *TipoCambioController {
+	return &TipoCambioController{service: service}
+}
+
+func (ctrl *TipoCambioController) Index(c *gin.Context) {
+	tipos, _ := ctrl.service.GetRecientes(c.Request.Context())
+
+	var monedas []string
+	for _, m := range models.Monedas {
+		if m != models.MonedaBOB {
+			monedas = append(monedas, m)
+		}
+	}
+
+	utils.Render(c, "admin/tipos_cambio", gin.H{
+		"Title":   "Tipos de Cambio",
+		"Tipos":   tipos,
+		"Monedas": monedas,
+		"Hoy":     time.Now().Format("2006-01-02"),
+	})
+}
+
+func (ctrl *TipoCambioController) Store(c *gin.Context) {
+	var req dtos.CreateTipoCambioRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos: moneda, fecha y valor son obligatorios")
+		c.Redirect(http.StatusFound, "/admin/tipos-cambio")
+		return
+	}
+
+	if err := ctrl.service.Create(c.Request.Context(), req, appcontext.AuthUser(c)); err != nil {
+		utils.SetErrorMessage(c, err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Tipo de cambio registrado")
+	}
+	c.Redirect(http.StatusFound, "/admin/tipos-cambio")
+}
+
+func (ctrl *TipoCambioController) Delete(c *gin.Context) {
+	if err := ctrl.service.Delete(c.Request.Context(), c.Param("id")); err != nil {
+		utils.SetErrorMessage(c, "Error al eliminar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Tipo de cambio eliminado")
+	}
+	c.Redirect(http.StatusFound, "/admin/tipos-cambio")
+}
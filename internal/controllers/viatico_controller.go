@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/services"
+	"sistema-pasajes/internal/utils"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ViaticoController struct {
+	service       *services.ViaticoService
+	ambitoService *services.AmbitoService
+}
+
+func NewViaticoController(service *services.ViaticoService, ambitoService *services.AmbitoService) *ViaticoController {
+	return &ViaticoController{service: service, ambitoService: ambitoService}
+}
+
+func (ctrl *ViaticoController) Categorias(c *gin.Context) {
+	categorias, _ := ctrl.service.GetCategorias(c.Request.Context())
+	zonas, _ := ctrl.service.GetZonas(c.Request.Context())
+	ambitos, _ := ctrl.ambitoService.GetAll(c.Request.Context())
+
+	utils.Render(c, "admin/viatico/categorias", gin.H{
+		"Title":      "Gestión de Viáticos",
+		"Categorias": categorias,
+		"Zonas":      zonas,
+		"Ambitos":    ambitos,
+		"TasaRCIVA":  ctrl.service.GetTasaRCIVA(c.Request.Context()) * 100,
+	})
+}
+
+func (ctrl *ViaticoController) StoreCategoria(c *gin.Context) {
+	var req dtos.CreateCategoriaViaticoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Datos inválidos: todos los campos son obligatorios")
+		c.Redirect(http.StatusFound, "/admin/viaticos/categorias")
+		return
+	}
+
+	if err := ctrl.service.SaveCategoria(c.Request.Context(), req); err != nil {
+		utils.SetErrorMessage(c, err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Escala de viático guardada")
+	}
+	c.Redirect(http.StatusFound, "/admin/viaticos/categorias")
+}
+
+func (ctrl *ViaticoController) DeleteCategoria(c *gin.Context) {
+	if err := ctrl.service.DeleteCategoria(c.Request.Context(), c.Param("id")); err != nil {
+		utils.SetErrorMessage(c, "Error al eliminar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Escala eliminada")
+	}
+	c.Redirect(http.StatusFound, "/admin/viaticos/categorias")
+}
+
+func (ctrl *ViaticoController) StoreZona(c *gin.Context) {
+	var req dtos.CreateZonaViaticoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "El nombre de la zona es obligatorio")
+		c.Redirect(http.StatusFound, "/admin/viaticos/categorias")
+		return
+	}
+
+	if err := ctrl.service.CreateZona(c.Request.Context(), req.Nombre); err != nil {
+		utils.SetErrorMessage(c, err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Zona registrada")
+	}
+	c.Redirect(http.StatusFound, "/admin/viaticos/categorias")
+}
+
+func (ctrl *ViaticoController) DeleteZona(c *gin.Context) {
+	if err := ctrl.service.DeleteZona(c.Request.Context(), c.Param("id")); err != nil {
+		utils.SetErrorMessage(c, "Error al eliminar: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, "Zona eliminada")
+	}
+	c.Redirect(http.StatusFound, "/admin/viaticos/categorias")
+}
+
+// Calcular liquida (o recalcula) los viáticos de un descargo oficial.
+func (ctrl *ViaticoController) Calcular(c *gin.Context) {
+	id := c.Param("id")
+	authUser := appcontext.AuthUser(c)
+	if authUser == nil || !authUser.IsAdminOrResponsable() {
+		c.Redirect(http.StatusFound, "/auth/login")
+		return
+	}
+
+	var req dtos.CalcularViaticoRequest
+	if err := c.ShouldBind(&req); err != nil {
+		utils.SetErrorMessage(c, "Seleccione la zona de destino")
+		c.Redirect(http.StatusFound, "/descargos/oficial/"+id)
+		return
+	}
+
+	if v, err := ctrl.service.Calcular(c.Request.Context(), id, req.ZonaViaticoID, authUser); err != nil {
+		utils.SetErrorMessage(c, "No se pudo liquidar viáticos: "+err.Error())
+	} else {
+		utils.SetSuccessMessage(c, fmt.Sprintf("Viáticos liquidados: %d días, líquido Bs %.2f", v.Dias, v.Liquido))
+	}
+	c.Redirect(http.StatusFound, "/descargos/oficial/"+id)
+}
+
+func planillaRango(c *gin.Context) (time.Time, time.Time) {
+	now := time.Now()
+	desde := utils.ParseDate("2006-01-02", c.Query("desde"))
+	hasta := utils.ParseDate("2006-01-02", c.Query("hasta"))
+	if desde.IsZero() {
+		desde = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	}
+	if hasta.IsZero() {
+		hasta = desde.AddDate(0, 1, -1)
+	}
+	return desde, hasta
+}
+
+// Planilla lista los viáticos liquidados de las comisiones que retornaron en el periodo.
+func (ctrl *ViaticoController) Planilla(c *gin.Context) {
+	desde, hasta := planillaRango(c)
+	viaticos, err := ctrl.service.GetPlanilla(c.Request.Context(), desde, hasta)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error consultando viáticos: "+err.Error())
+	}
+
+	var total, retencion, liquido float64
+	for _, v := range viaticos {
+		total += v.Total
+		retencion += v.Retencion
+		liquido += v.Liquido
+	}
+
+	utils.Render(c, "admin/viatico/planilla", gin.H{
+		"Title":     "Planilla de Viáticos",
+		"Viaticos":  viaticos,
+		"Desde":     desde.Format("2006-01-02"),
+		"Hasta":     hasta.Format("2006-01-02"),
+		"Total":     total,
+		"Retencion": retencion,
+		"Liquido":   liquido,
+	})
+}
+
+func (ctrl *ViaticoController) DownloadPlanillaExcel(c *gin.Context) {
+	desde, hasta := planillaRango(c)
+	f, err := ctrl.service.GeneratePlanillaExcel(c.Request.Context(), desde, hasta)
+	if err != nil {
+		utils.SetErrorMessage(c, "Error generando planilla: "+err.Error())
+		c.Redirect(http.StatusFound, "/admin/viaticos/planilla")
+		return
+	}
+
+	fileName := fmt.Sprintf("Planilla_Viaticos_%s_%s.xlsx", desde.Format("20060102"), hasta.Format("20060102"))
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename="+fileName)
+	_ = f.Write(c.Writer)
+}
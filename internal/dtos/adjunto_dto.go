@@ -0,0 +1,5 @@
+package dtos
+
+type RestaurarAdjuntoRequest struct {
+	Version int `form:"version" binding:"required,min=1"`
+}
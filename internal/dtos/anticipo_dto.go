@@ -0,0 +1,15 @@
+package dtos
+
+type SolicitarAnticipoRequest struct {
+	Monto    string `form:"monto" binding:"required"`
+	Concepto string `form:"concepto" binding:"required"`
+}
+
+type AprobarAnticipoRequest struct {
+	MontoAprobado string `form:"monto_aprobado" binding:"required"`
+	Observacion   string `form:"observacion"`
+}
+
+type RechazarAnticipoRequest struct {
+	Observacion string `form:"observacion" binding:"required"`
+}
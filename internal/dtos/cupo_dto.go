@@ -18,3 +18,27 @@ type TransferirCupoDerechoItemRequest struct {
 	Mes          string `form:"mes"`
 	ReturnURL    string `form:"return_url"`
 }
+
+type CreatePoliticaCupoRequest struct {
+	Nombre            string `form:"nombre" binding:"required"`
+	VigenteDesde      string `form:"vigente_desde" binding:"required"`
+	VigenteHasta      string `form:"vigente_hasta"`
+	SemanasPorMes     string `form:"semanas_por_mes"`
+	MesesReceso       string `form:"meses_receso"`
+	SemanasBloqueadas string `form:"semanas_bloqueadas"`
+	PermiteArrastre   bool   `form:"permite_arrastre"`
+	ArrastreMaximo    string `form:"arrastre_maximo"`
+	Observacion       string `form:"observacion"`
+
+	AjusteTipos         []string `form:"ajuste_tipo[]"`
+	AjusteDepartamentos []string `form:"ajuste_departamento[]"`
+	AjusteDeltas        []string `form:"ajuste_delta[]"`
+	AjusteMotivos       []string `form:"ajuste_motivo[]"`
+}
+
+type CreateLicenciaRequest struct {
+	TitularID  string `form:"titular_id" binding:"required"`
+	FechaDesde string `form:"fecha_desde" binding:"required"`
+	FechaHasta string `form:"fecha_hasta" binding:"required"`
+	Motivo     string `form:"motivo"`
+}
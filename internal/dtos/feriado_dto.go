@@ -0,0 +1,12 @@
+package dtos
+
+type CreateFeriadoRequest struct {
+	Nombre           string `form:"nombre" binding:"required"`
+	Tipo             string `form:"tipo" binding:"required"`
+	Mes              string `form:"mes"`
+	Dia              string `form:"dia"`
+	DiasDesdePascua  string `form:"dias_desde_pascua"`
+	Fecha            string `form:"fecha"`
+	Departamento     string `form:"departamento"`
+	TrasladarDomingo bool   `form:"trasladar_domingo"`
+}
@@ -0,0 +1,9 @@
+package dtos
+
+type FirmarDocumentoRequest struct {
+	Password string `form:"password" binding:"required"`
+}
+
+type RegistrarCertificadoRequest struct {
+	Password string `form:"password" binding:"required"`
+}
@@ -3,6 +3,7 @@ package dtos
 type CreatePasajeRequest struct {
 	SolicitudItemID string `form:"solicitud_item_id" binding:"required"`
 	Costo           string `form:"costo" binding:"required"`
+	Moneda          string `form:"moneda"`
 	FechaVuelo      string `form:"fecha_vuelo" binding:"required"`
 	FechaEmision    string `form:"fecha_emision"`
 	AerolineaID     string `form:"aerolinea_id"`
@@ -22,6 +23,7 @@ type CreatePasajeRequest struct {
 type UpdatePasajeRequest struct {
 	ID            string `form:"id" binding:"required"`
 	Costo         string `form:"costo" binding:"required"`
+	Moneda        string `form:"moneda"`
 	FechaVuelo    string `form:"fecha_vuelo" binding:"required"`
 	FechaEmision  string `form:"fecha_emision"`
 	AerolineaID   string `form:"aerolinea_id"`
@@ -36,6 +38,8 @@ type UpdatePasajeRequest struct {
 type UpdatePasajeStatusRequest struct {
 	ID     string `form:"id" binding:"required"`
 	Status string `form:"status" binding:"required"`
+
+	JustificacionMorosidad string `form:"justificacion_morosidad"`
 }
 
 type DevolverPasajeRequest struct {
@@ -44,6 +48,26 @@ type DevolverPasajeRequest struct {
 	CostoPenalidad string `form:"costo_penalidad"`
 }
 
+// ReemitirPasajeRequest registra el nuevo billete emitido por la agencia al cambiar el vuelo.
+type ReemitirPasajeRequest struct {
+	PasajeID         string `form:"pasaje_id" binding:"required"`
+	Motivo           string `form:"motivo" binding:"required"`
+	NumeroBillete    string `form:"numero_billete" binding:"required"`
+	NumeroVuelo      string `form:"numero_vuelo" binding:"required"`
+	FechaVuelo       string `form:"fecha_vuelo" binding:"required"`
+	FechaEmision     string `form:"fecha_emision" binding:"required"`
+	RutaID           string `form:"ruta_id"`
+	AerolineaID      string `form:"aerolinea_id"`
+	Costo            string `form:"costo" binding:"required"`
+	DiferenciaTarifa string `form:"diferencia_tarifa"`
+	Penalidad        string `form:"penalidad"`
+	NumeroFactura    string `form:"numero_factura"`
+	Glosa            string `form:"glosa"`
+
+	JustificacionMorosidad   string `form:"justificacion_morosidad"`
+	JustificacionSobreprecio string `form:"justificacion_sobreprecio"`
+}
+
 type UpdateServicioEmisionRequest struct {
 	ID            string `form:"id" binding:"required"`
 	RazonSocial   string `form:"servicio_razon_social"`
@@ -57,5 +81,26 @@ type CreatePasajeCargoRequest struct {
 	Tipo     string `form:"tipo" binding:"required"`
 	Factura  string `form:"factura" binding:"required"`
 	Monto    string `form:"monto" binding:"required"`
+	Moneda   string `form:"moneda"`
 	Glosa    string `form:"glosa"`
 }
+
+type CreateTipoCambioRequest struct {
+	Moneda string `form:"moneda" binding:"required"`
+	Fecha  string `form:"fecha" binding:"required"`
+	Valor  string `form:"valor" binding:"required"`
+	Fuente string `form:"fuente"`
+}
+
+// ETicketExtraidoResponse son los datos leídos del billete electrónico para precargar el formulario.
+type ETicketExtraidoResponse struct {
+	Extractor     string   `json:"extractor"`
+	AerolineaID   string   `json:"aerolinea_id"`
+	NumeroBillete string   `json:"numero_billete"`
+	NumeroVuelo   string   `json:"numero_vuelo"`
+	FechaVuelo    string   `json:"fecha_vuelo"`
+	Costo         float64  `json:"costo"`
+	Moneda        string   `json:"moneda"`
+	Ruta          string   `json:"ruta"`
+	Avisos        []string `json:"avisos"`
+}
@@ -0,0 +1,14 @@
+package dtos
+
+type CreateReglaDescargoRequest struct {
+	Concepto      string `form:"concepto"`
+	TipoSolicitud string `form:"tipo_solicitud"`
+	Requisito     string `form:"requisito" binding:"required"`
+	Etiqueta      string `form:"etiqueta"`
+	MinLongitud   string `form:"min_longitud"`
+}
+
+type UpdateReglaDescargoRequest struct {
+	Etiqueta    string `form:"etiqueta" binding:"required"`
+	MinLongitud string `form:"min_longitud"`
+}
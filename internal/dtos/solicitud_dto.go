@@ -38,6 +38,9 @@ type CreateSolicitudRequest struct {
 	SoloIda            bool   `form:"solo_ida"`
 	SoloVuelta         bool   `form:"solo_vuelta"`
 	TramosExtraJSON    string `form:"tramos_extra_json"`
+
+	JustificacionConflicto string `form:"justificacion_conflicto"`
+	JustificacionMorosidad string `form:"justificacion_morosidad"`
 }
 
 type UpdateSolicitudRequest struct {
@@ -61,4 +64,6 @@ type UpdateSolicitudRequest struct {
 	VueltaPorConfirmar   bool   `form:"vuelta_por_confirmar"`
 	SoloIda              bool   `form:"solo_ida"`
 	SoloVuelta           bool   `form:"solo_vuelta"`
+
+	JustificacionConflicto string `form:"justificacion_conflicto"`
 }
@@ -19,4 +19,7 @@ type CreateSolicitudOficialRequest struct {
 	AerolineaID         string                `form:"aerolinea_id"`
 	TramosIda           []TramoOficialRequest `form:"-"`
 	TramosVuelta        []TramoOficialRequest `form:"-"`
+
+	JustificacionConflicto string `form:"justificacion_conflicto"`
+	JustificacionMorosidad string `form:"justificacion_morosidad"`
 }
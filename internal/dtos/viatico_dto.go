@@ -0,0 +1,18 @@
+package dtos
+
+type CreateCategoriaViaticoRequest struct {
+	Codigo        string `form:"codigo" binding:"required"`
+	Nombre        string `form:"nombre" binding:"required"`
+	ZonaViaticoID string `form:"zona_viatico_id" binding:"required"`
+	AmbitoCodigo  string `form:"ambito_codigo" binding:"required"`
+	Monto         string `form:"monto" binding:"required"`
+	Moneda        string `form:"moneda" binding:"required"`
+}
+
+type CreateZonaViaticoRequest struct {
+	Nombre string `form:"nombre" binding:"required"`
+}
+
+type CalcularViaticoRequest struct {
+	ZonaViaticoID string `form:"zona_viatico_id" binding:"required"`
+}
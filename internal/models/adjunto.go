@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"path/filepath"
+)
+
+// Campos de archivo con historial de versiones; la clave se guarda en Adjunto.Campo.
+const (
+	AdjuntoPaseTramo      = "descargo_tramo.pase_abordo"
+	AdjuntoPasajeBoleto   = "pasaje.archivo"
+	AdjuntoPasajePase     = "pasaje.pase_abordo"
+	AdjuntoPasajeServicio = "pasaje.servicio"
+	AdjuntoPasajeBoleta   = "pasaje.comprobante"
+	AdjuntoCargo          = "pasaje_cargo.archivo"
+	AdjuntoAnexo          = "anexo_descargo.archivo"
+	AdjuntoMemorandum     = "descargo_oficial.memorandum"
+	AdjuntoBoletaDeposito = "descargo_oficial.boleta_deposito"
+)
+
+// CampoAdjunto indica dónde vive la ruta vigente de un campo versionado, para que al restaurar
+// una versión la fila que lo referencia apunte al archivo restaurado.
+type CampoAdjunto struct {
+	Etiqueta       string
+	Tabla          string
+	ColumnaArchivo string
+	ColumnaAdjunto string
+}
+
+var CamposAdjunto = map[string]CampoAdjunto{
+	AdjuntoPaseTramo:      {"Pase de abordar", "descargo_tramos", "archivo_pase_abordo", "pase_abordo_adjunto_id"},
+	AdjuntoPasajeBoleto:   {"Boleto / e-ticket", "pasajes", "archivo", "archivo_adjunto_id"},
+	AdjuntoPasajePase:     {"Pase de abordar del pasaje", "pasajes", "archivo_pase_abordo", "pase_abordo_adjunto_id"},
+	AdjuntoPasajeServicio: {"Factura de servicio", "pasajes", "servicio_archivo", "servicio_adjunto_id"},
+	AdjuntoPasajeBoleta:   {"Boleta de depósito del reembolso", "pasajes", "archivo_comprobante", "comprobante_adjunto_id"},
+	AdjuntoCargo:          {"Factura de cargo", "pasaje_cargos", "archivo", "adjunto_id"},
+	AdjuntoAnexo:          {"Anexo del informe", "anexos_descargo", "archivo", "adjunto_id"},
+	AdjuntoMemorandum:     {"Memorándum", "descargos_oficiales", "archivo_memorandum", "memorandum_adjunto_id"},
+	AdjuntoBoletaDeposito: {"Boleta de depósito del anticipo", "descargos_oficiales", "archivo_boleta_deposito", "boleta_deposito_adjunto_id"},
+}
+
+func mismoAdjunto(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// Adjunto agrupa las versiones de un archivo de respaldo. Reemplazar el archivo agrega una
+// versión en lugar de perder la anterior; Archivo es siempre la ruta de la versión vigente.
+type Adjunto struct {
+	BaseModel
+	Campo         string           `gorm:"size:50;not null;index"`
+	Archivo       string           `gorm:"size:255;not null"`
+	VersionActual int              `gorm:"not null;default:1"`
+	Versiones     []AdjuntoVersion `gorm:"foreignKey:AdjuntoID"`
+}
+
+func (Adjunto) TableName() string {
+	return "adjuntos"
+}
+
+func (a Adjunto) GetEtiqueta() string {
+	if c, ok := CamposAdjunto[a.Campo]; ok {
+		return c.Etiqueta
+	}
+	return "Adjunto"
+}
+
+type AdjuntoVersion struct {
+	BaseModel
+	AdjuntoID      string   `gorm:"size:36;not null;uniqueIndex:idx_adjunto_version"`
+	Version        int      `gorm:"not null;uniqueIndex:idx_adjunto_version"`
+	Archivo        string   `gorm:"size:255;not null"`
+	NombreOriginal string   `gorm:"size:255"`
+	Hash           string   `gorm:"size:64;index"` // SHA-256 del contenido
+	Tamano         int64    `gorm:"default:0"`
+	SubidoPorID    *string  `gorm:"size:36;index"`
+	SubidoPor      *Usuario `gorm:"foreignKey:SubidoPorID;<-:false"`
+
+	// Versión de la que se copió el archivo al restaurar; 0 si fue una subida.
+	RestauradaDe int `gorm:"default:0"`
+}
+
+func (AdjuntoVersion) TableName() string {
+	return "adjunto_versiones"
+}
+
+func (v AdjuntoVersion) GetNombre() string {
+	if v.NombreOriginal != "" {
+		return v.NombreOriginal
+	}
+	return filepath.Base(v.Archivo)
+}
+
+func (v AdjuntoVersion) GetHashCorto() string {
+	if len(v.Hash) > 12 {
+		return v.Hash[:12]
+	}
+	return v.Hash
+}
+
+func (v AdjuntoVersion) GetTamanoDisplay() string {
+	switch {
+	case v.Tamano <= 0:
+		return "-"
+	case v.Tamano < 1024:
+		return fmt.Sprintf("%d B", v.Tamano)
+	case v.Tamano < 1024*1024:
+		return fmt.Sprintf("%.1f KB", float64(v.Tamano)/1024)
+	default:
+		return fmt.Sprintf("%.1f MB", float64(v.Tamano)/(1024*1024))
+	}
+}
+
+func (v AdjuntoVersion) GetSubidoPorNombre() string {
+	if v.SubidoPor != nil {
+		return v.SubidoPor.GetNombreCompleto()
+	}
+	return "Registro previo"
+}
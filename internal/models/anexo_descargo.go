@@ -5,6 +5,8 @@ type AnexoDescargo struct {
 	DescargoOficialID string `gorm:"size:36;not null;index"`
 	Archivo           string `gorm:"size:255;not null"`
 
+	AdjuntoID *string `gorm:"size:36;index"`
+
 	// Seq is an auto-incrementing field managed by DB to ensure atomic sequential ordering
 	Seq int64 `gorm:"autoIncrement;not null;<-:false"`
 }
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	EstadoAnticipoSolicitado = "SOLICITADO"
+	EstadoAnticipoAprobado   = "APROBADO"
+	EstadoAnticipoRechazado  = "RECHAZADO"
+	EstadoAnticipoLiquidado  = "LIQUIDADO"
+)
+
+// Anticipo es el adelanto en bolivianos para transporte terrestre y gastos de una comisión
+// oficial. Se liquida contra los gastos del descargo (transporte terrestre y viáticos) al aprobarlo.
+type Anticipo struct {
+	BaseModel
+	SolicitudID string     `gorm:"size:36;not null;uniqueIndex"`
+	Solicitud   *Solicitud `gorm:"foreignKey:SolicitudID;<-:false"`
+	UsuarioID   string     `gorm:"size:36;not null;index"`
+	Usuario     *Usuario   `gorm:"foreignKey:UsuarioID;<-:false"`
+
+	Concepto        string  `gorm:"type:text"`
+	MontoSolicitado float64 `gorm:"type:decimal(15,2);not null"`
+	MontoAprobado   float64 `gorm:"type:decimal(15,2);default:0"`
+	Estado          string  `gorm:"size:20;not null;default:'SOLICITADO';index"`
+
+	RevisadoPorID *string    `gorm:"size:36"`
+	RevisadoPor   *Usuario   `gorm:"foreignKey:RevisadoPorID;<-:false"`
+	FechaRevision *time.Time `gorm:"type:timestamp"`
+	Observacion   string     `gorm:"type:text"`
+
+	// Liquidación contra el descargo.
+	DescargoID       *string    `gorm:"size:36;index"`
+	GastoDeclarado   float64    `gorm:"type:decimal(15,2);default:0"`
+	SaldoDevolver    float64    `gorm:"type:decimal(15,2);default:0"`
+	SaldoReembolsar  float64    `gorm:"type:decimal(15,2);default:0"`
+	FechaLiquidacion *time.Time `gorm:"type:timestamp"`
+}
+
+func (Anticipo) TableName() string {
+	return "anticipos"
+}
+
+// Liquidar compara el monto aprobado con lo gastado: el excedente lo devuelve el viajero
+// y el faltante se le reembolsa.
+func (a *Anticipo) Liquidar(gasto float64) {
+	a.GastoDeclarado = math.Round(gasto*100) / 100
+	saldo := math.Round((a.MontoAprobado-a.GastoDeclarado)*100) / 100
+	a.SaldoDevolver, a.SaldoReembolsar = 0, 0
+	if saldo > 0 {
+		a.SaldoDevolver = saldo
+	} else if saldo < 0 {
+		a.SaldoReembolsar = -saldo
+	}
+}
+
+// IsVigente indica si el anticipo fue entregado y debe liquidarse en el descargo.
+func (a Anticipo) IsVigente() bool {
+	return a.Estado == EstadoAnticipoAprobado || a.Estado == EstadoAnticipoLiquidado
+}
+
+func (a Anticipo) IsPendiente() bool {
+	return a.Estado == EstadoAnticipoSolicitado
+}
+
+func (a Anticipo) GetEstadoBadgeClass() string {
+	switch a.Estado {
+	case EstadoAnticipoAprobado:
+		return "bg-success-50 text-success-700 border-success-200"
+	case EstadoAnticipoRechazado:
+		return "bg-danger-50 text-danger-700 border-danger-200"
+	case EstadoAnticipoLiquidado:
+		return "bg-primary-50 text-primary-700 border-primary-200"
+	default:
+		return "bg-warning-50 text-warning-700 border-warning-200"
+	}
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var reBilleteNoAlfanumerico = regexp.MustCompile(`[^0-9A-Za-z]`)
+
+// NormalizarBillete quita separadores y espacios para comparar números de billete
+// escritos de distinta forma (930-2112345678, 930 2112345678, ...).
+func NormalizarBillete(numero string) string {
+	return strings.ToUpper(reBilleteNoAlfanumerico.ReplaceAllString(numero, ""))
+}
+
+// Los registros que llevan número de billete guardan además su forma normalizada, indexada,
+// para buscar duplicados por igualdad.
+
+func (p *Pasaje) BeforeSave(tx *gorm.DB) error {
+	p.BilleteNormalizado = NormalizarBillete(p.NumeroBillete)
+	return nil
+}
+
+func (d *DescargoTramo) BeforeSave(tx *gorm.DB) error {
+	d.BilleteNormalizado = NormalizarBillete(d.Billete)
+	return nil
+}
+
+func (o *OpenTicket) BeforeSave(tx *gorm.DB) error {
+	o.BilleteNormalizado = NormalizarBillete(o.NumeroBillete)
+	return nil
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// CalendarioFeed guarda el token privado con el que un usuario suscribe su calendario
+// (ICS) de viajes y cupos desde aplicaciones externas.
+type CalendarioFeed struct {
+	BaseModel
+	UsuarioID string   `gorm:"size:36;not null;uniqueIndex"`
+	Usuario   *Usuario `gorm:"foreignKey:UsuarioID;<-:false"`
+
+	Token        string     `gorm:"size:64;not null;uniqueIndex"`
+	UltimoAcceso *time.Time `gorm:"type:timestamp"`
+}
+
+func (CalendarioFeed) TableName() string {
+	return "calendario_feeds"
+}
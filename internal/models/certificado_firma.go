@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// CertificadoFirma es el certificado PKCS#12 con el que un usuario firma digitalmente los
+// formularios. La contraseña no se guarda: se valida al registrarlo y se pide en cada firma.
+type CertificadoFirma struct {
+	BaseModel
+	UsuarioID string   `gorm:"size:36;not null;uniqueIndex"`
+	Usuario   *Usuario `gorm:"foreignKey:UsuarioID;<-:false"`
+
+	Archivo     string    `gorm:"size:255;not null"`
+	Sujeto      string    `gorm:"size:255"`
+	Emisor      string    `gorm:"size:255"`
+	NumeroSerie string    `gorm:"size:100"`
+	Huella      string    `gorm:"size:64"`
+	ValidoDesde time.Time `gorm:"type:timestamp"`
+	ValidoHasta time.Time `gorm:"type:timestamp"`
+}
+
+func (CertificadoFirma) TableName() string {
+	return "certificados_firma"
+}
+
+func (c *CertificadoFirma) IsVigente() bool {
+	now := time.Now()
+	return now.After(c.ValidoDesde) && now.Before(c.ValidoHasta)
+}
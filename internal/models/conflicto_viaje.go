@@ -0,0 +1,91 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type NivelConflicto string
+
+const (
+	NivelConflictoBloqueante  NivelConflicto = "BLOQUEANTE"
+	NivelConflictoAdvertencia NivelConflicto = "ADVERTENCIA"
+)
+
+// ConflictoViaje describe un choque de fechas entre un tramo evaluado y otro viaje
+// del mismo beneficiario (tramo de otra solicitud o pasaje emitido). No se persiste.
+type ConflictoViaje struct {
+	Nivel           NivelConflicto
+	Fecha           time.Time
+	SolicitudID     string
+	SolicitudCodigo string
+	Concepto        string
+	Ruta            string
+	NumeroBillete   string
+	Motivo          string
+}
+
+func (c ConflictoViaje) IsBloqueante() bool {
+	return c.Nivel == NivelConflictoBloqueante
+}
+
+func (c ConflictoViaje) GetBadgeClass() string {
+	if c.IsBloqueante() {
+		return "bg-danger-50 text-danger-700 border-danger-200"
+	}
+	return "bg-warning-50 text-warning-700 border-warning-200"
+}
+
+func (c ConflictoViaje) String() string {
+	ref := c.SolicitudCodigo
+	if c.NumeroBillete != "" {
+		ref = fmt.Sprintf("%s (billete %s)", ref, c.NumeroBillete)
+	}
+	return fmt.Sprintf("%s %s %s: %s", c.Fecha.Format("02/01/2006"), ref, c.Ruta, c.Motivo)
+}
+
+// ConflictosViaje agrupa los conflictos detectados para una acción.
+type ConflictosViaje []ConflictoViaje
+
+func (cs ConflictosViaje) Bloqueantes() ConflictosViaje {
+	var out ConflictosViaje
+	for _, c := range cs {
+		if c.IsBloqueante() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (cs ConflictosViaje) Advertencias() ConflictosViaje {
+	var out ConflictosViaje
+	for _, c := range cs {
+		if !c.IsBloqueante() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (cs ConflictosViaje) HasBloqueantes() bool {
+	return len(cs.Bloqueantes()) > 0
+}
+
+func (cs ConflictosViaje) Resumen() string {
+	lines := make([]string, 0, len(cs))
+	for _, c := range cs {
+		lines = append(lines, c.String())
+	}
+	return strings.Join(lines, "; ")
+}
+
+// ConflictoViajeError se retorna cuando existen conflictos bloqueantes sin justificación de un administrador.
+type ConflictoViajeError struct {
+	Conflictos ConflictosViaje
+}
+
+func (e *ConflictoViajeError) Error() string {
+	return "el beneficiario ya tiene viajes en las mismas fechas: " + e.Conflictos.Resumen() +
+		". Un administrador puede autorizarlo registrando una justificación"
+}
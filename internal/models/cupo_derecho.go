@@ -6,15 +6,17 @@ import (
 
 type CupoDerecho struct {
 	BaseModel
-	SenTitularID string            `gorm:"size:36;not null;index;comment:Senador dueño del cupo por derecho"`
-	SenTitular   *Usuario          `gorm:"foreignKey:SenTitularID"`
-	Gestion      int               `gorm:"not null;index"`
-	Mes          int               `gorm:"not null;index"`
-	TotalSemanas int               `gorm:"not null"`
-	CupoTotal    int               `gorm:"not null"`
-	CupoUsado    int               `gorm:"default:0"`
-	Items        []CupoDerechoItem `gorm:"foreignKey:CupoDerechoID"`
-	Saldo        int               `gorm:"-"`
+	SenTitularID   string            `gorm:"size:36;not null;index;comment:Senador dueño del cupo por derecho"`
+	SenTitular     *Usuario          `gorm:"foreignKey:SenTitularID"`
+	Gestion        int               `gorm:"not null;index"`
+	Mes            int               `gorm:"not null;index"`
+	TotalSemanas   int               `gorm:"not null"`
+	CupoTotal      int               `gorm:"not null"`
+	CupoUsado      int               `gorm:"default:0"`
+	PoliticaCupoID *string           `gorm:"size:36;default:null;comment:Política aplicada al generar el periodo"`
+	PoliticaCupo   *PoliticaCupo     `gorm:"foreignKey:PoliticaCupoID"`
+	Items          []CupoDerechoItem `gorm:"foreignKey:CupoDerechoID"`
+	Saldo          int               `gorm:"-"`
 }
 
 func (c *CupoDerecho) AfterFind(tx *gorm.DB) (err error) {
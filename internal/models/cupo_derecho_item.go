@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -106,10 +107,21 @@ func (v CupoDerechoItem) IsActiveWeek() bool {
 	return now.After(*v.FechaDesde) && now.Before(v.FechaHasta.Add(24*time.Hour))
 }
 
+// IsSemanal distingue los cupos semanales de los adicionales o de arrastre de una política.
+func (v CupoDerechoItem) IsSemanal() bool {
+	return strings.HasPrefix(v.Semana, "Semana ")
+}
+
 func (v CupoDerechoItem) IsDisponible() bool {
 	return v.EstadoCupoDerechoCodigo == "DISPONIBLE"
 }
 
+// IsConsumido indica si el cupo cuenta como usado para su titular: está reservado, usado
+// o fue transferido a otro senador.
+func (v CupoDerechoItem) IsConsumido() bool {
+	return !v.IsDisponible() || v.SenAsignadoID != v.SenTitularID
+}
+
 func (v CupoDerechoItem) CanBeReverted() bool {
 	for i := range v.Solicitudes {
 		s := &v.Solicitudes[i]
@@ -126,6 +138,7 @@ func (v CupoDerechoItem) CanBeReverted() bool {
 
 type CupoDerechoItemPermissions struct {
 	CanTransfer        bool
+	CanSolicitarTransf bool
 	CanRevert          bool
 	CanPrint           bool
 	CanTomarCupo       bool
@@ -175,6 +188,10 @@ func (v CupoDerechoItem) GetPermissions(authUser *Usuario, targetUser *Usuario,
 	if isViewerAdminOrResponsable && !isTransferido && !perms.CanAsignarCupo {
 		perms.CanTransfer = true
 	}
+	isTitularPage := targetUser.ID == v.SenTitularID
+	if !isViewerAdminOrResponsable && isTitularPage && (authUser.ID == v.SenTitularID || isEncargado) && isDisponible && !isVencido && !isTransferido {
+		perms.CanSolicitarTransf = true
+	}
 	if isOwner || isViewerAdminOrResponsable || isEncargado {
 		sol := v.GetSolicitud()
 		hasOrigin := targetUser.OrigenIATA != nil && *targetUser.OrigenIATA != ""
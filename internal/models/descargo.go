@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 type EstadoDescargo string
 
@@ -42,6 +45,7 @@ type Descargo struct {
 
 	Estado  EstadoDescargo   `gorm:"size:50;default:'BORRADOR'"`
 	Oficial *DescargoOficial `gorm:"foreignKey:DescargoID"`
+	Viatico *Viatico         `gorm:"foreignKey:DescargoID;<-:false"`
 
 	authUser    *Usuario             `gorm:"-"`
 	Permissions *DescargoPermissions `gorm:"-"`
@@ -57,39 +61,21 @@ func (d Descargo) HasChanges(other Descargo) bool {
 		d.Estado != other.Estado
 }
 
-func (d Descargo) IsComplete() bool {
-	hasItinerary := false
-	for _, it := range d.Tramos {
-		if !it.EsOpenTicket {
-			hasItinerary = true
-			if it.Billete == "" || it.NumeroPaseAbordo == "" || it.ArchivoPaseAbordo == "" {
-				return false
-			}
-		}
-	}
-
-	if !hasItinerary && !d.allDevolucion() {
-		return false
-	}
-
-	isOficial := false
-	if d.Solicitud != nil {
-		isOficial = d.Solicitud.GetConceptoCodigo() == "OFICIAL"
-	}
-
-	if isOficial {
-		if d.Oficial == nil {
-			return false
-		}
-		if d.Oficial.ObjetivoViaje == "" ||
-			d.Oficial.InformeActividades == "" ||
-			d.Oficial.ResultadosViaje == "" ||
-			d.Oficial.ConclusionesRecomendaciones == "" {
-			return false
+// GetTramosConDiscrepanciaPase retorna los tramos cuyo pase de abordar no coincide con el
+// código de barras leído, para advertir al revisor antes de aprobar.
+func (d Descargo) GetTramosConDiscrepanciaPase() []DescargoTramo {
+	var tramos []DescargoTramo
+	for _, t := range d.Tramos {
+		if t.PaseVerificacion == PaseDiscrepancia {
+			tramos = append(tramos, t)
 		}
 	}
+	return tramos
+}
 
-	return true
+// IsComplete verifica el descargo contra las reglas de completitud de su concepto.
+func (d Descargo) IsComplete() bool {
+	return len(FaltantesDescargo(d, d.Solicitud)) == 0
 }
 
 func (d Descargo) allDevolucion() bool {
@@ -105,73 +91,7 @@ func (d Descargo) allDevolucion() bool {
 }
 
 func (d Descargo) GetMissingItemsHTML() string {
-	var missing []string
-
-	// Check Itinerary
-	hasItinerary := false
-	missingBilletes := false
-	missingPases := false
-	missingArchivos := false
-
-	for _, it := range d.Tramos {
-		if !it.EsOpenTicket {
-			hasItinerary = true
-			if it.Billete == "" {
-				missingBilletes = true
-			}
-			if it.NumeroPaseAbordo == "" {
-				missingPases = true
-			}
-			if it.ArchivoPaseAbordo == "" {
-				missingArchivos = true
-			}
-		}
-	}
-
-	if !hasItinerary && !d.allDevolucion() {
-		missing = append(missing, "Itinerario de viaje")
-	} else {
-		if missingBilletes {
-			missing = append(missing, "N° Billetes")
-		}
-		if missingPases {
-			missing = append(missing, "N° Pases a Bordo")
-		}
-		if missingArchivos {
-			missing = append(missing, "Archivos PDF/Imagen de Pases")
-		}
-	}
-
-	// Check Official Report
-	isOficial := false
-	if d.Solicitud != nil {
-		isOficial = d.Solicitud.GetConceptoCodigo() == "OFICIAL"
-	}
-
-	if isOficial {
-		if d.Oficial == nil {
-			missing = append(missing, "Informe Oficial PV-06")
-		} else {
-			if d.Oficial.ObjetivoViaje == "" ||
-				d.Oficial.InformeActividades == "" ||
-				d.Oficial.ResultadosViaje == "" ||
-				d.Oficial.ConclusionesRecomendaciones == "" {
-				missing = append(missing, "Campos del Informe Detallado")
-			}
-		}
-	}
-
-	if len(missing) == 0 {
-		return "Datos completos"
-	}
-
-	html := "<div class='text-left'><p class='text-[10px] font-bold border-b border-white/20 pb-1 mb-1'>Pendiente:</p><ul class='list-inside list-disc space-y-0.5'>"
-	for i := range missing {
-		html += "<li class='text-[10px]'>" + missing[i] + "</li>"
-	}
-	html += "</ul></div>"
-
-	return html
+	return FaltantesDescargoHTML(FaltantesDescargo(d, d.Solicitud), "Pendiente:")
 }
 
 func (Descargo) TableName() string {
@@ -253,6 +173,12 @@ func (d Descargo) IsInRevision() bool {
 	return d.Estado == EstadoDescargoEnRevision || d.Estado == EstadoDescargoEnRevisionOT
 }
 
+// PermiteLiquidarViatico indica si aún se puede (re)calcular el viático: una vez aprobado, el
+// descargo ya pasó a la planilla de pago.
+func (d Descargo) PermiteLiquidarViatico() bool {
+	return d.IsEditable() || d.IsInRevision()
+}
+
 func (d Descargo) CanEdit(user *Usuario) bool {
 	if !d.IsEditable() {
 		return false
@@ -300,6 +226,19 @@ func (d Descargo) CanRevertFinalization(u *Usuario) bool {
 	}
 	return u.IsAdminOrResponsable() && (d.Estado == EstadoDescargoFinalizado || d.Estado == EstadoDescargoOpenTicket)
 }
+
+// CanFirmar indica si el usuario puede firmar el formulario del descargo: el beneficiario, su
+// encargado o un administrador/responsable.
+func (d Descargo) CanFirmar(user *Usuario) bool {
+	if user == nil {
+		return false
+	}
+	if user.IsAdminOrResponsable() {
+		return true
+	}
+	return d.Solicitud != nil && d.Solicitud.CanFirmar(user)
+}
+
 func (d Descargo) isOwnerOrAdmin(user *Usuario) bool {
 	if user == nil {
 		return false
@@ -373,12 +312,25 @@ func (d Descargo) GetTransporteDisplay() string {
 	return " - "
 }
 
+// GastosDeclarados suma los gastos de la comisión que se descuentan del anticipo: el transporte
+// terrestre del informe y el líquido de la liquidación de viáticos, en bolivianos.
+func (d Descargo) GastosDeclarados() float64 {
+	total := 0.0
+	if d.Oficial != nil {
+		total += d.Oficial.GastosTransporteTerrestre()
+	}
+	if d.Viatico != nil {
+		total += d.Viatico.Liquido
+	}
+	return math.Round(total*100) / 100
+}
+
 func (d Descargo) GetTotalDevolucionPasajes() float64 {
 	totalValue := 0.0
 	if d.Solicitud != nil {
 		for _, item := range d.Solicitud.Items {
 			for _, p := range item.Pasajes {
-				totalValue += p.MontoReembolso
+				totalValue += p.GetMontoReembolsoBs()
 			}
 		}
 	}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"math"
 	"strings"
 	"time"
 )
@@ -19,9 +20,14 @@ type DescargoOficial struct {
 	ResultadosViaje             string `gorm:"column:resultados_viaje;type:text"`
 	ConclusionesRecomendaciones string `gorm:"column:conclusiones_recomendaciones;type:text"`
 
-	NroBoletaDeposito string `gorm:"size:100"`
-	DirigidoA         string `gorm:"size:255"`
-	LugarViaje        string `gorm:"size:100;default:''"`
+	NroBoletaDeposito     string `gorm:"size:100"`
+	ArchivoBoletaDeposito string `gorm:"size:255"`
+	DirigidoA             string `gorm:"size:255"`
+	LugarViaje            string `gorm:"size:100;default:''"`
+
+	// Historial de versiones de ArchivoMemorandum y ArchivoBoletaDeposito.
+	MemorandumAdjuntoID     *string `gorm:"size:36;index"`
+	BoletaDepositoAdjuntoID *string `gorm:"size:36;index"`
 
 	FechaSalida  time.Time `gorm:"type:timestamp"`
 	FechaRetorno time.Time `gorm:"type:timestamp"`
@@ -42,6 +48,9 @@ func (d DescargoOficial) HasChanges(other DescargoOficial) bool {
 		d.ResultadosViaje != other.ResultadosViaje ||
 		d.ConclusionesRecomendaciones != other.ConclusionesRecomendaciones ||
 		d.NroBoletaDeposito != other.NroBoletaDeposito ||
+		d.ArchivoBoletaDeposito != other.ArchivoBoletaDeposito ||
+		!mismoAdjunto(d.MemorandumAdjuntoID, other.MemorandumAdjuntoID) ||
+		!mismoAdjunto(d.BoletaDepositoAdjuntoID, other.BoletaDepositoAdjuntoID) ||
 		d.DirigidoA != other.DirigidoA ||
 		!d.FechaSalida.Equal(other.FechaSalida) ||
 		!d.FechaRetorno.Equal(other.FechaRetorno)
@@ -109,3 +118,12 @@ func (d DescargoOficial) GetTransporteList() []string {
 	}
 	return res
 }
+
+// GastosTransporteTerrestre suma los importes de transporte terrestre registrados en el informe.
+func (d DescargoOficial) GastosTransporteTerrestre() float64 {
+	total := 0.0
+	for _, t := range d.TransportesTerrestres {
+		total += t.Importe
+	}
+	return math.Round(total*100) / 100
+}
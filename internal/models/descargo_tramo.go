@@ -16,6 +16,14 @@ const (
 	TipoTramoVueltaReutilizada  TipoDescargoTramo = "VUELTA_REUT"
 )
 
+// Resultado de leer el código de barras (BCBP) del pase de abordar.
+const (
+	PaseVerificado   = "VERIFICADO"   // Los datos del código coinciden con el tramo
+	PaseDiscrepancia = "DISCREPANCIA" // El código se leyó pero difiere del tramo, pasaje o viajero
+	PaseSinCodigo    = "SIN_CODIGO"   // No se encontró un código legible en el archivo
+	PasePendiente    = "PENDIENTE"    // El archivo está en cola para leer su código
+)
+
 type DescargoTramo struct {
 	BaseModel
 	DescargoID        string            `gorm:"size:36;not null;index"`
@@ -35,6 +43,17 @@ type DescargoTramo struct {
 	EsModificacion    bool              `gorm:"default:false"`
 	EsReutilizado     bool              `gorm:"default:false"`
 
+	// BilleteNormalizado es Billete sin separadores; se completa al guardar.
+	BilleteNormalizado string `gorm:"size:100;index"`
+
+	// Historial de versiones del pase de abordar; ArchivoPaseAbordo es la versión vigente.
+	PaseAbordoAdjuntoID *string `gorm:"size:36;index"`
+
+	// Verificación del código de barras del pase; las discrepancias van una por línea.
+	PaseVerificacion  string `gorm:"size:20;default:''"`
+	PaseDiscrepancias string `gorm:"type:text;default:''"`
+	PaseBCBP          string `gorm:"size:500;default:''"`
+
 	OrigenIATA  *string  `gorm:"size:5;index" json:"origen_iata"`
 	Origen      *Destino `gorm:"foreignKey:OrigenIATA;references:IATA;<-:false" json:"origen"`
 	DestinoIATA *string  `gorm:"size:5;index" json:"destino_iata"`
@@ -89,6 +108,12 @@ func (d DescargoTramo) IsReprogramacion() bool {
 	return strings.HasSuffix(upper, "_REPRO") || strings.HasSuffix(upper, "_REPROG")
 }
 
+// IsDeEmision indica si el tramo se genera desde un pasaje emitido (original o reemisión) y
+// se sincroniza con él.
+func (d DescargoTramo) IsDeEmision() bool {
+	return d.PasajeID != nil && (d.IsOriginal() || d.IsReprogramacion())
+}
+
 func (d DescargoTramo) IsReutilizacion() bool {
 	return strings.HasSuffix(strings.ToUpper(string(d.Tipo)), "_REUT")
 }
@@ -122,6 +147,38 @@ func (d DescargoTramo) GetFechaStr() string {
 	return ""
 }
 
+func (d DescargoTramo) GetPaseDiscrepancias() []string {
+	if d.PaseDiscrepancias == "" {
+		return nil
+	}
+	return strings.Split(d.PaseDiscrepancias, "\n")
+}
+
+func (d DescargoTramo) GetPaseVerificacionLabel() string {
+	switch d.PaseVerificacion {
+	case PaseVerificado:
+		return "Código verificado"
+	case PaseDiscrepancia:
+		return "Discrepancia"
+	case PaseSinCodigo:
+		return "Sin código legible"
+	case PasePendiente:
+		return "Verificando código"
+	}
+	return ""
+}
+
+func (d DescargoTramo) GetPaseVerificacionBadgeClass() string {
+	switch d.PaseVerificacion {
+	case PaseVerificado:
+		return "bg-success-50 text-success-700 border-success-200"
+	case PaseDiscrepancia:
+		return "bg-danger-50 text-danger-700 border-danger-200"
+	default:
+		return "bg-neutral-50 text-neutral-600 border-neutral-200"
+	}
+}
+
 func (d DescargoTramo) GetTipoDisplay() string {
 	switch d.Tipo {
 	case TipoTramoIdaOriginal:
@@ -212,7 +269,8 @@ func (d DescargoTramo) HasChanges(other DescargoTramo) bool {
 		cmpPtr(d.PasajeID, other.PasajeID) ||
 		cmpPtr(d.SolicitudItemID, other.SolicitudItemID) ||
 		cmpPtr(d.OrigenIATA, other.OrigenIATA) ||
-		cmpPtr(d.DestinoIATA, other.DestinoIATA) {
+		cmpPtr(d.DestinoIATA, other.DestinoIATA) ||
+		cmpPtr(d.PaseAbordoAdjuntoID, other.PaseAbordoAdjuntoID) {
 		return true
 	}
 
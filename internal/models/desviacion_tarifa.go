@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+type EstadoDesviacionTarifa string
+
+const (
+	EstadoDesviacionDentroTolerancia EstadoDesviacionTarifa = "DENTRO_TOLERANCIA"
+	EstadoDesviacionAutorizada       EstadoDesviacionTarifa = "AUTORIZADA"
+)
+
+// DesviacionTarifa registra la comparación del costo de un pasaje contra la tarifa contratada
+// (RutaContrato) al momento de emitirlo. Un pasaje tiene a lo sumo un registro, que se
+// actualiza si vuelve a emitirse.
+type DesviacionTarifa struct {
+	BaseModel
+	PasajeID string  `gorm:"size:36;not null;uniqueIndex"`
+	Pasaje   *Pasaje `gorm:"foreignKey:PasajeID;<-:false"`
+
+	RutaID      string     `gorm:"size:36;not null;index"`
+	Ruta        *Ruta      `gorm:"foreignKey:RutaID;<-:false"`
+	AerolineaID string     `gorm:"size:36;not null;index"`
+	Aerolinea   *Aerolinea `gorm:"foreignKey:AerolineaID;<-:false"`
+	AgenciaID   *string    `gorm:"size:36;index"`
+	Agencia     *Agencia   `gorm:"foreignKey:AgenciaID;<-:false"`
+
+	MontoReferencial float64 `gorm:"type:decimal(10,2);not null"`
+	Costo            float64 `gorm:"type:decimal(10,2);not null"` // En Bs, al tipo de cambio de emisión
+	DesviacionPct    float64 `gorm:"type:decimal(7,2);not null"`
+	ToleranciaPct    float64 `gorm:"type:decimal(5,2);not null"`
+
+	Estado            EstadoDesviacionTarifa `gorm:"size:20;not null;index"`
+	Justificacion     string                 `gorm:"type:text"`
+	AutorizadoPorID   *string                `gorm:"size:36"`
+	AutorizadoPor     *Usuario               `gorm:"foreignKey:AutorizadoPorID;<-:false"`
+	FechaAutorizacion *time.Time             `gorm:"type:timestamp"`
+}
+
+func (DesviacionTarifa) TableName() string {
+	return "desviaciones_tarifa"
+}
+
+func (d DesviacionTarifa) ExcedeTolerancia() bool {
+	return d.DesviacionPct > d.ToleranciaPct
+}
+
+// GetSobreprecio retorna el monto pagado por encima de la tarifa contratada (0 si fue menor).
+func (d DesviacionTarifa) GetSobreprecio() float64 {
+	if d.Costo <= d.MontoReferencial {
+		return 0
+	}
+	return d.Costo - d.MontoReferencial
+}
+
+// IsAutorizadaPara indica si la autorización sigue siendo válida para el costo actual del
+// pasaje; si el costo cambió después de autorizar, hay que volver a autorizar.
+func (d DesviacionTarifa) IsAutorizadaPara(costo float64) bool {
+	return d.Estado == EstadoDesviacionAutorizada && d.Costo == costo
+}
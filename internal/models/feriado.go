@@ -0,0 +1,195 @@
+package models
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tipos de feriado según cómo se determina su fecha.
+const (
+	FeriadoFijo  = "FIJO"  // Mismo día y mes cada año
+	FeriadoMovil = "MOVIL" // Relativo al domingo de Pascua (Carnaval, Viernes Santo, Corpus Christi)
+	FeriadoUnico = "UNICO" // Solo en la fecha indicada (decretos, feriados extraordinarios)
+)
+
+// PlazoDescargoDiasHabiles es el plazo para presentar el descargo tras el retorno.
+const PlazoDescargoDiasHabiles = 8
+
+type Feriado struct {
+	BaseModel
+	Nombre string `gorm:"size:150;not null"`
+	Tipo   string `gorm:"size:10;not null;default:'FIJO'"`
+
+	Mes             int        `gorm:"default:0"`
+	Dia             int        `gorm:"default:0"`
+	DiasDesdePascua int        `gorm:"default:0"`
+	Fecha           *time.Time `gorm:"type:date"`
+
+	// DepartamentoCodigo nulo = feriado nacional.
+	DepartamentoCodigo *string       `gorm:"size:5;index;default:null"`
+	Departamento       *Departamento `gorm:"foreignKey:DepartamentoCodigo;references:Codigo;<-:false"`
+
+	// TrasladarDomingo pasa al lunes el feriado que cae en domingo.
+	TrasladarDomingo bool `gorm:"default:false"`
+	Activo           bool `gorm:"default:true"`
+}
+
+func (Feriado) TableName() string {
+	return "feriados"
+}
+
+func (f Feriado) GetDepartamentoCodigo() string {
+	if f.DepartamentoCodigo == nil {
+		return ""
+	}
+	return *f.DepartamentoCodigo
+}
+
+func (f Feriado) GetAmbito() string {
+	if f.Departamento != nil {
+		return f.Departamento.Nombre
+	}
+	if f.DepartamentoCodigo != nil {
+		return *f.DepartamentoCodigo
+	}
+	return "Nacional"
+}
+
+func (f Feriado) GetReglaDisplay() string {
+	switch f.Tipo {
+	case FeriadoMovil:
+		switch {
+		case f.DiasDesdePascua == 0:
+			return "Domingo de Pascua"
+		case f.DiasDesdePascua > 0:
+			return fmt.Sprintf("Pascua + %d días", f.DiasDesdePascua)
+		default:
+			return fmt.Sprintf("Pascua − %d días", -f.DiasDesdePascua)
+		}
+	case FeriadoUnico:
+		if f.Fecha != nil {
+			return f.Fecha.Format("02/01/2006")
+		}
+		return "-"
+	default:
+		return fmt.Sprintf("%02d/%02d cada año", f.Dia, f.Mes)
+	}
+}
+
+// GetFecha retorna el día en que el feriado cae en el año dado, ya trasladado si corresponde.
+func (f Feriado) GetFecha(anio int) (time.Time, bool) {
+	var fecha time.Time
+	switch f.Tipo {
+	case FeriadoUnico:
+		if f.Fecha == nil || f.Fecha.Year() != anio {
+			return time.Time{}, false
+		}
+		fecha = time.Date(anio, f.Fecha.Month(), f.Fecha.Day(), 0, 0, 0, 0, time.Local)
+	case FeriadoMovil:
+		fecha = DomingoDePascua(anio).AddDate(0, 0, f.DiasDesdePascua)
+	default:
+		if f.Mes < 1 || f.Mes > 12 || f.Dia < 1 || f.Dia > 31 {
+			return time.Time{}, false
+		}
+		fecha = time.Date(anio, time.Month(f.Mes), f.Dia, 0, 0, 0, 0, time.Local)
+	}
+	if f.TrasladarDomingo && fecha.Weekday() == time.Sunday {
+		fecha = fecha.AddDate(0, 0, 1)
+	}
+	return fecha, true
+}
+
+// DomingoDePascua calcula la Pascua gregoriana (algoritmo de Meeus/Jones/Butcher).
+func DomingoDePascua(anio int) time.Time {
+	a := anio % 19
+	b, c := anio/100, anio%100
+	d, e := b/4, b%4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i, k := c/4, c%4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	mes := (h + l - 7*m + 114) / 31
+	dia := (h+l-7*m+114)%31 + 1
+	return time.Date(anio, time.Month(mes), dia, 0, 0, 0, 0, time.Local)
+}
+
+// calendarioFeriados guarda los feriados activos para contar días hábiles sin consultar la base
+// en cada cálculo. Lo carga FeriadoService al iniciar y tras cada cambio.
+var calendarioFeriados = struct {
+	sync.RWMutex
+	feriados []Feriado
+	porAnio  map[int]map[string][]string // "2006-01-02" → departamentos ("" = nacional)
+}{porAnio: map[int]map[string][]string{}}
+
+// SetCalendarioFeriados reemplaza los feriados usados por EsDiaHabil.
+func SetCalendarioFeriados(feriados []Feriado) {
+	calendarioFeriados.Lock()
+	defer calendarioFeriados.Unlock()
+	calendarioFeriados.feriados = feriados
+	calendarioFeriados.porAnio = map[int]map[string][]string{}
+}
+
+func feriadosDelAnio(anio int) map[string][]string {
+	calendarioFeriados.RLock()
+	fechas, ok := calendarioFeriados.porAnio[anio]
+	calendarioFeriados.RUnlock()
+	if ok {
+		return fechas
+	}
+
+	calendarioFeriados.Lock()
+	defer calendarioFeriados.Unlock()
+	fechas = map[string][]string{}
+	for _, f := range calendarioFeriados.feriados {
+		if fecha, ok := f.GetFecha(anio); ok && f.Activo {
+			key := fecha.Format("2006-01-02")
+			fechas[key] = append(fechas[key], f.GetDepartamentoCodigo())
+		}
+	}
+	calendarioFeriados.porAnio[anio] = fechas
+	return fechas
+}
+
+// EsFeriado indica si la fecha es feriado nacional o del departamento dado.
+func EsFeriado(fecha time.Time, departamento string) bool {
+	for _, d := range feriadosDelAnio(fecha.Year())[fecha.Format("2006-01-02")] {
+		if d == "" || d == departamento {
+			return true
+		}
+	}
+	return false
+}
+
+// EsDiaHabil excluye fines de semana y feriados nacionales o del departamento.
+func EsDiaHabil(fecha time.Time, departamento string) bool {
+	if fecha.Weekday() == time.Saturday || fecha.Weekday() == time.Sunday {
+		return false
+	}
+	return !EsFeriado(fecha, departamento)
+}
+
+// SumarDiasHabiles retorna la fecha n días hábiles posterior a la dada.
+func SumarDiasHabiles(fecha time.Time, n int, departamento string) time.Time {
+	for dias := 0; dias < n; {
+		fecha = fecha.AddDate(0, 0, 1)
+		if EsDiaHabil(fecha, departamento) {
+			dias++
+		}
+	}
+	return fecha
+}
+
+// ContarDiasHabiles cuenta los días hábiles en (desde, hasta].
+func ContarDiasHabiles(desde, hasta time.Time, departamento string) int {
+	n := 0
+	for d := desde; d.Before(hasta); {
+		d = d.AddDate(0, 0, 1)
+		if EsDiaHabil(d, departamento) {
+			n++
+		}
+	}
+	return n
+}
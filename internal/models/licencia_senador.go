@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+type EstadoLicencia string
+
+const (
+	EstadoLicenciaProgramada EstadoLicencia = "PROGRAMADA"
+	EstadoLicenciaVigente    EstadoLicencia = "VIGENTE"
+	EstadoLicenciaFinalizada EstadoLicencia = "FINALIZADA"
+	EstadoLicenciaAnulada    EstadoLicencia = "ANULADA"
+)
+
+// LicenciaSenador es un periodo en que el titular está de licencia y su suplente viaja en
+// su lugar. Mientras está vigente, los cupos del titular se asignan automáticamente al
+// suplente; al terminar, los que no se usaron vuelven al titular.
+type LicenciaSenador struct {
+	BaseModel
+	TitularID  string   `gorm:"size:36;not null;index"`
+	Titular    *Usuario `gorm:"foreignKey:TitularID"`
+	SuplenteID string   `gorm:"size:36;not null;index"`
+	Suplente   *Usuario `gorm:"foreignKey:SuplenteID"`
+
+	FechaDesde time.Time      `gorm:"type:date;not null;index"`
+	FechaHasta time.Time      `gorm:"type:date;not null;index"`
+	Motivo     string         `gorm:"size:255"`
+	Estado     EstadoLicencia `gorm:"size:20;not null;default:'PROGRAMADA';index"`
+}
+
+func (LicenciaSenador) TableName() string {
+	return "licencias_senador"
+}
+
+// IsActivaEn indica si la licencia cubre la fecha dada (sin importar el estado).
+func (l LicenciaSenador) IsActivaEn(fecha time.Time) bool {
+	dia := time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, l.FechaDesde.Location())
+	return !dia.Before(l.FechaDesde) && !dia.After(l.FechaHasta)
+}
+
+func (l LicenciaSenador) IsAbierta() bool {
+	return l.Estado == EstadoLicenciaProgramada || l.Estado == EstadoLicenciaVigente
+}
+
+func (l LicenciaSenador) GetEstadoBadgeClass() string {
+	switch l.Estado {
+	case EstadoLicenciaVigente:
+		return "bg-success-50 text-success-700 border-success-200"
+	case EstadoLicenciaProgramada:
+		return "bg-info-50 text-info-700 border-info-200"
+	case EstadoLicenciaAnulada:
+		return "bg-danger-50 text-danger-700 border-danger-200"
+	default:
+		return "bg-neutral-100 text-neutral-600 border-neutral-200"
+	}
+}
+
+func (l LicenciaSenador) GetDias() int {
+	return int(l.FechaHasta.Sub(l.FechaDesde).Hours()/24) + 1
+}
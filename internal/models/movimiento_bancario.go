@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// Estados del reembolso de un pasaje (Pasaje.EstadoReembolso).
+const (
+	EstadoReembolsoPendiente  = "PENDIENTE"  // Hay monto a devolver pero falta la boleta o el comprobante
+	EstadoReembolsoDepositado = "DEPOSITADO" // El viajero registró boleta y comprobante
+	EstadoReembolsoVerificado = "VERIFICADO" // El depósito aparece en el extracto de la cuenta de devolución
+)
+
+// ExtractoBancario es un extracto de la cuenta de devoluciones importado desde CSV o xlsx.
+type ExtractoBancario struct {
+	BaseModel
+	Cuenta        string     `gorm:"size:50"`
+	ArchivoNombre string     `gorm:"size:255"`
+	Archivo       string     `gorm:"size:255"`
+	FechaDesde    *time.Time `gorm:"type:date"`
+	FechaHasta    *time.Time `gorm:"type:date"`
+	Movimientos   int        `gorm:"default:0"`
+	Duplicados    int        `gorm:"default:0"`
+}
+
+func (ExtractoBancario) TableName() string {
+	return "extractos_bancarios"
+}
+
+// MovimientoBancario es un abono del extracto. PasajeID se llena al conciliarlo con un reembolso.
+type MovimientoBancario struct {
+	BaseModel
+	ExtractoID  string            `gorm:"size:36;not null;index"`
+	Extracto    *ExtractoBancario `gorm:"foreignKey:ExtractoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;<-:false"`
+	Fecha       time.Time         `gorm:"type:date;not null;index"`
+	Referencia  string            `gorm:"size:100;index"`
+	Descripcion string            `gorm:"size:255"`
+	Monto       float64           `gorm:"type:decimal(12,2);not null"`
+	PasajeID    *string           `gorm:"size:36;index;default:null"`
+	Pasaje      *Pasaje           `gorm:"foreignKey:PasajeID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;<-:false"`
+	Observacion string            `gorm:"size:255;default:''"`
+}
+
+func (MovimientoBancario) TableName() string {
+	return "movimientos_bancarios"
+}
+
+func (m MovimientoBancario) IsConciliado() bool {
+	return m.PasajeID != nil && *m.PasajeID != ""
+}
@@ -0,0 +1,58 @@
+package models
+
+type TipoMovimientoCupo string
+
+const (
+	TipoMovimientoConsumo      TipoMovimientoCupo = "CONSUMO"
+	TipoMovimientoLiberacion   TipoMovimientoCupo = "LIBERACION"
+	TipoMovimientoConfirmacion TipoMovimientoCupo = "CONFIRMACION"
+	TipoMovimientoAjuste       TipoMovimientoCupo = "AJUSTE"
+)
+
+// MovimientoCupo es el libro de consumo de los cupos de derecho. Es de solo inserción:
+// cada cambio de estado o asignación de un cupo deja una fila y CupoDerecho.CupoUsado
+// es la suma de los Delta de su periodo.
+type MovimientoCupo struct {
+	BaseModel
+	CupoDerechoID     string  `gorm:"size:36;not null;index"`
+	CupoDerechoItemID *string `gorm:"size:36;index"`
+	SenTitularID      string  `gorm:"size:36;not null;index"`
+	Gestion           int     `gorm:"not null;index"`
+	Mes               int     `gorm:"not null;index"`
+
+	Tipo  TipoMovimientoCupo `gorm:"size:20;not null"`
+	Delta int                `gorm:"not null;comment:+1 consume, -1 libera, 0 solo confirma"`
+
+	EstadoAnterior   string `gorm:"size:50"`
+	EstadoNuevo      string `gorm:"size:50"`
+	AsignadoAnterior string `gorm:"size:36"`
+	AsignadoNuevo    string `gorm:"size:36"`
+
+	Origen      string  `gorm:"size:50;not null;comment:Acción que originó el movimiento"`
+	SolicitudID *string `gorm:"size:36;index"`
+	PasajeID    *string `gorm:"size:36;index"`
+	Observacion string  `gorm:"size:255"`
+}
+
+func (MovimientoCupo) TableName() string {
+	return "movimientos_cupo"
+}
+
+// DiscrepanciaCupo compara, para un cupo de derecho, el contador persistido con el libro
+// de movimientos y con el estado real de sus ítems.
+type DiscrepanciaCupo struct {
+	Cupo          CupoDerecho
+	Contador      int
+	Libro         int
+	Items         int
+	SinMovimiento bool
+}
+
+func (d DiscrepanciaCupo) IsConsistente() bool {
+	return d.Contador == d.Libro && d.Libro == d.Items
+}
+
+// GetAjusteLibro es el movimiento que alinea el libro con el estado de los ítems.
+func (d DiscrepanciaCupo) GetAjusteLibro() int {
+	return d.Items - d.Libro
+}
@@ -34,6 +34,9 @@ type OpenTicket struct {
 	MontoCredito   float64          `gorm:"type:decimal(10,2);default:0" json:"monto_credito"`
 	Estado         EstadoOpenTicket `gorm:"type:varchar(20);default:'PENDIENTE';index" json:"estado"`
 
+	// BilleteNormalizado es NumeroBillete sin separadores; se completa al guardar.
+	BilleteNormalizado string `gorm:"type:varchar(100);index" json:"-"`
+
 	// Uso y Consumo
 	SolicitudConsumoID *string    `gorm:"size:36;index" json:"solicitud_consumo_id"`
 	SolicitudConsumo   *Solicitud `gorm:"foreignKey:SolicitudConsumoID" json:"solicitud_consumo,omitempty"`
@@ -2,6 +2,7 @@ package models
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
 )
@@ -10,6 +11,14 @@ const (
 	EstadoPasajeRegistrado = "REGISTRADO"
 	EstadoPasajeEmitido    = "EMITIDO"
 	EstadoPasajeFinalizado = "FINALIZADO"
+	EstadoPasajeReemitido  = "REEMITIDO"
+)
+
+// Motivos de reemisión de un billete.
+const (
+	MotivoReemisionFecha = "CAMBIO_FECHA"
+	MotivoReemisionRuta  = "CAMBIO_RUTA"
+	MotivoReemisionVuelo = "CAMBIO_VUELO"
 )
 
 type PasajePermissions struct {
@@ -19,6 +28,7 @@ type PasajePermissions struct {
 	CanEmitir          bool
 	CanValidateUso     bool
 	CanDelete          bool
+	CanReemitir        bool
 	ShowActionsMenu    bool
 }
 
@@ -43,12 +53,24 @@ type Pasaje struct {
 
 	FechaVuelo   time.Time  `gorm:"type:timestamp"`
 	FechaEmision *time.Time `gorm:"type:date"`
+	// FechaEmitido es el momento en que se marcó EMITIDO por primera vez; CorreccionesEmision
+	// cuenta las veces que se revirtió la emisión para corregir datos de la agencia.
+	FechaEmitido        *time.Time `gorm:"type:timestamp"`
+	CorreccionesEmision int        `gorm:"default:0"`
 
 	NumeroBillete  string  `gorm:"size:100;index"`
 	Costo          float64 `gorm:"type:decimal(10,2)"`
 	CostoUtilizado float64 `gorm:"type:decimal(10,2);default:0" json:"costo_utilizado"`
 	MontoReembolso float64 `gorm:"type:decimal(10,2);default:0" json:"monto_reembolso"`
 
+	// BilleteNormalizado es NumeroBillete sin separadores; se completa al guardar.
+	BilleteNormalizado string `gorm:"size:100;index"`
+
+	// Moneda de Costo, CostoUtilizado, MontoReembolso y CostoPenalidad. TipoCambio es la
+	// cotización a bolivianos fijada a la fecha de emisión (1 para BOB).
+	Moneda     string  `gorm:"size:3;not null;default:'BOB'"`
+	TipoCambio float64 `gorm:"type:decimal(10,4);not null;default:1"`
+
 	EstadoPasajeCodigo string        `gorm:"size:50;not null;default:'REGISTRADO'"`
 	EstadoPasaje       *EstadoPasaje `gorm:"foreignKey:EstadoPasajeCodigo;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;<-:false"`
 
@@ -62,23 +84,47 @@ type Pasaje struct {
 	ServicioRazonSocial   string     `gorm:"type:varchar(255)"`
 	ServicioFacturaNumero string     `gorm:"type:varchar(100);index"`
 	ServicioFacturaFecha  *time.Time `gorm:"type:timestamp"`
-	ServicioMonto         float64    `gorm:"type:decimal(10,2);default:0"`
+	ServicioMonto         float64    `gorm:"type:decimal(10,2);default:0"` // Factura local, siempre en BOB
 	ServicioArchivo       string     `gorm:"type:varchar(255);default:''"`
 
-	NroBoletaDeposito  string        `gorm:"size:100;index"`
-	ArchivoComprobante string        `gorm:"size:255;default:''"`
-	FechaDeposito      *time.Time    `gorm:"type:timestamp"`
-	Cargos             []PasajeCargo `gorm:"foreignKey:PasajeID"`
+	NroBoletaDeposito  string     `gorm:"size:100;index"`
+	ArchivoComprobante string     `gorm:"size:255;default:''"`
+	FechaDeposito      *time.Time `gorm:"type:timestamp"`
+
+	// Historial de versiones de Archivo, ArchivoPaseAbordo, ServicioArchivo y ArchivoComprobante.
+	ArchivoAdjuntoID     *string `gorm:"size:36;index"`
+	PaseAbordoAdjuntoID  *string `gorm:"size:36;index"`
+	ServicioAdjuntoID    *string `gorm:"size:36;index"`
+	ComprobanteAdjuntoID *string `gorm:"size:36;index"`
+
+	// Conciliación del reembolso con el extracto de la cuenta de devoluciones.
+	EstadoReembolso      string        `gorm:"size:20;default:'';index"`
+	MovimientoBancarioID *string       `gorm:"size:36;index;default:null"`
+	FechaVerificacion    *time.Time    `gorm:"type:timestamp"`
+	Cargos               []PasajeCargo `gorm:"foreignKey:PasajeID"`
 
 	DescargoTramos []DescargoTramo `gorm:"foreignKey:PasajeID;<-:false"`
 
 	OpenTicketID *string     `gorm:"size:36;index;default:null"`
 	OpenTicket   *OpenTicket `gorm:"foreignKey:OpenTicketID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;<-:false"`
 
+	// Reemisión: el pasaje reemplaza a PasajeOriginalID tras un cambio de fecha, ruta o vuelo.
+	// Su Costo es la tarifa del nuevo billete; lo cobrado por el cambio queda en DiferenciaTarifa
+	// y PenalidadReemision, en la moneda del pasaje original.
+	PasajeOriginalID   *string `gorm:"size:36;index;default:null"`
+	PasajeOriginal     *Pasaje `gorm:"foreignKey:PasajeOriginalID;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;<-:false"`
+	MotivoReemision    string  `gorm:"size:20;default:''"`
+	DiferenciaTarifa   float64 `gorm:"type:decimal(10,2);default:0"`
+	PenalidadReemision float64 `gorm:"type:decimal(10,2);default:0"`
+
 	Seq int64 `gorm:"autoIncrement;not null;<-:false"`
 
 	authUser    *Usuario           `gorm:"-"`
 	Permissions *PasajePermissions `gorm:"-"`
+
+	// AvisoBillete se llena al guardar si el número de billete ya estaba registrado y el
+	// modo es ADVERTIR.
+	AvisoBillete string `gorm:"-"`
 }
 
 func (Pasaje) TableName() string {
@@ -149,6 +195,14 @@ func (p Pasaje) CanBeDeleted(u ...*Usuario) bool {
 }
 
 func (p Pasaje) CanBeReverted(u ...*Usuario) bool {
+	user := p.getAuthUser(u...)
+	if user == nil {
+		return false
+	}
+	return user.IsAdminOrResponsable() && p.GetEstado() == EstadoPasajeEmitido && !p.IsReemision()
+}
+
+func (p Pasaje) CanBeReissued(u ...*Usuario) bool {
 	user := p.getAuthUser(u...)
 	if user == nil {
 		return false
@@ -172,7 +226,30 @@ func (p Pasaje) IsFinalizado() bool {
 
 func (p Pasaje) IsDischargeable() bool {
 	st := p.GetEstadoCodigo()
-	return st == EstadoPasajeEmitido || st == EstadoPasajeFinalizado
+	return st == EstadoPasajeEmitido || st == EstadoPasajeFinalizado || st == EstadoPasajeReemitido
+}
+
+// IsReemision indica si el pasaje reemplaza a otro billete.
+func (p Pasaje) IsReemision() bool {
+	return p.PasajeOriginalID != nil && *p.PasajeOriginalID != ""
+}
+
+// IsReemitido indica si el billete fue reemplazado por una reemisión.
+func (p Pasaje) IsReemitido() bool {
+	return p.GetEstadoCodigo() == EstadoPasajeReemitido
+}
+
+func (p Pasaje) GetMotivoReemisionDisplay() string {
+	switch p.MotivoReemision {
+	case MotivoReemisionFecha:
+		return "Cambio de Fecha"
+	case MotivoReemisionRuta:
+		return "Cambio de Ruta"
+	case MotivoReemisionVuelo:
+		return "Cambio de Vuelo"
+	default:
+		return p.MotivoReemision
+	}
 }
 
 func (p Pasaje) HasOpenTicket() bool {
@@ -184,20 +261,117 @@ func (p Pasaje) HasOpenTicket() bool {
 	return false
 }
 
+// GetMontoCargos suma los cargos asociados, en bolivianos.
 func (p Pasaje) GetMontoCargos() float64 {
 	total := 0.0
 	for _, c := range p.Cargos {
-		total += c.Monto
+		total += c.GetMontoBs()
 	}
 	return total
 }
 
+func (p Pasaje) GetMoneda() string {
+	if p.Moneda == "" {
+		return MonedaBOB
+	}
+	return p.Moneda
+}
+
+func (p Pasaje) IsMonedaExtranjera() bool {
+	return p.GetMoneda() != MonedaBOB
+}
+
+func (p Pasaje) GetTipoCambio() float64 {
+	if !p.IsMonedaExtranjera() || p.TipoCambio <= 0 {
+		return 1
+	}
+	return p.TipoCambio
+}
+
+// ToBs convierte un monto en la moneda del pasaje a bolivianos con el tipo de cambio fijado.
+func (p Pasaje) ToBs(monto float64) float64 {
+	if !p.IsMonedaExtranjera() || p.TipoCambio <= 0 {
+		return monto
+	}
+	return math.Round(monto*p.TipoCambio*100) / 100
+}
+
+func (p Pasaje) GetCostoBs() float64 {
+	return p.ToBs(p.Costo)
+}
+
+func (p Pasaje) GetCostoUtilizadoBs() float64 {
+	return p.ToBs(p.CostoUtilizado)
+}
+
+func (p Pasaje) GetMontoReembolsoBs() float64 {
+	return p.ToBs(p.MontoReembolso)
+}
+
+// FormatMonto muestra el monto en la moneda original y, si es extranjera, su equivalente en Bs.
+func (p Pasaje) FormatMonto(monto float64) string {
+	if !p.IsMonedaExtranjera() {
+		return fmt.Sprintf("%.2f Bs", monto)
+	}
+	return fmt.Sprintf("%.2f %s (%.2f Bs)", monto, p.GetMoneda(), p.ToBs(monto))
+}
+
+// GetEstadoReembolso retorna el estado del reembolso; vacío si no hay monto a devolver.
+// Los registros anteriores al seguimiento se derivan de la boleta y el comprobante.
+func (p Pasaje) GetEstadoReembolso() string {
+	if p.MontoReembolso <= 0 {
+		return ""
+	}
+	if p.EstadoReembolso != "" {
+		return p.EstadoReembolso
+	}
+	if p.NroBoletaDeposito != "" && p.ArchivoComprobante != "" {
+		return EstadoReembolsoDepositado
+	}
+	return EstadoReembolsoPendiente
+}
+
+// SyncEstadoReembolso recalcula el estado tras editar la liquidación. Un reembolso verificado
+// lo sigue estando mientras no cambien la boleta ni el monto conciliados.
+func (p *Pasaje) SyncEstadoReembolso(boletaAnterior string, montoAnterior float64) {
+	if p.EstadoReembolso == EstadoReembolsoVerificado && p.MontoReembolso > 0 &&
+		p.NroBoletaDeposito == boletaAnterior && p.MontoReembolso == montoAnterior {
+		return
+	}
+	p.MovimientoBancarioID = nil
+	p.FechaVerificacion = nil
+	p.EstadoReembolso = ""
+	p.EstadoReembolso = p.GetEstadoReembolso()
+}
+
+// GetFechaReferenciaReembolso es la fecha desde la que corre el plazo del reembolso: el
+// depósito declarado o, si aún no hay, la fecha del vuelo.
+func (p Pasaje) GetFechaReferenciaReembolso() time.Time {
+	if p.GetEstadoReembolso() == EstadoReembolsoDepositado && p.FechaDeposito != nil {
+		return *p.FechaDeposito
+	}
+	return p.FechaVuelo
+}
+
+func (p Pasaje) GetEstadoReembolsoBadgeClass() string {
+	switch p.GetEstadoReembolso() {
+	case EstadoReembolsoVerificado:
+		return "bg-success-50 text-success-700 border-success-200"
+	case EstadoReembolsoDepositado:
+		return "bg-primary-50 text-primary-700 border-primary-200"
+	default:
+		return "bg-warning-50 text-warning-700 border-warning-200"
+	}
+}
+
 func (p Pasaje) GetStatusBannerClass() string {
 	switch p.GetEstado() {
 	case EstadoPasajeEmitido:
 		return "bg-success-600"
 	case EstadoPasajeFinalizado:
 		return "bg-neutral-800"
+	case EstadoPasajeReemitido:
+		return "bg-warning-600"
 	default:
 		return "bg-secondary-600"
 	}
@@ -212,8 +386,9 @@ func (p Pasaje) GetPermissions(u ...*Usuario) PasajePermissions {
 		CanEmitir:          p.CanBeEmitted(u...),
 		CanValidateUso:     false,
 		CanDelete:          p.CanBeDeleted(u...),
+		CanReemitir:        p.CanBeReissued(u...),
 	}
-	perms.ShowActionsMenu = perms.CanEdit || perms.CanMarkUsado || perms.CanRevertirEmision || perms.CanEmitir || perms.CanDelete
+	perms.ShowActionsMenu = perms.CanEdit || perms.CanMarkUsado || perms.CanRevertirEmision || perms.CanEmitir || perms.CanDelete || perms.CanReemitir
 	return perms
 }
 
@@ -241,6 +416,8 @@ func (p Pasaje) GetStatusBadgeClass() string {
 		return "bg-[#10B981] text-white font-bold"
 	case EstadoPasajeFinalizado:
 		return "bg-[#374151] text-white font-bold"
+	case EstadoPasajeReemitido:
+		return "bg-[#D97706] text-white font-bold"
 	default:
 		return "bg-neutral-100 text-neutral-800"
 	}
@@ -1,5 +1,7 @@
 package models
 
+import "math"
+
 type TipoCargoPasaje string
 
 const (
@@ -16,8 +18,12 @@ type PasajeCargo struct {
 	Tipo     string  `gorm:"size:50;not null;index"`
 	Factura  string  `gorm:"size:50;index"`
 	Monto    float64 `gorm:"type:decimal(10,2);default:0"`
-	Archivo  string  `gorm:"size:255;default:''"`
-	Glosa    string  `gorm:"type:text"`
+	// Moneda del monto y su cotización a bolivianos a la fecha de emisión del pasaje.
+	Moneda     string  `gorm:"size:3;not null;default:'BOB'"`
+	TipoCambio float64 `gorm:"type:decimal(10,4);not null;default:1"`
+	Archivo    string  `gorm:"size:255;default:''"`
+	AdjuntoID  *string `gorm:"size:36;index"`
+	Glosa      string  `gorm:"type:text"`
 }
 
 func (PasajeCargo) TableName() string {
@@ -40,3 +46,17 @@ func (c PasajeCargo) GetTipoDisplay() string {
 		return c.Tipo
 	}
 }
+
+func (c PasajeCargo) GetMoneda() string {
+	if c.Moneda == "" {
+		return MonedaBOB
+	}
+	return c.Moneda
+}
+
+func (c PasajeCargo) GetMontoBs() float64 {
+	if c.GetMoneda() == MonedaBOB || c.TipoCambio <= 0 {
+		return c.Monto
+	}
+	return math.Round(c.Monto*c.TipoCambio*100) / 100
+}
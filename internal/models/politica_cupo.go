@@ -0,0 +1,160 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// PoliticaCupo define cómo se generan los cupos de derecho de un periodo. Las políticas
+// son versionadas: una nueva versión se registra como fila nueva y la vigente para un
+// periodo es la activa con VigenteDesde más reciente que no lo supere.
+type PoliticaCupo struct {
+	BaseModel
+	Nombre  string `gorm:"size:100;not null;index"`
+	Version int    `gorm:"not null;default:1"`
+
+	VigenteDesde time.Time  `gorm:"type:date;not null;index"`
+	VigenteHasta *time.Time `gorm:"type:date"`
+	Activa       bool       `gorm:"default:true;index"`
+
+	SemanasPorMes     int    `gorm:"default:0;comment:Máximo de semanas con cupo por mes (0 = todas)"`
+	MesesReceso       string `gorm:"size:50;comment:Meses sin cupo separados por coma (1-12)"`
+	SemanasBloqueadas string `gorm:"type:text;comment:Lunes (YYYY-MM-DD) de semanas sin cupo separados por coma"`
+
+	PermiteArrastre bool `gorm:"default:false;comment:Arrastra cupos no usados del mes anterior"`
+	ArrastreMaximo  int  `gorm:"default:0"`
+
+	Observacion string `gorm:"type:text"`
+
+	Ajustes []PoliticaCupoAjuste `gorm:"foreignKey:PoliticaCupoID"`
+}
+
+func (PoliticaCupo) TableName() string {
+	return "politicas_cupo"
+}
+
+// PoliticaCupoAjuste suma (o resta, si Delta es negativo) cupos mensuales a los senadores
+// que coinciden con el tipo y/o departamento indicados. Un campo vacío aplica a todos.
+type PoliticaCupoAjuste struct {
+	BaseModel
+	PoliticaCupoID   string `gorm:"size:36;not null;index"`
+	TipoSenador      string `gorm:"size:50"`
+	DepartamentoCode string `gorm:"size:5"`
+	Delta            int    `gorm:"not null"`
+	Motivo           string `gorm:"size:255"`
+}
+
+func (PoliticaCupoAjuste) TableName() string {
+	return "politica_cupo_ajustes"
+}
+
+func (a PoliticaCupoAjuste) AplicaA(u *Usuario) bool {
+	if a.TipoSenador != "" && a.TipoSenador != u.Tipo {
+		return false
+	}
+	if a.DepartamentoCode != "" && (u.DepartamentoCode == nil || *u.DepartamentoCode != a.DepartamentoCode) {
+		return false
+	}
+	return true
+}
+
+// PoliticaCupoDefault reproduce la regla histórica: un cupo por cada semana del mes.
+func PoliticaCupoDefault() *PoliticaCupo {
+	return &PoliticaCupo{Nombre: "Estándar (una por semana)", Version: 0, Activa: true}
+}
+
+func (p *PoliticaCupo) IsDefault() bool {
+	return p.ID == ""
+}
+
+func (p *PoliticaCupo) IsVigenteEn(fecha time.Time) bool {
+	if !p.Activa || fecha.Before(p.VigenteDesde) {
+		return false
+	}
+	return p.VigenteHasta == nil || !fecha.After(*p.VigenteHasta)
+}
+
+func (p *PoliticaCupo) GetMesesReceso() []int {
+	var meses []int
+	for _, s := range splitCSV(p.MesesReceso) {
+		if m, err := strconv.Atoi(s); err == nil && m >= 1 && m <= 12 {
+			meses = append(meses, m)
+		}
+	}
+	return meses
+}
+
+func (p *PoliticaCupo) IsMesReceso(mes int) bool {
+	return slices.Contains(p.GetMesesReceso(), mes)
+}
+
+func (p *PoliticaCupo) GetSemanasBloqueadas() []string {
+	return splitCSV(p.SemanasBloqueadas)
+}
+
+// IsSemanaBloqueada indica si la semana que inicia el lunes dado está bloqueada.
+func (p *PoliticaCupo) IsSemanaBloqueada(lunes time.Time) bool {
+	return slices.Contains(p.GetSemanasBloqueadas(), lunes.Format("2006-01-02"))
+}
+
+// BloqueaItem indica si la política deja sin efecto un cupo ya generado (mes de receso
+// o semana bloqueada). Los cupos adicionales o de arrastre solo se ven afectados por el receso.
+func (p *PoliticaCupo) BloqueaItem(it CupoDerechoItem) bool {
+	if p.IsMesReceso(it.Mes) {
+		return true
+	}
+	return it.IsSemanal() && it.FechaDesde != nil && p.IsSemanaBloqueada(*it.FechaDesde)
+}
+
+// GetDelta suma los ajustes de la política aplicables al senador.
+func (p *PoliticaCupo) GetDelta(u *Usuario) int {
+	delta := 0
+	for _, a := range p.Ajustes {
+		if a.AplicaA(u) {
+			delta += a.Delta
+		}
+	}
+	return delta
+}
+
+func (p *PoliticaCupo) GetLabel() string {
+	if p.IsDefault() {
+		return p.Nombre
+	}
+	return fmt.Sprintf("%s v%d", p.Nombre, p.Version)
+}
+
+func (p *PoliticaCupo) GetResumen() string {
+	var partes []string
+	if p.SemanasPorMes > 0 {
+		partes = append(partes, fmt.Sprintf("máx. %d semanas/mes", p.SemanasPorMes))
+	} else {
+		partes = append(partes, "todas las semanas")
+	}
+	if meses := p.GetMesesReceso(); len(meses) > 0 {
+		partes = append(partes, fmt.Sprintf("%d mes(es) de receso", len(meses)))
+	}
+	if bloq := p.GetSemanasBloqueadas(); len(bloq) > 0 {
+		partes = append(partes, fmt.Sprintf("%d semana(s) bloqueada(s)", len(bloq)))
+	}
+	if len(p.Ajustes) > 0 {
+		partes = append(partes, fmt.Sprintf("%d ajuste(s)", len(p.Ajustes)))
+	}
+	if p.PermiteArrastre {
+		partes = append(partes, fmt.Sprintf("arrastre hasta %d", p.ArrastreMaximo))
+	}
+	return strings.Join(partes, ", ")
+}
+
+func splitCSV(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
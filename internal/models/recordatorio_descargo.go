@@ -0,0 +1,75 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Niveles de escalamiento de un recordatorio de descargo.
+const (
+	NivelRecordatorioBeneficiario = "BENEFICIARIO"
+	NivelRecordatorioEncargado    = "ENCARGADO"
+	NivelRecordatorioResponsable  = "RESPONSABLE"
+)
+
+const (
+	EstadoRecordatorioEnviado = "ENVIADO"
+	EstadoRecordatorioFallido = "FALLIDO"
+)
+
+// MaxIntentosRecordatorio limita los intentos FALLIDOS de una etapa; el job se reintenta una vez
+// por día hábil hasta agotarlos.
+const MaxIntentosRecordatorio = 3
+
+// RecordatorioDescargo registra cada envío (o intento fallido) de una etapa de recordatorio.
+// Una etapa ENVIADA, o que agotó sus intentos, no vuelve a enviarse para la misma solicitud.
+type RecordatorioDescargo struct {
+	BaseModel
+	SolicitudID string     `gorm:"size:36;not null;index:idx_recordatorio_etapa"`
+	Solicitud   *Solicitud `gorm:"foreignKey:SolicitudID;<-:false"`
+	UsuarioID   string     `gorm:"size:36;not null;index"`
+
+	// Etapa son los días hábiles respecto a la fecha límite: negativo antes, 0 el día, positivo en mora.
+	Etapa         int       `gorm:"not null;index:idx_recordatorio_etapa"`
+	Nivel         string    `gorm:"size:20;not null"`
+	FechaLimite   time.Time `gorm:"type:date;not null"`
+	DiasRestantes int       `gorm:"not null"`
+
+	Destinatarios string `gorm:"type:text"`
+	Copias        string `gorm:"type:text"`
+	Estado        string `gorm:"size:10;not null;index"`
+	Error         string `gorm:"type:text"`
+}
+
+func (RecordatorioDescargo) TableName() string {
+	return "recordatorios_descargo"
+}
+
+func (r RecordatorioDescargo) GetEtapaLabel() string {
+	switch {
+	case r.Etapa < 0:
+		return fmt.Sprintf("%d días antes del vencimiento", -r.Etapa)
+	case r.Etapa == 0:
+		return "Día del vencimiento"
+	default:
+		return fmt.Sprintf("%d días de mora", r.Etapa)
+	}
+}
+
+func (r RecordatorioDescargo) GetNivelLabel() string {
+	switch r.Nivel {
+	case NivelRecordatorioEncargado:
+		return "Escalado al encargado"
+	case NivelRecordatorioResponsable:
+		return "Escalado al responsable"
+	default:
+		return "Beneficiario"
+	}
+}
+
+func (r RecordatorioDescargo) GetEstadoBadgeClass() string {
+	if r.Estado == EstadoRecordatorioEnviado {
+		return "bg-success-50 text-success-700 border-success-200"
+	}
+	return "bg-danger-50 text-danger-700 border-danger-200"
+}
@@ -0,0 +1,309 @@
+package models
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Requisitos que una regla puede exigir al descargo.
+const (
+	RequisitoItinerario        = "ITINERARIO"
+	RequisitoBillete           = "TRAMO_BILLETE"
+	RequisitoNumeroPase        = "TRAMO_PASE_NUMERO"
+	RequisitoArchivoPase       = "TRAMO_PASE_ARCHIVO"
+	RequisitoInforme           = "INFORME"
+	RequisitoNroMemorandum     = "NRO_MEMORANDUM"
+	RequisitoArchivoMemorandum = "ARCHIVO_MEMORANDUM"
+	RequisitoObjetivoViaje     = "OBJETIVO_VIAJE"
+	RequisitoActividades       = "INFORME_ACTIVIDADES"
+	RequisitoResultados        = "RESULTADOS_VIAJE"
+	RequisitoConclusiones      = "CONCLUSIONES"
+	RequisitoTipoTransporte    = "TIPO_TRANSPORTE"
+	RequisitoDirigidoA         = "DIRIGIDO_A"
+	RequisitoAnexos            = "ANEXOS"
+)
+
+// RequisitoDescargo describe un requisito que puede configurarse.
+// Minimo indica qué significa MinLongitud para el requisito ("" si no aplica).
+type RequisitoDescargo struct {
+	Codigo string
+	Nombre string
+	Minimo string
+}
+
+// CatalogoRequisitosDescargo lista los requisitos en el orden en que aparecen en el checklist.
+var CatalogoRequisitosDescargo = []RequisitoDescargo{
+	{RequisitoItinerario, "Itinerario de viaje (tramos o devolución)", ""},
+	{RequisitoBillete, "N° de billete en cada tramo", ""},
+	{RequisitoNumeroPase, "N° de pase a bordo en cada tramo", ""},
+	{RequisitoArchivoPase, "Archivo del pase a bordo en cada tramo", ""},
+	{RequisitoInforme, "Informe oficial PV-06", ""},
+	{RequisitoNroMemorandum, "N° de memorándum", ""},
+	{RequisitoArchivoMemorandum, "Archivo del memorándum", ""},
+	{RequisitoObjetivoViaje, "Objetivo del viaje", "caracteres"},
+	{RequisitoActividades, "Informe de actividades", "caracteres"},
+	{RequisitoResultados, "Resultados del viaje", "caracteres"},
+	{RequisitoConclusiones, "Conclusiones y recomendaciones", "caracteres"},
+	{RequisitoTipoTransporte, "Tipo de transporte", ""},
+	{RequisitoDirigidoA, "Destinatario del informe", ""},
+	{RequisitoAnexos, "Anexos adjuntos", "archivos"},
+}
+
+func GetRequisitoDescargo(codigo string) (RequisitoDescargo, bool) {
+	for _, r := range CatalogoRequisitosDescargo {
+		if r.Codigo == codigo {
+			return r, true
+		}
+	}
+	return RequisitoDescargo{}, false
+}
+
+// ReglaDescargo exige un requisito al descargo de las solicitudes de un concepto o tipo.
+type ReglaDescargo struct {
+	BaseModel
+	// ConceptoCodigo y TipoSolicitudCodigo nulos = la regla aplica a todos.
+	ConceptoCodigo      *string        `gorm:"size:50;index;default:null"`
+	Concepto            *ConceptoViaje `gorm:"foreignKey:ConceptoCodigo;references:Codigo;<-:false"`
+	TipoSolicitudCodigo *string        `gorm:"size:50;index;default:null"`
+	TipoSolicitud       *TipoSolicitud `gorm:"foreignKey:TipoSolicitudCodigo;references:Codigo;<-:false"`
+
+	Requisito string `gorm:"size:30;not null"`
+	// Etiqueta es el texto que se muestra en el checklist de faltantes.
+	Etiqueta    string `gorm:"size:150;not null"`
+	MinLongitud int    `gorm:"default:0"`
+	Activo      bool   `gorm:"default:true"`
+}
+
+func (ReglaDescargo) TableName() string {
+	return "reglas_descargo"
+}
+
+func (r ReglaDescargo) GetAmbito() string {
+	switch {
+	case r.TipoSolicitud != nil:
+		return r.TipoSolicitud.Nombre
+	case r.TipoSolicitudCodigo != nil:
+		return *r.TipoSolicitudCodigo
+	case r.Concepto != nil:
+		return r.Concepto.Nombre
+	case r.ConceptoCodigo != nil:
+		return *r.ConceptoCodigo
+	}
+	return "Todos los conceptos"
+}
+
+func (r ReglaDescargo) GetRequisitoNombre() string {
+	if req, ok := GetRequisitoDescargo(r.Requisito); ok {
+		return req.Nombre
+	}
+	return r.Requisito
+}
+
+func (r ReglaDescargo) GetMinimoDisplay() string {
+	req, ok := GetRequisitoDescargo(r.Requisito)
+	if !ok || req.Minimo == "" || r.MinLongitud <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d %s", r.MinLongitud, req.Minimo)
+}
+
+// AplicaA indica si la regla corresponde al concepto y tipo de la solicitud.
+func (r ReglaDescargo) AplicaA(concepto, tipo string) bool {
+	if !r.Activo {
+		return false
+	}
+	if r.ConceptoCodigo != nil && *r.ConceptoCodigo != concepto {
+		return false
+	}
+	if r.TipoSolicitudCodigo != nil && *r.TipoSolicitudCodigo != tipo {
+		return false
+	}
+	return true
+}
+
+// ReglasDescargoPorDefecto son los requisitos vigentes antes de que fueran configurables;
+// rigen mientras no haya reglas registradas y sirven de semilla inicial.
+func ReglasDescargoPorDefecto() []ReglaDescargo {
+	oficial := "OFICIAL"
+	return []ReglaDescargo{
+		{Requisito: RequisitoItinerario, Etiqueta: "Itinerario de viaje", Activo: true},
+		{Requisito: RequisitoBillete, Etiqueta: "N° Billetes", Activo: true},
+		{Requisito: RequisitoNumeroPase, Etiqueta: "N° Pases a Bordo", Activo: true},
+		{Requisito: RequisitoArchivoPase, Etiqueta: "Archivos PDF/Imagen de Pases", Activo: true},
+		{ConceptoCodigo: &oficial, Requisito: RequisitoInforme, Etiqueta: "Informe Oficial PV-06", Activo: true},
+		{ConceptoCodigo: &oficial, Requisito: RequisitoObjetivoViaje, Etiqueta: "Objetivo del viaje", Activo: true},
+		{ConceptoCodigo: &oficial, Requisito: RequisitoActividades, Etiqueta: "Informe de actividades", Activo: true},
+		{ConceptoCodigo: &oficial, Requisito: RequisitoResultados, Etiqueta: "Resultados del viaje", Activo: true},
+		{ConceptoCodigo: &oficial, Requisito: RequisitoConclusiones, Etiqueta: "Conclusiones y recomendaciones", Activo: true},
+	}
+}
+
+// reglasDescargo guarda las reglas para evaluar descargos sin consultar la base
+// (se usan desde plantillas). Sin reglas registradas rigen las de por defecto.
+var reglasDescargo = struct {
+	sync.RWMutex
+	reglas []ReglaDescargo
+}{}
+
+func SetReglasDescargo(reglas []ReglaDescargo) {
+	reglasDescargo.Lock()
+	defer reglasDescargo.Unlock()
+	reglasDescargo.reglas = reglas
+}
+
+func getReglasDescargo() []ReglaDescargo {
+	reglasDescargo.RLock()
+	defer reglasDescargo.RUnlock()
+	if len(reglasDescargo.reglas) == 0 {
+		return ReglasDescargoPorDefecto()
+	}
+	return reglasDescargo.reglas
+}
+
+// reglasAplicables retorna una regla por requisito, en el orden del catálogo. Si varias
+// aplican al mismo requisito prevalece la más específica y el mayor mínimo.
+func reglasAplicables(concepto, tipo string) []ReglaDescargo {
+	porRequisito := map[string]ReglaDescargo{}
+	especificidad := func(r ReglaDescargo) int {
+		n := 0
+		if r.ConceptoCodigo != nil {
+			n++
+		}
+		if r.TipoSolicitudCodigo != nil {
+			n += 2
+		}
+		return n
+	}
+	for _, r := range getReglasDescargo() {
+		if !r.AplicaA(concepto, tipo) {
+			continue
+		}
+		actual, ok := porRequisito[r.Requisito]
+		if !ok {
+			porRequisito[r.Requisito] = r
+			continue
+		}
+		if r.MinLongitud > actual.MinLongitud {
+			actual.MinLongitud = r.MinLongitud
+		}
+		if especificidad(r) > especificidad(actual) {
+			actual.Etiqueta = r.Etiqueta
+			actual.ConceptoCodigo, actual.TipoSolicitudCodigo = r.ConceptoCodigo, r.TipoSolicitudCodigo
+		}
+		porRequisito[r.Requisito] = actual
+	}
+
+	var reglas []ReglaDescargo
+	for _, req := range CatalogoRequisitosDescargo {
+		if r, ok := porRequisito[req.Codigo]; ok {
+			reglas = append(reglas, r)
+		}
+	}
+	return reglas
+}
+
+// FaltantesDescargo lista las etiquetas de los requisitos que el descargo aún no cumple
+// según las reglas del concepto y tipo de su solicitud.
+func FaltantesDescargo(d Descargo, sol *Solicitud) []string {
+	concepto, tipo := "", ""
+	if sol != nil {
+		concepto, tipo = sol.GetConceptoCodigo(), sol.TipoSolicitudCodigo
+	}
+
+	hasItinerary := false
+	incompletos := map[string]bool{}
+	for _, it := range d.Tramos {
+		if it.EsOpenTicket {
+			continue
+		}
+		hasItinerary = true
+		incompletos[RequisitoBillete] = incompletos[RequisitoBillete] || it.Billete == ""
+		incompletos[RequisitoNumeroPase] = incompletos[RequisitoNumeroPase] || it.NumeroPaseAbordo == ""
+		incompletos[RequisitoArchivoPase] = incompletos[RequisitoArchivoPase] || it.ArchivoPaseAbordo == ""
+	}
+	sinItinerario := !hasItinerary && !d.allDevolucion()
+
+	reglas := reglasAplicables(concepto, tipo)
+	exigeInforme := reglaExigida(reglas, RequisitoInforme)
+
+	var missing []string
+	for _, r := range reglas {
+		falta := false
+		switch r.Requisito {
+		case RequisitoItinerario:
+			falta = sinItinerario
+		case RequisitoBillete, RequisitoNumeroPase, RequisitoArchivoPase:
+			// Sin itinerario basta con pedir el itinerario.
+			falta = incompletos[r.Requisito] && !(sinItinerario && reglaExigida(reglas, RequisitoItinerario))
+		case RequisitoInforme:
+			falta = d.Oficial == nil
+		default:
+			if d.Oficial == nil {
+				// El informe faltante ya resume sus campos.
+				falta = !exigeInforme
+			} else {
+				falta = !cumpleCampoInforme(*d.Oficial, r)
+			}
+		}
+		if falta {
+			missing = append(missing, r.Etiqueta)
+		}
+	}
+	return missing
+}
+
+func reglaExigida(reglas []ReglaDescargo, requisito string) bool {
+	for _, r := range reglas {
+		if r.Requisito == requisito {
+			return true
+		}
+	}
+	return false
+}
+
+func cumpleCampoInforme(o DescargoOficial, r ReglaDescargo) bool {
+	var valor string
+	switch r.Requisito {
+	case RequisitoNroMemorandum:
+		valor = o.NroMemorandum
+	case RequisitoArchivoMemorandum:
+		valor = o.ArchivoMemorandum
+	case RequisitoObjetivoViaje:
+		valor = o.ObjetivoViaje
+	case RequisitoActividades:
+		valor = o.InformeActividades
+	case RequisitoResultados:
+		valor = o.ResultadosViaje
+	case RequisitoConclusiones:
+		valor = o.ConclusionesRecomendaciones
+	case RequisitoTipoTransporte:
+		valor = o.TipoTransporte
+	case RequisitoDirigidoA:
+		valor = o.DirigidoA
+	case RequisitoAnexos:
+		return len(o.Anexos) >= max(r.MinLongitud, 1)
+	default:
+		return true
+	}
+	valor = strings.TrimSpace(valor)
+	return valor != "" && utf8.RuneCountInString(valor) >= r.MinLongitud
+}
+
+// FaltantesDescargoHTML arma el checklist de faltantes para los tooltips.
+func FaltantesDescargoHTML(missing []string, titulo string) string {
+	if len(missing) == 0 {
+		return "Datos completos"
+	}
+
+	var b strings.Builder
+	b.WriteString("<div class='text-left'><p class='text-[10px] font-bold border-b border-white/20 pb-1 mb-1'>" + titulo + "</p><ul class='list-inside list-disc space-y-0.5'>")
+	for _, item := range missing {
+		// Las etiquetas las define el administrador.
+		b.WriteString("<li class='text-[10px]'>" + html.EscapeString(item) + "</li>")
+	}
+	b.WriteString("</ul></div>")
+	return b.String()
+}
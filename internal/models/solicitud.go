@@ -32,6 +32,8 @@ type Solicitud struct {
 
 	Descargo *Descargo `gorm:"foreignKey:SolicitudID"`
 
+	RecordatoriosDescargo []RecordatorioDescargo `gorm:"foreignKey:SolicitudID"`
+
 	Autorizacion string `gorm:"size:100;index"`
 
 	Motivo string `gorm:"type:text"`
@@ -604,6 +606,16 @@ func (s *Solicitud) RevertApprovalItem(itemID string) bool {
 	return false
 }
 
+// GetPasajeActivoID retorna el primer pasaje vigente de los tramos, si existe.
+func (s Solicitud) GetPasajeActivoID() *string {
+	for _, it := range s.Items {
+		if p := it.GetPasajeActivo(); p != nil {
+			return &p.ID
+		}
+	}
+	return nil
+}
+
 func (s *Solicitud) AreAllItemsInactive() bool {
 	if len(s.Items) == 0 {
 		return true
@@ -847,41 +859,36 @@ func (s Solicitud) GetDiasRestantesDescargo() int {
 		return 999
 	}
 
-	diasLimite := 0
-	limite := *maxDate
-	for diasLimite < 8 {
-		limite = limite.AddDate(0, 0, 1)
-		if limite.Weekday() != time.Saturday && limite.Weekday() != time.Sunday {
-			diasLimite++
-		}
-	}
+	departamento := s.Usuario.GetDepartamentoCodigo()
+	limite := SumarDiasHabiles(*maxDate, PlazoDescargoDiasHabiles, departamento)
 
 	hoy := time.Now().Truncate(24 * time.Hour)
 	limiteTrunc := limite.Truncate(24 * time.Hour)
 
 	if hoy.After(limiteTrunc) {
-		// Calcular cuántos días hábiles de mora
-		mora := 0
-		d := limiteTrunc
-		for d.Before(hoy) {
-			d = d.AddDate(0, 0, 1)
-			if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
-				mora++
-			}
-		}
-		return -mora
+		// Días hábiles de mora
+		return -ContarDiasHabiles(limiteTrunc, hoy, departamento)
 	}
+	return ContarDiasHabiles(hoy, limiteTrunc, departamento)
+}
 
-	// Días restantes
-	restantes := 0
-	d := hoy
-	for d.Before(limiteTrunc) {
-		d = d.AddDate(0, 0, 1)
-		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
-			restantes++
+// TieneDescargoPendiente indica que el beneficiario aún no presentó el descargo de sus pasajes.
+func (s Solicitud) TieneDescargoPendiente() bool {
+	if s.Descargo != nil {
+		switch s.Descargo.Estado {
+		case EstadoDescargoEnRevision, EstadoDescargoOpenTicket, EstadoDescargoFinalizado:
+			return false
 		}
 	}
-	return restantes
+	return !s.HasCompleteDescargo()
+}
+
+// GetDiasMoraDescargo retorna los días hábiles de mora del descargo (0 si está en plazo).
+func (s Solicitud) GetDiasMoraDescargo() int {
+	if !s.TieneDescargoPendiente() {
+		return 0
+	}
+	return max(0, -s.GetDiasRestantesDescargo())
 }
 
 // GetMontoTotalAsignado calcula el costo total de todos los pasajes emitidos originalmente para esta solicitud.
@@ -892,7 +899,7 @@ func (s Solicitud) GetMontoTotalAsignado() float64 {
 			st := p.GetEstadoCodigo()
 			// Sumamos los emitidos o usados (lo que representa el gasto administrativo inicial)
 			if st == "EMITIDO" || st == "FINALIZADO" {
-				total += p.Costo
+				total += p.GetCostoBs()
 			}
 		}
 	}
@@ -917,6 +924,15 @@ func (s Solicitud) CanView(user *Usuario) bool {
 	return false
 }
 
+// CanFirmar indica si el usuario puede firmar el formulario de la solicitud: el beneficiario, su
+// encargado o un administrador/responsable.
+func (s Solicitud) CanFirmar(user *Usuario) bool {
+	if user == nil {
+		return false
+	}
+	return user.IsAdminOrResponsable() || s.UsuarioID == user.ID || s.Usuario.IsManagedBy(user)
+}
+
 func (s Solicitud) CanMarkUsado(u *Usuario) bool {
 	if u == nil {
 		return false
@@ -1122,106 +1138,14 @@ func (s Solicitud) GetCostoTotalPasajes() float64 {
 }
 
 func (s Solicitud) HasCompleteDescargo() bool {
-	if s.Descargo == nil {
-		return false
-	}
-
-	hasItinerary := false
-	for _, it := range s.Descargo.Tramos {
-		if !it.EsOpenTicket {
-			hasItinerary = true
-			if it.Billete == "" || it.NumeroPaseAbordo == "" || it.ArchivoPaseAbordo == "" {
-				return false
-			}
-		}
-	}
-
-	if !hasItinerary && !s.Descargo.allDevolucion() {
-		return false
-	}
-	if s.GetConceptoCodigo() == "OFICIAL" {
-		if s.Descargo.Oficial == nil {
-			return false
-		}
-		if s.Descargo.Oficial.ObjetivoViaje == "" ||
-			s.Descargo.Oficial.InformeActividades == "" ||
-			s.Descargo.Oficial.ResultadosViaje == "" ||
-			s.Descargo.Oficial.ConclusionesRecomendaciones == "" {
-			return false
-		}
-	}
-
-	return true
+	return s.Descargo != nil && len(FaltantesDescargo(*s.Descargo, &s)) == 0
 }
 
 func (s Solicitud) GetDescargoMissingItems() string {
 	if s.Descargo == nil {
 		return "No se ha iniciado el descargo"
 	}
-
-	var missing []string
-
-	// Check Itinerary
-	hasItinerary := false
-	missingBilletes := false
-	missingPases := false
-	missingArchivos := false
-
-	for _, it := range s.Descargo.Tramos {
-		if !it.EsOpenTicket {
-			hasItinerary = true
-			if it.Billete == "" {
-				missingBilletes = true
-			}
-			if it.NumeroPaseAbordo == "" {
-				missingPases = true
-			}
-			if it.ArchivoPaseAbordo == "" {
-				missingArchivos = true
-			}
-		}
-	}
-
-	if !hasItinerary && !s.Descargo.allDevolucion() {
-		missing = append(missing, "Itinerario de viaje")
-	} else {
-		if missingBilletes {
-			missing = append(missing, "N° Billetes")
-		}
-		if missingPases {
-			missing = append(missing, "N° Pases a Bordo")
-		}
-		if missingArchivos {
-			missing = append(missing, "Archivos PDF/Imagen de Pases")
-		}
-	}
-
-	// Check Official Report
-	if s.GetConceptoCodigo() == "OFICIAL" {
-		if s.Descargo.Oficial == nil {
-			missing = append(missing, "Informe Oficial PV-06")
-		} else {
-			if s.Descargo.Oficial.ObjetivoViaje == "" ||
-				s.Descargo.Oficial.InformeActividades == "" ||
-				s.Descargo.Oficial.ResultadosViaje == "" ||
-				s.Descargo.Oficial.ConclusionesRecomendaciones == "" {
-				missing = append(missing, "Campos del Informe Detallado")
-			}
-		}
-	}
-
-	if len(missing) == 0 {
-		return "Datos completos"
-	}
-
-	var html strings.Builder
-	html.WriteString("<div class='text-left'><p class='text-[10px] font-bold border-b border-white/20 pb-1 mb-1'>Pendiente de llenar:</p><ul class='list-inside list-disc space-y-0.5'>")
-	for _, item := range missing {
-		html.WriteString("<li class='text-[10px]'>" + item + "</li>")
-	}
-	html.WriteString("</ul></div>")
-
-	return html.String()
+	return FaltantesDescargoHTML(FaltantesDescargo(*s.Descargo, &s), "Pendiente de llenar:")
 }
 
 // GetChanges compares current solicitud with old state and returns dirty fields map for GORM Updates
@@ -1252,3 +1176,19 @@ func (s *Solicitud) GetChanges(old Solicitud) map[string]any {
 func (s Solicitud) IsOpenTicket() bool {
 	return s.Descargo != nil && s.Descargo.Estado == EstadoDescargoOpenTicket
 }
+
+// EtapaRecordatorioAtendida indica si la etapa ya no debe intentarse: se envió con éxito o
+// agotó los reintentos tras fallar.
+func (s Solicitud) EtapaRecordatorioAtendida(etapa int) bool {
+	fallidos := 0
+	for _, r := range s.RecordatoriosDescargo {
+		if r.Etapa != etapa {
+			continue
+		}
+		if r.Estado == EstadoRecordatorioEnviado {
+			return true
+		}
+		fallidos++
+	}
+	return fallidos >= MaxIntentosRecordatorio
+}
@@ -33,9 +33,11 @@ type SolicitudItem struct {
 	DestinoIATA string   `gorm:"size:5;not null"`
 	Destino     *Destino `gorm:"foreignKey:DestinoIATA;references:IATA"`
 
-	Fecha        *time.Time           `gorm:"type:timestamp"`
-	EstadoCodigo *string              `gorm:"size:20;index;default:'SOLICITADO'"`
-	Estado       *EstadoSolicitudItem `gorm:"foreignKey:EstadoCodigo;references:Codigo;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;<-:false"`
+	Fecha *time.Time `gorm:"type:timestamp"`
+	// FechaAprobacion marca el inicio del plazo de emisión de la agencia.
+	FechaAprobacion *time.Time           `gorm:"type:timestamp"`
+	EstadoCodigo    *string              `gorm:"size:20;index;default:'SOLICITADO'"`
+	Estado          *EstadoSolicitudItem `gorm:"foreignKey:EstadoCodigo;references:Codigo;constraint:OnUpdate:CASCADE,OnDelete:RESTRICT;<-:false"`
 
 	AerolineaID *string    `gorm:"size:36;index;default:null"`
 	Aerolinea   *Aerolinea `gorm:"foreignKey:AerolineaID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL;<-:false"`
@@ -176,13 +178,40 @@ func (t SolicitudItem) GetPasajeActivo() *Pasaje {
 	}
 	for i := range t.Pasajes {
 		p := &t.Pasajes[i]
-		if p.EstadoPasajeCodigo != "" {
+		// Un billete reemitido quedó reemplazado por su reemisión.
+		if p.EstadoPasajeCodigo != "" && !p.IsReemitido() {
 			return p
 		}
 	}
 	return nil
 }
 
+// GetCadenasReemision agrupa los pasajes del tramo en cadenas original → reemisiones, en orden.
+// Solo incluye las cadenas que tienen al menos una reemisión.
+func (t SolicitudItem) GetCadenasReemision() [][]*Pasaje {
+	siguiente := make(map[string]*Pasaje)
+	for i := range t.Pasajes {
+		p := &t.Pasajes[i]
+		if p.IsReemision() {
+			siguiente[*p.PasajeOriginalID] = p
+		}
+	}
+
+	var cadenas [][]*Pasaje
+	for i := range t.Pasajes {
+		p := &t.Pasajes[i]
+		if p.IsReemision() || siguiente[p.ID] == nil {
+			continue
+		}
+		cadena := []*Pasaje{p}
+		for next := siguiente[p.ID]; next != nil && len(cadena) <= len(t.Pasajes); next = siguiente[next.ID] {
+			cadena = append(cadena, next)
+		}
+		cadenas = append(cadenas, cadena)
+	}
+	return cadenas
+}
+
 func (t *SolicitudItem) GetChanges(old SolicitudItem) map[string]any {
 	changes := make(map[string]any)
 
@@ -207,6 +236,11 @@ func (t *SolicitudItem) GetChanges(old SolicitudItem) map[string]any {
 		changes["fecha"] = t.Fecha
 	}
 
+	if (t.FechaAprobacion == nil) != (old.FechaAprobacion == nil) ||
+		(t.FechaAprobacion != nil && old.FechaAprobacion != nil && !t.FechaAprobacion.Equal(*old.FechaAprobacion)) {
+		changes["fecha_aprobacion"] = t.FechaAprobacion
+	}
+
 	return changes
 }
 
@@ -253,8 +287,9 @@ func (t SolicitudItem) GetCostoTotal() float64 {
 	total := 0.0
 	for _, p := range t.Pasajes {
 		estado := p.GetEstadoCodigo()
-		if estado != "" {
-			total += p.Costo
+		// Un billete reemitido lo reemplaza su reemisión, que suma su tarifa y la penalidad del cambio.
+		if estado != "" && estado != EstadoPasajeReemitido {
+			total += p.GetCostoBs() + p.ToBs(p.PenalidadReemision)
 		}
 	}
 	return total
@@ -265,6 +300,8 @@ func (t SolicitudItem) GetCostoTotal() float64 {
 func (t *SolicitudItem) Approve() {
 	st := "APROBADO"
 	t.EstadoCodigo = &st
+	now := time.Now()
+	t.FechaAprobacion = &now
 }
 
 func (t *SolicitudItem) Reject() {
@@ -286,6 +323,7 @@ func (t *SolicitudItem) Finalize() {
 func (t *SolicitudItem) RevertApproval() {
 	st := "SOLICITADO"
 	t.EstadoCodigo = &st
+	t.FechaAprobacion = nil
 	t.CreatedAt = time.Now()
 }
 
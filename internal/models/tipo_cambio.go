@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+const (
+	MonedaBOB = "BOB"
+	MonedaUSD = "USD"
+)
+
+// Monedas son las monedas aceptadas para montos de pasajes y cargos.
+var Monedas = []string{MonedaBOB, MonedaUSD}
+
+func IsMonedaValida(moneda string) bool {
+	for _, m := range Monedas {
+		if m == moneda {
+			return true
+		}
+	}
+	return false
+}
+
+// MonedaSimbolo retorna la abreviatura con que se imprime la moneda en formularios.
+func MonedaSimbolo(moneda string) string {
+	if moneda == MonedaUSD {
+		return "$us"
+	}
+	return "Bs"
+}
+
+// TipoCambio es la cotización oficial de una moneda en bolivianos para una fecha. Para
+// convertir un monto se usa la última cotización registrada hasta esa fecha.
+type TipoCambio struct {
+	BaseModel
+	Moneda string    `gorm:"size:3;not null;uniqueIndex:idx_tipo_cambio_moneda_fecha"`
+	Fecha  time.Time `gorm:"type:date;not null;uniqueIndex:idx_tipo_cambio_moneda_fecha"`
+	Valor  float64   `gorm:"type:decimal(10,4);not null"`
+	Fuente string    `gorm:"size:100"`
+}
+
+func (TipoCambio) TableName() string {
+	return "tipos_cambio"
+}
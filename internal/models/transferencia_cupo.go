@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+type EstadoTransferenciaCupo string
+
+const (
+	EstadoTransferenciaPendiente EstadoTransferenciaCupo = "PENDIENTE"
+	EstadoTransferenciaAprobada  EstadoTransferenciaCupo = "APROBADA"
+	EstadoTransferenciaRechazada EstadoTransferenciaCupo = "RECHAZADA"
+)
+
+type TipoTransferenciaCupo string
+
+const (
+	TipoTransferenciaCupoTransferencia TipoTransferenciaCupo = "TRANSFERENCIA"
+	TipoTransferenciaCupoReversion     TipoTransferenciaCupo = "REVERSION"
+)
+
+// TransferenciaCupo es el historial de movimientos de un cupo de derecho entre senadores.
+// Cada transferencia, toma, asignación o reversión deja una fila; las solicitadas por el
+// titular quedan PENDIENTE hasta que un responsable las resuelve.
+type TransferenciaCupo struct {
+	BaseModel
+	CupoDerechoItemID string           `gorm:"size:36;not null;index"`
+	CupoDerechoItem   *CupoDerechoItem `gorm:"foreignKey:CupoDerechoItemID"`
+
+	Tipo   TipoTransferenciaCupo   `gorm:"size:20;not null;default:'TRANSFERENCIA'"`
+	Estado EstadoTransferenciaCupo `gorm:"size:20;not null;default:'PENDIENTE';index"`
+
+	DeUsuarioID   string   `gorm:"size:36;not null;index;comment:Titular del uso antes del movimiento"`
+	DeUsuario     *Usuario `gorm:"foreignKey:DeUsuarioID"`
+	ParaUsuarioID string   `gorm:"size:36;not null;index;comment:Titular del uso después del movimiento"`
+	ParaUsuario   *Usuario `gorm:"foreignKey:ParaUsuarioID"`
+
+	SolicitanteID string   `gorm:"size:36;not null;index"`
+	Solicitante   *Usuario `gorm:"foreignKey:SolicitanteID"`
+	AprobadorID   *string  `gorm:"size:36;default:null"`
+	Aprobador     *Usuario `gorm:"foreignKey:AprobadorID"`
+
+	Motivo             string     `gorm:"size:255"`
+	FechaSolicitud     time.Time  `gorm:"type:timestamp;not null"`
+	FechaResolucion    *time.Time `gorm:"type:timestamp"`
+	ObservacionRechazo string     `gorm:"size:255"`
+
+	LicenciaID *string `gorm:"size:36;index;comment:Licencia del titular que originó el movimiento automático"`
+}
+
+func (TransferenciaCupo) TableName() string {
+	return "transferencias_cupo"
+}
+
+func (t TransferenciaCupo) IsPendiente() bool {
+	return t.Estado == EstadoTransferenciaPendiente
+}
+
+func (t TransferenciaCupo) GetEstadoBadgeClass() string {
+	switch t.Estado {
+	case EstadoTransferenciaAprobada:
+		return "bg-success-50 text-success-700 border-success-200"
+	case EstadoTransferenciaRechazada:
+		return "bg-danger-50 text-danger-700 border-danger-200"
+	default:
+		return "bg-warning-50 text-warning-700 border-warning-200"
+	}
+}
+
+func (t TransferenciaCupo) GetAprobadorNombre() string {
+	if t.Aprobador != nil {
+		return t.Aprobador.GetNombreCompleto()
+	}
+	if t.Estado == EstadoTransferenciaAprobada {
+		return "Automática"
+	}
+	return ""
+}
@@ -109,6 +109,14 @@ func (u *Usuario) GetOrigenIATA() string {
 	return *u.OrigenIATA
 }
 
+// GetDepartamentoCodigo define qué feriados departamentales se descuentan de sus plazos.
+func (u Usuario) GetDepartamentoCodigo() string {
+	if u.DepartamentoCode == nil {
+		return ""
+	}
+	return *u.DepartamentoCode
+}
+
 func (u *Usuario) GetOrigenNombre() string {
 	if u.Origen == nil {
 		return ""
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// ZonaViatico agrupa destinos con la misma escala (capital, provincia, Sudamérica, resto del mundo...).
+type ZonaViatico struct {
+	BaseModel
+	Nombre string `gorm:"size:100;not null;uniqueIndex"`
+}
+
+func (ZonaViatico) TableName() string {
+	return "zonas_viatico"
+}
+
+// CategoriaViatico es el monto diario para una categoría de viajero (Cargo.Categoria),
+// zona de destino y ámbito del viaje.
+type CategoriaViatico struct {
+	BaseModel
+	Codigo        int          `gorm:"not null;uniqueIndex:idx_categoria_viatico"`
+	Nombre        string       `gorm:"size:100;not null"`
+	ZonaViaticoID string       `gorm:"size:36;not null;uniqueIndex:idx_categoria_viatico"`
+	ZonaViatico   *ZonaViatico `gorm:"foreignKey:ZonaViaticoID;<-:false"`
+	AmbitoCodigo  string       `gorm:"size:20;not null;uniqueIndex:idx_categoria_viatico"`
+	Ambito        *AmbitoViaje `gorm:"foreignKey:AmbitoCodigo;references:Codigo;<-:false"`
+	Monto         float64      `gorm:"type:decimal(10,2);not null"`
+	Moneda        string       `gorm:"size:3;not null;default:'BOB'"`
+}
+
+func (CategoriaViatico) TableName() string {
+	return "categorias_viatico"
+}
+
+func (c CategoriaViatico) GetMonedaSimbolo() string {
+	return MonedaSimbolo(c.Moneda)
+}
+
+func (c CategoriaViatico) GetAmbitoNombre() string {
+	if c.Ambito != nil {
+		return c.Ambito.Nombre
+	}
+	return c.AmbitoCodigo
+}
+
+// Viatico es la liquidación de viáticos de un descargo oficial. Guarda la escala, el tipo de
+// cambio y la tasa aplicados para que cambios posteriores no alteren lo ya liquidado.
+type Viatico struct {
+	BaseModel
+	DescargoID string    `gorm:"size:36;not null;uniqueIndex"`
+	Descargo   *Descargo `gorm:"foreignKey:DescargoID;<-:false"`
+	UsuarioID  string    `gorm:"size:36;not null;index"`
+	Usuario    *Usuario  `gorm:"foreignKey:UsuarioID;<-:false"`
+
+	CategoriaViaticoID string `gorm:"size:36;not null"`
+	Categoria          int    `gorm:"not null"`
+	CategoriaNombre    string `gorm:"size:100"`
+	ZonaNombre         string `gorm:"size:100"`
+	AmbitoCodigo       string `gorm:"size:20"`
+
+	FechaSalida  time.Time `gorm:"type:timestamp;not null"`
+	FechaRetorno time.Time `gorm:"type:timestamp;not null;index"`
+	Dias         int       `gorm:"not null"`
+
+	MontoDiario float64 `gorm:"type:decimal(10,2);not null"`
+	Moneda      string  `gorm:"size:3;not null"`
+	TipoCambio  float64 `gorm:"type:decimal(10,4);default:1"`
+
+	// Montos en bolivianos.
+	Total     float64 `gorm:"type:decimal(15,2);not null"`
+	TasaRCIVA float64 `gorm:"column:tasa_rc_iva;type:decimal(5,4);not null"`
+	Retencion float64 `gorm:"type:decimal(15,2);not null"`
+	Liquido   float64 `gorm:"type:decimal(15,2);not null"`
+}
+
+func (Viatico) TableName() string {
+	return "viaticos"
+}
+
+// Liquidar calcula el total en bolivianos, la retención RC-IVA y el líquido pagable.
+func (v *Viatico) Liquidar() {
+	v.Total = math.Round(v.MontoDiario*float64(v.Dias)*v.TipoCambio*100) / 100
+	v.Retencion = math.Round(v.Total*v.TasaRCIVA*100) / 100
+	v.Liquido = math.Round((v.Total-v.Retencion)*100) / 100
+}
+
+func (v Viatico) GetMonedaSimbolo() string {
+	return MonedaSimbolo(v.Moneda)
+}
+
+func (v Viatico) GetTasaRCIVAPorcentaje() float64 {
+	return v.TasaRCIVA * 100
+}
+
+// DiasViatico cuenta los días calendario de la comisión, incluidos el de salida y el de retorno.
+func DiasViatico(salida, retorno time.Time) int {
+	if salida.IsZero() || retorno.IsZero() {
+		return 0
+	}
+	s := time.Date(salida.Year(), salida.Month(), salida.Day(), 0, 0, 0, 0, time.UTC)
+	r := time.Date(retorno.Year(), retorno.Month(), retorno.Day(), 0, 0, 0, 0, time.UTC)
+	if r.Before(s) {
+		return 0
+	}
+	return int(r.Sub(s).Hours()/24) + 1
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type AdjuntoRepository struct {
+	db *gorm.DB
+}
+
+func NewAdjuntoRepository(db *gorm.DB) *AdjuntoRepository {
+	return &AdjuntoRepository{db: db}
+}
+
+func (r *AdjuntoRepository) WithContext(ctx context.Context) *AdjuntoRepository {
+	return &AdjuntoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *AdjuntoRepository) FindByID(ctx context.Context, id string) (*models.Adjunto, error) {
+	var a models.Adjunto
+	if err := r.db.WithContext(ctx).First(&a, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// FindConVersiones carga el adjunto con su historial, de la versión más reciente a la primera.
+func (r *AdjuntoRepository) FindConVersiones(ctx context.Context, id string) (*models.Adjunto, error) {
+	var a models.Adjunto
+	err := r.db.WithContext(ctx).
+		Preload("Versiones", func(db *gorm.DB) *gorm.DB { return db.Order("version DESC") }).
+		Preload("Versiones.SubidoPor").
+		First(&a, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *AdjuntoRepository) FindVersion(ctx context.Context, adjuntoID string, version int) (*models.AdjuntoVersion, error) {
+	var v models.AdjuntoVersion
+	if err := r.db.WithContext(ctx).First(&v, "adjunto_id = ? AND version = ?", adjuntoID, version).Error; err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// Create guarda un adjunto nuevo junto con su primera versión.
+func (r *AdjuntoRepository) Create(ctx context.Context, a *models.Adjunto, v *models.AdjuntoVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(a).Error; err != nil {
+			return err
+		}
+		v.AdjuntoID = a.ID
+		return tx.Omit(clause.Associations).Create(v).Error
+	})
+}
+
+// AgregarVersion registra v como versión vigente del adjunto.
+func (r *AdjuntoRepository) AgregarVersion(ctx context.Context, a *models.Adjunto, v *models.AdjuntoVersion) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return agregarVersion(tx, a, v)
+	})
+}
+
+// Restaurar registra v como versión vigente y actualiza la ruta en la fila que referencia al
+// adjunto, según el campo al que pertenece.
+func (r *AdjuntoRepository) Restaurar(ctx context.Context, a *models.Adjunto, v *models.AdjuntoVersion) error {
+	campo, ok := models.CamposAdjunto[a.Campo]
+	if !ok {
+		return fmt.Errorf("campo de adjunto desconocido: %s", a.Campo)
+	}
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := agregarVersion(tx, a, v); err != nil {
+			return err
+		}
+		return tx.Table(campo.Tabla).
+			Where(campo.ColumnaAdjunto+" = ?", a.ID).
+			Update(campo.ColumnaArchivo, v.Archivo).Error
+	})
+}
+
+func agregarVersion(tx *gorm.DB, a *models.Adjunto, v *models.AdjuntoVersion) error {
+	// Bloquea el adjunto para que dos reemplazos simultáneos no tomen el mismo número.
+	var actual models.Adjunto
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&actual, "id = ?", a.ID).Error; err != nil {
+		return err
+	}
+	v.AdjuntoID = a.ID
+	v.Version = actual.VersionActual + 1
+	if err := tx.Omit(clause.Associations).Create(v).Error; err != nil {
+		return err
+	}
+	a.Archivo = v.Archivo
+	a.VersionActual = v.Version
+	return tx.Model(a).Updates(map[string]interface{}{
+		"archivo":        a.Archivo,
+		"version_actual": a.VersionActual,
+	}).Error
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type AnticipoRepository struct {
+	db *gorm.DB
+}
+
+func NewAnticipoRepository(db *gorm.DB) *AnticipoRepository {
+	return &AnticipoRepository{db: db}
+}
+
+func (r *AnticipoRepository) WithTx(tx *gorm.DB) *AnticipoRepository {
+	return &AnticipoRepository{db: tx}
+}
+
+func (r *AnticipoRepository) WithContext(ctx context.Context) *AnticipoRepository {
+	return &AnticipoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *AnticipoRepository) FindByID(ctx context.Context, id string) (*models.Anticipo, error) {
+	var a models.Anticipo
+	err := r.db.WithContext(ctx).Preload("Solicitud").Preload("Usuario").Preload("RevisadoPor").
+		First(&a, "id = ?", id).Error
+	return &a, err
+}
+
+func (r *AnticipoRepository) FindBySolicitudID(ctx context.Context, solicitudID string) (*models.Anticipo, error) {
+	var a models.Anticipo
+	err := r.db.WithContext(ctx).Preload("RevisadoPor").First(&a, "solicitud_id = ?", solicitudID).Error
+	return &a, err
+}
+
+// FindByEstado lista los anticipos en el estado dado; vacío trae todos.
+func (r *AnticipoRepository) FindByEstado(ctx context.Context, estado string) ([]models.Anticipo, error) {
+	var list []models.Anticipo
+	query := r.db.WithContext(ctx).Preload("Solicitud").Preload("Usuario")
+	if estado != "" {
+		query = query.Where("estado = ?", estado)
+	}
+	err := query.Order("created_at DESC").Find(&list).Error
+	return list, err
+}
+
+func (r *AnticipoRepository) Create(ctx context.Context, a *models.Anticipo) error {
+	return r.db.WithContext(ctx).Omit("Solicitud", "Usuario", "RevisadoPor").Create(a).Error
+}
+
+func (r *AnticipoRepository) Update(ctx context.Context, a *models.Anticipo) error {
+	return r.db.WithContext(ctx).Model(a).Select("*").Omit("Solicitud", "Usuario", "RevisadoPor", "CreatedAt", "CreatedBy").Updates(a).Error
+}
@@ -15,6 +15,10 @@ func NewAuditRepository(db *gorm.DB) *AuditRepository {
 	return &AuditRepository{db: db}
 }
 
+func (r *AuditRepository) WithTx(tx *gorm.DB) *AuditRepository {
+	return &AuditRepository{db: tx}
+}
+
 func (r *AuditRepository) Create(ctx context.Context, log *models.AuditLog) error {
 	return r.db.WithContext(ctx).Create(log).Error
 }
@@ -0,0 +1,112 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UsoBillete es una aparición de un número de billete en pasajes, tramos de descargo
+// u open tickets, con el viajero al que pertenece.
+type UsoBillete struct {
+	Fuente          string // PASAJE, DESCARGO, OPEN_TICKET
+	RegistroID      string
+	NumeroBillete   string
+	Normalizado     string
+	UsuarioID       string
+	UsuarioNombre   string
+	SolicitudID     string
+	SolicitudCodigo string
+	PasajeID        string // Pasaje propio o al que refiere el tramo/open ticket
+	OpenTicketID    string // Open ticket del que proviene el pasaje (reemisión)
+	OriginalID      string // Pasaje que reemplaza, si es una reemisión
+	CreatedAt       time.Time
+}
+
+const (
+	UsoBilletePasaje     = "PASAJE"
+	UsoBilleteDescargo   = "DESCARGO"
+	UsoBilleteOpenTicket = "OPEN_TICKET"
+)
+
+// sqlUsosBillete une las tres fuentes; con filtrarNumero cada rama se limita a @numero sobre
+// su columna normalizada (indexada) para no recorrer las tablas.
+func sqlUsosBillete(filtrarNumero bool) string {
+	filtro := func(col string) string {
+		if !filtrarNumero {
+			return ""
+		}
+		return " AND " + col + " = @numero"
+	}
+	return `
+SELECT 'PASAJE' AS fuente, p.id AS registro_id, p.numero_billete AS numero_billete,
+	p.billete_normalizado AS normalizado,
+	s.usuario_id AS usuario_id, TRIM(CONCAT_WS(' ', u.firstname, u.lastname, u.surname)) AS usuario_nombre,
+	s.id AS solicitud_id, s.codigo AS solicitud_codigo, p.id AS pasaje_id,
+	COALESCE(p.open_ticket_id, si.open_ticket_id, '') AS open_ticket_id,
+	COALESCE(p.pasaje_original_id, '') AS original_id, p.created_at AS created_at
+FROM pasajes p
+JOIN solicitudes s ON s.id = p.solicitud_id
+LEFT JOIN solicitud_items si ON si.id = p.solicitud_item_id
+LEFT JOIN usuarios u ON u.id = s.usuario_id
+WHERE p.deleted_at IS NULL AND p.numero_billete <> ''` + filtro("p.billete_normalizado") + `
+UNION ALL
+SELECT 'DESCARGO', dt.id, dt.billete,
+	dt.billete_normalizado,
+	d.usuario_id, TRIM(CONCAT_WS(' ', u.firstname, u.lastname, u.surname)),
+	s.id, s.codigo, COALESCE(dt.pasaje_id, ''), '', '', dt.created_at
+FROM descargo_tramos dt
+JOIN descargos d ON d.id = dt.descargo_id AND d.deleted_at IS NULL
+JOIN solicitudes s ON s.id = d.solicitud_id
+LEFT JOIN usuarios u ON u.id = d.usuario_id
+WHERE dt.deleted_at IS NULL AND dt.billete <> ''` + filtro("dt.billete_normalizado") + `
+UNION ALL
+SELECT 'OPEN_TICKET', ot.id::text, ot.numero_billete,
+	ot.billete_normalizado,
+	ot.usuario_id::text, TRIM(CONCAT_WS(' ', u.firstname, u.lastname, u.surname)),
+	COALESCE(s.id, ''), COALESCE(s.codigo, ''), COALESCE(ot.pasaje_id, ''), ot.id::text, '', ot.created_at
+FROM open_tickets ot
+LEFT JOIN descargos d ON d.id = ot.descargo_id::text
+LEFT JOIN solicitudes s ON s.id = d.solicitud_id
+LEFT JOIN usuarios u ON u.id = ot.usuario_id::text
+WHERE ot.deleted_at IS NULL AND ot.numero_billete <> '' AND ot.estado <> 'CANCELADO'` + filtro("ot.billete_normalizado")
+}
+
+type BilleteRepository struct {
+	db *gorm.DB
+}
+
+func NewBilleteRepository(db *gorm.DB) *BilleteRepository {
+	return &BilleteRepository{db: db}
+}
+
+func (r *BilleteRepository) WithContext(ctx context.Context) *BilleteRepository {
+	return &BilleteRepository{db: r.db.WithContext(ctx)}
+}
+
+// FindUsos lista todas las apariciones del número (ya normalizado) en las tres fuentes.
+func (r *BilleteRepository) FindUsos(ctx context.Context, normalizado string) ([]UsoBillete, error) {
+	var usos []UsoBillete
+	err := r.db.WithContext(ctx).
+		Raw("SELECT * FROM ("+sqlUsosBillete(true)+") usos ORDER BY created_at", map[string]any{"numero": normalizado}).
+		Scan(&usos).Error
+	return usos, err
+}
+
+// FindColisiones lista los usos de números que aparecen en más de un pasaje o con más
+// de un viajero, ordenados por número para agruparlos en el reporte.
+func (r *BilleteRepository) FindColisiones(ctx context.Context) ([]UsoBillete, error) {
+	var usos []UsoBillete
+	err := r.db.WithContext(ctx).Raw(`
+WITH usos AS (` + sqlUsosBillete(false) + `),
+repetidos AS (
+	SELECT normalizado FROM usos
+	GROUP BY normalizado
+	HAVING COUNT(DISTINCT usuario_id) > 1 OR COUNT(*) FILTER (WHERE fuente = 'PASAJE') > 1
+)
+SELECT usos.* FROM usos JOIN repetidos USING (normalizado)
+ORDER BY usos.normalizado, usos.created_at`).
+		Scan(&usos).Error
+	return usos, err
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CalendarioFeedRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarioFeedRepository(db *gorm.DB) *CalendarioFeedRepository {
+	return &CalendarioFeedRepository{db: db}
+}
+
+func (r *CalendarioFeedRepository) WithContext(ctx context.Context) *CalendarioFeedRepository {
+	return &CalendarioFeedRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *CalendarioFeedRepository) Create(ctx context.Context, f *models.CalendarioFeed) error {
+	return r.db.WithContext(ctx).Create(f).Error
+}
+
+func (r *CalendarioFeedRepository) Update(ctx context.Context, f *models.CalendarioFeed) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(f).Error
+}
+
+func (r *CalendarioFeedRepository) FindByUsuarioID(ctx context.Context, usuarioID string) (*models.CalendarioFeed, error) {
+	var f models.CalendarioFeed
+	if err := r.db.WithContext(ctx).First(&f, "usuario_id = ?", usuarioID).Error; err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *CalendarioFeedRepository) FindByToken(ctx context.Context, token string) (*models.CalendarioFeed, error) {
+	var f models.CalendarioFeed
+	if err := r.db.WithContext(ctx).Preload("Usuario").First(&f, "token = ?", token).Error; err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *CalendarioFeedRepository) UpdateUltimoAcceso(ctx context.Context, id string, fecha time.Time) error {
+	return r.db.WithContext(ctx).Model(&models.CalendarioFeed{}).Where("id = ?", id).UpdateColumn("ultimo_acceso", fecha).Error
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CertificadoFirmaRepository struct {
+	db *gorm.DB
+}
+
+func NewCertificadoFirmaRepository(db *gorm.DB) *CertificadoFirmaRepository {
+	return &CertificadoFirmaRepository{db: db}
+}
+
+func (r *CertificadoFirmaRepository) WithContext(ctx context.Context) *CertificadoFirmaRepository {
+	return &CertificadoFirmaRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *CertificadoFirmaRepository) Create(ctx context.Context, c *models.CertificadoFirma) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(c).Error
+}
+
+func (r *CertificadoFirmaRepository) Update(ctx context.Context, c *models.CertificadoFirma) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(c).Error
+}
+
+func (r *CertificadoFirmaRepository) FindByUsuarioID(ctx context.Context, usuarioID string) (*models.CertificadoFirma, error) {
+	var c models.CertificadoFirma
+	if err := r.db.WithContext(ctx).First(&c, "usuario_id = ?", usuarioID).Error; err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Delete borra el registro físicamente para que el usuario pueda registrar otro certificado
+// sin chocar con el índice único.
+func (r *CertificadoFirmaRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.CertificadoFirma{}, "id = ?", id).Error
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"sistema-pasajes/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -131,6 +132,49 @@ func (r *CupoDerechoItemRepository) FindByPeriodo(ctx context.Context, gestion,
 	return list, err
 }
 
+// FindForTitularByRango retorna los cupos del titular cuya vigencia se cruza con el rango dado.
+func (r *CupoDerechoItemRepository) FindForTitularByRango(ctx context.Context, titularID string, desde, hasta time.Time) ([]models.CupoDerechoItem, error) {
+	var list []models.CupoDerechoItem
+	err := r.db.WithContext(ctx).
+		Where("sen_titular_id = ? AND fecha_desde <= ? AND fecha_hasta >= ?", titularID, hasta, desde).
+		Order("fecha_desde ASC, seq ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// FindDisponiblesParaCalendario retorna los cupos disponibles asignados al usuario (o a quienes
+// tiene a cargo) que siguen vigentes desde la fecha indicada.
+func (r *CupoDerechoItemRepository) FindDisponiblesParaCalendario(ctx context.Context, userID string, desde time.Time) ([]models.CupoDerechoItem, error) {
+	var list []models.CupoDerechoItem
+	err := r.db.WithContext(ctx).
+		Preload("SenAsignado").
+		Where(
+			"(sen_asignado_id = ? OR sen_asignado_id IN (?))",
+			userID,
+			r.db.WithContext(ctx).Table("usuarios").Select("id").Where("encargado_id = ?", userID),
+		).
+		Where("estado_cupo_derecho_codigo = ? AND fecha_hasta >= ?", "DISPONIBLE", desde).
+		Order("fecha_desde ASC, seq ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *CupoDerechoItemRepository) FindByGestion(ctx context.Context, gestion int) ([]models.CupoDerechoItem, error) {
+	var list []models.CupoDerechoItem
+	err := r.db.WithContext(ctx).Where("gestion = ?", gestion).Find(&list).Error
+	return list, err
+}
+
+// CountConsumidosByCupo cuenta los ítems que no están disponibles para su titular
+// (ver CupoDerechoItem.IsConsumido).
+func (r *CupoDerechoItemRepository) CountConsumidosByCupo(ctx context.Context, cupoDerechoID string) (int, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.CupoDerechoItem{}).
+		Where("cupo_derecho_id = ? AND (estado_cupo_derecho_codigo <> 'DISPONIBLE' OR sen_asignado_id <> sen_titular_id)", cupoDerechoID).
+		Count(&count).Error
+	return int(count), err
+}
+
 func (r *CupoDerechoItemRepository) FindByID(ctx context.Context, id string) (*models.CupoDerechoItem, error) {
 	var v models.CupoDerechoItem
 	err := r.db.WithContext(ctx).
@@ -147,6 +191,18 @@ func (r *CupoDerechoItemRepository) FindByID(ctx context.Context, id string) (*m
 	return &v, err
 }
 
+// LockByID bloquea la fila del cupo hasta el fin de la transacción para serializar
+// transferencias concurrentes del mismo cupo.
+func (r *CupoDerechoItemRepository) LockByID(ctx context.Context, id string) error {
+	var v models.CupoDerechoItem
+	return r.db.WithContext(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&v, "id = ?", id).Error
+}
+
+// Delete da de baja lógica un cupo; su historial (solicitudes anuladas, movimientos) lo sigue referenciando.
+func (r *CupoDerechoItemRepository) Delete(ctx context.Context, v *models.CupoDerechoItem) error {
+	return r.db.WithContext(ctx).Delete(v).Error
+}
+
 func (r *CupoDerechoItemRepository) DeleteUnscoped(ctx context.Context, v *models.CupoDerechoItem) error {
 	return r.db.WithContext(ctx).Unscoped().Delete(v).Error
 }
@@ -58,6 +58,21 @@ func (r *CupoDerechoRepository) FindByPeriodo(ctx context.Context, gestion, mes
 	return cupos, err
 }
 
+func (r *CupoDerechoRepository) FindByGestion(ctx context.Context, gestion int) ([]models.CupoDerecho, error) {
+	var cupos []models.CupoDerecho
+	err := r.db.WithContext(ctx).
+		Preload("SenTitular").
+		Joins("JOIN usuarios ON usuarios.id = cupos_derecho.sen_titular_id").
+		Where("gestion = ?", gestion).
+		Order("mes ASC, usuarios.lastname ASC, usuarios.surname ASC, usuarios.firstname ASC").
+		Find(&cupos).Error
+	return cupos, err
+}
+
+func (r *CupoDerechoRepository) UpdateUsado(ctx context.Context, id string, usado int) error {
+	return r.db.WithContext(ctx).Model(&models.CupoDerecho{}).Where("id = ?", id).Update("cupo_usado", usado).Error
+}
+
 func (r *CupoDerechoRepository) FindByTitular(ctx context.Context, titularID string, gestion int) ([]models.CupoDerecho, error) {
 	var cupos []models.CupoDerecho
 	err := r.db.WithContext(ctx).Where("sen_titular_id = ? AND gestion = ?", titularID, gestion).Order("mes asc").Find(&cupos).Error
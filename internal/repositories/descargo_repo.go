@@ -94,6 +94,7 @@ func (r *DescargoRepository) FindBySolicitudID(ctx context.Context, solicitudID
 		Preload("Oficial").
 		Preload("Oficial.Anexos", func(db *gorm.DB) *gorm.DB { return db.Order("seq ASC") }).
 		Preload("Oficial.TransportesTerrestres", func(db *gorm.DB) *gorm.DB { return db.Order("seq ASC") }).
+		Preload("Viatico").
 		Where("solicitud_id = ?", solicitudID).First(&descargo).Error
 	return &descargo, err
 }
@@ -130,6 +131,7 @@ func (r *DescargoRepository) FindByID(ctx context.Context, id string) (*models.D
 		Preload("Oficial").
 		Preload("Oficial.Anexos", func(db *gorm.DB) *gorm.DB { return db.Order("seq ASC") }).
 		Preload("Oficial.TransportesTerrestres", func(db *gorm.DB) *gorm.DB { return db.Order("seq ASC") }).
+		Preload("Viatico").
 		First(&descargo, "id = ?", id).Error
 	return &descargo, err
 }
@@ -256,7 +258,7 @@ func (r *DescargoRepository) Update(ctx context.Context, descargo *models.Descar
 
 		if descargo.HasChanges(existing) {
 			if err := tx.Model(descargo).Select("*").
-				Omit("Tramos", "Oficial", "Anexos", "Terrestres", "CreatedAt", "CreatedBy").
+				Omit("Tramos", "Oficial", "Viatico", "Anexos", "Terrestres", "CreatedAt", "CreatedBy").
 				Updates(descargo).Error; err != nil {
 				return err
 			}
@@ -311,6 +313,15 @@ func (r *DescargoRepository) Update(ctx context.Context, descargo *models.Descar
 	})
 }
 
+// UpdateVerificacionPase guarda el resultado de leer el código de barras del pase de abordar.
+func (r *DescargoRepository) UpdateVerificacionPase(ctx context.Context, tramoID, estado, discrepancias, bcbp string) error {
+	return r.db.WithContext(ctx).Model(&models.DescargoTramo{}).Where("id = ?", tramoID).Updates(map[string]interface{}{
+		"pase_verificacion":  estado,
+		"pase_discrepancias": discrepancias,
+		"pase_bcbp":          bcbp,
+	}).Error
+}
+
 func (r *DescargoRepository) UpdateOficial(ctx context.Context, oficial *models.DescargoOficial) error {
 	var existing models.DescargoOficial
 	if err := r.db.WithContext(ctx).First(&existing, "id = ?", oficial.ID).Error; err != nil {
@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DesviacionTarifaRepository struct {
+	db *gorm.DB
+}
+
+func NewDesviacionTarifaRepository(db *gorm.DB) *DesviacionTarifaRepository {
+	return &DesviacionTarifaRepository{db: db}
+}
+
+func (r *DesviacionTarifaRepository) WithTx(tx *gorm.DB) *DesviacionTarifaRepository {
+	return &DesviacionTarifaRepository{db: tx}
+}
+
+func (r *DesviacionTarifaRepository) WithContext(ctx context.Context) *DesviacionTarifaRepository {
+	return &DesviacionTarifaRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *DesviacionTarifaRepository) RunTransaction(fn func(repo *DesviacionTarifaRepository, tx *gorm.DB) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(r.WithTx(tx), tx)
+	})
+}
+
+func (r *DesviacionTarifaRepository) Save(ctx context.Context, d *models.DesviacionTarifa) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(d).Error
+}
+
+func (r *DesviacionTarifaRepository) FindByPasajeID(ctx context.Context, pasajeID string) (*models.DesviacionTarifa, error) {
+	var d models.DesviacionTarifa
+	if err := r.db.WithContext(ctx).First(&d, "pasaje_id = ?", pasajeID).Error; err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// FindForReport retorna las desviaciones de pasajes emitidos en el rango (por fecha de emisión
+// del pasaje, o de registro si no la tiene).
+func (r *DesviacionTarifaRepository) FindForReport(ctx context.Context, filter dtos.ReportFilterRequest) ([]models.DesviacionTarifa, error) {
+	var list []models.DesviacionTarifa
+	query := r.db.WithContext(ctx).
+		Preload("Pasaje.Solicitud.Usuario").
+		Preload("Ruta").
+		Preload("Aerolinea").
+		Preload("Agencia").
+		Preload("AutorizadoPor").
+		Joins("JOIN pasajes ON pasajes.id = desviaciones_tarifa.pasaje_id AND pasajes.deleted_at IS NULL")
+
+	if filter.FechaDesde != "" {
+		query = query.Where("COALESCE(pasajes.fecha_emision, desviaciones_tarifa.created_at::date) >= ?", filter.FechaDesde)
+	}
+	if filter.FechaHasta != "" {
+		query = query.Where("COALESCE(pasajes.fecha_emision, desviaciones_tarifa.created_at::date) <= ?", filter.FechaHasta)
+	}
+	if filter.AerolineaID != "" {
+		query = query.Where("desviaciones_tarifa.aerolinea_id = ?", filter.AerolineaID)
+	}
+	if filter.AgenciaID != "" {
+		query = query.Where("desviaciones_tarifa.agencia_id = ?", filter.AgenciaID)
+	}
+
+	err := query.Order("desviaciones_tarifa.desviacion_pct DESC").Find(&list).Error
+	return list, err
+}
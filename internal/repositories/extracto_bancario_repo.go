@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"sistema-pasajes/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExtractoBancarioRepository struct {
+	db *gorm.DB
+}
+
+func NewExtractoBancarioRepository(db *gorm.DB) *ExtractoBancarioRepository {
+	return &ExtractoBancarioRepository{db: db}
+}
+
+func (r *ExtractoBancarioRepository) WithContext(ctx context.Context) *ExtractoBancarioRepository {
+	return &ExtractoBancarioRepository{db: r.db.WithContext(ctx)}
+}
+
+// Create guarda el extracto con sus movimientos en una transacción.
+func (r *ExtractoBancarioRepository) Create(ctx context.Context, e *models.ExtractoBancario, movimientos []models.MovimientoBancario) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(e).Error; err != nil {
+			return err
+		}
+		for i := range movimientos {
+			movimientos[i].ExtractoID = e.ID
+		}
+		if len(movimientos) == 0 {
+			return nil
+		}
+		return tx.Omit(clause.Associations).CreateInBatches(movimientos, 200).Error
+	})
+}
+
+func (r *ExtractoBancarioRepository) FindRecientes(ctx context.Context, limit int) ([]models.ExtractoBancario, error) {
+	var list []models.ExtractoBancario
+	err := r.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+// ExisteMovimiento evita duplicar abonos al importar extractos que se solapan.
+func (r *ExtractoBancarioRepository) ExisteMovimiento(ctx context.Context, fecha time.Time, referencia string, monto float64) bool {
+	var count int64
+	r.db.WithContext(ctx).Model(&models.MovimientoBancario{}).
+		Where("fecha = ? AND referencia = ? AND monto = ?", fecha, referencia, monto).
+		Count(&count)
+	return count > 0
+}
+
+func (r *ExtractoBancarioRepository) FindMovimientoByID(ctx context.Context, id string) (*models.MovimientoBancario, error) {
+	var m models.MovimientoBancario
+	if err := r.db.WithContext(ctx).First(&m, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// FindMovimientosSinConciliar retorna los abonos que aún no se asociaron a un reembolso.
+func (r *ExtractoBancarioRepository) FindMovimientosSinConciliar(ctx context.Context) ([]models.MovimientoBancario, error) {
+	var list []models.MovimientoBancario
+	err := r.db.WithContext(ctx).
+		Where("pasaje_id IS NULL AND monto > 0").
+		Order("fecha DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// FindReembolsosNoVerificados retorna los pasajes con monto a devolver cuyo depósito aún no
+// se encontró en un extracto.
+func (r *ExtractoBancarioRepository) FindReembolsosNoVerificados(ctx context.Context) ([]models.Pasaje, error) {
+	var list []models.Pasaje
+	err := r.db.WithContext(ctx).
+		Preload("Solicitud.Usuario").
+		Where("monto_reembolso > 0 AND COALESCE(estado_reembolso, '') <> ?", models.EstadoReembolsoVerificado).
+		Order("fecha_vuelo ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// Vincular marca el reembolso como verificado con el movimiento del extracto. Solo vincula un
+// abono libre con un reembolso pendiente de verificar; así no pisa una conciliación previa.
+func (r *ExtractoBancarioRepository) Vincular(ctx context.Context, movimientoID, pasajeID string) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.MovimientoBancario{}).Where("id = ? AND pasaje_id IS NULL", movimientoID).
+			Updates(map[string]interface{}{"pasaje_id": pasajeID, "observacion": ""})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return errors.New("el movimiento ya está conciliado con otro reembolso")
+		}
+		res = tx.Model(&models.Pasaje{}).
+			Where("id = ? AND monto_reembolso > 0 AND COALESCE(estado_reembolso, '') <> ?", pasajeID, models.EstadoReembolsoVerificado).
+			Updates(map[string]interface{}{
+				"estado_reembolso":       models.EstadoReembolsoVerificado,
+				"movimiento_bancario_id": movimientoID,
+				"fecha_verificacion":     time.Now(),
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return errors.New("el pasaje no tiene un reembolso pendiente de verificar")
+		}
+		return nil
+	})
+}
+
+func (r *ExtractoBancarioRepository) UpdateObservacion(ctx context.Context, movimientoID, observacion string) error {
+	return r.db.WithContext(ctx).Model(&models.MovimientoBancario{}).
+		Where("id = ?", movimientoID).Update("observacion", observacion).Error
+}
+
+// LiberarHuerfanos desvincula los movimientos cuyo pasaje ya no los referencia (la boleta o el
+// monto del reembolso cambiaron después de conciliar).
+func (r *ExtractoBancarioRepository) LiberarHuerfanos(ctx context.Context) error {
+	return r.db.WithContext(ctx).Exec(`
+UPDATE movimientos_bancarios SET pasaje_id = NULL
+WHERE pasaje_id IS NOT NULL AND NOT EXISTS (
+	SELECT 1 FROM pasajes p
+	WHERE p.id = movimientos_bancarios.pasaje_id AND p.movimiento_bancario_id = movimientos_bancarios.id AND p.deleted_at IS NULL
+)`).Error
+}
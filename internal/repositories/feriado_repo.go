@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type FeriadoRepository struct {
+	db *gorm.DB
+}
+
+func NewFeriadoRepository(db *gorm.DB) *FeriadoRepository {
+	return &FeriadoRepository{db: db}
+}
+
+func (r *FeriadoRepository) WithContext(ctx context.Context) *FeriadoRepository {
+	return &FeriadoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *FeriadoRepository) Create(ctx context.Context, f *models.Feriado) error {
+	return r.db.WithContext(ctx).Omit("Departamento").Create(f).Error
+}
+
+func (r *FeriadoRepository) FindByID(ctx context.Context, id string) (*models.Feriado, error) {
+	var f models.Feriado
+	err := r.db.WithContext(ctx).First(&f, "id = ?", id).Error
+	return &f, err
+}
+
+func (r *FeriadoRepository) FindAll(ctx context.Context) ([]models.Feriado, error) {
+	var list []models.Feriado
+	err := r.db.WithContext(ctx).Preload("Departamento").
+		Order("departamento_codigo NULLS FIRST, tipo, mes, dia, dias_desde_pascua, fecha").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *FeriadoRepository) UpdateActivo(ctx context.Context, id string, activo bool) error {
+	return r.db.WithContext(ctx).Model(&models.Feriado{}).Where("id = ?", id).Update("activo", activo).Error
+}
+
+func (r *FeriadoRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.Feriado{}, "id = ?", id).Error
+}
@@ -0,0 +1,69 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type LicenciaSenadorRepository struct {
+	db *gorm.DB
+}
+
+func NewLicenciaSenadorRepository(db *gorm.DB) *LicenciaSenadorRepository {
+	return &LicenciaSenadorRepository{db: db}
+}
+
+func (r *LicenciaSenadorRepository) WithContext(ctx context.Context) *LicenciaSenadorRepository {
+	return &LicenciaSenadorRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *LicenciaSenadorRepository) Create(ctx context.Context, l *models.LicenciaSenador) error {
+	return r.db.WithContext(ctx).Create(l).Error
+}
+
+func (r *LicenciaSenadorRepository) UpdateEstado(ctx context.Context, id string, estado models.EstadoLicencia) error {
+	return r.db.WithContext(ctx).Model(&models.LicenciaSenador{}).Where("id = ?", id).Update("estado", estado).Error
+}
+
+func (r *LicenciaSenadorRepository) FindByID(ctx context.Context, id string) (*models.LicenciaSenador, error) {
+	var l models.LicenciaSenador
+	err := r.db.WithContext(ctx).Preload("Titular").Preload("Suplente").First(&l, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *LicenciaSenadorRepository) FindAll(ctx context.Context) ([]models.LicenciaSenador, error) {
+	var list []models.LicenciaSenador
+	err := r.db.WithContext(ctx).
+		Preload("Titular").
+		Preload("Suplente").
+		Order("fecha_desde DESC").
+		Find(&list).Error
+	return list, err
+}
+
+// FindAbiertas retorna las licencias programadas o vigentes, que el job debe revisar.
+func (r *LicenciaSenadorRepository) FindAbiertas(ctx context.Context) ([]models.LicenciaSenador, error) {
+	var list []models.LicenciaSenador
+	err := r.db.WithContext(ctx).
+		Preload("Titular").
+		Preload("Suplente").
+		Where("estado IN ?", []models.EstadoLicencia{models.EstadoLicenciaProgramada, models.EstadoLicenciaVigente}).
+		Order("fecha_desde ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *LicenciaSenadorRepository) ExistsSolapada(ctx context.Context, titularID string, desde, hasta time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.LicenciaSenador{}).
+		Where("titular_id = ? AND estado IN ? AND fecha_desde <= ? AND fecha_hasta >= ?",
+			titularID, []models.EstadoLicencia{models.EstadoLicenciaProgramada, models.EstadoLicenciaVigente}, hasta, desde).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type MovimientoCupoRepository struct {
+	db *gorm.DB
+}
+
+func NewMovimientoCupoRepository(db *gorm.DB) *MovimientoCupoRepository {
+	return &MovimientoCupoRepository{db: db}
+}
+
+func (r *MovimientoCupoRepository) WithTx(tx *gorm.DB) *MovimientoCupoRepository {
+	return &MovimientoCupoRepository{db: tx}
+}
+
+func (r *MovimientoCupoRepository) WithContext(ctx context.Context) *MovimientoCupoRepository {
+	return &MovimientoCupoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *MovimientoCupoRepository) Create(ctx context.Context, m *models.MovimientoCupo) error {
+	return r.db.WithContext(ctx).Create(m).Error
+}
+
+func (r *MovimientoCupoRepository) CountByCupo(ctx context.Context, cupoDerechoID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.MovimientoCupo{}).
+		Where("cupo_derecho_id = ?", cupoDerechoID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *MovimientoCupoRepository) SumDeltaByCupo(ctx context.Context, cupoDerechoID string) (int, error) {
+	var total int
+	err := r.db.WithContext(ctx).Model(&models.MovimientoCupo{}).
+		Select("COALESCE(SUM(delta), 0)").
+		Where("cupo_derecho_id = ?", cupoDerechoID).
+		Scan(&total).Error
+	return total, err
+}
+
+type SaldoLibroCupo struct {
+	CupoDerechoID string
+	Total         int
+	Movimientos   int
+}
+
+// SumDeltaByGestion agrupa el libro por cupo de derecho para toda una gestión.
+func (r *MovimientoCupoRepository) SumDeltaByGestion(ctx context.Context, gestion int) (map[string]SaldoLibroCupo, error) {
+	var rows []SaldoLibroCupo
+	err := r.db.WithContext(ctx).Model(&models.MovimientoCupo{}).
+		Select("cupo_derecho_id, COALESCE(SUM(delta), 0) AS total, COUNT(*) AS movimientos").
+		Where("gestion = ?", gestion).
+		Group("cupo_derecho_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	saldos := make(map[string]SaldoLibroCupo, len(rows))
+	for _, row := range rows {
+		saldos[row.CupoDerechoID] = row
+	}
+	return saldos, nil
+}
+
+func (r *MovimientoCupoRepository) FindByCupo(ctx context.Context, cupoDerechoID string) ([]models.MovimientoCupo, error) {
+	var list []models.MovimientoCupo
+	err := r.db.WithContext(ctx).
+		Where("cupo_derecho_id = ?", cupoDerechoID).
+		Order("created_at ASC").
+		Find(&list).Error
+	return list, err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sistema-pasajes/internal/dtos"
 	"sistema-pasajes/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -129,6 +130,71 @@ func (r *PasajeRepository) FindConsolidado(ctx context.Context, filter dtos.Repo
 	return pasajes, err
 }
 
+// FindEmitidosByUsuarioEnRango retorna los pasajes emitidos o finalizados del beneficiario cuya
+// fecha de vuelo cae en el rango dado, excluyendo los de la solicitud indicada.
+func (r *PasajeRepository) FindEmitidosByUsuarioEnRango(ctx context.Context, usuarioID, excludeSolicitudID string, desde, hasta time.Time) ([]models.Pasaje, error) {
+	var pasajes []models.Pasaje
+	query := r.db.WithContext(ctx).
+		Joins("INNER JOIN solicitudes ON solicitudes.id = pasajes.solicitud_id AND solicitudes.deleted_at IS NULL").
+		Preload("Solicitud.TipoSolicitud.ConceptoViaje").
+		Preload("RutaPasaje.Origen").
+		Preload("RutaPasaje.Destino").
+		Where("solicitudes.usuario_id = ?", usuarioID).
+		Where("pasajes.estado_pasaje_codigo IN ?", []string{models.EstadoPasajeEmitido, models.EstadoPasajeFinalizado}).
+		Where("pasajes.fecha_vuelo >= ? AND pasajes.fecha_vuelo < ?", desde, hasta)
+
+	if excludeSolicitudID != "" {
+		query = query.Where("pasajes.solicitud_id <> ?", excludeSolicitudID)
+	}
+
+	err := query.Order("pasajes.fecha_vuelo ASC").Find(&pasajes).Error
+	return pasajes, err
+}
+
+// EmisionAgencia resume un pasaje emitido para las métricas de servicio de su agencia.
+// Aprobado y Emitido se toman de las marcas de estado y, para registros anteriores a ellas,
+// del registro de auditoría.
+type EmisionAgencia struct {
+	PasajeID      string
+	AgenciaID     string
+	AgenciaNombre string
+	Aprobado      *time.Time
+	Emitido       time.Time
+	EsReemision   bool
+	Penalidad     float64 // En bolivianos
+	Correcciones  int
+}
+
+// FindEmisionesAgencia retorna los pasajes con agencia emitidos en [desde, hasta).
+func (r *PasajeRepository) FindEmisionesAgencia(ctx context.Context, desde, hasta time.Time) ([]EmisionAgencia, error) {
+	var emisiones []EmisionAgencia
+	err := r.db.WithContext(ctx).Raw(`
+SELECT * FROM (
+	SELECT p.id AS pasaje_id, p.agencia_id, a.nombre AS agencia_nombre,
+		COALESCE(si.fecha_aprobacion,
+			(SELECT MAX(al.created_at) FROM audit_logs al
+			 WHERE al.action = 'APROBAR_SOLICITUD' AND al.entity_id = p.solicitud_id AND al.created_at <= p.created_at)) AS aprobado,
+		COALESCE(p.fecha_emitido,
+			(SELECT MIN(al.created_at) FROM audit_logs al
+			 WHERE al.action = 'CAMBIAR_ESTADO_PASAJE' AND al.entity_id = p.id AND al.new_value = ?),
+			p.fecha_emision) AS emitido,
+		p.pasaje_original_id IS NOT NULL AS es_reemision,
+		(p.costo_penalidad + p.penalidad_reemision) * p.tipo_cambio AS penalidad,
+		p.correcciones_emision AS correcciones
+	FROM pasajes p
+	JOIN agencias a ON a.id = p.agencia_id
+	LEFT JOIN solicitud_items si ON si.id = p.solicitud_item_id
+	WHERE p.deleted_at IS NULL AND p.estado_pasaje_codigo IN ?
+) e
+WHERE e.emitido >= ? AND e.emitido < ?
+ORDER BY e.agencia_nombre, e.emitido`,
+		models.EstadoPasajeEmitido,
+		[]string{models.EstadoPasajeEmitido, models.EstadoPasajeFinalizado, models.EstadoPasajeReemitido},
+		desde, hasta).
+		Scan(&emisiones).Error
+	return emisiones, err
+}
+
 func (r *PasajeRepository) GetDB() *gorm.DB {
 	return r.db
 }
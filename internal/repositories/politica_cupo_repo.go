@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PoliticaCupoRepository struct {
+	db *gorm.DB
+}
+
+func NewPoliticaCupoRepository(db *gorm.DB) *PoliticaCupoRepository {
+	return &PoliticaCupoRepository{db: db}
+}
+
+func (r *PoliticaCupoRepository) WithTx(tx *gorm.DB) *PoliticaCupoRepository {
+	return &PoliticaCupoRepository{db: tx}
+}
+
+func (r *PoliticaCupoRepository) WithContext(ctx context.Context) *PoliticaCupoRepository {
+	return &PoliticaCupoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *PoliticaCupoRepository) RunTransaction(fn func(repo *PoliticaCupoRepository, tx *gorm.DB) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(r.WithTx(tx), tx)
+	})
+}
+
+func (r *PoliticaCupoRepository) FindAll(ctx context.Context) ([]models.PoliticaCupo, error) {
+	var list []models.PoliticaCupo
+	err := r.db.WithContext(ctx).Preload("Ajustes").
+		Order("vigente_desde desc, version desc").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *PoliticaCupoRepository) FindByID(ctx context.Context, id string) (*models.PoliticaCupo, error) {
+	var p models.PoliticaCupo
+	err := r.db.WithContext(ctx).Preload("Ajustes").First(&p, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// FindVigente retorna la política activa en vigor a la fecha indicada.
+func (r *PoliticaCupoRepository) FindVigente(ctx context.Context, fecha time.Time) (*models.PoliticaCupo, error) {
+	var p models.PoliticaCupo
+	err := r.db.WithContext(ctx).Preload("Ajustes").
+		Where("activa = ? AND vigente_desde <= ?", true, fecha).
+		Where("vigente_hasta IS NULL OR vigente_hasta >= ?", fecha).
+		Order("vigente_desde desc, version desc").
+		First(&p).Error
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *PoliticaCupoRepository) MaxVersionByNombre(ctx context.Context, nombre string) (int, error) {
+	var max int
+	err := r.db.WithContext(ctx).Model(&models.PoliticaCupo{}).
+		Where("nombre = ?", nombre).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&max).Error
+	return max, err
+}
+
+func (r *PoliticaCupoRepository) Create(ctx context.Context, p *models.PoliticaCupo) error {
+	return r.db.WithContext(ctx).Create(p).Error
+}
+
+func (r *PoliticaCupoRepository) UpdateActiva(ctx context.Context, id string, activa bool) error {
+	return r.db.WithContext(ctx).Model(&models.PoliticaCupo{}).Where("id = ?", id).Update("activa", activa).Error
+}
@@ -0,0 +1,24 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type RecordatorioDescargoRepository struct {
+	db *gorm.DB
+}
+
+func NewRecordatorioDescargoRepository(db *gorm.DB) *RecordatorioDescargoRepository {
+	return &RecordatorioDescargoRepository{db: db}
+}
+
+func (r *RecordatorioDescargoRepository) WithContext(ctx context.Context) *RecordatorioDescargoRepository {
+	return &RecordatorioDescargoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *RecordatorioDescargoRepository) Create(ctx context.Context, rec *models.RecordatorioDescargo) error {
+	return r.db.WithContext(ctx).Omit("Solicitud").Create(rec).Error
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ReglaDescargoRepository struct {
+	db *gorm.DB
+}
+
+func NewReglaDescargoRepository(db *gorm.DB) *ReglaDescargoRepository {
+	return &ReglaDescargoRepository{db: db}
+}
+
+func (r *ReglaDescargoRepository) WithContext(ctx context.Context) *ReglaDescargoRepository {
+	return &ReglaDescargoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *ReglaDescargoRepository) Create(ctx context.Context, regla *models.ReglaDescargo) error {
+	return r.db.WithContext(ctx).Omit("Concepto", "TipoSolicitud").Create(regla).Error
+}
+
+func (r *ReglaDescargoRepository) FindByID(ctx context.Context, id string) (*models.ReglaDescargo, error) {
+	var regla models.ReglaDescargo
+	err := r.db.WithContext(ctx).First(&regla, "id = ?", id).Error
+	return &regla, err
+}
+
+func (r *ReglaDescargoRepository) FindAll(ctx context.Context) ([]models.ReglaDescargo, error) {
+	var list []models.ReglaDescargo
+	err := r.db.WithContext(ctx).Preload("Concepto").Preload("TipoSolicitud").
+		Order("concepto_codigo NULLS FIRST, tipo_solicitud_codigo NULLS FIRST, created_at").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *ReglaDescargoRepository) Update(ctx context.Context, regla *models.ReglaDescargo) error {
+	return r.db.WithContext(ctx).Model(regla).Select("Etiqueta", "MinLongitud").Updates(regla).Error
+}
+
+func (r *ReglaDescargoRepository) UpdateActivo(ctx context.Context, id string, activo bool) error {
+	return r.db.WithContext(ctx).Model(&models.ReglaDescargo{}).Where("id = ?", id).Update("activo", activo).Error
+}
+
+func (r *ReglaDescargoRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.ReglaDescargo{}, "id = ?", id).Error
+}
@@ -137,6 +137,15 @@ func (r *RutaRepository) AssignContract(ctx context.Context, contrato *models.Ru
 	return r.db.WithContext(ctx).Create(contrato).Error
 }
 
+func (r *RutaRepository) FindContract(ctx context.Context, rutaID, aerolineaID string) (*models.RutaContrato, error) {
+	var contrato models.RutaContrato
+	err := r.db.WithContext(ctx).Where("ruta_id = ? AND aerolinea_id = ?", rutaID, aerolineaID).First(&contrato).Error
+	if err != nil {
+		return nil, err
+	}
+	return &contrato, nil
+}
+
 func (r *RutaRepository) GetContractsByRuta(ctx context.Context, rutaID string) ([]models.RutaContrato, error) {
 	var contratos []models.RutaContrato
 	err := r.db.WithContext(ctx).Preload("Aerolinea").Where("ruta_id = ?", rutaID).Find(&contratos).Error
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"sistema-pasajes/internal/models"
+	"time"
 
 	"gorm.io/gorm"
 )
@@ -46,3 +47,24 @@ func (r *SolicitudItemRepository) UpdateTimestamps(ctx context.Context, id strin
 		"updated_at": updatedAt,
 	}).Error
 }
+
+// FindActivosByUsuarioEnRango retorna los tramos con fecha definida del beneficiario en el rango dado,
+// excluyendo tramos rechazados/cancelados y los de la solicitud indicada.
+func (r *SolicitudItemRepository) FindActivosByUsuarioEnRango(ctx context.Context, usuarioID, excludeSolicitudID string, desde, hasta time.Time) ([]models.SolicitudItem, error) {
+	var items []models.SolicitudItem
+	query := r.db.WithContext(ctx).
+		Joins("INNER JOIN solicitudes ON solicitudes.id = solicitud_items.solicitud_id AND solicitudes.deleted_at IS NULL").
+		Preload("Solicitud.TipoSolicitud.ConceptoViaje").
+		Where("solicitudes.usuario_id = ?", usuarioID).
+		Where("solicitud_items.fecha IS NOT NULL").
+		Where("solicitud_items.fecha >= ? AND solicitud_items.fecha < ?", desde, hasta).
+		Where("COALESCE(solicitud_items.estado_codigo, 'SOLICITADO') NOT IN ?", []string{"RECHAZADO", "CANCELADO"}).
+		Where("COALESCE(solicitudes.estado_solicitud_codigo, 'SOLICITADO') <> ?", "RECHAZADO")
+
+	if excludeSolicitudID != "" {
+		query = query.Where("solicitud_items.solicitud_id <> ?", excludeSolicitudID)
+	}
+
+	err := query.Order("solicitud_items.fecha ASC").Find(&items).Error
+	return items, err
+}
@@ -347,6 +347,9 @@ func (r *SolicitudRepository) FindByID(ctx context.Context, id string) (*models.
 		Preload("TipoItinerario").
 		Preload("AmbitoViaje").
 		Preload("CupoDerechoItem").
+		Preload("RecordatoriosDescargo", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at DESC")
+		}).
 		First(&solicitud, "id = ?", id).Error
 	if err != nil {
 		return nil, err
@@ -378,7 +381,19 @@ func (r *SolicitudRepository) ExistsByCodigo(ctx context.Context, codigo string)
 
 func (r *SolicitudRepository) FindPendientesDeDescargo(ctx context.Context) ([]models.Solicitud, error) {
 	var solicitudes []models.Solicitud
-	err := r.db.WithContext(ctx).Preload("Usuario.Encargado").
+	err := r.pendientesDeDescargoQuery(ctx).Find(&solicitudes).Error
+	return solicitudes, err
+}
+
+// FindPendientesDeDescargoByUsuario retorna las solicitudes del beneficiario con pasajes emitidos sin descargo finalizado.
+func (r *SolicitudRepository) FindPendientesDeDescargoByUsuario(ctx context.Context, usuarioID string) ([]models.Solicitud, error) {
+	var solicitudes []models.Solicitud
+	err := r.pendientesDeDescargoQuery(ctx).Where("solicitudes.usuario_id = ?", usuarioID).Find(&solicitudes).Error
+	return solicitudes, err
+}
+
+func (r *SolicitudRepository) pendientesDeDescargoQuery(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).Preload("Usuario.Encargado").
 		Preload("Items", func(db *gorm.DB) *gorm.DB {
 			return db.Order("seq ASC")
 		}).
@@ -388,12 +403,12 @@ func (r *SolicitudRepository) FindPendientesDeDescargo(ctx context.Context) ([]m
 		Preload("TipoSolicitud.ConceptoViaje").
 		Preload("Descargo.Tramos").
 		Preload("Descargo.Oficial").
+		Preload("Descargo.Oficial.Anexos").
+		Preload("RecordatoriosDescargo").
 		Joins("LEFT JOIN descargos ON solicitudes.id = descargos.solicitud_id").
 		Where("(descargos.id IS NULL OR descargos.estado != ?)", models.EstadoDescargoFinalizado).
 		Where("solicitudes.estado_solicitud_codigo IN (?)", []string{"PARCIALMENTE_APROBADO", "APROBADO", "EMITIDO"}).
-		Where(fmt.Sprintf("EXISTS (SELECT 1 FROM pasajes p JOIN solicitud_items si ON p.solicitud_item_id = si.id WHERE si.solicitud_id = solicitudes.id AND p.estado_pasaje_codigo = '%s')", models.EstadoPasajeEmitido)).
-		Find(&solicitudes).Error
-	return solicitudes, err
+		Where(fmt.Sprintf("EXISTS (SELECT 1 FROM pasajes p JOIN solicitud_items si ON p.solicitud_item_id = si.id WHERE si.solicitud_id = solicitudes.id AND p.estado_pasaje_codigo = '%s')", models.EstadoPasajeEmitido))
 }
 
 func (r *SolicitudRepository) FindPendientesDeDescargoUI(ctx context.Context, userID string, isAdmin bool) ([]models.Solicitud, error) {
@@ -423,6 +438,7 @@ func (r *SolicitudRepository) FindPendientesDeDescargoUI(ctx context.Context, us
 		Preload("Aerolinea").
 		Preload("Descargo.Tramos").
 		Preload("Descargo.Oficial").
+		Preload("Descargo.Oficial.Anexos").
 		Joins("LEFT JOIN descargos ON solicitudes.id = descargos.solicitud_id").
 		Where("(descargos.id IS NULL OR descargos.estado != ?)", models.EstadoDescargoFinalizado).
 		Where("solicitudes.estado_solicitud_codigo IN (?)", []string{"PARCIALMENTE_APROBADO", "APROBADO", "EMITIDO"}).
@@ -472,6 +488,7 @@ func (r *SolicitudRepository) FindPendientesDeDescargoPaginated(ctx context.Cont
 		Preload("Aerolinea").
 		Preload("Descargo.Tramos").
 		Preload("Descargo.Oficial").
+		Preload("Descargo.Oficial.Anexos").
 		Joins("LEFT JOIN descargos ON solicitudes.id = descargos.solicitud_id").
 		Joins("LEFT JOIN usuarios ON solicitudes.usuario_id = usuarios.id").
 		Where("(descargos.id IS NULL OR descargos.estado != ?)", models.EstadoDescargoFinalizado).
@@ -543,6 +560,37 @@ func (r *SolicitudRepository) FindPendientesByUsuarioID(ctx context.Context, usu
 	return solicitudes, err
 }
 
+// FindParaCalendario retorna las solicitudes vigentes del usuario (propias, registradas por él o
+// de quienes tiene a cargo) con algún tramo desde la fecha indicada.
+func (r *SolicitudRepository) FindParaCalendario(ctx context.Context, userID string, desde time.Time) ([]models.Solicitud, error) {
+	var solicitudes []models.Solicitud
+	err := r.db.WithContext(ctx).
+		Preload("Usuario").
+		Preload("Items", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq ASC")
+		}).
+		Preload("Items.Origen").
+		Preload("Items.Destino").
+		Preload("Items.Pasajes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("seq ASC")
+		}).
+		Preload("Items.Pasajes.Aerolinea").
+		Preload("TipoSolicitud.ConceptoViaje").
+		Preload("Descargo.Tramos").
+		Preload("Descargo.Oficial").
+		Preload("Descargo.Oficial.Anexos").
+		Where(
+			"solicitudes.usuario_id = ? OR solicitudes.created_by = ? OR solicitudes.usuario_id IN (?)",
+			userID,
+			userID,
+			r.db.WithContext(ctx).Table("usuarios").Select("id").Where("encargado_id = ?", userID),
+		).
+		Where("solicitudes.estado_solicitud_codigo NOT IN ?", []string{"RECHAZADO", "CANCELADO"}).
+		Where("EXISTS (SELECT 1 FROM solicitud_items si WHERE si.solicitud_id = solicitudes.id AND si.fecha >= ?)", desde).
+		Find(&solicitudes).Error
+	return solicitudes, err
+}
+
 func (r *SolicitudRepository) FindWithOpenTicketDescargoPaginated(ctx context.Context, userID string, isAdmin bool, page, limit int, searchTerm string) (*PaginatedSolicitudes, error) {
 	var solicitudes []models.Solicitud
 	var total int64
@@ -0,0 +1,54 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TipoCambioRepository struct {
+	db *gorm.DB
+}
+
+func NewTipoCambioRepository(db *gorm.DB) *TipoCambioRepository {
+	return &TipoCambioRepository{db: db}
+}
+
+func (r *TipoCambioRepository) WithContext(ctx context.Context) *TipoCambioRepository {
+	return &TipoCambioRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *TipoCambioRepository) Create(ctx context.Context, tc *models.TipoCambio) error {
+	return r.db.WithContext(ctx).Create(tc).Error
+}
+
+func (r *TipoCambioRepository) Delete(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.TipoCambio{}, "id = ?", id).Error
+}
+
+func (r *TipoCambioRepository) FindAll(ctx context.Context, limit int) ([]models.TipoCambio, error) {
+	var list []models.TipoCambio
+	err := r.db.WithContext(ctx).Order("fecha DESC, moneda ASC").Limit(limit).Find(&list).Error
+	return list, err
+}
+
+func (r *TipoCambioRepository) ExistsByMonedaFecha(ctx context.Context, moneda string, fecha time.Time) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.TipoCambio{}).Where("moneda = ? AND fecha = ?", moneda, fecha).Count(&count).Error
+	return count > 0, err
+}
+
+// FindVigente retorna la última cotización de la moneda registrada hasta la fecha dada.
+func (r *TipoCambioRepository) FindVigente(ctx context.Context, moneda string, fecha time.Time) (*models.TipoCambio, error) {
+	var tc models.TipoCambio
+	err := r.db.WithContext(ctx).
+		Where("moneda = ? AND fecha <= ?", moneda, fecha).
+		Order("fecha DESC").
+		First(&tc).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tc, nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TransferenciaCupoRepository struct {
+	db *gorm.DB
+}
+
+func NewTransferenciaCupoRepository(db *gorm.DB) *TransferenciaCupoRepository {
+	return &TransferenciaCupoRepository{db: db}
+}
+
+func (r *TransferenciaCupoRepository) WithTx(tx *gorm.DB) *TransferenciaCupoRepository {
+	return &TransferenciaCupoRepository{db: tx}
+}
+
+func (r *TransferenciaCupoRepository) WithContext(ctx context.Context) *TransferenciaCupoRepository {
+	return &TransferenciaCupoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *TransferenciaCupoRepository) Create(ctx context.Context, t *models.TransferenciaCupo) error {
+	return r.db.WithContext(ctx).Create(t).Error
+}
+
+func (r *TransferenciaCupoRepository) Update(ctx context.Context, t *models.TransferenciaCupo) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(t).Error
+}
+
+func (r *TransferenciaCupoRepository) preloadAll(db *gorm.DB) *gorm.DB {
+	return db.Preload("CupoDerechoItem").
+		Preload("CupoDerechoItem.SenTitular").
+		Preload("DeUsuario").
+		Preload("ParaUsuario").
+		Preload("Solicitante").
+		Preload("Aprobador")
+}
+
+func (r *TransferenciaCupoRepository) FindByID(ctx context.Context, id string) (*models.TransferenciaCupo, error) {
+	var t models.TransferenciaCupo
+	err := r.preloadAll(r.db.WithContext(ctx)).First(&t, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TransferenciaCupoRepository) FindPendientes(ctx context.Context) ([]models.TransferenciaCupo, error) {
+	var list []models.TransferenciaCupo
+	err := r.preloadAll(r.db.WithContext(ctx)).
+		Where("estado = ?", models.EstadoTransferenciaPendiente).
+		Order("fecha_solicitud ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *TransferenciaCupoRepository) FindPendientesByItemIDs(ctx context.Context, itemIDs []string) ([]models.TransferenciaCupo, error) {
+	var list []models.TransferenciaCupo
+	if len(itemIDs) == 0 {
+		return list, nil
+	}
+	err := r.db.WithContext(ctx).Preload("ParaUsuario").
+		Where("estado = ? AND cupo_derecho_item_id IN ?", models.EstadoTransferenciaPendiente, itemIDs).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *TransferenciaCupoRepository) ExistsPendienteByItem(ctx context.Context, itemID string) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.TransferenciaCupo{}).
+		Where("estado = ? AND cupo_derecho_item_id = ?", models.EstadoTransferenciaPendiente, itemID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ExistsOtraPendienteByItem indica si el cupo tiene una solicitud pendiente distinta de exceptID.
+func (r *TransferenciaCupoRepository) ExistsOtraPendienteByItem(ctx context.Context, itemID, exceptID string) (bool, error) {
+	var count int64
+	q := r.db.WithContext(ctx).Model(&models.TransferenciaCupo{}).
+		Where("estado = ? AND cupo_derecho_item_id = ?", models.EstadoTransferenciaPendiente, itemID)
+	if exceptID != "" {
+		q = q.Where("id <> ?", exceptID)
+	}
+	err := q.Count(&count).Error
+	return count > 0, err
+}
+
+func (r *TransferenciaCupoRepository) FindByItemID(ctx context.Context, itemID string) ([]models.TransferenciaCupo, error) {
+	var list []models.TransferenciaCupo
+	err := r.preloadAll(r.db.WithContext(ctx)).
+		Where("cupo_derecho_item_id = ?", itemID).
+		Order("fecha_solicitud ASC").
+		Find(&list).Error
+	return list, err
+}
+
+// FindByPeriodo retorna el historial de movimientos de los cupos de un periodo.
+func (r *TransferenciaCupoRepository) FindByPeriodo(ctx context.Context, gestion, mes int) ([]models.TransferenciaCupo, error) {
+	var list []models.TransferenciaCupo
+	err := r.preloadAll(r.db.WithContext(ctx)).
+		Joins("JOIN cupo_derecho_items ON cupo_derecho_items.id = transferencias_cupo.cupo_derecho_item_id").
+		Where("cupo_derecho_items.gestion = ? AND cupo_derecho_items.mes = ?", gestion, mes).
+		Order("transferencias_cupo.fecha_solicitud ASC").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *TransferenciaCupoRepository) FindRecientes(ctx context.Context, limit int) ([]models.TransferenciaCupo, error) {
+	var list []models.TransferenciaCupo
+	err := r.preloadAll(r.db.WithContext(ctx)).
+		Where("estado <> ?", models.EstadoTransferenciaPendiente).
+		Order("fecha_solicitud DESC").
+		Limit(limit).
+		Find(&list).Error
+	return list, err
+}
+
+func (r *TransferenciaCupoRepository) FindByLicencia(ctx context.Context, licenciaID string) ([]models.TransferenciaCupo, error) {
+	var list []models.TransferenciaCupo
+	err := r.db.WithContext(ctx).
+		Where("licencia_id = ? AND estado = ?", licenciaID, models.EstadoTransferenciaAprobada).
+		Order("fecha_solicitud ASC").
+		Find(&list).Error
+	return list, err
+}
@@ -281,6 +281,18 @@ func (r *UsuarioRepository) FindAllSenators(ctx context.Context) ([]models.Usuar
 	return usuarios, err
 }
 
+// FindActiveTitulares devuelve los senadores titulares en ejercicio: excluye los dados de baja
+// por la sincronización (eliminados) y las cuentas bloqueadas.
+func (r *UsuarioRepository) FindActiveTitulares(ctx context.Context) ([]models.Usuario, error) {
+	var usuarios []models.Usuario
+	err := r.db.WithContext(ctx).
+		Preload("Origen").Preload("Departamento").
+		Where("tipo = ? AND is_blocked = ?", models.TipoSenadorTitular, false).
+		Order("lastname ASC, firstname ASC").
+		Find(&usuarios).Error
+	return usuarios, err
+}
+
 func (r *UsuarioRepository) FindAdminsAndResponsables(ctx context.Context) ([]models.Usuario, error) {
 	var usuarios []models.Usuario
 	err := r.db.WithContext(ctx).Where("rol_codigo IN ?", []string{models.RolAdmin, models.RolResponsable}).
@@ -0,0 +1,104 @@
+package repositories
+
+import (
+	"context"
+	"sistema-pasajes/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ViaticoRepository struct {
+	db *gorm.DB
+}
+
+func NewViaticoRepository(db *gorm.DB) *ViaticoRepository {
+	return &ViaticoRepository{db: db}
+}
+
+func (r *ViaticoRepository) WithContext(ctx context.Context) *ViaticoRepository {
+	return &ViaticoRepository{db: r.db.WithContext(ctx)}
+}
+
+func (r *ViaticoRepository) FindZonas(ctx context.Context) ([]models.ZonaViatico, error) {
+	var list []models.ZonaViatico
+	err := r.db.WithContext(ctx).Order("nombre").Find(&list).Error
+	return list, err
+}
+
+func (r *ViaticoRepository) CreateZona(ctx context.Context, zona *models.ZonaViatico) error {
+	return r.db.WithContext(ctx).Create(zona).Error
+}
+
+func (r *ViaticoRepository) CountCategoriasByZona(ctx context.Context, zonaID string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.CategoriaViatico{}).Where("zona_viatico_id = ?", zonaID).Count(&count).Error
+	return count, err
+}
+
+func (r *ViaticoRepository) DeleteZona(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.ZonaViatico{}, "id = ?", id).Error
+}
+
+func (r *ViaticoRepository) FindCategorias(ctx context.Context) ([]models.CategoriaViatico, error) {
+	var list []models.CategoriaViatico
+	err := r.db.WithContext(ctx).Preload("ZonaViatico").Preload("Ambito").
+		Joins("LEFT JOIN zonas_viatico ON zonas_viatico.id = categorias_viatico.zona_viatico_id").
+		Order("categorias_viatico.ambito_codigo, zonas_viatico.nombre, categorias_viatico.codigo").
+		Find(&list).Error
+	return list, err
+}
+
+func (r *ViaticoRepository) FindCategoriaByID(ctx context.Context, id string) (*models.CategoriaViatico, error) {
+	var cat models.CategoriaViatico
+	err := r.db.WithContext(ctx).Preload("ZonaViatico").First(&cat, "id = ?", id).Error
+	return &cat, err
+}
+
+func (r *ViaticoRepository) FindCategoria(ctx context.Context, codigo int, zonaID, ambito string) (*models.CategoriaViatico, error) {
+	var cat models.CategoriaViatico
+	err := r.db.WithContext(ctx).Preload("ZonaViatico").
+		Where("codigo = ? AND zona_viatico_id = ? AND ambito_codigo = ?", codigo, zonaID, ambito).
+		First(&cat).Error
+	return &cat, err
+}
+
+func (r *ViaticoRepository) CreateCategoria(ctx context.Context, cat *models.CategoriaViatico) error {
+	return r.db.WithContext(ctx).Omit("ZonaViatico", "Ambito").Create(cat).Error
+}
+
+func (r *ViaticoRepository) UpdateCategoria(ctx context.Context, cat *models.CategoriaViatico) error {
+	return r.db.WithContext(ctx).Model(cat).Select("Nombre", "Monto", "Moneda").Updates(cat).Error
+}
+
+func (r *ViaticoRepository) DeleteCategoria(ctx context.Context, id string) error {
+	return r.db.WithContext(ctx).Unscoped().Delete(&models.CategoriaViatico{}, "id = ?", id).Error
+}
+
+func (r *ViaticoRepository) FindByDescargoID(ctx context.Context, descargoID string) (*models.Viatico, error) {
+	var v models.Viatico
+	err := r.db.WithContext(ctx).First(&v, "descargo_id = ?", descargoID).Error
+	return &v, err
+}
+
+func (r *ViaticoRepository) Create(ctx context.Context, v *models.Viatico) error {
+	return r.db.WithContext(ctx).Omit("Descargo", "Usuario").Create(v).Error
+}
+
+func (r *ViaticoRepository) Update(ctx context.Context, v *models.Viatico) error {
+	return r.db.WithContext(ctx).Model(v).Select("*").Omit("Descargo", "Usuario", "CreatedAt", "CreatedBy").Updates(v).Error
+}
+
+// FindByRetorno lista las liquidaciones de comisiones que retornaron en el rango, para la planilla.
+func (r *ViaticoRepository) FindByRetorno(ctx context.Context, desde, hasta time.Time) ([]models.Viatico, error) {
+	var list []models.Viatico
+	err := r.db.WithContext(ctx).
+		Preload("Usuario").
+		Preload("Usuario.Cargo").
+		Preload("Descargo").
+		Preload("Descargo.Solicitud").
+		Where("fecha_retorno >= ? AND fecha_retorno < ?", desde, hasta.AddDate(0, 0, 1)).
+		Order("fecha_retorno, created_at").
+		Find(&list).Error
+	return list, err
+}
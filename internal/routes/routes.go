@@ -35,12 +35,25 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 	landingCtrl := container.LandingController
 	openTicketCtrl := container.OpenTicketController
 	destinoCtrl := container.DestinoController
+	politicaCupoCtrl := container.PoliticaCupoController
+	conciliacionCupoCtrl := container.ConciliacionCupoController
+	licenciaCtrl := container.LicenciaController
+	calendarioCtrl := container.CalendarioController
+	tipoCambioCtrl := container.TipoCambioController
+	reembolsoCtrl := container.ReembolsoController
+	feriadoCtrl := container.FeriadoController
+	reglaDescargoCtrl := container.ReglaDescargoController
+	viaticoCtrl := container.ViaticoController
+	anticipoCtrl := container.AnticipoController
+	firmaCtrl := container.FirmaController
+	adjuntoCtrl := container.AdjuntoController
 
 	r.GET("/auth/login", authCtrl.ShowLogin)
 	r.POST("/auth/login", middleware.RateLimitMiddleware(loginLimiter), authCtrl.Login)
 	r.GET("/auth/logout", authCtrl.Logout)
 	r.GET("/acerca-de", landingCtrl.ShowAbout)
 	r.GET("/raw-file", descargoDerechoCtrl.RawFile)
+	r.GET("/calendario/:token", calendarioCtrl.Feed)
 
 	protected := r.Group("/")
 	protected.Use(middleware.AuthRequired())
@@ -51,6 +64,13 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 		protected.GET("/dashboard", dashboardCtrl.Index)
 
 		protected.GET("/perfil", perfilCtrl.Show)
+		protected.POST("/perfil/calendario/regenerar", calendarioCtrl.Regenerar)
+		protected.POST("/perfil/certificado", firmaCtrl.RegistrarCertificado)
+		protected.POST("/perfil/certificado/eliminar", firmaCtrl.EliminarCertificado)
+		protected.GET("/firmas/verificar", firmaCtrl.Verificar)
+		protected.POST("/firmas/verificar", firmaCtrl.VerificarDocumento)
+		protected.GET("/adjuntos/:id/historial", adjuntoCtrl.Historial)
+		protected.POST("/adjuntos/:id/restaurar", adjuntoCtrl.Restaurar)
 		protected.GET("/perfil/open-tickets", openTicketCtrl.ListByUser)
 		protected.GET("/pasajes/open-tickets", openTicketCtrl.List)
 		protected.GET("/pasajes/open-tickets/:id/modal-programar", openTicketCtrl.GetProgramarModal)
@@ -83,6 +103,7 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 		protected.GET("/solicitudes/derecho/:id/detalle", solicitudDerechoCtrl.Show)
 		protected.GET("/solicitudes/derecho/:id/modal-editar", solicitudDerechoCtrl.GetEditModal)
 		protected.GET("/solicitudes/derecho/:id/print", solicitudDerechoCtrl.Print)
+		protected.POST("/solicitudes/derecho/:id/firmar", solicitudDerechoCtrl.Firmar)
 
 		// Descargos Derecho
 		protected.GET("/descargos/derecho/nuevo/:id", descargoDerechoCtrl.Store)
@@ -92,10 +113,12 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 		protected.POST("/descargos/derecho/:id/completar-utilizacion", descargoDerechoCtrl.UpdateUtilizacion)
 		protected.POST("/descargos/derecho/:id/actualizar", descargoDerechoCtrl.Update)
 		protected.GET("/descargos/derecho/:id/imprimir", descargoDerechoCtrl.Print)
+		protected.POST("/descargos/derecho/:id/firmar", descargoDerechoCtrl.Firmar)
 		protected.GET("/descargos/derecho/:id/imprimir-ot", descargoDerechoCtrl.PrintOpenTicket)
 		protected.GET("/descargos/derecho/:id/previsualizar", descargoDerechoCtrl.Preview)
 		protected.GET("/descargos/derecho/:id/previsualizar-ot", descargoDerechoCtrl.PreviewOT)
 		protected.POST("/descargos/derecho/:id/aprobar", descargoDerechoCtrl.Approve)
+		protected.POST("/descargos/derecho/:id/verificar-pases", descargoDerechoCtrl.VerificarPases)
 		protected.POST("/descargos/derecho/:id/rechazar", descargoDerechoCtrl.Reject)
 		protected.POST("/descargos/derecho/:id/enviar", descargoDerechoCtrl.Submit)
 		protected.POST("/descargos/derecho/:id/revertir-aprobacion", descargoDerechoCtrl.RevertApproval)
@@ -117,6 +140,7 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 		protected.GET("/solicitudes/oficial/:id/detalle", solicitudOficialCtrl.Show)
 		protected.GET("/solicitudes/oficial/:id/modal-editar", solicitudOficialCtrl.GetEditModal)
 		protected.GET("/solicitudes/oficial/:id/print", solicitudOficialCtrl.Print)
+		protected.POST("/solicitudes/oficial/:id/firmar", solicitudOficialCtrl.Firmar)
 
 		// Descargos Oficial
 		protected.GET("/descargos/oficial/nuevo/:id", descargoOficialCtrl.Store)
@@ -124,11 +148,14 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 		protected.GET("/descargos/oficial/:id/editar", descargoOficialCtrl.Edit)
 		protected.POST("/descargos/oficial/:id/actualizar", descargoOficialCtrl.Update)
 		protected.GET("/descargos/oficial/:id/imprimir", descargoOficialCtrl.Print)
+		protected.POST("/descargos/oficial/:id/firmar", descargoOficialCtrl.Firmar)
 		protected.GET("/descargos/oficial/:id/previsualizar", descargoOficialCtrl.Preview)
 		protected.POST("/descargos/oficial/:id/aprobar", descargoOficialCtrl.Approve)
+		protected.POST("/descargos/oficial/:id/verificar-pases", descargoOficialCtrl.VerificarPases)
 		protected.POST("/descargos/oficial/:id/rechazar", descargoOficialCtrl.Reject)
 		protected.POST("/descargos/oficial/:id/enviar", descargoOficialCtrl.Submit)
 		protected.POST("/descargos/oficial/:id/revertir-aprobacion", descargoOficialCtrl.RevertApproval)
+		protected.POST("/descargos/oficial/:id/viatico", viaticoCtrl.Calcular)
 		protected.GET("/descargos/oficial/nueva-fila", descargoOficialCtrl.NuevaFila)
 
 		protected.POST("/solicitudes/oficial/:id/actualizar", solicitudOficialCtrl.Update)
@@ -140,15 +167,20 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 		protected.POST("/solicitudes/oficial/:id/items/:item_id/aprobar", solicitudOficialCtrl.ApproveItem)
 		protected.POST("/solicitudes/oficial/:id/items/:item_id/revertir-aprobacion", solicitudOficialCtrl.RevertApprovalItem)
 		protected.POST("/solicitudes/oficial/:id/items/:item_id/rechazar", solicitudOficialCtrl.RejectItem)
+		protected.POST("/solicitudes/oficial/:id/anticipo", anticipoCtrl.Solicitar)
 
 		protected.POST("/solicitudes/:id/pasajes", pasajeCtrl.Store)
 		protected.GET("/solicitudes/:id/pasajes/nuevo", pasajeCtrl.GetCreateModal)
 		protected.POST("/pasajes/update-status", pasajeCtrl.UpdateStatus)
+		protected.POST("/pasajes/eticket/extraer", pasajeCtrl.ExtraerETicket)
+		protected.POST("/pasajes/:id/autorizar-tarifa", pasajeCtrl.AutorizarTarifa)
 		protected.GET("/pasajes/:id/preview", pasajeCtrl.Preview)
 		protected.POST("/pasajes/devolver", pasajeCtrl.Devolver)
+		protected.POST("/pasajes/reemitir", pasajeCtrl.Reemitir)
 		protected.POST("/pasajes/update", pasajeCtrl.Update)
 		protected.GET("/pasajes/:id/editar", pasajeCtrl.GetEditModal)
 		protected.GET("/pasajes/:id/devolver", pasajeCtrl.GetDevolverModal)
+		protected.GET("/pasajes/:id/reemitir", pasajeCtrl.GetReemitirModal)
 		protected.GET("/pasajes/:id/modal-usado", pasajeCtrl.GetUsadoModal)
 		protected.GET("/pasajes/:id/modal-servicio", pasajeCtrl.GetServicioModal)
 		protected.POST("/pasajes/:id/servicio", pasajeCtrl.UpdateServicio)
@@ -199,6 +231,10 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 			adminOnly.GET("/admin/reports/morosidad-excel", container.ReportController.DownloadMorosidadExcel)
 			adminOnly.GET("/admin/reports/cupos-excel", container.ReportController.DownloadUsoCuposExcel)
 			adminOnly.GET("/admin/reports/aerolineas-excel", container.ReportController.DownloadEstadisticasAerolineaExcel)
+			adminOnly.GET("/admin/reports/sobreprecios-excel", container.ReportController.DownloadSobrepreciosExcel)
+			adminOnly.GET("/admin/reports/colisiones-billete-excel", container.ReportController.DownloadColisionesBilleteExcel)
+			adminOnly.GET("/admin/reports/agencias", container.ReportController.Agencias)
+			adminOnly.GET("/admin/reports/agencias-excel", container.ReportController.DownloadAgenciasExcel)
 
 			// Regularización de fechas
 			adminOnly.GET("/solicitudes/:id/regularizacion-modal", solicitudCtrl.GetRegularizacionModal)
@@ -221,8 +257,48 @@ func SetupRoutes(r *gin.Engine, container *app.Container, loginLimiter *middlewa
 			protected.POST("/admin/cupos/asignar", cupoCtrl.AsignarCupo)
 			protected.POST("/admin/cupos/transferir", cupoCtrl.Transferir)
 			protected.POST("/admin/cupos/derechos/:id/revertir-transferencia", cupoCtrl.RevertirTransferencia)
+			protected.POST("/cupos/transferencias/solicitar", cupoCtrl.SolicitarTransferencia)
+			sysAdmin.GET("/admin/cupos/transferencias", cupoCtrl.Transferencias)
+			sysAdmin.POST("/admin/cupos/transferencias/:id/aprobar", cupoCtrl.AprobarTransferencia)
+			sysAdmin.POST("/admin/cupos/transferencias/:id/rechazar", cupoCtrl.RechazarTransferencia)
 
 			sysAdmin.POST("/admin/cupos/reset", cupoCtrl.Reset)
+			sysAdmin.GET("/admin/cupos/politicas", politicaCupoCtrl.Index)
+			sysAdmin.POST("/admin/cupos/politicas", politicaCupoCtrl.Store)
+			sysAdmin.POST("/admin/cupos/politicas/:id/estado", politicaCupoCtrl.Toggle)
+			sysAdmin.GET("/admin/cupos/conciliacion", conciliacionCupoCtrl.Index)
+			sysAdmin.POST("/admin/cupos/conciliacion/corregir", conciliacionCupoCtrl.Corregir)
+			sysAdmin.GET("/admin/tipos-cambio", tipoCambioCtrl.Index)
+			sysAdmin.POST("/admin/tipos-cambio", tipoCambioCtrl.Store)
+			sysAdmin.POST("/admin/tipos-cambio/:id/eliminar", tipoCambioCtrl.Delete)
+			sysAdmin.GET("/admin/feriados", feriadoCtrl.Index)
+			sysAdmin.POST("/admin/feriados", feriadoCtrl.Store)
+			sysAdmin.POST("/admin/feriados/:id/toggle", feriadoCtrl.Toggle)
+			sysAdmin.POST("/admin/feriados/:id/eliminar", feriadoCtrl.Delete)
+
+			sysAdmin.GET("/admin/reglas-descargo", reglaDescargoCtrl.Index)
+			sysAdmin.POST("/admin/reglas-descargo", reglaDescargoCtrl.Store)
+			sysAdmin.POST("/admin/reglas-descargo/:id", reglaDescargoCtrl.Update)
+			sysAdmin.POST("/admin/reglas-descargo/:id/toggle", reglaDescargoCtrl.Toggle)
+			sysAdmin.POST("/admin/reglas-descargo/:id/eliminar", reglaDescargoCtrl.Delete)
+
+			sysAdmin.GET("/admin/viaticos/categorias", viaticoCtrl.Categorias)
+			sysAdmin.POST("/admin/viaticos/categorias", viaticoCtrl.StoreCategoria)
+			sysAdmin.POST("/admin/viaticos/categorias/:id/eliminar", viaticoCtrl.DeleteCategoria)
+			sysAdmin.POST("/admin/viaticos/zonas", viaticoCtrl.StoreZona)
+			sysAdmin.POST("/admin/viaticos/zonas/:id/eliminar", viaticoCtrl.DeleteZona)
+			sysAdmin.GET("/admin/viaticos/planilla", viaticoCtrl.Planilla)
+			sysAdmin.GET("/admin/viaticos/planilla/excel", viaticoCtrl.DownloadPlanillaExcel)
+			sysAdmin.GET("/admin/anticipos", anticipoCtrl.Index)
+			sysAdmin.POST("/admin/anticipos/:id/aprobar", anticipoCtrl.Aprobar)
+			sysAdmin.POST("/admin/anticipos/:id/rechazar", anticipoCtrl.Rechazar)
+			sysAdmin.GET("/admin/reembolsos", reembolsoCtrl.Index)
+			sysAdmin.POST("/admin/reembolsos/importar", reembolsoCtrl.Importar)
+			sysAdmin.POST("/admin/reembolsos/conciliar", reembolsoCtrl.Conciliar)
+			sysAdmin.POST("/admin/reembolsos/vincular", reembolsoCtrl.Vincular)
+			sysAdmin.GET("/admin/licencias", licenciaCtrl.Index)
+			sysAdmin.POST("/admin/licencias", licenciaCtrl.Store)
+			sysAdmin.POST("/admin/licencias/:id/anular", licenciaCtrl.Anular)
 
 			sysAdmin.GET("/admin/aerolineas", aerolineaCtrl.Index)
 			sysAdmin.GET("/admin/aerolineas/nueva", aerolineaCtrl.New)
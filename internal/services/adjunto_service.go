@@ -0,0 +1,164 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sistema-pasajes/internal/appcontext"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+
+	"gorm.io/gorm"
+)
+
+// AdjuntoService lleva el historial de versiones de los archivos de respaldo: cada reemplazo
+// agrega una versión y cualquier versión anterior puede volver a ser la vigente.
+type AdjuntoService struct {
+	repo         *repositories.AdjuntoRepository
+	auditService *AuditService
+}
+
+func NewAdjuntoService(repo *repositories.AdjuntoRepository, auditService *AuditService) *AdjuntoService {
+	return &AdjuntoService{repo: repo, auditService: auditService}
+}
+
+// Versionar registra archivo como versión vigente del adjunto adjuntoID del campo indicado y
+// devuelve el ID que debe guardar la fila. Sin adjunto previo crea uno; si el archivo no cambió
+// no hace nada, y si viene vacío conserva el historial existente.
+func (s *AdjuntoService) Versionar(ctx context.Context, campo string, adjuntoID *string, archivo string) (*string, error) {
+	if archivo == "" {
+		return adjuntoID, nil
+	}
+
+	if adjuntoID != nil {
+		a, err := s.repo.FindByID(ctx, *adjuntoID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if a != nil {
+			if a.Archivo == archivo {
+				return adjuntoID, nil
+			}
+			v := nuevaVersionAdjunto(ctx, archivo, true)
+			if err := s.repo.AgregarVersion(ctx, a, v); err != nil {
+				return nil, fmt.Errorf("error registrando la nueva versión del adjunto: %w", err)
+			}
+			return adjuntoID, nil
+		}
+	}
+
+	a := &models.Adjunto{Campo: campo, Archivo: archivo, VersionActual: 1}
+	v := nuevaVersionAdjunto(ctx, archivo, false)
+	v.Version = 1
+	if err := s.repo.Create(ctx, a, v); err != nil {
+		return nil, fmt.Errorf("error registrando el adjunto: %w", err)
+	}
+	return &a.ID, nil
+}
+
+// VersionarPasaje registra las versiones de los archivos del pasaje antes de guardarlo.
+func (s *AdjuntoService) VersionarPasaje(ctx context.Context, p *models.Pasaje) error {
+	var err error
+	if p.ArchivoAdjuntoID, err = s.Versionar(ctx, models.AdjuntoPasajeBoleto, p.ArchivoAdjuntoID, p.Archivo); err != nil {
+		return err
+	}
+	if p.PaseAbordoAdjuntoID, err = s.Versionar(ctx, models.AdjuntoPasajePase, p.PaseAbordoAdjuntoID, p.ArchivoPaseAbordo); err != nil {
+		return err
+	}
+	if p.ServicioAdjuntoID, err = s.Versionar(ctx, models.AdjuntoPasajeServicio, p.ServicioAdjuntoID, p.ServicioArchivo); err != nil {
+		return err
+	}
+	p.ComprobanteAdjuntoID, err = s.Versionar(ctx, models.AdjuntoPasajeBoleta, p.ComprobanteAdjuntoID, p.ArchivoComprobante)
+	return err
+}
+
+// VersionarTramos registra las versiones de los pases de abordar de los tramos antes de guardarlos.
+// Los tramos que ya existían conservan el adjunto que tenían en anteriores.
+func (s *AdjuntoService) VersionarTramos(ctx context.Context, tramos []models.DescargoTramo, anteriores map[string]models.DescargoTramo) error {
+	for i := range tramos {
+		t := &tramos[i]
+		if original, ok := anteriores[t.ID]; ok && t.ID != "" {
+			t.PaseAbordoAdjuntoID = original.PaseAbordoAdjuntoID
+		}
+		id, err := s.Versionar(ctx, models.AdjuntoPaseTramo, t.PaseAbordoAdjuntoID, t.ArchivoPaseAbordo)
+		if err != nil {
+			return err
+		}
+		t.PaseAbordoAdjuntoID = id
+	}
+	return nil
+}
+
+// nuevaVersionAdjunto arma la versión con el hash y tamaño del archivo guardado. El nombre
+// original y quien lo subió se conocen si el archivo llegó en esta petición; un reemplazo sin
+// esos datos se atribuye al usuario que guardó el cambio.
+func nuevaVersionAdjunto(ctx context.Context, archivo string, reemplazo bool) *models.AdjuntoVersion {
+	v := &models.AdjuntoVersion{Archivo: archivo}
+	nombre, subido := appcontext.GetUploadedFileName(ctx, archivo)
+	if subido {
+		v.NombreOriginal = nombre
+	} else {
+		v.NombreOriginal = filepath.Base(archivo)
+	}
+	if subido || reemplazo {
+		v.SubidoPorID = appcontext.GetUserIDFromContext(ctx)
+	}
+
+	f, err := os.Open(archivo)
+	if err != nil {
+		slog.Warn("No se pudo leer el adjunto para calcular su huella", "archivo", archivo, "error", err)
+		return v
+	}
+	defer f.Close()
+	h := sha256.New()
+	if n, err := io.Copy(h, f); err == nil {
+		v.Hash = hex.EncodeToString(h.Sum(nil))
+		v.Tamano = n
+	}
+	return v
+}
+
+func (s *AdjuntoService) GetHistorial(ctx context.Context, id string) (*models.Adjunto, error) {
+	return s.repo.FindConVersiones(ctx, id)
+}
+
+// Restaurar vuelve a poner vigente una versión anterior agregándola como versión nueva, de modo
+// que el historial no se reescribe.
+func (s *AdjuntoService) Restaurar(ctx context.Context, id string, version int) (*models.Adjunto, error) {
+	a, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if version == a.VersionActual {
+		return nil, errors.New("esa versión ya es la vigente")
+	}
+	origen, err := s.repo.FindVersion(ctx, id, version)
+	if err != nil {
+		return nil, fmt.Errorf("no existe la versión %d del adjunto", version)
+	}
+	if _, err := os.Stat(origen.Archivo); err != nil {
+		return nil, fmt.Errorf("el archivo de la versión %d ya no está disponible", version)
+	}
+
+	anterior := a.Archivo
+	v := &models.AdjuntoVersion{
+		Archivo:        origen.Archivo,
+		NombreOriginal: origen.NombreOriginal,
+		Hash:           origen.Hash,
+		Tamano:         origen.Tamano,
+		SubidoPorID:    appcontext.GetUserIDFromContext(ctx),
+		RestauradaDe:   origen.Version,
+	}
+	if err := s.repo.Restaurar(ctx, a, v); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, "RESTAURAR_ADJUNTO", "adjunto", a.ID, anterior, fmt.Sprintf("v%d (%s)", origen.Version, origen.Archivo), "", "")
+	return a, nil
+}
@@ -7,6 +7,9 @@ import (
 	"sistema-pasajes/internal/models"
 	"sistema-pasajes/internal/repositories"
 	"sistema-pasajes/internal/utils"
+	"slices"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -14,11 +17,20 @@ import (
 )
 
 type AlertaService struct {
-	solicitudRepo *repositories.SolicitudRepository
-	descargoRepo  *repositories.DescargoRepository
-	emailService  *EmailService
+	solicitudRepo    *repositories.SolicitudRepository
+	descargoRepo     *repositories.DescargoRepository
+	recordatorioRepo *repositories.RecordatorioDescargoRepository
+	userRepo         *repositories.UsuarioRepository
+	configService    *ConfiguracionService
+	emailService     *EmailService
 }
 
+const (
+	recordatorioEtapasDefault             = "-5,0,3,10"
+	recordatorioEscalarEncargadoDefault   = 3
+	recordatorioEscalarResponsableDefault = 10
+)
+
 type AlertaDescargoJob struct {
 	Service *AlertaService
 }
@@ -34,16 +46,74 @@ func (j *AlertaDescargoJob) Run(ctx context.Context) error {
 func NewAlertaService(
 	solicitudRepo *repositories.SolicitudRepository,
 	descargoRepo *repositories.DescargoRepository,
+	recordatorioRepo *repositories.RecordatorioDescargoRepository,
+	userRepo *repositories.UsuarioRepository,
+	configService *ConfiguracionService,
 	emailService *EmailService,
 ) *AlertaService {
 	return &AlertaService{
-		solicitudRepo: solicitudRepo,
-		descargoRepo:  descargoRepo,
-		emailService:  emailService,
+		solicitudRepo:    solicitudRepo,
+		descargoRepo:     descargoRepo,
+		recordatorioRepo: recordatorioRepo,
+		userRepo:         userRepo,
+		configService:    configService,
+		emailService:     emailService,
+	}
+}
+
+// GetEtapasRecordatorio retorna las etapas configuradas (DESCARGO_RECORDATORIO_ETAPAS), en días
+// hábiles respecto a la fecha límite: negativo antes del vencimiento, positivo en mora.
+func (s *AlertaService) GetEtapasRecordatorio(ctx context.Context) []int {
+	etapas := parseEtapas(s.configService.GetValue(ctx, "DESCARGO_RECORDATORIO_ETAPAS"))
+	if len(etapas) == 0 {
+		etapas = parseEtapas(recordatorioEtapasDefault)
+	}
+	return etapas
+}
+
+func parseEtapas(valor string) []int {
+	var etapas []int
+	for _, p := range strings.Split(valor, ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(p)); err == nil {
+			etapas = append(etapas, n)
+		}
+	}
+	sort.Ints(etapas)
+	return slices.Compact(etapas)
+}
+
+func (s *AlertaService) getDiasConfig(ctx context.Context, clave string, def int) int {
+	if dias, err := strconv.Atoi(strings.TrimSpace(s.configService.GetValue(ctx, clave))); err == nil && dias >= 0 {
+		return dias
+	}
+	return def
+}
+
+// nivelEscalamiento decide a quién se dirige la etapa según los días de mora configurados.
+func (s *AlertaService) nivelEscalamiento(ctx context.Context, etapa int) string {
+	switch {
+	case etapa >= s.getDiasConfig(ctx, "DESCARGO_ESCALAR_RESPONSABLE_DIAS", recordatorioEscalarResponsableDefault):
+		return models.NivelRecordatorioResponsable
+	case etapa >= s.getDiasConfig(ctx, "DESCARGO_ESCALAR_ENCARGADO_DIAS", recordatorioEscalarEncargadoDefault):
+		return models.NivelRecordatorioEncargado
+	default:
+		return models.NivelRecordatorioBeneficiario
+	}
+}
+
+// etapaAlcanzada retorna la última etapa alcanzada según los días restantes. Las etapas anteriores
+// que no se enviaron (p. ej. por un viaje registrado tarde) quedan superadas por esta.
+func etapaAlcanzada(etapas []int, diasRestantes int) (int, bool) {
+	for i := len(etapas) - 1; i >= 0; i-- {
+		if etapas[i] <= -diasRestantes {
+			return etapas[i], true
+		}
 	}
+	return 0, false
 }
 
-// ProcesarAlertasDescargo revisa las solicitudes que requieren descargo y envía alertas si están próximas al límite.
+// ProcesarAlertasDescargo revisa las solicitudes que requieren descargo y envía el recordatorio de la
+// etapa alcanzada, si aún no se envió, escalando al encargado y al responsable según la mora.
 func (s *AlertaService) ProcesarAlertasDescargo(ctx context.Context) error {
 	log.Println("[AlertaService] Iniciando procesamiento de alertas de descargo...")
 
@@ -60,18 +130,20 @@ func (s *AlertaService) ProcesarAlertasDescargo(ctx context.Context) error {
 	}
 
 	hoy := time.Now().In(loc)
+	etapas := s.GetEtapasRecordatorio(ctx)
 	alertasEnviadas := 0
 
-	for _, sol := range solicitudes {
-		if sol.Descargo != nil {
-			if sol.Descargo.Estado == models.EstadoDescargoEnRevision ||
-				sol.Descargo.Estado == models.EstadoDescargoOpenTicket ||
-				sol.Descargo.Estado == models.EstadoDescargoFinalizado {
-				continue
+	var responsables []models.Usuario
+	if usuarios, err := s.userRepo.FindAdminsAndResponsables(ctx); err == nil {
+		for _, u := range usuarios {
+			if u.IsResponsable() {
+				responsables = append(responsables, u)
 			}
 		}
+	}
 
-		if sol.HasCompleteDescargo() {
+	for _, sol := range solicitudes {
+		if !sol.TieneDescargoPendiente() {
 			continue
 		}
 
@@ -81,7 +153,7 @@ func (s *AlertaService) ProcesarAlertasDescargo(ctx context.Context) error {
 		}
 		fechaFin := *maxVuelo
 
-		fechaLimite := utils.CalcularFechaLimiteDescargo(fechaFin)
+		fechaLimite := utils.CalcularFechaLimiteDescargo(fechaFin, sol.Usuario.GetDepartamentoCodigo())
 
 		y, m, d := fechaFin.Date()
 		fechaFinLocal := time.Date(y, m, d, 0, 0, 0, 0, loc)
@@ -90,12 +162,31 @@ func (s *AlertaService) ProcesarAlertasDescargo(ctx context.Context) error {
 			continue
 		}
 
-		// Enviar alerta
-		if err := s.enviarAlertaDescargoEmail(sol, fechaLimite); err != nil {
+		dias := sol.GetDiasRestantesDescargo()
+		etapa, ok := etapaAlcanzada(etapas, dias)
+		if !ok || sol.EtapaRecordatorioAtendida(etapa) {
+			continue
+		}
+
+		rec := &models.RecordatorioDescargo{
+			SolicitudID:   sol.ID,
+			UsuarioID:     sol.UsuarioID,
+			Etapa:         etapa,
+			Nivel:         s.nivelEscalamiento(ctx, etapa),
+			FechaLimite:   fechaLimite,
+			DiasRestantes: dias,
+			Estado:        models.EstadoRecordatorioEnviado,
+		}
+		if err := s.enviarAlertaDescargoEmail(sol, rec, responsables); err != nil {
 			log.Printf("[AlertaService] Error enviando email para solicitud %s: %v", sol.Codigo, err)
+			rec.Estado = models.EstadoRecordatorioFallido
+			rec.Error = err.Error()
 		} else {
 			alertasEnviadas++
 		}
+		if err := s.recordatorioRepo.Create(ctx, rec); err != nil {
+			log.Printf("[AlertaService] Error registrando recordatorio de %s: %v", sol.Codigo, err)
+		}
 	}
 
 	log.Printf("[AlertaService] Procesamiento finalizado. Alertas enviadas: %d", alertasEnviadas)
@@ -114,25 +205,46 @@ func (s *AlertaService) obtenerFechaFinViaje(sol models.Solicitud) time.Time {
 	return lastDate
 }
 
-func (s *AlertaService) enviarAlertaDescargoEmail(sol models.Solicitud, fechaLimite time.Time) error {
+func (s *AlertaService) enviarAlertaDescargoEmail(sol models.Solicitud, rec *models.RecordatorioDescargo, responsables []models.Usuario) error {
 	beneficiario := sol.Usuario
-	if beneficiario.Email == "" {
-		return fmt.Errorf("el beneficiario %s no tiene correo electrónico", beneficiario.GetNombreCompleto())
+	var destinatarios, copias []string
+	if beneficiario.Email != "" {
+		destinatarios = append(destinatarios, beneficiario.Email)
 	}
 
-	destinatarios := []string{beneficiario.Email}
-	var copias []string
-
-	if beneficiario.Encargado != nil && beneficiario.Encargado.Email != "" {
+	if rec.Nivel != models.NivelRecordatorioBeneficiario && beneficiario.Encargado != nil && beneficiario.Encargado.Email != "" {
 		copias = append(copias, beneficiario.Encargado.Email)
 	}
+	if rec.Nivel == models.NivelRecordatorioResponsable {
+		for _, r := range responsables {
+			if r.Email != "" {
+				copias = append(copias, r.Email)
+			}
+		}
+	}
+	if len(destinatarios) == 0 {
+		// Sin correo del beneficiario, el escalamiento llega igual a quienes iban en copia.
+		destinatarios, copias = copias, nil
+	}
+	if len(destinatarios) == 0 {
+		return fmt.Errorf("el beneficiario %s no tiene correo electrónico", beneficiario.GetNombreCompleto())
+	}
+	rec.Destinatarios = strings.Join(destinatarios, ", ")
+	rec.Copias = strings.Join(copias, ", ")
 
 	var ocultos []string
 
-	subject := fmt.Sprintf("[ALERTA] Pendiente de Descargo de Pasajes - %s", sol.Codigo)
+	prefijo := "[RECORDATORIO]"
+	switch {
+	case rec.Nivel != models.NivelRecordatorioBeneficiario:
+		prefijo = "[ESCALAMIENTO]"
+	case rec.Etapa >= 0:
+		prefijo = "[ALERTA]"
+	}
+	subject := fmt.Sprintf("%s Pendiente de Descargo de Pasajes - %s", prefijo, sol.Codigo)
 
-	fechaLimiteStr := fechaLimite.Format("02/01/2006")
-	days := sol.GetDiasRestantesDescargo()
+	fechaLimiteStr := rec.FechaLimite.Format("02/01/2006")
+	days := rec.DiasRestantes
 	statusText := ""
 	statusBadgeColor := "#EAB308" // Yellow/Orange default
 
@@ -166,7 +278,7 @@ func (s *AlertaService) enviarAlertaDescargoEmail(sol models.Solicitud, fechaLim
 					<p style="margin: 0; font-weight: bold;">Estado del Plazo: %s</p>
 					<p style="margin: 5px 0 0 0; font-weight: bold; color: #4B5563;">Fecha Límite de Presentación: %s</p>
 					<p style="margin: 10px 0 0 0; font-size: 14px; color: #6B7280;">
-						Recuerde que dispone de 8 días hábiles administrativos a partir de la fecha de retorno para formalizar su descargo.
+						Recuerde que dispone de %d días hábiles administrativos a partir de la fecha de retorno para formalizar su descargo.
 					</p>
 				</div>
 
@@ -183,7 +295,7 @@ func (s *AlertaService) enviarAlertaDescargoEmail(sol models.Solicitud, fechaLim
 				Este es un mensaje automático del Sistema de Gestión de Pasajes - Senado.
 			</div>
 		</div>
-	`, statusBadgeColor, sol.Codigo, statusText, beneficiario.GetNombreCompleto(), sol.Codigo, statusBadgeColor, statusText, fechaLimiteStr, directUrl)
+	`, statusBadgeColor, sol.Codigo, statusText, beneficiario.GetNombreCompleto(), sol.Codigo, statusBadgeColor, statusText, fechaLimiteStr, models.PlazoDescargoDiasHabiles, directUrl)
 
 	return s.emailService.SendEmail(destinatarios, copias, ocultos, subject, body)
 }
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type AnticipoService struct {
+	repo          *repositories.AnticipoRepository
+	solicitudRepo *repositories.SolicitudRepository
+	descargoRepo  *repositories.DescargoRepository
+	auditService  *AuditService
+}
+
+func NewAnticipoService(
+	repo *repositories.AnticipoRepository,
+	solicitudRepo *repositories.SolicitudRepository,
+	descargoRepo *repositories.DescargoRepository,
+	auditService *AuditService,
+) *AnticipoService {
+	return &AnticipoService{
+		repo:          repo,
+		solicitudRepo: solicitudRepo,
+		descargoRepo:  descargoRepo,
+		auditService:  auditService,
+	}
+}
+
+func (s *AnticipoService) GetByID(ctx context.Context, id string) (*models.Anticipo, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// GetBySolicitudID devuelve nil si la solicitud no tiene anticipo.
+func (s *AnticipoService) GetBySolicitudID(ctx context.Context, solicitudID string) (*models.Anticipo, error) {
+	a, err := s.repo.FindBySolicitudID(ctx, solicitudID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return a, err
+}
+
+func (s *AnticipoService) GetByEstado(ctx context.Context, estado string) ([]models.Anticipo, error) {
+	return s.repo.FindByEstado(ctx, estado)
+}
+
+// verificarDescargoAbierto impide mover el anticipo cuando el descargo ya fue presentado.
+func (s *AnticipoService) verificarDescargoAbierto(ctx context.Context, solicitudID string) error {
+	descargo, err := s.descargoRepo.FindBySolicitudID(ctx, solicitudID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !descargo.IsEditable() {
+		return errors.New("el descargo de la comisión ya fue presentado")
+	}
+	return nil
+}
+
+// Solicitar registra el anticipo de una solicitud oficial. Un anticipo rechazado puede volver a solicitarse.
+func (s *AnticipoService) Solicitar(ctx context.Context, solicitudID string, req dtos.SolicitarAnticipoRequest, actor *models.Usuario) error {
+	sol, err := s.solicitudRepo.FindByID(ctx, solicitudID)
+	if err != nil {
+		return err
+	}
+	if !sol.IsOficial() {
+		return errors.New("solo las comisiones oficiales pueden solicitar anticipo")
+	}
+	if !sol.CanView(actor) {
+		return errors.New("no tiene permiso sobre esta solicitud")
+	}
+	if estado := sol.GetEstado(); estado == "RECHAZADO" || estado == "FINALIZADO" {
+		return fmt.Errorf("no se puede solicitar anticipo para una solicitud %s", strings.ToLower(estado))
+	}
+	if err := s.verificarDescargoAbierto(ctx, solicitudID); err != nil {
+		return err
+	}
+
+	monto := utils.ParseFloat(req.Monto)
+	if monto <= 0 {
+		return errors.New("el monto del anticipo debe ser mayor a cero")
+	}
+	concepto := strings.TrimSpace(req.Concepto)
+	if concepto == "" {
+		return errors.New("indique el concepto del anticipo")
+	}
+
+	existing, err := s.repo.FindBySolicitudID(ctx, solicitudID)
+	if err == nil {
+		if existing.Estado != models.EstadoAnticipoRechazado {
+			return errors.New("la solicitud ya tiene un anticipo registrado")
+		}
+		existing.Concepto = concepto
+		existing.MontoSolicitado = monto
+		existing.MontoAprobado = 0
+		existing.Estado = models.EstadoAnticipoSolicitado
+		existing.RevisadoPorID = nil
+		existing.FechaRevision = nil
+		existing.Observacion = ""
+		existing.UpdatedBy = &actor.ID
+		if err := s.repo.Update(ctx, existing); err != nil {
+			return err
+		}
+		s.auditService.Log(ctx, "SOLICITAR_ANTICIPO", "anticipo", existing.ID, models.EstadoAnticipoRechazado, fmt.Sprintf("Bs %.2f", monto), "", "")
+		return nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	a := &models.Anticipo{
+		SolicitudID:     sol.ID,
+		UsuarioID:       sol.UsuarioID,
+		Concepto:        concepto,
+		MontoSolicitado: monto,
+		Estado:          models.EstadoAnticipoSolicitado,
+	}
+	a.CreatedBy = &actor.ID
+	if err := s.repo.Create(ctx, a); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "SOLICITAR_ANTICIPO", "anticipo", a.ID, "", fmt.Sprintf("Bs %.2f", monto), "", "")
+	return nil
+}
+
+// Aprobar fija el monto entregado, que no puede superar lo solicitado.
+func (s *AnticipoService) Aprobar(ctx context.Context, id string, req dtos.AprobarAnticipoRequest, actor *models.Usuario) error {
+	a, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !a.IsPendiente() {
+		return fmt.Errorf("el anticipo no está pendiente (%s)", a.Estado)
+	}
+	if err := s.verificarDescargoAbierto(ctx, a.SolicitudID); err != nil {
+		return err
+	}
+	monto := utils.ParseFloat(req.MontoAprobado)
+	if monto <= 0 {
+		return errors.New("el monto aprobado debe ser mayor a cero")
+	}
+	if monto > a.MontoSolicitado {
+		return fmt.Errorf("el monto aprobado no puede superar lo solicitado (Bs %.2f)", a.MontoSolicitado)
+	}
+
+	now := time.Now()
+	a.MontoAprobado = monto
+	a.Estado = models.EstadoAnticipoAprobado
+	a.RevisadoPorID = &actor.ID
+	a.FechaRevision = &now
+	a.Observacion = strings.TrimSpace(req.Observacion)
+	a.UpdatedBy = &actor.ID
+	if err := s.repo.Update(ctx, a); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "APROBAR_ANTICIPO", "anticipo", a.ID, models.EstadoAnticipoSolicitado, fmt.Sprintf("Bs %.2f", monto), "", "")
+	return nil
+}
+
+func (s *AnticipoService) Rechazar(ctx context.Context, id string, req dtos.RechazarAnticipoRequest, actor *models.Usuario) error {
+	a, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !a.IsPendiente() {
+		return fmt.Errorf("el anticipo no está pendiente (%s)", a.Estado)
+	}
+	observacion := strings.TrimSpace(req.Observacion)
+	if observacion == "" {
+		return errors.New("indique el motivo del rechazo")
+	}
+
+	now := time.Now()
+	a.Estado = models.EstadoAnticipoRechazado
+	a.RevisadoPorID = &actor.ID
+	a.FechaRevision = &now
+	a.Observacion = observacion
+	a.UpdatedBy = &actor.ID
+	if err := s.repo.Update(ctx, a); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "RECHAZAR_ANTICIPO", "anticipo", a.ID, models.EstadoAnticipoSolicitado, models.EstadoAnticipoRechazado+": "+observacion, "", "")
+	return nil
+}
+
+// GetParaDescargo devuelve el anticipo de la comisión. Mientras el descargo no se apruebe lo
+// proyecta (sin guardar) contra los gastos registrados hasta ahora, para mostrar al viajero
+// cuánto deberá devolver.
+func (s *AnticipoService) GetParaDescargo(ctx context.Context, descargo *models.Descargo) (*models.Anticipo, error) {
+	a, err := s.GetBySolicitudID(ctx, descargo.SolicitudID)
+	if err != nil || a == nil {
+		return a, err
+	}
+	if a.IsVigente() && (descargo.IsEditable() || descargo.IsInRevision()) {
+		a.Liquidar(descargo.GastosDeclarados())
+	}
+	return a, nil
+}
+
+// VerificarEnvio impide enviar el descargo mientras el anticipo está pendiente de aprobación.
+func (s *AnticipoService) VerificarEnvio(ctx context.Context, descargo *models.Descargo) error {
+	a, err := s.GetBySolicitudID(ctx, descargo.SolicitudID)
+	if err != nil || a == nil {
+		return err
+	}
+	if a.IsPendiente() {
+		return errors.New("el anticipo de la comisión está pendiente de aprobación; resuélvalo antes de enviar el descargo")
+	}
+	return nil
+}
+
+// LiquidarTx salda el anticipo contra los gastos del descargo dentro de la transacción tx. Se
+// llama al aprobar el descargo, cuando los viáticos ya se calcularon en la revisión; si sobra
+// dinero exige la boleta de depósito. Volver a aprobar un descargo revertido liquida de nuevo.
+func (s *AnticipoService) LiquidarTx(ctx context.Context, tx *gorm.DB, descargo *models.Descargo, userID string) error {
+	a, err := s.GetBySolicitudID(ctx, descargo.SolicitudID)
+	if err != nil || a == nil {
+		return err
+	}
+	if a.IsPendiente() {
+		return errors.New("el anticipo de la comisión está pendiente de aprobación; resuélvalo antes de aprobar el descargo")
+	}
+	if !a.IsVigente() {
+		return nil
+	}
+
+	anterior := a.Estado
+	a.Liquidar(descargo.GastosDeclarados())
+
+	if a.SaldoDevolver > 0 {
+		if descargo.Oficial == nil || strings.TrimSpace(descargo.Oficial.NroBoletaDeposito) == "" || descargo.Oficial.ArchivoBoletaDeposito == "" {
+			return fmt.Errorf("el viajero debe devolver Bs %.2f del anticipo; observe el descargo para que registre la boleta de depósito", a.SaldoDevolver)
+		}
+	}
+
+	now := time.Now()
+	a.DescargoID = &descargo.ID
+	a.Estado = models.EstadoAnticipoLiquidado
+	a.FechaLiquidacion = &now
+	a.UpdatedBy = &userID
+	if err := s.repo.WithTx(tx).Update(ctx, a); err != nil {
+		return err
+	}
+
+	if err := s.auditService.LogTx(ctx, tx, "LIQUIDAR_ANTICIPO", "anticipo", a.ID, anterior,
+		fmt.Sprintf("Anticipo Bs %.2f, gastos Bs %.2f, devolver Bs %.2f, reembolsar Bs %.2f", a.MontoAprobado, a.GastoDeclarado, a.SaldoDevolver, a.SaldoReembolsar), "", ""); err != nil {
+		return err
+	}
+	slog.Info("Anticipo liquidado", "id", a.ID, "descargo_id", descargo.ID, "devolver", a.SaldoDevolver, "reembolsar", a.SaldoReembolsar)
+	return nil
+}
@@ -6,6 +6,8 @@ import (
 	"sistema-pasajes/internal/models"
 	"sistema-pasajes/internal/repositories"
 	"sistema-pasajes/internal/utils"
+
+	"gorm.io/gorm"
 )
 
 type AuditService struct {
@@ -17,6 +19,15 @@ func NewAuditService(repo *repositories.AuditRepository) *AuditService {
 }
 
 func (s *AuditService) Log(ctx context.Context, action, entityType, entityID, oldVal, newVal, ip, userAgent string) error {
+	return s.repo.Create(ctx, newAuditEntry(ctx, action, entityType, entityID, oldVal, newVal, ip, userAgent))
+}
+
+// LogTx registra la entrada dentro de la transacción tx: si se revierte, la entrada también.
+func (s *AuditService) LogTx(ctx context.Context, tx *gorm.DB, action, entityType, entityID, oldVal, newVal, ip, userAgent string) error {
+	return s.repo.WithTx(tx).Create(ctx, newAuditEntry(ctx, action, entityType, entityID, oldVal, newVal, ip, userAgent))
+}
+
+func newAuditEntry(ctx context.Context, action, entityType, entityID, oldVal, newVal, ip, userAgent string) *models.AuditLog {
 	userID := appcontext.GetUserIDFromContext(ctx)
 
 	// Fallback a contexto si no se proveen explícitamente
@@ -38,7 +49,7 @@ func (s *AuditService) Log(ctx context.Context, action, entityType, entityID, ol
 		}
 	}
 
-	entry := &models.AuditLog{
+	return &models.AuditLog{
 		Action:     safeAction,
 		EntityType: safeEntityType,
 		EntityID:   entityID,
@@ -48,8 +59,6 @@ func (s *AuditService) Log(ctx context.Context, action, entityType, entityID, ol
 		IP:         safeIP,
 		UserAgent:  safeUserAgent,
 	}
-
-	return s.repo.Create(ctx, entry)
 }
 
 func (s *AuditService) GetHistory(ctx context.Context, entityType, entityID string) ([]models.AuditLog, error) {
@@ -61,7 +70,7 @@ func (s *AuditService) GetAll(ctx context.Context, filters map[string]string, li
 }
 
 func (s *AuditService) GetAvailableFilters(ctx context.Context) (actions []string, entities []string, err error) {
-	actions = []string{"LOGIN", "LOGOUT", "CREAR_SOLICITUD", "ACTUALIZAR_SOLICITUD", "APROBAR_SOLICITUD", "RECHAZAR_SOLICITUD", "ACTUALIZAR_DESCARGO", "SUBMIT_DESCARGO", "APROBAR_DESCARGO"}
-	entities = []string{"solicitud", "pasaje", "descargo", "usuario", "auth"}
+	actions = []string{"LOGIN", "LOGOUT", "CREAR_SOLICITUD", "ACTUALIZAR_SOLICITUD", "APROBAR_SOLICITUD", "RECHAZAR_SOLICITUD", "ACTUALIZAR_DESCARGO", "SUBMIT_DESCARGO", "APROBAR_DESCARGO", "OMITIR_CONFLICTO_VIAJE", "OMITIR_MOROSIDAD", "CREAR_POLITICA_CUPO", "SOLICITAR_TRANSFERENCIA_CUPO", "APROBAR_TRANSFERENCIA_CUPO", "RECHAZAR_TRANSFERENCIA_CUPO", "CONCILIAR_CUPOS", "CREAR_LICENCIA", "ANULAR_LICENCIA", "ESTADO_LICENCIA", "ASIGNAR_CUPO_LICENCIA", "REVERTIR_CUPO_LICENCIA", "AUTORIZAR_SOBREPRECIO", "CREAR_TIPO_CAMBIO", "ELIMINAR_TIPO_CAMBIO", "CREAR_FERIADO", "ESTADO_FERIADO", "ELIMINAR_FERIADO", "CREAR_REGLA_DESCARGO", "EDITAR_REGLA_DESCARGO", "ESTADO_REGLA_DESCARGO", "ELIMINAR_REGLA_DESCARGO", "CREAR_ZONA_VIATICO", "ELIMINAR_ZONA_VIATICO", "CREAR_ESCALA_VIATICO", "EDITAR_ESCALA_VIATICO", "ELIMINAR_ESCALA_VIATICO", "CALCULAR_VIATICO", "SOLICITAR_ANTICIPO", "APROBAR_ANTICIPO", "RECHAZAR_ANTICIPO", "LIQUIDAR_ANTICIPO", "BILLETE_DUPLICADO", "REEMITIR_PASAJE", "IMPORTAR_EXTRACTO", "VERIFICAR_REEMBOLSO", "REGISTRAR_CERTIFICADO", "ELIMINAR_CERTIFICADO", "FIRMAR_DOCUMENTO", "RESTAURAR_ADJUNTO"}
+	entities = []string{"solicitud", "pasaje", "descargo", "usuario", "auth", "politica_cupo", "transferencia_cupo", "cupo_derecho", "licencia_senador", "cupo_derecho_item", "tipo_cambio", "feriado", "regla_descargo", "viatico", "anticipo", "billete", "reembolso", "certificado_firma", "adjunto"}
 	return
 }
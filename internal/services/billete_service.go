@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"strings"
+)
+
+const (
+	BilleteDuplicadoBloquear = "BLOQUEAR"
+	BilleteDuplicadoAdvertir = "ADVERTIR"
+)
+
+// BilleteService valida que un número de billete no se use dos veces entre pasajes,
+// tramos de descargo y open tickets, salvo en una cadena de reemisión legítima.
+type BilleteService struct {
+	repo          *repositories.BilleteRepository
+	configService *ConfiguracionService
+	auditService  *AuditService
+}
+
+func NewBilleteService(
+	repo *repositories.BilleteRepository,
+	configService *ConfiguracionService,
+	auditService *AuditService,
+) *BilleteService {
+	return &BilleteService{
+		repo:          repo,
+		configService: configService,
+		auditService:  auditService,
+	}
+}
+
+// GetModo indica qué hacer con un billete repetido por el mismo viajero: BLOQUEAR (por
+// defecto) o ADVERTIR. El uso por otro viajero siempre se bloquea.
+func (s *BilleteService) GetModo(ctx context.Context) string {
+	if strings.ToUpper(strings.TrimSpace(s.configService.GetValue(ctx, "BILLETE_DUPLICADO_MODO"))) == BilleteDuplicadoAdvertir {
+		return BilleteDuplicadoAdvertir
+	}
+	return BilleteDuplicadoBloquear
+}
+
+// AvisoBillete es un billete repetido admitido en modo ADVERTIR. Se audita con RegistrarAviso
+// una vez que el registro se guardó.
+type AvisoBillete struct {
+	Mensaje     string
+	Normalizado string
+	RepetidoID  string
+}
+
+// Verificar busca otros usos del número del candidato. Retorna error si debe bloquearse o un
+// aviso (no nil) si el modo es ADVERTIR. El candidato se identifica por Fuente + RegistroID
+// (vacío si aún no existe) y debe traer UsuarioID, PasajeID, OpenTicketID y OriginalID si corresponden.
+func (s *BilleteService) Verificar(ctx context.Context, candidato repositories.UsoBillete) (*AvisoBillete, error) {
+	normalizado := models.NormalizarBillete(candidato.NumeroBillete)
+	if normalizado == "" {
+		return nil, nil
+	}
+	usos, err := s.repo.FindUsos(ctx, normalizado)
+	if err != nil {
+		return nil, err
+	}
+
+	var repetidos []repositories.UsoBillete
+	for _, u := range usos {
+		if candidato.RegistroID != "" && u.Fuente == candidato.Fuente && u.RegistroID == candidato.RegistroID {
+			continue
+		}
+		if u.UsuarioID != candidato.UsuarioID {
+			return nil, fmt.Errorf("el billete %s ya está registrado para %s (%s)",
+				candidato.NumeroBillete, u.UsuarioNombre, describirUsoBillete(u))
+		}
+		if candidato.Fuente != repositories.UsoBilletePasaje || u.Fuente != repositories.UsoBilletePasaje {
+			// Descargos y open tickets repiten el billete de su propio pasaje.
+			continue
+		}
+		if esReemision(candidato, u, usos) {
+			continue
+		}
+		repetidos = append(repetidos, u)
+	}
+	if len(repetidos) == 0 {
+		return nil, nil
+	}
+
+	msg := fmt.Sprintf("ya existe un pasaje con el número de billete %s (%s)",
+		candidato.NumeroBillete, describirUsoBillete(repetidos[0]))
+	if s.GetModo(ctx) == BilleteDuplicadoBloquear {
+		return nil, fmt.Errorf("%s", msg)
+	}
+	return &AvisoBillete{Mensaje: msg, Normalizado: normalizado, RepetidoID: repetidos[0].RegistroID}, nil
+}
+
+// RegistrarAviso audita el billete repetido admitido, ya con el ID del registro guardado.
+func (s *BilleteService) RegistrarAviso(ctx context.Context, aviso *AvisoBillete, registroID string) {
+	if aviso == nil {
+		return
+	}
+	s.auditService.Log(ctx, "BILLETE_DUPLICADO", "billete", aviso.Normalizado, aviso.RepetidoID, registroID, "", "")
+}
+
+// esReemision reconoce los pasajes de una misma cadena que conservan el número del billete:
+// la reemisión directa de otro pasaje o el pasaje emitido desde el open ticket que generó.
+func esReemision(candidato, uso repositories.UsoBillete, usos []repositories.UsoBillete) bool {
+	if candidato.OriginalID != "" && candidato.OriginalID == uso.RegistroID {
+		return true
+	}
+	if uso.OriginalID != "" && candidato.RegistroID != "" && uso.OriginalID == candidato.RegistroID {
+		return true
+	}
+	for _, ot := range usos {
+		if ot.Fuente != repositories.UsoBilleteOpenTicket || ot.PasajeID == "" {
+			continue
+		}
+		if candidato.OpenTicketID == ot.RegistroID && ot.PasajeID == uso.RegistroID {
+			return true
+		}
+		if uso.OpenTicketID == ot.RegistroID && ot.PasajeID == candidato.RegistroID && candidato.RegistroID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func describirUsoBillete(u repositories.UsoBillete) string {
+	origen := "pasaje"
+	switch u.Fuente {
+	case repositories.UsoBilleteDescargo:
+		origen = "descargo"
+	case repositories.UsoBilleteOpenTicket:
+		origen = "open ticket"
+	}
+	if u.SolicitudCodigo != "" {
+		return origen + " de la solicitud " + u.SolicitudCodigo
+	}
+	return origen
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+)
+
+// calendarioDiasAtras es cuánto hacia atrás incluye el feed, para que los plazos de descargo
+// vencidos recientemente sigan visibles.
+const calendarioDiasAtras = 30
+
+// CalendarioService genera los feeds iCalendar (ICS) personales de viajes, cupos y plazos
+// de descargo.
+type CalendarioService struct {
+	feedRepo      *repositories.CalendarioFeedRepository
+	solicitudRepo *repositories.SolicitudRepository
+	itemRepo      *repositories.CupoDerechoItemRepository
+}
+
+func NewCalendarioService(
+	feedRepo *repositories.CalendarioFeedRepository,
+	solicitudRepo *repositories.SolicitudRepository,
+	itemRepo *repositories.CupoDerechoItemRepository,
+) *CalendarioService {
+	return &CalendarioService{
+		feedRepo:      feedRepo,
+		solicitudRepo: solicitudRepo,
+		itemRepo:      itemRepo,
+	}
+}
+
+// GetOrCreateFeed retorna el feed del usuario, creándolo con un token nuevo la primera vez.
+func (s *CalendarioService) GetOrCreateFeed(ctx context.Context, usuarioID string) (*models.CalendarioFeed, error) {
+	feed, err := s.feedRepo.FindByUsuarioID(ctx, usuarioID)
+	if err == nil {
+		return feed, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	token, err := generarTokenCalendario()
+	if err != nil {
+		return nil, err
+	}
+	feed = &models.CalendarioFeed{
+		BaseModel: models.BaseModel{CreatedBy: &usuarioID},
+		UsuarioID: usuarioID,
+		Token:     token,
+	}
+	if err := s.feedRepo.Create(ctx, feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+// RegenerarToken invalida la URL anterior del feed (p. ej. si fue compartida por error).
+func (s *CalendarioService) RegenerarToken(ctx context.Context, usuarioID string) (*models.CalendarioFeed, error) {
+	feed, err := s.GetOrCreateFeed(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	token, err := generarTokenCalendario()
+	if err != nil {
+		return nil, err
+	}
+	feed.Token = token
+	feed.UpdatedBy = &usuarioID
+	if err := s.feedRepo.Update(ctx, feed); err != nil {
+		return nil, err
+	}
+	return feed, nil
+}
+
+func (s *CalendarioService) GetFeedURL(feed *models.CalendarioFeed) string {
+	return fmt.Sprintf("%s/calendario/%s.ics", strings.TrimRight(viper.GetString("APP_URL"), "/"), feed.Token)
+}
+
+// GenerarICS arma el calendario del dueño del token. Se genera en cada consulta, por lo que
+// refleja siempre el estado actual de solicitudes, pasajes y cupos.
+func (s *CalendarioService) GenerarICS(ctx context.Context, token string) ([]byte, error) {
+	feed, err := s.feedRepo.FindByToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	_ = s.feedRepo.UpdateUltimoAcceso(ctx, feed.ID, now)
+
+	desde := now.AddDate(0, 0, -calendarioDiasAtras)
+	solicitudes, err := s.solicitudRepo.FindParaCalendario(ctx, feed.UsuarioID, desde)
+	if err != nil {
+		return nil, err
+	}
+	cupos, err := s.itemRepo.FindDisponiblesParaCalendario(ctx, feed.UsuarioID, desde)
+	if err != nil {
+		return nil, err
+	}
+
+	nombre := "Pasajes"
+	if feed.Usuario != nil {
+		nombre = "Pasajes - " + feed.Usuario.GetNombreCompleto()
+	}
+
+	cal := newICSCalendar(nombre, now)
+	for _, sol := range solicitudes {
+		for _, item := range sol.Items {
+			if item.Fecha == nil || item.Fecha.Before(desde) || item.IsRechazado() || item.IsCancelado() {
+				continue
+			}
+			cal.addVuelo(sol, item, feed.UsuarioID)
+		}
+		if limite := fechaLimiteDescargoPendiente(sol); limite != nil {
+			cal.addDescargo(sol, *limite, feed.UsuarioID)
+		}
+	}
+	for _, it := range cupos {
+		if it.FechaDesde == nil || it.FechaHasta == nil {
+			continue
+		}
+		cal.addCupo(it, feed.UsuarioID)
+	}
+
+	return cal.bytes(), nil
+}
+
+// fechaLimiteDescargoPendiente retorna el plazo de descargo si la solicitud aún debe presentarlo,
+// con el mismo criterio que las alertas por correo.
+func fechaLimiteDescargoPendiente(sol models.Solicitud) *time.Time {
+	if sol.Descargo != nil {
+		switch sol.Descargo.Estado {
+		case models.EstadoDescargoEnRevision, models.EstadoDescargoOpenTicket, models.EstadoDescargoFinalizado:
+			return nil
+		}
+	}
+	if sol.HasCompleteDescargo() {
+		return nil
+	}
+	maxVuelo := sol.GetMaxFechaVueloEmitida()
+	if maxVuelo == nil {
+		return nil
+	}
+	limite := utils.CalcularFechaLimiteDescargo(*maxVuelo, sol.Usuario.GetDepartamentoCodigo())
+	return &limite
+}
+
+func generarTokenCalendario() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// --- Escritura iCalendar (RFC 5545) ---
+
+const icsTZID = "America/La_Paz"
+
+// locLaPaz es la hora de Bolivia (DTSTART con TZID, fechas de vuelo); sin tzdata se usa su
+// desfase fijo, ya que no tiene horario de verano.
+var locLaPaz = func() *time.Location {
+	if loc, err := time.LoadLocation(icsTZID); err == nil {
+		return loc
+	}
+	return time.FixedZone("-04", -4*60*60)
+}()
+
+type icsCalendar struct {
+	b     strings.Builder
+	stamp string
+}
+
+func newICSCalendar(nombre string, now time.Time) *icsCalendar {
+	c := &icsCalendar{stamp: now.UTC().Format("20060102T150405Z")}
+	c.line("BEGIN:VCALENDAR")
+	c.line("VERSION:2.0")
+	c.line("PRODID:-//Senado//Sistema de Pasajes//ES")
+	c.line("CALSCALE:GREGORIAN")
+	c.line("METHOD:PUBLISH")
+	c.line("X-WR-CALNAME:" + icsEscape(nombre))
+	c.line("X-WR-TIMEZONE:" + icsTZID)
+	c.line("REFRESH-INTERVAL;VALUE=DURATION:PT1H")
+	c.line("X-PUBLISHED-TTL:PT1H")
+	// Bolivia no tiene horario de verano: basta un único STANDARD.
+	c.line("BEGIN:VTIMEZONE")
+	c.line("TZID:" + icsTZID)
+	c.line("BEGIN:STANDARD")
+	c.line("DTSTART:19700101T000000")
+	c.line("TZOFFSETFROM:-0400")
+	c.line("TZOFFSETTO:-0400")
+	c.line("TZNAME:-04")
+	c.line("END:STANDARD")
+	c.line("END:VTIMEZONE")
+	return c
+}
+
+func (c *icsCalendar) addVuelo(sol models.Solicitud, item models.SolicitudItem, ownerID string) {
+	inicio := *item.Fecha
+	estado := "TENTATIVE"
+	var detalle []string
+	detalle = append(detalle, fmt.Sprintf("Solicitud %s (%s)", sol.Codigo, sol.GetEstado()))
+	detalle = append(detalle, "Tramo: "+string(item.Tipo)+" - "+item.GetEstado())
+
+	if p := item.GetPasajeActivo(); p != nil && p.GetEstadoCodigo() != models.EstadoPasajeRegistrado {
+		estado = "CONFIRMED"
+		if !p.FechaVuelo.IsZero() {
+			inicio = p.FechaVuelo
+		}
+		if p.Aerolinea != nil {
+			detalle = append(detalle, "Aerolínea: "+p.Aerolinea.Nombre)
+		}
+		if p.NumeroVuelo != "" {
+			detalle = append(detalle, "Vuelo: "+p.NumeroVuelo)
+		}
+		if p.NumeroBillete != "" {
+			detalle = append(detalle, "Billete: "+p.NumeroBillete)
+		}
+	}
+
+	resumen := fmt.Sprintf("Vuelo %s → %s", item.GetOrigenLabel(), item.GetDestinoLabel())
+	if sol.UsuarioID != ownerID {
+		resumen += " (" + sol.Usuario.GetNombreCompleto() + ")"
+	}
+
+	c.line("BEGIN:VEVENT")
+	c.line("UID:vuelo-" + item.ID + "@sistema-pasajes")
+	c.line("DTSTAMP:" + c.stamp)
+	c.line("LAST-MODIFIED:" + item.UpdatedAt.UTC().Format("20060102T150405Z"))
+	c.line("DTSTART;TZID=" + icsTZID + ":" + inicio.In(locLaPaz).Format("20060102T150405"))
+	c.line("DURATION:PT1H")
+	c.line("SUMMARY:" + icsEscape(resumen))
+	c.line("LOCATION:" + icsEscape(item.GetOrigenLabel()))
+	c.line("DESCRIPTION:" + icsEscape(strings.Join(detalle, "\n")))
+	c.line("STATUS:" + estado)
+	c.line("URL:" + urlSolicitud(sol))
+	c.line("END:VEVENT")
+}
+
+func (c *icsCalendar) addDescargo(sol models.Solicitud, limite time.Time, ownerID string) {
+	resumen := "Plazo de descargo " + sol.Codigo
+	if sol.UsuarioID != ownerID {
+		resumen += " (" + sol.Usuario.GetNombreCompleto() + ")"
+	}
+
+	c.line("BEGIN:VEVENT")
+	c.line("UID:descargo-" + sol.ID + "@sistema-pasajes")
+	c.line("DTSTAMP:" + c.stamp)
+	c.line("DTSTART;VALUE=DATE:" + limite.Format("20060102"))
+	c.line("DTEND;VALUE=DATE:" + limite.AddDate(0, 0, 1).Format("20060102"))
+	c.line("SUMMARY:" + icsEscape(resumen))
+	c.line("DESCRIPTION:" + icsEscape(fmt.Sprintf("Último día para presentar el descargo de la solicitud %s (%s).", sol.Codigo, sol.GetItinerarioResumen())))
+	c.line("TRANSP:TRANSPARENT")
+	c.line("URL:" + urlSolicitud(sol))
+	c.line("END:VEVENT")
+}
+
+func (c *icsCalendar) addCupo(it models.CupoDerechoItem, ownerID string) {
+	resumen := "Cupo disponible " + it.Semana
+	if it.SenAsignadoID != ownerID && it.SenAsignado != nil {
+		resumen += " (" + it.SenAsignado.GetNombreCompleto() + ")"
+	}
+
+	c.line("BEGIN:VEVENT")
+	c.line("UID:cupo-" + it.ID + "@sistema-pasajes")
+	c.line("DTSTAMP:" + c.stamp)
+	c.line("LAST-MODIFIED:" + it.UpdatedAt.UTC().Format("20060102T150405Z"))
+	c.line("DTSTART;VALUE=DATE:" + it.FechaDesde.Format("20060102"))
+	c.line("DTEND;VALUE=DATE:" + it.FechaHasta.AddDate(0, 0, 1).Format("20060102"))
+	c.line("SUMMARY:" + icsEscape(strings.TrimSpace(resumen)))
+	c.line("TRANSP:TRANSPARENT")
+	c.line(fmt.Sprintf("URL:%s/cupos/derecho/%s/%d/%d", strings.TrimRight(viper.GetString("APP_URL"), "/"), it.SenAsignadoID, it.Gestion, it.Mes))
+	c.line("END:VEVENT")
+}
+
+func (c *icsCalendar) bytes() []byte {
+	c.line("END:VCALENDAR")
+	return []byte(c.b.String())
+}
+
+// line escribe una línea de contenido plegándola a 75 octetos, sin cortar caracteres UTF-8.
+func (c *icsCalendar) line(s string) {
+	limite := 75
+	for len(s) > limite {
+		corte := limite
+		for corte > 0 && (s[corte]&0xC0) == 0x80 {
+			corte--
+		}
+		c.b.WriteString(s[:corte])
+		c.b.WriteString("\r\n ")
+		s = s[corte:]
+		// Las líneas de continuación llevan un espacio inicial.
+		limite = 74
+	}
+	c.b.WriteString(s)
+	c.b.WriteString("\r\n")
+}
+
+func icsEscape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+	return r.Replace(s)
+}
+
+func urlSolicitud(sol models.Solicitud) string {
+	tipoPath := "oficial"
+	if strings.HasPrefix(strings.ToUpper(sol.GetConceptoCodigo()), "DERECHO") {
+		tipoPath = "derecho"
+	}
+	return fmt.Sprintf("%s/solicitudes/%s/%s/detalle", strings.TrimRight(viper.GetString("APP_URL"), "/"), tipoPath, sol.ID)
+}
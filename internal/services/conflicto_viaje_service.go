@@ -0,0 +1,230 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"slices"
+	"strings"
+	"time"
+)
+
+// margenConflictoDias amplía la búsqueda para detectar viajes que envuelven al evaluado
+// (ej: ida antes y retorno después del tramo que se está registrando).
+const margenConflictoDias = 15
+
+type ConflictoViajeService struct {
+	solicitudItemRepo *repositories.SolicitudItemRepository
+	pasajeRepo        *repositories.PasajeRepository
+	auditService      *AuditService
+}
+
+func NewConflictoViajeService(
+	solicitudItemRepo *repositories.SolicitudItemRepository,
+	pasajeRepo *repositories.PasajeRepository,
+	auditService *AuditService,
+) *ConflictoViajeService {
+	return &ConflictoViajeService{
+		solicitudItemRepo: solicitudItemRepo,
+		pasajeRepo:        pasajeRepo,
+		auditService:      auditService,
+	}
+}
+
+// Detectar compara las fechas de los tramos evaluados contra los demás tramos no rechazados
+// y los pasajes emitidos del beneficiario. Mismo día con un viaje aprobado o emitido es bloqueante;
+// mismo día con una solicitud pendiente o fechas superpuestas generan advertencia.
+func (s *ConflictoViajeService) Detectar(ctx context.Context, usuarioID, solicitudID string, items []models.SolicitudItem) (models.ConflictosViaje, error) {
+	dias := map[string]bool{}
+	var inicio, fin time.Time
+	for _, it := range items {
+		if it.Fecha == nil || it.IsRechazado() || it.IsCancelado() {
+			continue
+		}
+		d := truncateDay(*it.Fecha)
+		dias[d.Format("2006-01-02")] = true
+		if inicio.IsZero() || d.Before(inicio) {
+			inicio = d
+		}
+		if fin.IsZero() || d.After(fin) {
+			fin = d
+		}
+	}
+	if len(dias) == 0 {
+		return nil, nil
+	}
+
+	desde := inicio.AddDate(0, 0, -margenConflictoDias)
+	hasta := fin.AddDate(0, 0, margenConflictoDias+1)
+
+	otrosItems, err := s.solicitudItemRepo.FindActivosByUsuarioEnRango(ctx, usuarioID, solicitudID, desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+	pasajes, err := s.pasajeRepo.FindEmitidosByUsuarioEnRango(ctx, usuarioID, solicitudID, desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rango de cada viaje ajeno para detectar superposición aunque no coincida el día exacto.
+	type rango struct{ inicio, fin time.Time }
+	rangos := map[string]*rango{}
+	extend := func(solID string, f time.Time) {
+		d := truncateDay(f)
+		r, ok := rangos[solID]
+		if !ok {
+			rangos[solID] = &rango{inicio: d, fin: d}
+			return
+		}
+		if d.Before(r.inicio) {
+			r.inicio = d
+		}
+		if d.After(r.fin) {
+			r.fin = d
+		}
+	}
+	for _, it := range otrosItems {
+		extend(it.SolicitudID, *it.Fecha)
+	}
+	for _, p := range pasajes {
+		extend(p.SolicitudID, p.FechaVuelo)
+	}
+
+	found := map[string]models.ConflictoViaje{}
+	add := func(c models.ConflictoViaje) {
+		key := c.SolicitudID + "|" + c.Fecha.Format("2006-01-02")
+		// Se conserva el de mayor severidad; los pasajes se registran primero y tienen prioridad.
+		if prev, ok := found[key]; ok && (prev.IsBloqueante() || !c.IsBloqueante()) {
+			return
+		}
+		found[key] = c
+	}
+
+	for _, p := range pasajes {
+		d := truncateDay(p.FechaVuelo)
+		c := models.ConflictoViaje{
+			Fecha:         d,
+			SolicitudID:   p.SolicitudID,
+			Ruta:          p.GetRutaDisplay(),
+			NumeroBillete: p.NumeroBillete,
+		}
+		if p.Solicitud != nil {
+			c.SolicitudCodigo = p.Solicitud.Codigo
+			c.Concepto = p.Solicitud.GetConceptoCodigo()
+		}
+		if dias[d.Format("2006-01-02")] {
+			c.Nivel = models.NivelConflictoBloqueante
+			c.Motivo = "pasaje emitido para el mismo día"
+			add(c)
+		}
+	}
+
+	for _, it := range otrosItems {
+		d := truncateDay(*it.Fecha)
+		if !dias[d.Format("2006-01-02")] {
+			continue
+		}
+		c := models.ConflictoViaje{
+			Fecha:       d,
+			SolicitudID: it.SolicitudID,
+			Ruta:        fmt.Sprintf("%s → %s", it.OrigenIATA, it.DestinoIATA),
+		}
+		if it.Solicitud != nil {
+			c.SolicitudCodigo = it.Solicitud.Codigo
+			c.Concepto = it.Solicitud.GetConceptoCodigo()
+		}
+		if it.IsAprobado() || it.IsEmitido() || it.IsFinalizado() {
+			c.Nivel = models.NivelConflictoBloqueante
+			c.Motivo = "tramo aprobado para el mismo día"
+		} else {
+			c.Nivel = models.NivelConflictoAdvertencia
+			c.Motivo = "otra solicitud pendiente para el mismo día"
+		}
+		add(c)
+	}
+
+	var result models.ConflictosViaje
+	reported := map[string]bool{}
+	for _, c := range found {
+		reported[c.SolicitudID] = true
+		result = append(result, c)
+	}
+
+	// Superposición de rangos sin coincidencia exacta de día
+	for solID, r := range rangos {
+		if reported[solID] || r.fin.Before(inicio) || r.inicio.After(fin) {
+			continue
+		}
+		c := models.ConflictoViaje{
+			Nivel:       models.NivelConflictoAdvertencia,
+			Fecha:       r.inicio,
+			SolicitudID: solID,
+			Motivo:      fmt.Sprintf("viaje superpuesto del %s al %s", r.inicio.Format("02/01"), r.fin.Format("02/01")),
+		}
+		for _, it := range otrosItems {
+			if it.SolicitudID == solID && it.Solicitud != nil {
+				c.SolicitudCodigo = it.Solicitud.Codigo
+				c.Concepto = it.Solicitud.GetConceptoCodigo()
+				break
+			}
+		}
+		if c.SolicitudCodigo == "" {
+			for _, p := range pasajes {
+				if p.SolicitudID == solID && p.Solicitud != nil {
+					c.SolicitudCodigo = p.Solicitud.Codigo
+					c.Concepto = p.Solicitud.GetConceptoCodigo()
+					break
+				}
+			}
+		}
+		result = append(result, c)
+	}
+
+	sortConflictos(result)
+	return result, nil
+}
+
+// Evaluar detecta conflictos y bloquea la acción si hay conflictos bloqueantes, salvo que un
+// administrador haya registrado una justificación.
+func (s *ConflictoViajeService) Evaluar(ctx context.Context, usuarioID, solicitudID string, items []models.SolicitudItem, user *models.Usuario, justificacion string) (models.ConflictosViaje, error) {
+	conflictos, err := s.Detectar(ctx, usuarioID, solicitudID, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if conflictos.HasBloqueantes() {
+		if user == nil || !user.IsAdmin() || strings.TrimSpace(justificacion) == "" {
+			return conflictos, &models.ConflictoViajeError{Conflictos: conflictos.Bloqueantes()}
+		}
+	}
+	return conflictos, nil
+}
+
+// RegistrarOmision deja constancia en auditoría cuando un administrador autoriza una acción con conflictos bloqueantes.
+func (s *ConflictoViajeService) RegistrarOmision(ctx context.Context, solicitudID string, conflictos models.ConflictosViaje, justificacion string) error {
+	if !conflictos.HasBloqueantes() || strings.TrimSpace(justificacion) == "" {
+		return nil
+	}
+	return s.auditService.Log(ctx, "OMITIR_CONFLICTO_VIAJE", "solicitud", solicitudID,
+		conflictos.Bloqueantes().Resumen(), strings.TrimSpace(justificacion), "", "")
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func sortConflictos(cs models.ConflictosViaje) {
+	slices.SortFunc(cs, func(a, b models.ConflictoViaje) int {
+		if c := a.Fecha.Compare(b.Fecha); c != 0 {
+			return c
+		}
+		if a.IsBloqueante() != b.IsBloqueante() {
+			if a.IsBloqueante() {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.SolicitudCodigo, b.SolicitudCodigo)
+	})
+}
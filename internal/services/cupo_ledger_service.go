@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// CupoLedgerService mantiene el libro de consumo de cupos. CupoDerecho.CupoUsado se
+// deriva siempre de la suma de sus movimientos.
+type CupoLedgerService struct {
+	repo         *repositories.MovimientoCupoRepository
+	cupoRepo     *repositories.CupoDerechoRepository
+	itemRepo     *repositories.CupoDerechoItemRepository
+	auditService *AuditService
+}
+
+func NewCupoLedgerService(
+	repo *repositories.MovimientoCupoRepository,
+	cupoRepo *repositories.CupoDerechoRepository,
+	itemRepo *repositories.CupoDerechoItemRepository,
+	auditService *AuditService,
+) *CupoLedgerService {
+	return &CupoLedgerService{
+		repo:         repo,
+		cupoRepo:     cupoRepo,
+		itemRepo:     itemRepo,
+		auditService: auditService,
+	}
+}
+
+// RegistrarTx asienta el cambio de un ítem (antes -> despues) y actualiza el contador del periodo.
+func (s *CupoLedgerService) RegistrarTx(ctx context.Context, tx *gorm.DB, antes, despues models.CupoDerechoItem, origen string, solicitudID, pasajeID *string) error {
+	if despues.CupoDerechoID == "" {
+		return nil
+	}
+	if antes.EstadoCupoDerechoCodigo == despues.EstadoCupoDerechoCodigo && antes.SenAsignadoID == despues.SenAsignadoID {
+		return nil
+	}
+
+	delta := consumoItem(despues) - consumoItem(antes)
+	if err := s.abrirLibroTx(ctx, tx, despues, delta); err != nil {
+		return err
+	}
+
+	tipo := models.TipoMovimientoConfirmacion
+	switch {
+	case delta > 0:
+		tipo = models.TipoMovimientoConsumo
+	case delta < 0:
+		tipo = models.TipoMovimientoLiberacion
+	}
+
+	itemID := despues.ID
+	mov := &models.MovimientoCupo{
+		CupoDerechoID:     despues.CupoDerechoID,
+		CupoDerechoItemID: &itemID,
+		SenTitularID:      despues.SenTitularID,
+		Gestion:           despues.Gestion,
+		Mes:               despues.Mes,
+		Tipo:              tipo,
+		Delta:             delta,
+		EstadoAnterior:    antes.EstadoCupoDerechoCodigo,
+		EstadoNuevo:       despues.EstadoCupoDerechoCodigo,
+		AsignadoAnterior:  antes.SenAsignadoID,
+		AsignadoNuevo:     despues.SenAsignadoID,
+		Origen:            origen,
+		SolicitudID:       solicitudID,
+		PasajeID:          pasajeID,
+	}
+	if err := s.repo.WithTx(tx).Create(ctx, mov); err != nil {
+		return err
+	}
+
+	return s.actualizarContadorTx(ctx, tx, despues.CupoDerechoID)
+}
+
+// UsadoTx retorna el consumo del cupo según el libro, abriéndolo si aún no tiene movimientos.
+func (s *CupoLedgerService) UsadoTx(ctx context.Context, tx *gorm.DB, cupo *models.CupoDerecho) (int, error) {
+	ref := models.CupoDerechoItem{CupoDerechoID: cupo.ID, SenTitularID: cupo.SenTitularID, Gestion: cupo.Gestion, Mes: cupo.Mes}
+	if err := s.abrirLibroTx(ctx, tx, ref, 0); err != nil {
+		return 0, err
+	}
+	return s.repo.WithTx(tx).SumDeltaByCupo(ctx, cupo.ID)
+}
+
+// AnularPeriodoTx compensa el libro de los cupos de un periodo cuyos ítems serán eliminados.
+func (s *CupoLedgerService) AnularPeriodoTx(ctx context.Context, tx *gorm.DB, gestion, mes int, origen string) error {
+	cupos, err := s.cupoRepo.WithTx(tx).FindByPeriodo(ctx, gestion, mes)
+	if err != nil {
+		return err
+	}
+
+	repoTx := s.repo.WithTx(tx)
+	for _, c := range cupos {
+		saldo, err := repoTx.SumDeltaByCupo(ctx, c.ID)
+		if err != nil {
+			return err
+		}
+		if saldo != 0 {
+			if err := repoTx.Create(ctx, s.ajuste(c, -saldo, origen, "Periodo reiniciado")); err != nil {
+				return err
+			}
+		}
+		if err := s.cupoRepo.WithTx(tx).UpdateUsado(ctx, c.ID, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// abrirLibroTx registra el saldo de apertura de un cupo sin movimientos previos, tomado del
+// estado de sus ítems antes del cambio en curso (delta).
+func (s *CupoLedgerService) abrirLibroTx(ctx context.Context, tx *gorm.DB, ref models.CupoDerechoItem, delta int) error {
+	repoTx := s.repo.WithTx(tx)
+	count, err := repoTx.CountByCupo(ctx, ref.CupoDerechoID)
+	if err != nil || count > 0 {
+		return err
+	}
+
+	consumidos, err := s.itemRepo.WithTx(tx).CountConsumidosByCupo(ctx, ref.CupoDerechoID)
+	if err != nil {
+		return err
+	}
+	apertura := consumidos - delta
+	if apertura == 0 {
+		return nil
+	}
+
+	cupo := models.CupoDerecho{BaseModel: models.BaseModel{ID: ref.CupoDerechoID}, SenTitularID: ref.SenTitularID, Gestion: ref.Gestion, Mes: ref.Mes}
+	return repoTx.Create(ctx, s.ajuste(cupo, apertura, "APERTURA", "Saldo inicial tomado de los ítems"))
+}
+
+func (s *CupoLedgerService) actualizarContadorTx(ctx context.Context, tx *gorm.DB, cupoID string) error {
+	usado, err := s.repo.WithTx(tx).SumDeltaByCupo(ctx, cupoID)
+	if err != nil {
+		return err
+	}
+	return s.cupoRepo.WithTx(tx).UpdateUsado(ctx, cupoID, usado)
+}
+
+func (s *CupoLedgerService) ajuste(c models.CupoDerecho, delta int, origen, observacion string) *models.MovimientoCupo {
+	return &models.MovimientoCupo{
+		CupoDerechoID: c.ID,
+		SenTitularID:  c.SenTitularID,
+		Gestion:       c.Gestion,
+		Mes:           c.Mes,
+		Tipo:          models.TipoMovimientoAjuste,
+		Delta:         delta,
+		Origen:        origen,
+		Observacion:   observacion,
+	}
+}
+
+func (s *CupoLedgerService) GetMovimientos(ctx context.Context, cupoID string) ([]models.MovimientoCupo, error) {
+	return s.repo.FindByCupo(ctx, cupoID)
+}
+
+// PeriodoDiscrepancia agrupa los cupos inconsistentes de un mes.
+type PeriodoDiscrepancia struct {
+	Gestion   int
+	Mes       int
+	MesNombre string
+	Cupos     []models.DiscrepanciaCupo
+}
+
+// Conciliar compara, para cada cupo de la gestión, el contador, el libro y el estado de los
+// ítems. Solo retorna los periodos con al menos un cupo en desacuerdo.
+func (s *CupoLedgerService) Conciliar(ctx context.Context, gestion int) ([]PeriodoDiscrepancia, error) {
+	cupos, err := s.cupoRepo.FindByGestion(ctx, gestion)
+	if err != nil {
+		return nil, err
+	}
+	saldos, err := s.repo.SumDeltaByGestion(ctx, gestion)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.itemRepo.FindByGestion(ctx, gestion)
+	if err != nil {
+		return nil, err
+	}
+
+	consumidos := make(map[string]int)
+	for _, it := range items {
+		if it.IsConsumido() {
+			consumidos[it.CupoDerechoID]++
+		}
+	}
+
+	meses := utils.GetMonthNames()
+	byMes := make(map[int]*PeriodoDiscrepancia)
+	var periodos []*PeriodoDiscrepancia
+	for _, c := range cupos {
+		saldo, tieneLibro := saldos[c.ID]
+		d := models.DiscrepanciaCupo{
+			Cupo:          c,
+			Contador:      c.CupoUsado,
+			Libro:         saldo.Total,
+			Items:         consumidos[c.ID],
+			SinMovimiento: !tieneLibro,
+		}
+		if d.IsConsistente() {
+			continue
+		}
+
+		p, ok := byMes[c.Mes]
+		if !ok {
+			p = &PeriodoDiscrepancia{Gestion: gestion, Mes: c.Mes, MesNombre: meses[c.Mes]}
+			byMes[c.Mes] = p
+			periodos = append(periodos, p)
+		}
+		p.Cupos = append(p.Cupos, d)
+	}
+
+	result := make([]PeriodoDiscrepancia, 0, len(periodos))
+	for _, p := range periodos {
+		result = append(result, *p)
+	}
+	return result, nil
+}
+
+// CorregirPeriodo toma el estado de los ítems como fuente de verdad: asienta un ajuste en el
+// libro por la diferencia y recalcula el contador. Retorna la cantidad de cupos corregidos.
+func (s *CupoLedgerService) CorregirPeriodo(ctx context.Context, gestion, mes int) (int, error) {
+	periodos, err := s.Conciliar(ctx, gestion)
+	if err != nil {
+		return 0, err
+	}
+
+	var cupos []models.DiscrepanciaCupo
+	for _, p := range periodos {
+		if p.Mes == mes {
+			cupos = p.Cupos
+		}
+	}
+	if len(cupos) == 0 {
+		return 0, nil
+	}
+
+	err = s.cupoRepo.WithContext(ctx).RunTransaction(func(cupoRepoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
+		for _, d := range cupos {
+			if ajuste := d.GetAjusteLibro(); ajuste != 0 {
+				obs := fmt.Sprintf("Contador %d, libro %d, ítems %d", d.Contador, d.Libro, d.Items)
+				if err := s.repo.WithTx(tx).Create(ctx, s.ajuste(d.Cupo, ajuste, "CONCILIACION", obs)); err != nil {
+					return err
+				}
+			}
+			if err := s.actualizarContadorTx(ctx, tx, d.Cupo.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	s.auditService.Log(ctx, "CONCILIAR_CUPOS", "cupo_derecho", fmt.Sprintf("%d-%02d", gestion, mes), "", fmt.Sprintf("%d cupo(s) corregido(s)", len(cupos)), "", "")
+	return len(cupos), nil
+}
+
+func consumoItem(it models.CupoDerechoItem) int {
+	if it.IsConsumido() {
+		return 1
+	}
+	return 0
+}
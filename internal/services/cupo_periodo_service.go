@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+	"time"
+)
+
+// CupoPeriodoService genera por adelantado los cupos de derecho del siguiente periodo
+// y avisa a los responsables, sin depender de la generación manual o perezosa.
+type CupoPeriodoService struct {
+	cupoService  *CupoService
+	cupoRepo     *repositories.CupoDerechoRepository
+	userRepo     *repositories.UsuarioRepository
+	notifService *NotificationService
+}
+
+type GeneracionCuposJob struct {
+	Service *CupoPeriodoService
+	// PeriodoActual completa el mes en curso (cambio de periodo) en lugar del siguiente.
+	PeriodoActual bool
+}
+
+func (j *GeneracionCuposJob) Name() string {
+	return "GeneracionCuposJob"
+}
+
+func (j *GeneracionCuposJob) Run(ctx context.Context) error {
+	offset := 1
+	if j.PeriodoActual {
+		offset = 0
+	}
+	_, err := j.Service.GenerarPeriodoRelativo(ctx, offset)
+	return err
+}
+
+// ReporteGeneracionCupos resume el resultado de una generación de periodo.
+type ReporteGeneracionCupos struct {
+	Gestion    int
+	Mes        int
+	Generados  int
+	Existentes int
+	SinDatos   []string
+	Errores    []string
+	Procesados int
+	GeneradoEn time.Time
+}
+
+func (r *ReporteGeneracionCupos) GetPeriodo() string {
+	return fmt.Sprintf("%s %d", utils.GetMonthNames()[r.Mes], r.Gestion)
+}
+
+func NewCupoPeriodoService(
+	cupoService *CupoService,
+	cupoRepo *repositories.CupoDerechoRepository,
+	userRepo *repositories.UsuarioRepository,
+	notifService *NotificationService,
+) *CupoPeriodoService {
+	return &CupoPeriodoService{
+		cupoService:  cupoService,
+		cupoRepo:     cupoRepo,
+		userRepo:     userRepo,
+		notifService: notifService,
+	}
+}
+
+// GenerarPeriodoRelativo genera los cupos del mes actual más offset meses (hora de La Paz).
+func (s *CupoPeriodoService) GenerarPeriodoRelativo(ctx context.Context, offset int) (*ReporteGeneracionCupos, error) {
+	loc, err := time.LoadLocation("America/La_Paz")
+	if err != nil {
+		loc = time.Local
+	}
+	now := time.Now().In(loc)
+	periodo := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc).AddDate(0, offset, 0)
+
+	return s.GenerarPeriodo(ctx, periodo.Year(), int(periodo.Month()))
+}
+
+// GenerarPeriodo crea los cupos faltantes de cada senador titular para el periodo indicado.
+// Es idempotente: los periodos ya existentes solo se completan. Un error en un senador no
+// detiene al resto; se reporta y se notifica a los responsables.
+func (s *CupoPeriodoService) GenerarPeriodo(ctx context.Context, gestion, mes int) (*ReporteGeneracionCupos, error) {
+	log.Printf("[CupoPeriodoService] Generando cupos de derecho para %d/%d...", mes, gestion)
+
+	senadores, err := s.userRepo.FindActiveTitulares(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error al obtener senadores titulares: %w", err)
+	}
+
+	reporte := &ReporteGeneracionCupos{
+		Gestion:    gestion,
+		Mes:        mes,
+		GeneradoEn: time.Now(),
+	}
+
+	for i := range senadores {
+		sen := &senadores[i]
+		reporte.Procesados++
+
+		if faltantes := datosFaltantesCupo(sen); len(faltantes) > 0 {
+			reporte.SinDatos = append(reporte.SinDatos,
+				fmt.Sprintf("%s (sin %s)", sen.GetNombreCompleto(), strings.Join(faltantes, ", ")))
+		}
+
+		_, errExiste := s.cupoRepo.FindByTitularAndPeriodo(ctx, sen.ID, gestion, mes)
+
+		if err := s.cupoService.generateCuposDerechoForSenador(ctx, sen, gestion, mes); err != nil {
+			log.Printf("[CupoPeriodoService] Error generando cupos para %s: %v", sen.GetNombreCompleto(), err)
+			reporte.Errores = append(reporte.Errores, fmt.Sprintf("%s: %v", sen.GetNombreCompleto(), err))
+			continue
+		}
+
+		if errExiste != nil {
+			reporte.Generados++
+		} else {
+			reporte.Existentes++
+		}
+	}
+
+	log.Printf("[CupoPeriodoService] Periodo %d/%d: %d generados, %d existentes, %d sin datos, %d errores",
+		mes, gestion, reporte.Generados, reporte.Existentes, len(reporte.SinDatos), len(reporte.Errores))
+
+	if reporte.Generados > 0 || len(reporte.Errores) > 0 {
+		s.notificarResponsables(ctx, reporte)
+	}
+
+	return reporte, nil
+}
+
+func (s *CupoPeriodoService) notificarResponsables(ctx context.Context, r *ReporteGeneracionCupos) {
+	title := "Nuevo periodo de cupos: " + r.GetPeriodo()
+
+	var sb strings.Builder
+	sb.WriteString("<ul class='list-none space-y-0.5 mt-1'>")
+	fmt.Fprintf(&sb, "<li><strong>Cupos generados:</strong> %d senador(es)</li>", r.Generados)
+	if len(r.SinDatos) > 0 {
+		fmt.Fprintf(&sb, "<li><strong>Sin datos de origen/departamento:</strong> %s</li>", strings.Join(r.SinDatos, "; "))
+	}
+	if len(r.Errores) > 0 {
+		fmt.Fprintf(&sb, "<li><strong>Errores:</strong> %s</li>", strings.Join(r.Errores, "; "))
+	}
+	sb.WriteString("</ul>")
+
+	targetURL := fmt.Sprintf("/admin/cupos?gestion=%d&mes=%d", r.Gestion, r.Mes)
+	if err := s.notifService.NotifyAdmins(ctx, title, sb.String(), "cupo_periodo", targetURL); err != nil {
+		log.Printf("[CupoPeriodoService] Error notificando nuevo periodo: %v", err)
+	}
+}
+
+// datosFaltantesCupo lista los datos que el senador necesita para solicitar pasajes de derecho.
+func datosFaltantesCupo(u *models.Usuario) []string {
+	var faltantes []string
+	if u.OrigenIATA == nil || *u.OrigenIATA == "" {
+		faltantes = append(faltantes, "origen")
+	}
+	if u.DepartamentoCode == nil || *u.DepartamentoCode == "" {
+		faltantes = append(faltantes, "departamento")
+	}
+	return faltantes
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 	"sistema-pasajes/internal/models"
 	"sistema-pasajes/internal/repositories"
 	"sistema-pasajes/internal/utils"
@@ -19,6 +20,11 @@ type CupoService struct {
 	userRepo      *repositories.UsuarioRepository
 	itemRepo      *repositories.CupoDerechoItemRepository
 	solicitudRepo *repositories.SolicitudRepository
+	politicaRepo  *repositories.PoliticaCupoRepository
+	transferRepo  *repositories.TransferenciaCupoRepository
+	notifService  *NotificationService
+	auditService  *AuditService
+	ledger        *CupoLedgerService
 }
 
 type CupoInfo struct {
@@ -34,12 +40,22 @@ func NewCupoService(
 	userRepo *repositories.UsuarioRepository,
 	itemRepo *repositories.CupoDerechoItemRepository,
 	solicitudRepo *repositories.SolicitudRepository,
+	politicaRepo *repositories.PoliticaCupoRepository,
+	transferRepo *repositories.TransferenciaCupoRepository,
+	notifService *NotificationService,
+	auditService *AuditService,
+	ledger *CupoLedgerService,
 ) *CupoService {
 	return &CupoService{
 		repo:          repo,
 		userRepo:      userRepo,
 		itemRepo:      itemRepo,
 		solicitudRepo: solicitudRepo,
+		politicaRepo:  politicaRepo,
+		transferRepo:  transferRepo,
+		notifService:  notifService,
+		auditService:  auditService,
+		ledger:        ledger,
 	}
 }
 
@@ -124,25 +140,42 @@ func (s *CupoService) GenerateCuposDerechoForMonth(ctx context.Context, gestion
 
 func (s *CupoService) generateCuposDerechoForSenador(ctx context.Context, user *models.Usuario, gestion int, mes int) error {
 	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
-		return s.generateCuposDerechoTx(ctx, repoTx, s.itemRepo.WithTx(tx), user, gestion, mes)
+		return s.generateCuposDerechoTx(ctx, repoTx, tx, user, gestion, mes)
 	})
 }
 
-func (s *CupoService) generateCuposDerechoTx(ctx context.Context, cupoRepoTx *repositories.CupoDerechoRepository, itemRepoTx *repositories.CupoDerechoItemRepository, user *models.Usuario, gestion, mes int) error {
+func (s *CupoService) generateCuposDerechoTx(ctx context.Context, cupoRepoTx *repositories.CupoDerechoRepository, tx *gorm.DB, user *models.Usuario, gestion, mes int) error {
+	itemRepoTx := s.itemRepo.WithTx(tx)
 	weeksInfo := utils.GetWeeksInMonth(gestion, time.Month(mes))
 	semanas := len(weeksInfo)
 	if semanas == 0 {
 		return fmt.Errorf("error calculando semanas para %d/%d", mes, gestion)
 	}
 
+	// Los ajustes, el arrastre y la política asociada al mes son los vigentes al cierre del mes;
+	// cada semana se rige por la política en vigor en su propio inicio.
+	politicaEn := s.politicasPorFecha(ctx)
+	politica := politicaEn(finDeMes(gestion, mes))
+	arrastre, err := s.calcularArrastreTx(ctx, itemRepoTx, politica, user.ID, gestion, mes)
+	if err != nil {
+		return err
+	}
+	plan := s.PlanificarCupos(politicaEn, user, gestion, mes, arrastre)
+
+	var politicaID *string
+	if !politica.IsDefault() {
+		politicaID = &politica.ID
+	}
+
 	cupo, err := cupoRepoTx.WithContext(ctx).FindByTitularAndPeriodo(ctx, user.ID, gestion, mes)
 	if err != nil {
 		newCupo := models.CupoDerecho{
-			SenTitularID: user.ID,
-			Gestion:      gestion,
-			Mes:          mes,
-			TotalSemanas: semanas,
-			CupoTotal:    semanas,
+			SenTitularID:   user.ID,
+			Gestion:        gestion,
+			Mes:            mes,
+			TotalSemanas:   semanas,
+			CupoTotal:      len(plan),
+			PoliticaCupoID: politicaID,
 		}
 		if err := cupoRepoTx.WithContext(ctx).Create(ctx, &newCupo); err != nil {
 			return err
@@ -155,24 +188,46 @@ func (s *CupoService) generateCuposDerechoTx(ctx context.Context, cupoRepoTx *re
 		return err
 	}
 
+	enPlan := make(map[string]bool, len(plan))
+	for _, pc := range plan {
+		enPlan[pc.Semana] = true
+	}
+
+	// Los cupos que ya no forman parte del plan se eliminan si nadie los tocó; los usados,
+	// reservados o transferidos se conservan y siguen contando en el total.
 	existingWeeks := make(map[string]bool)
+	conservados := 0
 	for _, it := range items {
-		existingWeeks[it.Semana] = true
+		if enPlan[it.Semana] {
+			existingWeeks[it.Semana] = true
+			continue
+		}
+		removible, err := s.esCupoRemovibleTx(ctx, tx, it)
+		if err != nil {
+			return err
+		}
+		if !removible {
+			conservados++
+			continue
+		}
+		if err := itemRepoTx.WithContext(ctx).Delete(ctx, &it); err != nil {
+			return err
+		}
 	}
 
 	var newItems []models.CupoDerechoItem
-	for i, w := range weeksInfo {
-		semanaKey := fmt.Sprintf("Semana %d", i+1)
-		if !existingWeeks[semanaKey] {
+	for _, pc := range plan {
+		if !existingWeeks[pc.Semana] {
+			desde, hasta := pc.Desde, pc.Hasta
 			it := models.CupoDerechoItem{
 				CupoDerechoID:           cupo.ID,
 				SenTitularID:            user.ID,
 				SenAsignadoID:           user.ID,
-				Semana:                  semanaKey,
+				Semana:                  pc.Semana,
 				Gestion:                 gestion,
 				Mes:                     mes,
-				FechaDesde:              &w.Inicio,
-				FechaHasta:              &w.Fin,
+				FechaDesde:              &desde,
+				FechaHasta:              &hasta,
 				EstadoCupoDerechoCodigo: "DISPONIBLE",
 			}
 			newItems = append(newItems, it)
@@ -185,99 +240,506 @@ func (s *CupoService) generateCuposDerechoTx(ctx context.Context, cupoRepoTx *re
 		}
 	}
 
+	total := len(plan) + conservados
+	if cupo.CupoTotal != total || !equalPtr(cupo.PoliticaCupoID, politicaID) {
+		cupo.CupoTotal = total
+		cupo.PoliticaCupoID = politicaID
+		if err := cupoRepoTx.WithContext(ctx).Update(ctx, cupo); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func (s *CupoService) TransferirCupoDerecho(ctx context.Context, itemID string, targetUserID string, motivo string) error {
-	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
-		itemRepoTx := s.itemRepo.WithTx(tx)
-		item, err := itemRepoTx.WithContext(ctx).FindByID(ctx, itemID)
-		if err != nil {
-			return err
+// esCupoRemovibleTx indica si un cupo fuera del plan puede eliminarse: sigue disponible para
+// su titular y no tiene transferencias realizadas ni pendientes.
+func (s *CupoService) esCupoRemovibleTx(ctx context.Context, tx *gorm.DB, it models.CupoDerechoItem) (bool, error) {
+	if !it.IsDisponible() || it.EsTransferido || it.SenAsignadoID != it.SenTitularID {
+		return false, nil
+	}
+	pendiente, err := s.transferRepo.WithTx(tx).ExistsPendienteByItem(ctx, it.ID)
+	if err != nil {
+		return false, err
+	}
+	return !pendiente, nil
+}
+
+// PlanCupo es un cupo que la política en vigor asigna a un periodo.
+type PlanCupo struct {
+	Semana string
+	Desde  time.Time
+	Hasta  time.Time
+}
+
+// GetPoliticaEn retorna la política en vigor en la fecha o la estándar si no hay ninguna.
+func (s *CupoService) GetPoliticaEn(ctx context.Context, fecha time.Time) *models.PoliticaCupo {
+	politica, err := s.politicaRepo.FindVigente(ctx, fecha)
+	if err != nil || politica == nil {
+		return models.PoliticaCupoDefault()
+	}
+	return politica
+}
+
+// GetPoliticaVigente retorna la política que rige el mes en su conjunto (ajustes y arrastre):
+// la vigente al cierre del periodo.
+func (s *CupoService) GetPoliticaVigente(ctx context.Context, gestion, mes int) *models.PoliticaCupo {
+	return s.GetPoliticaEn(ctx, finDeMes(gestion, mes))
+}
+
+// GetPoliticaItem retorna la política que rige el cupo: la vigente al inicio de su semana o,
+// para adicionales y arrastres, la del mes.
+func (s *CupoService) GetPoliticaItem(ctx context.Context, it models.CupoDerechoItem) *models.PoliticaCupo {
+	return s.GetPoliticaEn(ctx, fechaPolitica(it))
+}
+
+// politicasPorFecha devuelve una búsqueda de la política en vigor por fecha que reutiliza
+// las ya consultadas.
+func (s *CupoService) politicasPorFecha(ctx context.Context) func(time.Time) *models.PoliticaCupo {
+	cache := make(map[string]*models.PoliticaCupo)
+	return func(fecha time.Time) *models.PoliticaCupo {
+		key := fecha.Format("2006-01-02")
+		if p, ok := cache[key]; ok {
+			return p
 		}
-		if item.EstadoCupoDerechoCodigo != "DISPONIBLE" {
-			return errors.New("el cupo no está disponible para transferir")
+		p := s.GetPoliticaEn(ctx, fecha)
+		cache[key] = p
+		return p
+	}
+}
+
+func fechaPolitica(it models.CupoDerechoItem) time.Time {
+	if it.IsSemanal() && it.FechaDesde != nil {
+		return *it.FechaDesde
+	}
+	return finDeMes(it.Gestion, it.Mes)
+}
+
+func finDeMes(gestion, mes int) time.Time {
+	return time.Date(gestion, time.Month(mes), 1, 0, 0, 0, 0, time.Local).AddDate(0, 1, -1)
+}
+
+// PlanificarCupos aplica las políticas al calendario del mes: cada semana se evalúa con la
+// política vigente en su inicio (receso, semana bloqueada, semanas por mes) y los ajustes y el
+// arrastre con la del cierre del mes.
+func (s *CupoService) PlanificarCupos(politicaEn func(time.Time) *models.PoliticaCupo, user *models.Usuario, gestion, mes, arrastre int) []PlanCupo {
+	var plan []PlanCupo
+	for i, w := range utils.GetWeeksInMonth(gestion, time.Month(mes)) {
+		p := politicaEn(w.Inicio)
+		if p.IsMesReceso(mes) || p.IsSemanaBloqueada(w.Inicio) {
+			continue
 		}
+		if p.SemanasPorMes > 0 && len(plan) >= p.SemanasPorMes {
+			continue
+		}
+		plan = append(plan, PlanCupo{Semana: fmt.Sprintf("Semana %d", i+1), Desde: w.Inicio, Hasta: w.Fin})
+	}
 
-		item.SenAsignadoID = targetUserID
-		item.EsTransferido = true
-		item.MotivoTransfer = motivo
-		nowTransfer := time.Now()
-		item.FechaTransfer = &nowTransfer
+	inicioMes := time.Date(gestion, time.Month(mes), 1, 0, 0, 0, 0, time.Local)
+	finMes := finDeMes(gestion, mes)
+	politica := politicaEn(finMes)
+	if politica.IsMesReceso(mes) {
+		return plan
+	}
 
-		if err := itemRepoTx.WithContext(ctx).Update(ctx, item); err != nil {
-			return err
+	delta := politica.GetDelta(user)
+	if delta < 0 {
+		plan = plan[:max(len(plan)+delta, 0)]
+	}
+	for i := 1; i <= delta; i++ {
+		plan = append(plan, PlanCupo{Semana: fmt.Sprintf("Adicional %d", i), Desde: inicioMes, Hasta: finMes})
+	}
+	for i := 1; i <= arrastre; i++ {
+		plan = append(plan, PlanCupo{Semana: fmt.Sprintf("Arrastre %d", i), Desde: inicioMes, Hasta: finMes})
+	}
+
+	return plan
+}
+
+// calcularArrastreTx cuenta los cupos no usados ni transferidos del mes anterior que la política permite arrastrar.
+func (s *CupoService) calcularArrastreTx(ctx context.Context, itemRepo *repositories.CupoDerechoItemRepository, politica *models.PoliticaCupo, titularID string, gestion, mes int) (int, error) {
+	if !politica.PermiteArrastre || politica.ArrastreMaximo <= 0 {
+		return 0, nil
+	}
+
+	anterior := time.Date(gestion, time.Month(mes), 1, 0, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	items, err := itemRepo.WithContext(ctx).FindByHolderAndPeriodo(ctx, titularID, anterior.Year(), int(anterior.Month()))
+	if err != nil {
+		return 0, err
+	}
+
+	libres := 0
+	for _, it := range items {
+		if it.IsDisponible() && !it.EsTransferido && it.IsSemanal() {
+			libres++
 		}
+	}
+	return min(libres, politica.ArrastreMaximo), nil
+}
 
-		return s.syncCupoUsadoTx(ctx, repoTx, itemRepoTx, item.SenTitularID, item.Gestion, item.Mes)
-	})
+func equalPtr(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
-func (s *CupoService) RevertirTransferencia(ctx context.Context, itemID string) error {
+// TransferirCupoDerecho aplica de inmediato una transferencia realizada por un responsable,
+// el encargado o el propio suplente, dejando constancia en el historial.
+func (s *CupoService) TransferirCupoDerecho(ctx context.Context, itemID string, targetUserID string, motivo string, actor *models.Usuario) error {
 	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
-		itemRepoTx := s.itemRepo.WithTx(tx)
-		item, err := itemRepoTx.WithContext(ctx).FindByID(ctx, itemID)
+		mov := &models.TransferenciaCupo{SolicitanteID: actor.ID, Motivo: motivo}
+		if actor.IsAdminOrResponsable() {
+			mov.AprobadorID = &actor.ID
+		}
+		return s.transferirTx(ctx, tx, itemID, targetUserID, mov)
+	})
+}
+
+func (s *CupoService) transferirTx(ctx context.Context, tx *gorm.DB, itemID, targetUserID string, mov *models.TransferenciaCupo) error {
+	itemRepoTx := s.itemRepo.WithTx(tx)
+	if err := itemRepoTx.LockByID(ctx, itemID); err != nil {
+		return err
+	}
+	item, err := itemRepoTx.WithContext(ctx).FindByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	transferRepoTx := s.transferRepo.WithTx(tx)
+	if mov.ID != "" {
+		// Una aprobación concurrente pudo resolver la solicitud después de leerla.
+		actual, err := transferRepoTx.FindByID(ctx, mov.ID)
 		if err != nil {
 			return err
 		}
-		if !item.EsTransferido {
-			return errors.New("el cupo no está en estado transferido")
+		if !actual.IsPendiente() {
+			return errors.New("la solicitud de transferencia ya fue resuelta")
 		}
+	}
+	if otra, err := transferRepoTx.ExistsOtraPendienteByItem(ctx, itemID, mov.ID); err != nil {
+		return err
+	} else if otra {
+		return errors.New("ya existe una solicitud de transferencia pendiente para este cupo; resuélvala primero")
+	}
+	if item.EstadoCupoDerechoCodigo != "DISPONIBLE" {
+		return errors.New("el cupo no está disponible para transferir")
+	}
+	if item.SenAsignadoID == targetUserID {
+		return errors.New("el cupo ya está asignado a ese senador")
+	}
 
-		item.SenAsignadoID = item.SenTitularID
-		item.EsTransferido = false
-		item.MotivoTransfer = ""
-		item.FechaTransfer = nil
+	antes := *item
+	nowTransfer := time.Now()
+	mov.CupoDerechoItemID = item.ID
+	mov.Tipo = models.TipoTransferenciaCupoTransferencia
+	mov.Estado = models.EstadoTransferenciaAprobada
+	mov.DeUsuarioID = item.SenAsignadoID
+	mov.ParaUsuarioID = targetUserID
+	mov.FechaResolucion = &nowTransfer
+	if mov.FechaSolicitud.IsZero() {
+		mov.FechaSolicitud = nowTransfer
+	}
 
-		if err := itemRepoTx.WithContext(ctx).Update(ctx, item); err != nil {
-			return err
-		}
+	item.SenAsignadoID = targetUserID
+	item.EsTransferido = true
+	item.MotivoTransfer = mov.Motivo
+	item.FechaTransfer = &nowTransfer
+
+	if err := itemRepoTx.WithContext(ctx).Update(ctx, item); err != nil {
+		return err
+	}
 
-		return s.syncCupoUsadoTx(ctx, repoTx, itemRepoTx, item.SenTitularID, item.Gestion, item.Mes)
+	if mov.ID == "" {
+		err = transferRepoTx.Create(ctx, mov)
+	} else {
+		err = transferRepoTx.Update(ctx, mov)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.ledger.RegistrarTx(ctx, tx, antes, *item, "TRANSFERIR_CUPO", nil, nil); err != nil {
+		return err
+	}
+
+	return s.syncCupoUsadoTx(ctx, tx, item.SenTitularID, item.Gestion, item.Mes)
+}
+
+func (s *CupoService) RevertirTransferencia(ctx context.Context, itemID string, actor *models.Usuario) error {
+	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
+		mov := &models.TransferenciaCupo{
+			SolicitanteID: actor.ID,
+			AprobadorID:   &actor.ID,
+			Motivo:        "Reversión de transferencia",
+		}
+		return s.revertirTx(ctx, tx, itemID, mov)
 	})
 }
 
-func (s *CupoService) ProcesarConsumoPasaje(ctx context.Context, usuarioID string, gestion, mes int) error {
-	return s.SyncCupoUsado(ctx, usuarioID, gestion, mes)
+func (s *CupoService) revertirTx(ctx context.Context, tx *gorm.DB, itemID string, mov *models.TransferenciaCupo) error {
+	itemRepoTx := s.itemRepo.WithTx(tx)
+	item, err := itemRepoTx.WithContext(ctx).FindByID(ctx, itemID)
+	if err != nil {
+		return err
+	}
+	if !item.EsTransferido {
+		return errors.New("el cupo no está en estado transferido")
+	}
+
+	now := time.Now()
+	mov.CupoDerechoItemID = item.ID
+	mov.Tipo = models.TipoTransferenciaCupoReversion
+	mov.Estado = models.EstadoTransferenciaAprobada
+	mov.DeUsuarioID = item.SenAsignadoID
+	mov.ParaUsuarioID = item.SenTitularID
+	mov.FechaSolicitud = now
+	mov.FechaResolucion = &now
+
+	antes := *item
+	item.SenAsignadoID = item.SenTitularID
+	item.EsTransferido = false
+	item.MotivoTransfer = ""
+	item.FechaTransfer = nil
+
+	if err := itemRepoTx.WithContext(ctx).Update(ctx, item); err != nil {
+		return err
+	}
+	if err := s.transferRepo.WithTx(tx).Create(ctx, mov); err != nil {
+		return err
+	}
+	if err := s.ledger.RegistrarTx(ctx, tx, antes, *item, "REVERTIR_TRANSFERENCIA", nil, nil); err != nil {
+		return err
+	}
+
+	return s.syncCupoUsadoTx(ctx, tx, item.SenTitularID, item.Gestion, item.Mes)
 }
 
-func (s *CupoService) GetAllByPeriodo(ctx context.Context, gestion, mes int) ([]models.CupoDerecho, error) {
-	return s.repo.FindByPeriodo(ctx, gestion, mes)
+// AsignarCuposLicencia transfiere al suplente los cupos disponibles del titular cuyas fechas
+// caen dentro de la licencia. Es idempotente: los ya asignados se omiten.
+func (s *CupoService) AsignarCuposLicencia(ctx context.Context, lic *models.LicenciaSenador) ([]models.CupoDerechoItem, error) {
+	items, err := s.itemRepo.WithContext(ctx).FindForTitularByRango(ctx, lic.TitularID, lic.FechaDesde, lic.FechaHasta.Add(24*time.Hour-time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	var asignados []models.CupoDerechoItem
+	for _, it := range items {
+		if !it.IsDisponible() || it.SenAsignadoID != lic.TitularID {
+			continue
+		}
+		if s.GetPoliticaItem(ctx, it).BloqueaItem(it) {
+			continue
+		}
+
+		mov := s.movimientoLicencia(lic, "Licencia del titular")
+		err := s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
+			return s.transferirTx(ctx, tx, it.ID, lic.SuplenteID, mov)
+		})
+		if err != nil {
+			log.Printf("[Licencia %s] No se pudo asignar el cupo %s: %v", lic.ID, it.ID, err)
+			continue
+		}
+		asignados = append(asignados, it)
+	}
+	return asignados, nil
 }
 
-func (s *CupoService) GetByID(ctx context.Context, id string) (*models.CupoDerecho, error) {
-	return s.repo.FindByID(ctx, id)
+// RevertirCuposLicencia devuelve al titular los cupos que la licencia asignó al suplente y que
+// siguen sin usarse.
+func (s *CupoService) RevertirCuposLicencia(ctx context.Context, lic *models.LicenciaSenador) ([]models.CupoDerechoItem, error) {
+	movs, err := s.transferRepo.FindByLicencia(ctx, lic.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	vistos := make(map[string]bool)
+	var revertidos []models.CupoDerechoItem
+	for _, m := range movs {
+		if m.Tipo != models.TipoTransferenciaCupoTransferencia || vistos[m.CupoDerechoItemID] {
+			continue
+		}
+		vistos[m.CupoDerechoItemID] = true
+
+		item, err := s.itemRepo.WithContext(ctx).FindByID(ctx, m.CupoDerechoItemID)
+		if err != nil || !item.IsDisponible() || item.SenAsignadoID != lic.SuplenteID {
+			continue
+		}
+
+		mov := s.movimientoLicencia(lic, "Fin de licencia del titular")
+		err = s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
+			return s.revertirTx(ctx, tx, item.ID, mov)
+		})
+		if err != nil {
+			log.Printf("[Licencia %s] No se pudo revertir el cupo %s: %v", lic.ID, item.ID, err)
+			continue
+		}
+		revertidos = append(revertidos, *item)
+	}
+	return revertidos, nil
 }
 
-func (s *CupoService) GetAllCuposDerechoByPeriodo(ctx context.Context, gestion, mes int) ([]models.CupoDerechoItem, error) {
-	return s.itemRepo.FindByPeriodo(ctx, gestion, mes)
+func (s *CupoService) movimientoLicencia(lic *models.LicenciaSenador, motivo string) *models.TransferenciaCupo {
+	solicitante := lic.TitularID
+	if lic.CreatedBy != nil {
+		solicitante = *lic.CreatedBy
+	}
+	licID := lic.ID
+	return &models.TransferenciaCupo{
+		SolicitanteID: solicitante,
+		Motivo:        motivo,
+		LicenciaID:    &licID,
+	}
 }
 
-func (s *CupoService) GetCupo(ctx context.Context, usuarioID string, gestion, mes int) (*models.CupoDerecho, error) {
-	return s.repo.FindByTitularAndPeriodo(ctx, usuarioID, gestion, mes)
+// SolicitarTransferencia registra el pedido del titular (o su encargado) para ceder un cupo.
+// No surte efecto hasta que un responsable lo apruebe.
+func (s *CupoService) SolicitarTransferencia(ctx context.Context, itemID, targetUserID, motivo string, actor *models.Usuario) (*models.TransferenciaCupo, error) {
+	item, err := s.itemRepo.WithContext(ctx).FindByID(ctx, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	titular, err := s.userRepo.FindByID(ctx, item.SenTitularID)
+	if err != nil {
+		return nil, err
+	}
+	if actor.ID != titular.ID && !titular.IsManagedBy(actor) {
+		return nil, errors.New("solo el titular del cupo o su encargado pueden solicitar la transferencia")
+	}
+	if !item.IsDisponible() {
+		return nil, errors.New("el cupo no está disponible para transferir")
+	}
+	if item.IsVencido() {
+		return nil, errors.New("no se puede transferir un cupo vencido")
+	}
+	if item.SenAsignadoID == targetUserID {
+		return nil, errors.New("el cupo ya está asignado a ese senador")
+	}
+	if pendiente, _ := s.transferRepo.ExistsPendienteByItem(ctx, itemID); pendiente {
+		return nil, errors.New("ya existe una solicitud de transferencia pendiente para este cupo")
+	}
+
+	target, err := s.userRepo.FindByID(ctx, targetUserID)
+	if err != nil || !target.IsSenador() {
+		return nil, errors.New("el destinatario debe ser un senador")
+	}
+
+	mov := &models.TransferenciaCupo{
+		CupoDerechoItemID: item.ID,
+		Tipo:              models.TipoTransferenciaCupoTransferencia,
+		Estado:            models.EstadoTransferenciaPendiente,
+		DeUsuarioID:       item.SenAsignadoID,
+		ParaUsuarioID:     targetUserID,
+		SolicitanteID:     actor.ID,
+		Motivo:            motivo,
+		FechaSolicitud:    time.Now(),
+	}
+	if err := s.transferRepo.Create(ctx, mov); err != nil {
+		return nil, err
+	}
+	s.auditService.Log(ctx, "SOLICITAR_TRANSFERENCIA_CUPO", "transferencia_cupo", mov.ID, "", target.GetNombreResumido()+": "+motivo, "", "")
+
+	s.notifService.NotifyAdmins(ctx,
+		"Solicitud de transferencia de cupo",
+		fmt.Sprintf("<ul class='list-none space-y-0.5 mt-1'><li><strong>De:</strong> %s</li><li><strong>Para:</strong> %s</li><li><strong>Cupo:</strong> %s</li></ul>",
+			titular.GetNombreResumido(), target.GetNombreResumido(), item.GetWeekLabel()),
+		"cupo_transferencia",
+		"/admin/cupos/transferencias",
+	)
+
+	return mov, nil
 }
 
-func (s *CupoService) IncrementarUso(ctx context.Context, usuarioID string, gestion, mes int) error {
-	cupo, err := s.repo.FindByTitularAndPeriodo(ctx, usuarioID, gestion, mes)
+func (s *CupoService) AprobarTransferencia(ctx context.Context, id string, actor *models.Usuario) error {
+	mov, err := s.transferRepo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !mov.IsPendiente() {
+		return errors.New("la solicitud de transferencia ya fue resuelta")
+	}
+
+	mov.AprobadorID = &actor.ID
+	err = s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
+		return s.transferirTx(ctx, tx, mov.CupoDerechoItemID, mov.ParaUsuarioID, mov)
+	})
 	if err != nil {
 		return err
 	}
-	cupo.CupoUsado++
-	return s.repo.Update(ctx, cupo)
+
+	s.auditService.Log(ctx, "APROBAR_TRANSFERENCIA_CUPO", "transferencia_cupo", mov.ID, string(models.EstadoTransferenciaPendiente), string(mov.Estado), "", "")
+	s.notificarResolucion(ctx, mov, "aprobada")
+	return nil
 }
 
-func (s *CupoService) RevertirUso(ctx context.Context, usuarioID string, gestion, mes int) error {
-	cupo, err := s.repo.FindByTitularAndPeriodo(ctx, usuarioID, gestion, mes)
+func (s *CupoService) RechazarTransferencia(ctx context.Context, id, observacion string, actor *models.Usuario) error {
+	mov, err := s.transferRepo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
-	if cupo.CupoUsado > 0 {
-		cupo.CupoUsado--
-		return s.repo.Update(ctx, cupo)
+	if !mov.IsPendiente() {
+		return errors.New("la solicitud de transferencia ya fue resuelta")
 	}
+
+	now := time.Now()
+	mov.Estado = models.EstadoTransferenciaRechazada
+	mov.AprobadorID = &actor.ID
+	mov.FechaResolucion = &now
+	mov.ObservacionRechazo = observacion
+	if err := s.transferRepo.Update(ctx, mov); err != nil {
+		return err
+	}
+
+	s.auditService.Log(ctx, "RECHAZAR_TRANSFERENCIA_CUPO", "transferencia_cupo", mov.ID, string(models.EstadoTransferenciaPendiente), string(mov.Estado)+": "+observacion, "", "")
+	s.notificarResolucion(ctx, mov, "rechazada")
 	return nil
 }
 
+func (s *CupoService) notificarResolucion(ctx context.Context, mov *models.TransferenciaCupo, resultado string) {
+	para := mov.ParaUsuarioID
+	if mov.ParaUsuario != nil {
+		para = mov.ParaUsuario.GetNombreResumido()
+	}
+	s.notifService.NotifyUser(ctx, mov.SolicitanteID,
+		"Transferencia de cupo "+resultado,
+		fmt.Sprintf("Su solicitud de transferencia a %s fue %s.", para, resultado),
+		"cupo_transferencia",
+		fmt.Sprintf("/cupos/derecho/%s/%d", mov.DeUsuarioID, time.Now().Year()),
+	)
+}
+
+func (s *CupoService) GetTransferenciasPendientes(ctx context.Context) ([]models.TransferenciaCupo, error) {
+	return s.transferRepo.FindPendientes(ctx)
+}
+
+func (s *CupoService) GetTransferenciasRecientes(ctx context.Context, limit int) ([]models.TransferenciaCupo, error) {
+	return s.transferRepo.FindRecientes(ctx, limit)
+}
+
+func (s *CupoService) GetHistorialTransferencias(ctx context.Context, itemID string) ([]models.TransferenciaCupo, error) {
+	return s.transferRepo.FindByItemID(ctx, itemID)
+}
+
+func (s *CupoService) ProcesarConsumoPasaje(ctx context.Context, usuarioID string, gestion, mes int) error {
+	return s.SyncCupoUsado(ctx, usuarioID, gestion, mes)
+}
+
+func (s *CupoService) GetAllByPeriodo(ctx context.Context, gestion, mes int) ([]models.CupoDerecho, error) {
+	return s.repo.FindByPeriodo(ctx, gestion, mes)
+}
+
+func (s *CupoService) GetByID(ctx context.Context, id string) (*models.CupoDerecho, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+func (s *CupoService) GetAllCuposDerechoByPeriodo(ctx context.Context, gestion, mes int) ([]models.CupoDerechoItem, error) {
+	return s.itemRepo.FindByPeriodo(ctx, gestion, mes)
+}
+
+func (s *CupoService) GetCupo(ctx context.Context, usuarioID string, gestion, mes int) (*models.CupoDerecho, error) {
+	return s.repo.FindByTitularAndPeriodo(ctx, usuarioID, gestion, mes)
+}
+
 func (s *CupoService) ResetCuposDerechoForMonth(ctx context.Context, gestion, mes int) error {
 	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
 		itemRepoTx := s.itemRepo.WithTx(tx)
@@ -285,6 +747,9 @@ func (s *CupoService) ResetCuposDerechoForMonth(ctx context.Context, gestion, me
 		if err != nil {
 			return err
 		}
+		if err := s.ledger.AnularPeriodoTx(ctx, tx, gestion, mes, "RESET_PERIODO"); err != nil {
+			return err
+		}
 		for _, it := range items {
 			if err := itemRepoTx.WithContext(ctx).DeleteUnscoped(ctx, &it); err != nil {
 				return err
@@ -310,34 +775,42 @@ func (s *CupoService) SyncUsoForPeriod(ctx context.Context, gestion, mes int) er
 
 func (s *CupoService) SyncCupoUsado(ctx context.Context, senadorID string, gestion, mes int) error {
 	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.CupoDerechoRepository, tx *gorm.DB) error {
-		return s.syncCupoUsadoTx(ctx, repoTx, s.itemRepo.WithTx(tx), senadorID, gestion, mes)
+		return s.syncCupoUsadoTx(ctx, tx, senadorID, gestion, mes)
 	})
 }
 
-func (s *CupoService) syncCupoUsadoTx(ctx context.Context, cupoRepo *repositories.CupoDerechoRepository, itemRepo *repositories.CupoDerechoItemRepository, senadorID string, gestion, mes int) error {
+// syncCupoUsadoTx recalcula el total del periodo a partir de sus ítems y toma el consumo
+// del libro de movimientos.
+func (s *CupoService) syncCupoUsadoTx(ctx context.Context, tx *gorm.DB, senadorID string, gestion, mes int) error {
+	cupoRepo := s.repo.WithTx(tx)
 	cupo, err := cupoRepo.WithContext(ctx).FindByTitularAndPeriodo(ctx, senadorID, gestion, mes)
 	if err != nil {
 		return err
 	}
 
-	items, err := itemRepo.WithContext(ctx).FindByHolderAndPeriodo(ctx, senadorID, gestion, mes)
+	items, err := s.itemRepo.WithTx(tx).WithContext(ctx).FindByCupoDerechoID(ctx, cupo.ID)
 	if err != nil {
 		return err
 	}
 
-	availableCount := 0
+	// La política en vigor puede dejar sin efecto cupos aún disponibles (receso o semana bloqueada).
+	politicaEn := s.politicasPorFecha(ctx)
+
+	total := 0
 	for _, it := range items {
-		if it.EstadoCupoDerechoCodigo == "DISPONIBLE" {
-			availableCount++
+		if it.IsDisponible() && politicaEn(fechaPolitica(it)).BloqueaItem(it) {
+			continue
 		}
+		total++
 	}
 
-	used := cupo.CupoTotal - availableCount
-	if used < 0 {
-		used = 0
+	used, err := s.ledger.UsadoTx(ctx, tx, cupo)
+	if err != nil {
+		return err
 	}
 
-	if cupo.CupoUsado != used {
+	if cupo.CupoUsado != used || cupo.CupoTotal != total {
+		cupo.CupoTotal = total
 		cupo.CupoUsado = used
 		return cupoRepo.WithContext(ctx).Update(ctx, cupo)
 	}
@@ -450,7 +923,9 @@ func (s *CupoService) CanTransferCupo(ctx context.Context, authUser *models.Usua
 
 type CupoDerechoItemView struct {
 	models.CupoDerechoItem
-	Permissions models.CupoDerechoItemPermissions
+	Permissions            models.CupoDerechoItemPermissions
+	Bloqueado              bool
+	TransferenciaPendiente *models.TransferenciaCupo
 }
 
 type MonthGroup struct {
@@ -459,6 +934,7 @@ type MonthGroup struct {
 	Items            []CupoDerechoItemView
 	SuplenteHasQuota bool
 	TargetHasQuota   bool
+	Politica         *models.PoliticaCupo
 }
 
 func (s *CupoService) BuildMonthGroups(items []models.CupoDerechoItem, targetUser, authUser *models.Usuario) []*MonthGroup {
@@ -494,6 +970,70 @@ func (s *CupoService) BuildMonthGroups(items []models.CupoDerechoItem, targetUse
 	return grouped
 }
 
+// ApplyPoliticas asocia a cada mes la política en vigor y deshabilita los cupos que bloquea la
+// política vigente en la fecha de cada uno.
+func (s *CupoService) ApplyPoliticas(ctx context.Context, monthGroups []*MonthGroup, gestion int) {
+	politicaEn := s.politicasPorFecha(ctx)
+	for _, mg := range monthGroups {
+		if mg == nil || len(mg.Items) == 0 {
+			continue
+		}
+		mg.Politica = politicaEn(finDeMes(gestion, mg.MonthNum))
+		for j := range mg.Items {
+			item := &mg.Items[j]
+			if !item.IsDisponible() || !politicaEn(fechaPolitica(item.CupoDerechoItem)).BloqueaItem(item.CupoDerechoItem) {
+				continue
+			}
+			item.Bloqueado = true
+			item.Permissions.CanCreate = false
+			item.Permissions.CanCreateIdaVuelta = false
+			item.Permissions.CanTomarCupo = false
+			item.Permissions.CanAsignarCupo = false
+			item.Permissions.CanTransfer = false
+			item.Permissions.CanSolicitarTransf = false
+		}
+	}
+}
+
+// ApplyTransferenciasPendientes marca los cupos con una solicitud de transferencia en espera
+// de aprobación para evitar que se soliciten dos veces.
+func (s *CupoService) ApplyTransferenciasPendientes(ctx context.Context, monthGroups []*MonthGroup) {
+	var itemIDs []string
+	for _, mg := range monthGroups {
+		if mg == nil {
+			continue
+		}
+		for _, it := range mg.Items {
+			itemIDs = append(itemIDs, it.ID)
+		}
+	}
+	if len(itemIDs) == 0 {
+		return
+	}
+
+	pendientes, err := s.transferRepo.FindPendientesByItemIDs(ctx, itemIDs)
+	if err != nil || len(pendientes) == 0 {
+		return
+	}
+	byItem := make(map[string]*models.TransferenciaCupo, len(pendientes))
+	for i := range pendientes {
+		byItem[pendientes[i].CupoDerechoItemID] = &pendientes[i]
+	}
+
+	for _, mg := range monthGroups {
+		if mg == nil {
+			continue
+		}
+		for j := range mg.Items {
+			item := &mg.Items[j]
+			if mov, ok := byItem[item.ID]; ok {
+				item.TransferenciaPendiente = mov
+				item.Permissions.CanSolicitarTransf = false
+			}
+		}
+	}
+}
+
 func (s *CupoService) GetDisplayMonths(monthGroups []*MonthGroup, gestion int) []*MonthGroup {
 	var displayMonths []*MonthGroup
 	currentYear := time.Now().Year()
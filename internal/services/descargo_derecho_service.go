@@ -19,6 +19,7 @@ type DescargoDerechoService struct {
 	solicitudService *SolicitudService
 	auditService     *AuditService
 	pasajeRepo       *repositories.PasajeRepository
+	adjuntoService   *AdjuntoService
 }
 
 func NewDescargoDerechoService(
@@ -28,6 +29,7 @@ func NewDescargoDerechoService(
 	solicitudService *SolicitudService,
 	auditService *AuditService,
 	pasajeRepo *repositories.PasajeRepository,
+	adjuntoService *AdjuntoService,
 ) *DescargoDerechoService {
 	return &DescargoDerechoService{
 		repo:             repo,
@@ -36,6 +38,7 @@ func NewDescargoDerechoService(
 		solicitudService: solicitudService,
 		auditService:     auditService,
 		pasajeRepo:       pasajeRepo,
+		adjuntoService:   adjuntoService,
 	}
 }
 
@@ -118,6 +121,9 @@ func (s *DescargoDerechoService) SyncItineraryFromSolicitud(ctx context.Context,
 				continue
 			}
 			tipo := models.TipoDescargoTramo(tipoPrefix + "_ORIGINAL")
+			if p.IsReemision() {
+				tipo = models.TipoDescargoTramo(tipoPrefix + "_REPRO")
+			}
 			legs := p.GetTramosLegs()
 			for _, leg := range legs {
 				tVuelo := p.FechaVuelo
@@ -137,6 +143,7 @@ func (s *DescargoDerechoService) SyncItineraryFromSolicitud(ctx context.Context,
 					NumeroVuelo:     "",
 					OrigenIATA:      &orig,
 					DestinoIATA:     &dest,
+					EsModificacion:  p.IsReemitido(),
 					TramoNombre:     leg.GetLabel(),
 					Seq:             seqCounter,
 				})
@@ -161,7 +168,7 @@ func (s *DescargoDerechoService) SyncItineraryFromSolicitud(ctx context.Context,
 	// Indexar los tramos ORIGINALES ya existentes por PasajeID + Tipo + Ruta (Origen/Destino) con soporte de unicidad semántica
 	existingByKey := make(map[string][]models.DescargoTramo)
 	for _, tramoGuardado := range descargo.Tramos {
-		if tramoGuardado.PasajeID != nil && tramoGuardado.IsOriginal() {
+		if tramoGuardado.IsDeEmision() {
 			key := fmt.Sprintf("%s_%s_%s_%s", *tramoGuardado.PasajeID, string(tramoGuardado.Tipo), tramoGuardado.GetOrigenIATA(), tramoGuardado.GetDestinoIATA())
 			existingByKey[key] = append(existingByKey[key], tramoGuardado)
 		}
@@ -182,6 +189,11 @@ func (s *DescargoDerechoService) SyncItineraryFromSolicitud(ctx context.Context,
 
 			// Restaurar el campo volátil (no persistido en DB) para el ViewModel/Template
 			existing.TramoNombre = tramoEmitido.TramoNombre
+			if tramoEmitido.EsModificacion && !existing.EsModificacion {
+				// El billete fue reemitido después de sincronizar
+				existing.EsModificacion = true
+				modified = true
+			}
 
 			tramosOriginalesNuevos = append(tramosOriginalesNuevos, existing)
 		} else {
@@ -204,7 +216,7 @@ func (s *DescargoDerechoService) SyncItineraryFromSolicitud(ctx context.Context,
 
 	tramosNoOriginales := make([]models.DescargoTramo, 0)
 	for _, tramoGuardado := range descargo.Tramos {
-		if !tramoGuardado.IsOriginal() {
+		if !tramoGuardado.IsDeEmision() {
 			tramosNoOriginales = append(tramosNoOriginales, tramoGuardado)
 		}
 	}
@@ -251,6 +263,7 @@ func (s *DescargoDerechoService) UpdateDerecho(ctx context.Context, descargoID s
 
 		// Buscar el pasaje original y actualizar campos financieros
 		if p, err := s.pasajeRepo.FindByID(ctx, pasajeID); err == nil {
+			boletaAnterior, montoAnterior := p.NroBoletaDeposito, p.MontoReembolso
 			p.MontoReembolso = monto
 			p.CostoUtilizado = p.Costo - monto
 			p.NroBoletaDeposito = nroBoleta
@@ -264,6 +277,10 @@ func (s *DescargoDerechoService) UpdateDerecho(ctx context.Context, descargoID s
 				p.ArchivoComprobante = ""
 				p.FechaDeposito = nil
 			}
+			p.SyncEstadoReembolso(boletaAnterior, montoAnterior)
+			if err := s.adjuntoService.VersionarPasaje(ctx, p); err != nil {
+				return err
+			}
 			_ = s.pasajeRepo.Update(ctx, p)
 		}
 	}
@@ -365,7 +382,7 @@ func (s *DescargoDerechoService) UpdateDerecho(ctx context.Context, descargoID s
 
 		// 4. Domain Rule: Data Protection for issued segments
 		if idRow != "" {
-			if original, ok := existingMap[idRow]; ok && original.PasajeID != nil && original.IsOriginal() {
+			if original, ok := existingMap[idRow]; ok && original.IsDeEmision() {
 				// Fields from a pre-issued ticket segment are protected
 				det := models.DescargoTramo{
 					BaseModel:         models.BaseModel{ID: idRow},
@@ -417,6 +434,12 @@ func (s *DescargoDerechoService) UpdateDerecho(ctx context.Context, descargoID s
 	}
 
 	descargo.Tramos = tramosProcesados
+	if err := s.descargoService.VerificarBilletes(ctx, descargo); err != nil {
+		return err
+	}
+	if err := s.adjuntoService.VersionarTramos(ctx, descargo.Tramos, existingMap); err != nil {
+		return err
+	}
 	if err := s.repo.Update(ctx, descargo); err != nil {
 		return err
 	}
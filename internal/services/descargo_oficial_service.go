@@ -18,6 +18,7 @@ type DescargoOficialService struct {
 	solicitudService *SolicitudService
 	auditService     *AuditService
 	pasajeRepo       *repositories.PasajeRepository
+	adjuntoService   *AdjuntoService
 }
 
 func NewDescargoOficialService(
@@ -27,6 +28,7 @@ func NewDescargoOficialService(
 	solicitudService *SolicitudService,
 	auditService *AuditService,
 	pasajeRepo *repositories.PasajeRepository,
+	adjuntoService *AdjuntoService,
 ) *DescargoOficialService {
 	return &DescargoOficialService{
 		repo:             repo,
@@ -35,6 +37,7 @@ func NewDescargoOficialService(
 		solicitudService: solicitudService,
 		auditService:     auditService,
 		pasajeRepo:       pasajeRepo,
+		adjuntoService:   adjuntoService,
 	}
 }
 
@@ -159,6 +162,9 @@ func (s *DescargoOficialService) SyncItineraryFromSolicitud(ctx context.Context,
 				continue
 			}
 			tipo := models.TipoDescargoTramo(tipoPrefix + "_ORIGINAL")
+			if p.IsReemision() {
+				tipo = models.TipoDescargoTramo(tipoPrefix + "_REPRO")
+			}
 			legs := p.GetTramosLegs()
 			for _, leg := range legs {
 				tVuelo := p.FechaVuelo
@@ -178,6 +184,7 @@ func (s *DescargoOficialService) SyncItineraryFromSolicitud(ctx context.Context,
 					NumeroVuelo:     "",
 					OrigenIATA:      &orig,
 					DestinoIATA:     &dest,
+					EsModificacion:  p.IsReemitido(),
 					TramoNombre:     leg.GetLabel(),
 					Seq:             seqCounter,
 				})
@@ -197,7 +204,7 @@ func (s *DescargoOficialService) SyncItineraryFromSolicitud(ctx context.Context,
 	// Esta llave atómica asegura la unicidad semántica de cada pierna del viaje (tramoGuardado)
 	existingByKey := make(map[string][]models.DescargoTramo)
 	for _, tramoGuardado := range descargo.Tramos {
-		if tramoGuardado.PasajeID != nil && tramoGuardado.IsOriginal() {
+		if tramoGuardado.IsDeEmision() {
 			key := fmt.Sprintf("%s_%s_%s_%s", *tramoGuardado.PasajeID, string(tramoGuardado.Tipo), tramoGuardado.GetOrigenIATA(), tramoGuardado.GetDestinoIATA())
 			existingByKey[key] = append(existingByKey[key], tramoGuardado)
 		}
@@ -218,6 +225,11 @@ func (s *DescargoOficialService) SyncItineraryFromSolicitud(ctx context.Context,
 
 			// Restaurar el campo volátil (no persistido en DB) para el ViewModel/Template
 			existing.TramoNombre = tramoEmitido.TramoNombre
+			if tramoEmitido.EsModificacion && !existing.EsModificacion {
+				// El billete fue reemitido después de sincronizar
+				existing.EsModificacion = true
+				modified = true
+			}
 
 			tramosOriginalesNuevos = append(tramosOriginalesNuevos, existing)
 		} else {
@@ -242,7 +254,7 @@ func (s *DescargoOficialService) SyncItineraryFromSolicitud(ctx context.Context,
 	// Reconstruir el slice completo: tramos originales sincronizados + reprogramados + devoluciones (sin cambios)
 	tramosNoOriginales := make([]models.DescargoTramo, 0)
 	for _, tramoGuardado := range descargo.Tramos {
-		if !tramoGuardado.IsOriginal() {
+		if !tramoGuardado.IsDeEmision() {
 			tramosNoOriginales = append(tramosNoOriginales, tramoGuardado)
 		}
 	}
@@ -251,7 +263,7 @@ func (s *DescargoOficialService) SyncItineraryFromSolicitud(ctx context.Context,
 	return s.repo.Update(ctx, descargo)
 }
 
-func (s *DescargoOficialService) UpdateOficial(ctx context.Context, id string, req dtos.CreateDescargoRequest, userID string, pasesAbordoPaths []string, terrestrePaths []string, anexoPaths []string, boletasPaths []string, memoPath string) error {
+func (s *DescargoOficialService) UpdateOficial(ctx context.Context, id string, req dtos.CreateDescargoRequest, userID string, pasesAbordoPaths []string, terrestrePaths []string, anexoPaths []string, boletasPaths []string, memoPath string, boletaDepositoPath string) error {
 	descargo, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
@@ -300,6 +312,7 @@ func (s *DescargoOficialService) UpdateOficial(ctx context.Context, id string, r
 
 		// Buscar el pasaje original y actualizar campos financieros y de archivo
 		if p, err := s.pasajeRepo.FindByID(ctx, pasajeID); err == nil {
+			boletaAnterior, montoAnterior := p.NroBoletaDeposito, p.MontoReembolso
 			p.MontoReembolso = monto
 			p.CostoUtilizado = p.Costo - monto
 			p.NroBoletaDeposito = nroBoleta
@@ -314,6 +327,11 @@ func (s *DescargoOficialService) UpdateOficial(ctx context.Context, id string, r
 				p.FechaDeposito = nil
 			}
 
+			p.SyncEstadoReembolso(boletaAnterior, montoAnterior)
+			if err := s.adjuntoService.VersionarPasaje(ctx, p); err != nil {
+				return err
+			}
+
 			// Actualización robusta del pasaje
 			_ = s.pasajeRepo.Update(ctx, p)
 		}
@@ -334,6 +352,13 @@ func (s *DescargoOficialService) UpdateOficial(ctx context.Context, id string, r
 	descargo.Oficial.TipoTransporte = req.TipoTransporte
 	descargo.Oficial.PlacaVehiculo = req.PlacaVehiculo
 	descargo.Oficial.ArchivoMemorandum = memoPath
+	descargo.Oficial.ArchivoBoletaDeposito = boletaDepositoPath
+	if descargo.Oficial.MemorandumAdjuntoID, err = s.adjuntoService.Versionar(ctx, models.AdjuntoMemorandum, descargo.Oficial.MemorandumAdjuntoID, memoPath); err != nil {
+		return err
+	}
+	if descargo.Oficial.BoletaDepositoAdjuntoID, err = s.adjuntoService.Versionar(ctx, models.AdjuntoBoletaDeposito, descargo.Oficial.BoletaDepositoAdjuntoID, boletaDepositoPath); err != nil {
+		return err
+	}
 
 	fs, err := utils.ParseDateAndTime(req.FechaSalida, req.HoraSalida)
 	if err != nil {
@@ -366,13 +391,21 @@ func (s *DescargoOficialService) UpdateOficial(ctx context.Context, id string, r
 		}
 	}
 
-	// 3. Anexos
+	// 3. Anexos (los que se mantienen conservan su historial)
+	adjuntosAnexos := make(map[string]*string)
+	for _, a := range descargo.Oficial.Anexos {
+		adjuntosAnexos[a.Archivo] = a.AdjuntoID
+	}
 	s.repo.ClearAnexos(ctx, oficialID)
 	if len(anexoPaths) > 0 {
 		var anexos []models.AnexoDescargo
 		for _, path := range anexoPaths {
 			if path != "" {
-				anexos = append(anexos, models.AnexoDescargo{DescargoOficialID: oficialID, Archivo: path})
+				adjuntoID, err := s.adjuntoService.Versionar(ctx, models.AdjuntoAnexo, adjuntosAnexos[path], path)
+				if err != nil {
+					return err
+				}
+				anexos = append(anexos, models.AnexoDescargo{DescargoOficialID: oficialID, Archivo: path, AdjuntoID: adjuntoID})
 			}
 		}
 		if len(anexos) > 0 {
@@ -525,6 +558,12 @@ func (s *DescargoOficialService) UpdateOficial(ctx context.Context, id string, r
 	}
 
 	descargo.Tramos = tramosProcesados
+	if err := s.descargoService.VerificarBilletes(ctx, descargo); err != nil {
+		return err
+	}
+	if err := s.adjuntoService.VersionarTramos(ctx, descargo.Tramos, existingMap); err != nil {
+		return err
+	}
 	if err := s.repo.Update(ctx, descargo); err != nil {
 		return err
 	}
@@ -5,8 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"sistema-pasajes/internal/models"
 	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"sistema-pasajes/internal/worker"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
 type DescargoService struct {
@@ -16,6 +23,8 @@ type DescargoService struct {
 	solicitudService  *SolicitudService
 	usuarioService    *UsuarioService
 	auditService      *AuditService
+	billeteService    *BilleteService
+	anticipoService   *AnticipoService
 }
 
 func NewDescargoService(
@@ -25,6 +34,8 @@ func NewDescargoService(
 	solicitudService *SolicitudService,
 	usuarioService *UsuarioService,
 	auditService *AuditService,
+	billeteService *BilleteService,
+	anticipoService *AnticipoService,
 ) *DescargoService {
 	return &DescargoService{
 		repo:              repo,
@@ -33,7 +44,134 @@ func NewDescargoService(
 		solicitudService:  solicitudService,
 		usuarioService:    usuarioService,
 		auditService:      auditService,
+		billeteService:    billeteService,
+		anticipoService:   anticipoService,
+	}
+}
+
+// VerificarBilletes rechaza los tramos cuyo billete ya está registrado para otro viajero.
+func (s *DescargoService) VerificarBilletes(ctx context.Context, descargo *models.Descargo) error {
+	for _, t := range descargo.Tramos {
+		candidato := repositories.UsoBillete{
+			Fuente:        repositories.UsoBilleteDescargo,
+			RegistroID:    t.ID,
+			NumeroBillete: t.Billete,
+			UsuarioID:     descargo.UsuarioID,
+		}
+		if t.PasajeID != nil {
+			candidato.PasajeID = *t.PasajeID
+		}
+		if _, err := s.billeteService.Verificar(ctx, candidato); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// VerificarPasesAbordo lee el código de barras (BCBP) de cada pase de abordar y lo compara
+// con el tramo declarado, el pasaje y el viajero. El resultado queda en el tramo para el revisor.
+func (s *DescargoService) VerificarPasesAbordo(ctx context.Context, descargo *models.Descargo) error {
+	var viajero *models.Usuario
+	if descargo.Solicitud != nil && descargo.Solicitud.Usuario.ID != "" {
+		viajero = &descargo.Solicitud.Usuario
+	}
+
+	for i := range descargo.Tramos {
+		t := &descargo.Tramos[i]
+		if t.EsOpenTicket || t.ArchivoPaseAbordo == "" {
+			continue
+		}
+
+		estado, discrepancias, codigo := models.PaseSinCodigo, "", ""
+		if textos, err := leerCodigosPase(t.ArchivoPaseAbordo); err != nil {
+			slog.Warn("No se pudo leer el código del pase de abordar", "tramo_id", t.ID, "archivo", t.ArchivoPaseAbordo, "error", err)
+		} else {
+			for _, texto := range textos {
+				pase, err := ParseBCBP(texto)
+				if err != nil {
+					continue
+				}
+				pasaje := t.Pasaje
+				if pasaje == nil && descargo.Solicitud != nil {
+					pasaje = t.GetPasajeCorrespondiente(descargo.Solicitud)
+				}
+				difs := CompararPaseBCBP(pase, *t, pasaje, viajero)
+				estado, discrepancias, codigo = models.PaseVerificado, strings.Join(difs, "\n"), texto
+				if len(difs) > 0 {
+					estado = models.PaseDiscrepancia
+				}
+				break
+			}
+		}
+
+		if err := s.repo.UpdateVerificacionPase(ctx, t.ID, estado, discrepancias, codigo); err != nil {
+			return err
+		}
+		t.PaseVerificacion, t.PaseDiscrepancias, t.PaseBCBP = estado, discrepancias, codigo
+	}
+	return nil
+}
+
+// Límites para leer el código de un pase: el archivo lo sube el viajero y el PDF417 se
+// decodifica por fuerza bruta sobre cada imagen.
+const (
+	maxPaseAbordoBytes = 10 << 20
+	timeoutPaseAbordo  = 30 * time.Second
+)
+
+// leerCodigosPase decodifica los códigos de barras del archivo con límite de tamaño y de tiempo.
+// Un pánico del decodificador se informa como error del archivo.
+func leerCodigosPase(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
 	}
+	if info.Size() > maxPaseAbordoBytes {
+		return nil, fmt.Errorf("el archivo supera %d MB", maxPaseAbordoBytes>>20)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	type resultado struct {
+		textos []string
+		err    error
+	}
+	ch := make(chan resultado, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				ch <- resultado{err: fmt.Errorf("pánico al decodificar: %v", r)}
+			}
+		}()
+		textos, err := utils.DecodeBarcodes(data)
+		ch <- resultado{textos, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.textos, r.err
+	case <-time.After(timeoutPaseAbordo):
+		return nil, fmt.Errorf("la lectura del código superó %s", timeoutPaseAbordo)
+	}
+}
+
+// VerificarPasesJob lee en segundo plano los códigos de los pases de un descargo enviado.
+type VerificarPasesJob struct {
+	Service    *DescargoService
+	DescargoID string
+}
+
+func (j *VerificarPasesJob) Name() string {
+	return "VerificarPasesJob:" + j.DescargoID
+}
+
+func (j *VerificarPasesJob) Run(ctx context.Context) error {
+	descargo, err := j.Service.repo.FindByID(ctx, j.DescargoID)
+	if err != nil {
+		return err
+	}
+	return j.Service.VerificarPasesAbordo(ctx, descargo)
 }
 
 func (s *DescargoService) GetBySolicitudID(ctx context.Context, solicitudID string) (*models.Descargo, error) {
@@ -86,6 +224,10 @@ func (s *DescargoService) Submit(ctx context.Context, id, userID string) error {
 		return fmt.Errorf("el descargo no se puede enviar en su estado actual (%s)", descargo.Estado)
 	}
 
+	if err := s.anticipoService.VerificarEnvio(ctx, descargo); err != nil {
+		return err
+	}
+
 	oldState := descargo.Estado
 	newState := models.EstadoDescargoEnRevision
 
@@ -98,6 +240,16 @@ func (s *DescargoService) Submit(ctx context.Context, id, userID string) error {
 	// Sincronizar Open Tickets desde los tramos marcados
 	_ = s.openTicketService.SyncFromDescargo(ctx, descargo, userID)
 
+	// Los pases los sube el viajero: se leen fuera de la petición y el resultado queda en cada tramo.
+	for _, t := range descargo.Tramos {
+		if !t.EsOpenTicket && t.ArchivoPaseAbordo != "" {
+			if err := s.repo.UpdateVerificacionPase(ctx, t.ID, models.PasePendiente, "", ""); err != nil {
+				slog.Warn("No se pudo marcar el pase de abordar como pendiente", "tramo_id", t.ID, "error", err)
+			}
+		}
+	}
+	worker.GetPool().Submit(&VerificarPasesJob{Service: s, DescargoID: id})
+
 	s.auditService.Log(ctx, "ENVIAR_DESCARGO", "descargo", id, string(oldState), string(newState), "", "")
 	slog.Info("Descargo enviado a revisión", "id", id, "codigo", descargo.Codigo, "user_id", userID, "state", newState)
 	return nil
@@ -120,7 +272,13 @@ func (s *DescargoService) Approve(ctx context.Context, id string, userID string)
 	descargo.Estado = newState
 	descargo.Observaciones = ""
 	descargo.UpdatedBy = &userID
-	if err := s.repo.Update(ctx, descargo); err != nil {
+	err = s.repo.RunTransaction(func(repoTx *repositories.DescargoRepository, tx *gorm.DB) error {
+		if err := s.anticipoService.LiquidarTx(ctx, tx, descargo, userID); err != nil {
+			return err
+		}
+		return repoTx.Update(ctx, descargo)
+	})
+	if err != nil {
 		return err
 	}
 
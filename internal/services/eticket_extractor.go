@@ -0,0 +1,309 @@
+package services
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"sistema-pasajes/internal/models"
+)
+
+// ETicketDatos son los campos reconocidos en el texto de un billete electrónico.
+// Los campos vacíos no pudieron identificarse.
+type ETicketDatos struct {
+	Aerolinea     string // Sigla IATA del transportista (OB, 8J, Z8...)
+	NumeroBillete string
+	NumeroVuelo   string
+	FechaVuelo    *time.Time
+	OrigenIATA    string
+	DestinoIATA   string
+	Costo         float64
+	Moneda        string
+}
+
+func (d ETicketDatos) IsVacio() bool {
+	return d.NumeroBillete == "" && d.NumeroVuelo == "" && d.FechaVuelo == nil && d.Costo == 0
+}
+
+// ETicketExtractor reconoce la plantilla de billete de una aerolínea y extrae sus datos.
+// Para soportar un nuevo formato basta implementar la interfaz y registrarlo en
+// ETicketService.Registrar.
+type ETicketExtractor interface {
+	Nombre() string
+	Reconoce(texto string) bool
+	Extraer(texto string) ETicketDatos
+}
+
+// layoutETicket es un extractor basado en expresiones regulares; cubre las plantillas
+// de las aerolíneas nacionales, que varían sobre todo en sus marcadores y etiquetas.
+type layoutETicket struct {
+	nombre     string
+	sigla      string
+	marcadores []string
+	prefijo    string // Código contable IATA con el que empiezan sus billetes
+	reBillete  *regexp.Regexp
+	reVuelo    *regexp.Regexp // Número de vuelo precedido de la sigla; nil en el genérico
+	reTotal    *regexp.Regexp
+}
+
+func (l *layoutETicket) Nombre() string { return l.nombre }
+
+func (l *layoutETicket) Reconoce(texto string) bool {
+	if len(l.marcadores) == 0 {
+		return true
+	}
+	upper := strings.ToUpper(texto)
+	for _, m := range l.marcadores {
+		if strings.Contains(upper, m) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *layoutETicket) Extraer(texto string) ETicketDatos {
+	datos := ETicketDatos{Aerolinea: l.sigla}
+
+	reBillete := l.reBillete
+	if reBillete == nil {
+		reBillete = reETicketBilleteEtiqueta
+	}
+	datos.NumeroBillete = extraerNumeroBillete(texto, reBillete, l.prefijo)
+
+	lineaVuelo := ""
+	if l.reVuelo != nil {
+		for _, linea := range strings.Split(texto, "\n") {
+			if m := l.reVuelo.FindStringSubmatch(linea); m != nil {
+				datos.NumeroVuelo = l.sigla + "-" + m[1]
+				lineaVuelo = linea
+				break
+			}
+		}
+	}
+	if datos.NumeroVuelo == "" {
+		datos.NumeroVuelo, lineaVuelo = extraerVueloGenerico(texto)
+		if datos.Aerolinea == "" && datos.NumeroVuelo != "" {
+			datos.Aerolinea, _, _ = strings.Cut(datos.NumeroVuelo, "-")
+		}
+	}
+
+	datos.FechaVuelo = extraerFechaVuelo(texto, lineaVuelo)
+	datos.OrigenIATA, datos.DestinoIATA = extraerRuta(texto, lineaVuelo)
+
+	reTotal := l.reTotal
+	if reTotal == nil {
+		reTotal = reETicketTotal
+	}
+	datos.Costo, datos.Moneda = extraerTotal(texto, reTotal)
+	return datos
+}
+
+// ETicketExtractoresNacionales son las plantillas conocidas de las aerolíneas nacionales.
+// El genérico va al final y se usa cuando ninguna otra reconoce el documento.
+func ETicketExtractoresNacionales() []ETicketExtractor {
+	return etLayoutsNacionales
+}
+
+// Las plantillas se arman una sola vez para no compilar sus expresiones en cada extracción.
+var etLayoutsNacionales = []ETicketExtractor{
+	&layoutETicket{
+		nombre:     "Boliviana de Aviación",
+		sigla:      "OB",
+		marcadores: []string{"BOLIVIANA DE AVIACION", "BOLIVIANA DE AVIACIÓN", "BOA.BO", "WWW.BOA"},
+		prefijo:    "930",
+		reVuelo:    reVueloDeSigla("OB"),
+	},
+	&layoutETicket{
+		nombre:     "EcoJet",
+		sigla:      "8J",
+		marcadores: []string{"ECOJET", "ECO JET"},
+		reVuelo:    reVueloDeSigla("8J"),
+		reTotal:    regexp.MustCompile(`(?i)(?:total\s+pagado|total\s+a\s+pagar|importe\s+total|total)\s*[:\-]?\s*(BOB|BS\.?|USD|\$US|US\$)?\s*([\d][\d.,]*)\s*(BOB|BS|USD)?`),
+	},
+	&layoutETicket{
+		nombre:     "Amaszonas",
+		sigla:      "Z8",
+		marcadores: []string{"AMASZONAS"},
+		reVuelo:    reVueloDeSigla("Z8"),
+	},
+	&layoutETicket{
+		nombre:     "Transportes Aéreos Militares",
+		sigla:      "TAM",
+		marcadores: []string{"TRANSPORTES AEREOS MILITARES", "TRANSPORTES AÉREOS MILITARES", "TAM.BO"},
+		reVuelo:    reVueloDeSigla("TAM"),
+	},
+	&layoutETicket{nombre: "Genérico IATA"},
+}
+
+func reVueloDeSigla(sigla string) *regexp.Regexp {
+	return regexp.MustCompile(`\b` + regexp.QuoteMeta(sigla) + `\s*-?\s*(\d{2,4})\b`)
+}
+
+var (
+	reETicketBilleteEtiqueta = regexp.MustCompile(`(?i)(?:n[°ºo.]*\s*(?:de\s+)?(?:billete|boleto|ticket)|ticket\s*(?:number|no\.?|nro\.?)|e-?ticket|etkt|billete\s+electr[oó]nico)\s*[:#]?\s*(\d{3})[\s-]?(\d{10})\b`)
+	reETicketBilleteLibre    = regexp.MustCompile(`\b(\d{3})[\s-]?(\d{10})\b`)
+	reETicketVuelo           = regexp.MustCompile(`\b([A-Z][A-Z0-9]|[A-Z0-9][A-Z])\s*-?\s*(\d{2,4})\b`)
+	reETicketFechaTexto      = regexp.MustCompile(`(?i)\b(\d{1,2})\s?-?(ENE|FEB|MAR|ABR|APR|MAY|JUN|JUL|AGO|AUG|SEP|SET|OCT|NOV|DIC|DEC)[A-Z]*\.?\s?-?(\d{4}|\d{2})\b`)
+	reETicketFechaNumerica   = regexp.MustCompile(`\b(\d{1,2})[/.-](\d{1,2})[/.-](\d{4})\b`)
+	reETicketHora            = regexp.MustCompile(`\b([01]?\d|2[0-3])[:hH]([0-5]\d)\b`)
+	reETicketRuta            = regexp.MustCompile(`\b([A-Z]{3})\s*(?:-|/|→|>|–|\s)\s*([A-Z]{3})\b`)
+	reETicketTotal           = regexp.MustCompile(`(?i)(?:total\s+a\s+pagar|importe\s+total|tarifa\s+total|grand\s+total|total)\s*[:\-]?\s*(BOB|BS\.?|USD|\$US|US\$)?\s*([\d][\d.,]*)\s*(BOB|BS|USD)?`)
+	reETicketFechaEtiqueta   = regexp.MustCompile(`(?i)(?:fecha\s+(?:de\s+)?(?:vuelo|viaje|salida)|salida|departure)`)
+)
+
+var etMeses = map[string]time.Month{
+	"ENE": time.January, "FEB": time.February, "MAR": time.March, "ABR": time.April, "APR": time.April,
+	"MAY": time.May, "JUN": time.June, "JUL": time.July, "AGO": time.August, "AUG": time.August,
+	"SEP": time.September, "SET": time.September, "OCT": time.October, "NOV": time.November,
+	"DIC": time.December, "DEC": time.December,
+}
+
+// Palabras de tres letras que aparecen junto a la ruta y no son aeropuertos.
+var etNoIATA = map[string]bool{
+	"BOB": true, "USD": true, "ENE": true, "FEB": true, "MAR": true, "ABR": true, "APR": true,
+	"MAY": true, "JUN": true, "JUL": true, "AGO": true, "AUG": true, "SEP": true, "SET": true,
+	"OCT": true, "NOV": true, "DIC": true, "DEC": true, "TAM": true, "BOA": true, "PNR": true,
+	"NRO": true, "TKT": true, "DEL": true, "LOS": true, "LAS": true, "THE": true, "AND": true,
+}
+
+func extraerNumeroBillete(texto string, reEtiqueta *regexp.Regexp, prefijo string) string {
+	if m := reEtiqueta.FindStringSubmatch(texto); m != nil {
+		return m[1] + m[2]
+	}
+	var primero string
+	for _, m := range reETicketBilleteLibre.FindAllStringSubmatch(texto, -1) {
+		if prefijo != "" && m[1] == prefijo {
+			return m[1] + m[2]
+		}
+		if primero == "" {
+			primero = m[1] + m[2]
+		}
+	}
+	return primero
+}
+
+func extraerVueloGenerico(texto string) (string, string) {
+	for _, linea := range strings.Split(texto, "\n") {
+		upper := strings.ToUpper(linea)
+		if !strings.Contains(upper, "VUELO") && !strings.Contains(upper, "FLIGHT") && reETicketRuta.FindString(linea) == "" {
+			continue
+		}
+		for _, m := range reETicketVuelo.FindAllStringSubmatch(linea, -1) {
+			if etNoIATA[m[1]] {
+				continue
+			}
+			return m[1] + "-" + m[2], linea
+		}
+	}
+	return "", ""
+}
+
+// extraerFechaVuelo prioriza la fecha en la línea del vuelo; si no la hay, la que sigue
+// a una etiqueta de salida, para no confundirla con la fecha de emisión.
+func extraerFechaVuelo(texto, lineaVuelo string) *time.Time {
+	candidatos := []string{lineaVuelo}
+	if loc := reETicketFechaEtiqueta.FindStringIndex(texto); loc != nil {
+		fin := loc[1] + 80
+		if fin > len(texto) {
+			fin = len(texto)
+		}
+		candidatos = append(candidatos, texto[loc[1]:fin])
+	}
+	candidatos = append(candidatos, texto)
+
+	for _, fragmento := range candidatos {
+		if fragmento == "" {
+			continue
+		}
+		fecha, ok := parsearFechaETicket(fragmento)
+		if !ok {
+			continue
+		}
+		if m := reETicketHora.FindStringSubmatch(fragmento); m != nil && fragmento != texto {
+			h, _ := strconv.Atoi(m[1])
+			mi, _ := strconv.Atoi(m[2])
+			fecha = time.Date(fecha.Year(), fecha.Month(), fecha.Day(), h, mi, 0, 0, time.Local)
+		}
+		return &fecha
+	}
+	return nil
+}
+
+func parsearFechaETicket(s string) (time.Time, bool) {
+	if m := reETicketFechaTexto.FindStringSubmatch(s); m != nil {
+		dia, _ := strconv.Atoi(m[1])
+		anio, _ := strconv.Atoi(m[3])
+		if anio < 100 {
+			anio += 2000
+		}
+		if mes, ok := etMeses[strings.ToUpper(m[2])]; ok && dia >= 1 && dia <= 31 {
+			return time.Date(anio, mes, dia, 0, 0, 0, 0, time.Local), true
+		}
+	}
+	if m := reETicketFechaNumerica.FindStringSubmatch(s); m != nil {
+		dia, _ := strconv.Atoi(m[1])
+		mes, _ := strconv.Atoi(m[2])
+		anio, _ := strconv.Atoi(m[3])
+		if dia >= 1 && dia <= 31 && mes >= 1 && mes <= 12 {
+			return time.Date(anio, time.Month(mes), dia, 0, 0, 0, 0, time.Local), true
+		}
+	}
+	return time.Time{}, false
+}
+
+func extraerRuta(texto, lineaVuelo string) (string, string) {
+	for _, fragmento := range []string{lineaVuelo, texto} {
+		for _, m := range reETicketRuta.FindAllStringSubmatch(fragmento, -1) {
+			if etNoIATA[m[1]] || etNoIATA[m[2]] || m[1] == m[2] {
+				continue
+			}
+			return m[1], m[2]
+		}
+	}
+	return "", ""
+}
+
+func extraerTotal(texto string, reTotal *regexp.Regexp) (float64, string) {
+	matches := reTotal.FindAllStringSubmatch(texto, -1)
+	// El total final del billete suele ser la última coincidencia
+	for i := len(matches) - 1; i >= 0; i-- {
+		m := matches[i]
+		monto := parsearMontoETicket(m[2])
+		if monto <= 0 {
+			continue
+		}
+		moneda := models.MonedaBOB
+		for _, c := range []string{m[1], m[3]} {
+			c = strings.ToUpper(strings.TrimSuffix(c, "."))
+			if c == "USD" || c == "$US" || c == "US$" {
+				moneda = models.MonedaUSD
+			}
+		}
+		return monto, moneda
+	}
+	return 0, ""
+}
+
+// parsearMontoETicket interpreta "1,234.50" y "1.234,50": el último separador seguido
+// de dos dígitos es el decimal.
+func parsearMontoETicket(s string) float64 {
+	s = strings.TrimRight(s, ".,")
+	lastDot, lastComma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	dec := max(lastDot, lastComma)
+	if dec >= 0 && len(s)-dec-1 != 2 {
+		dec = -1
+	}
+
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			sb.WriteRune(r)
+		case i == dec:
+			sb.WriteRune('.')
+		}
+	}
+	v, _ := strconv.ParseFloat(sb.String(), 64)
+	return v
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+)
+
+// ETicketService lee los PDF de billetes electrónicos para precargar el registro de
+// pasajes y advierte cuando no coinciden con el tramo solicitado.
+type ETicketService struct {
+	solicitudItemRepo *repositories.SolicitudItemRepository
+	aerolineaRepo     *repositories.AerolineaRepository
+	destinoRepo       *repositories.DestinoRepository
+	extractores       []ETicketExtractor
+}
+
+func NewETicketService(
+	solicitudItemRepo *repositories.SolicitudItemRepository,
+	aerolineaRepo *repositories.AerolineaRepository,
+	destinoRepo *repositories.DestinoRepository,
+) *ETicketService {
+	return &ETicketService{
+		solicitudItemRepo: solicitudItemRepo,
+		aerolineaRepo:     aerolineaRepo,
+		destinoRepo:       destinoRepo,
+		extractores:       ETicketExtractoresNacionales(),
+	}
+}
+
+// Registrar agrega un extractor con prioridad sobre los existentes.
+func (s *ETicketService) Registrar(e ETicketExtractor) {
+	s.extractores = append([]ETicketExtractor{e}, s.extractores...)
+}
+
+func (s *ETicketService) Extraer(ctx context.Context, data []byte, itemID string) (*dtos.ETicketExtraidoResponse, error) {
+	texto, err := utils.ExtractPDFText(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var datos ETicketDatos
+	var nombre string
+	for _, e := range s.extractores {
+		if e.Reconoce(texto) {
+			datos, nombre = e.Extraer(texto), e.Nombre()
+			break
+		}
+	}
+	if datos.IsVacio() {
+		return nil, errors.New("no se reconocieron datos de billete en el PDF")
+	}
+
+	res := &dtos.ETicketExtraidoResponse{
+		Extractor:     nombre,
+		NumeroBillete: datos.NumeroBillete,
+		NumeroVuelo:   datos.NumeroVuelo,
+		Costo:         datos.Costo,
+		Moneda:        datos.Moneda,
+		Avisos:        []string{},
+	}
+	if datos.FechaVuelo != nil {
+		res.FechaVuelo = datos.FechaVuelo.Format("2006-01-02 15:04")
+	}
+
+	// Solo se confía en la ruta si ambos códigos existen en el catálogo de destinos
+	if datos.OrigenIATA != "" {
+		_, errOrigen := s.destinoRepo.FindByIATA(ctx, datos.OrigenIATA)
+		_, errDestino := s.destinoRepo.FindByIATA(ctx, datos.DestinoIATA)
+		if errOrigen != nil || errDestino != nil {
+			datos.OrigenIATA, datos.DestinoIATA = "", ""
+		} else {
+			res.Ruta = datos.OrigenIATA + "-" + datos.DestinoIATA
+		}
+	}
+
+	if datos.Aerolinea != "" {
+		if aerolineas, err := s.aerolineaRepo.FindAllActive(ctx); err == nil {
+			for _, a := range aerolineas {
+				if strings.EqualFold(strings.TrimSpace(a.Sigla), datos.Aerolinea) {
+					res.AerolineaID = a.ID
+					break
+				}
+			}
+		}
+	}
+
+	if itemID == "" {
+		return res, nil
+	}
+	item, err := s.solicitudItemRepo.FindByID(ctx, itemID)
+	if err != nil {
+		return res, nil
+	}
+
+	if res.Ruta != "" && (datos.OrigenIATA != item.OrigenIATA || datos.DestinoIATA != item.DestinoIATA) {
+		res.Avisos = append(res.Avisos, fmt.Sprintf("La ruta del billete (%s) no coincide con el tramo solicitado (%s-%s).", res.Ruta, item.OrigenIATA, item.DestinoIATA))
+	}
+	if datos.FechaVuelo != nil && item.Fecha != nil && datos.FechaVuelo.Format("2006-01-02") != item.Fecha.Format("2006-01-02") {
+		res.Avisos = append(res.Avisos, fmt.Sprintf("La fecha de vuelo del billete (%s) difiere de la solicitada (%s).", datos.FechaVuelo.Format("02/01/2006"), item.Fecha.Format("02/01/2006")))
+	}
+	if res.AerolineaID != "" && item.AerolineaID != nil && *item.AerolineaID != res.AerolineaID {
+		res.Avisos = append(res.Avisos, fmt.Sprintf("El billete es de %s y no de la aerolínea solicitada.", nombre))
+	}
+	return res, nil
+}
@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sistema-pasajes/internal/models"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/xuri/excelize/v2"
+	"golang.org/x/text/encoding/charmap"
+)
+
+var ErrExtractoSinEncabezado = errors.New("no se encontró la fila de encabezados (se esperan columnas de fecha y monto/crédito)")
+
+var reMontoNoNumerico = regexp.MustCompile(`[^0-9,.\-]`)
+
+// columnasExtracto son las posiciones reconocidas en el encabezado del extracto (-1 si falta).
+type columnasExtracto struct {
+	fecha, referencia, descripcion, monto, credito, debito int
+}
+
+// parsearExtractoBancario lee un extracto en CSV o xlsx y retorna sus abonos. Las columnas se
+// reconocen por el encabezado, que puede estar precedido por filas con datos de la cuenta.
+func parsearExtractoBancario(nombre string, data []byte) ([]models.MovimientoBancario, error) {
+	var filas [][]string
+	var err error
+	switch strings.ToLower(filepath.Ext(nombre)) {
+	case ".xlsx", ".xlsm":
+		filas, err = leerFilasXLSX(data)
+	case ".csv", ".txt":
+		filas, err = leerFilasCSV(data)
+	default:
+		return nil, fmt.Errorf("formato no soportado: use CSV o xlsx")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	inicio, cols := -1, columnasExtracto{}
+	for i := 0; i < len(filas) && i < 20; i++ {
+		if c, ok := detectarColumnasExtracto(filas[i]); ok {
+			inicio, cols = i, c
+			break
+		}
+	}
+	if inicio < 0 {
+		return nil, ErrExtractoSinEncabezado
+	}
+
+	var movimientos []models.MovimientoBancario
+	for _, fila := range filas[inicio+1:] {
+		fecha, ok := parsearFechaExtracto(celda(fila, cols.fecha))
+		if !ok {
+			continue
+		}
+		var monto float64
+		if cols.credito >= 0 {
+			monto = parsearMontoExtracto(celda(fila, cols.credito))
+		} else {
+			monto = parsearMontoExtracto(celda(fila, cols.monto))
+		}
+		// Solo interesan los abonos a la cuenta de devoluciones.
+		if monto <= 0 {
+			continue
+		}
+		movimientos = append(movimientos, models.MovimientoBancario{
+			Fecha:       fecha,
+			Referencia:  strings.TrimSpace(celda(fila, cols.referencia)),
+			Descripcion: truncar(strings.TrimSpace(celda(fila, cols.descripcion)), 255),
+			Monto:       monto,
+		})
+	}
+	return movimientos, nil
+}
+
+func leerFilasXLSX(data []byte) ([][]string, error) {
+	f, err := excelize.OpenReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el archivo xlsx: %w", err)
+	}
+	defer f.Close()
+	hojas := f.GetSheetList()
+	if len(hojas) == 0 {
+		return nil, fmt.Errorf("el archivo xlsx no tiene hojas")
+	}
+	// Valores sin formato: las fechas llegan como número de serie y los montos sin separadores.
+	return f.GetRows(hojas[0], excelize.Options{RawCellValue: true})
+}
+
+func leerFilasCSV(data []byte) ([][]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if !utf8.Valid(data) {
+		// Los bancos suelen exportar en Latin-1.
+		if decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(data); err == nil {
+			data = decoded
+		}
+	}
+
+	primera := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		primera = data[:i]
+	}
+	sep := ','
+	if bytes.Count(primera, []byte(";")) > bytes.Count(primera, []byte(",")) {
+		sep = ';'
+	} else if bytes.Count(primera, []byte("\t")) > bytes.Count(primera, []byte(",")) {
+		sep = '\t'
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = sep
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	filas, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer el CSV: %w", err)
+	}
+	return filas, nil
+}
+
+func detectarColumnasExtracto(fila []string) (columnasExtracto, bool) {
+	c := columnasExtracto{-1, -1, -1, -1, -1, -1}
+	for i, v := range fila {
+		h := normalizarEncabezado(v)
+		switch {
+		case h == "":
+		case c.fecha < 0 && strings.Contains(h, "fecha"):
+			c.fecha = i
+		case c.referencia < 0 && (strings.Contains(h, "referencia") || strings.Contains(h, "documento") || strings.Contains(h, "boleta") ||
+			strings.Contains(h, "comprobante") || strings.HasPrefix(h, "nro") || strings.HasPrefix(h, "numero") || strings.HasPrefix(h, "n°")):
+			c.referencia = i
+		case c.credito < 0 && (strings.Contains(h, "credito") || strings.Contains(h, "abono") || strings.Contains(h, "haber") || strings.Contains(h, "deposito")):
+			c.credito = i
+		case c.debito < 0 && (strings.Contains(h, "debito") || strings.Contains(h, "cargo") || strings.Contains(h, "retiro") || h == "debe"):
+			c.debito = i
+		case c.monto < 0 && (strings.Contains(h, "monto") || strings.Contains(h, "importe")):
+			c.monto = i
+		case c.descripcion < 0 && (strings.Contains(h, "descripcion") || strings.Contains(h, "concepto") || strings.Contains(h, "glosa") || strings.Contains(h, "detalle")):
+			c.descripcion = i
+		}
+	}
+	return c, c.fecha >= 0 && (c.monto >= 0 || c.credito >= 0)
+}
+
+func normalizarEncabezado(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer("á", "a", "é", "e", "í", "i", "ó", "o", "ú", "u", "º", "°").Replace(s)
+}
+
+func celda(fila []string, i int) string {
+	if i < 0 || i >= len(fila) {
+		return ""
+	}
+	return fila[i]
+}
+
+func truncar(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+func parsearFechaExtracto(v string) (time.Time, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return time.Time{}, false
+	}
+	// Número de serie de Excel.
+	if serial, err := strconv.ParseFloat(v, 64); err == nil && serial > 20000 && serial < 80000 {
+		t, err := excelize.ExcelDateToTime(serial, false)
+		if err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local), true
+		}
+	}
+	if i := strings.IndexAny(v, " T"); i > 0 {
+		v = v[:i]
+	}
+	for _, layout := range []string{"02/01/2006", "2/1/2006", "2006-01-02", "02-01-2006", "02.01.2006", "02/01/06", "2006/01/02"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parsearMontoExtracto acepta "1.234,56", "1,234.56", "1234.56" o "Bs 1234,5". El último
+// separador es el decimal si le siguen uno o dos dígitos.
+func parsearMontoExtracto(v string) float64 {
+	v = reMontoNoNumerico.ReplaceAllString(strings.TrimSpace(v), "")
+	if v == "" {
+		return 0
+	}
+	ultimo := strings.LastIndexAny(v, ".,")
+	if ultimo >= 0 {
+		entero := strings.NewReplacer(".", "", ",", "").Replace(v[:ultimo])
+		decimales := v[ultimo+1:]
+		if len(decimales) <= 2 {
+			v = entero + "." + decimales
+		} else {
+			v = entero + decimales
+		}
+	}
+	monto, _ := strconv.ParseFloat(v, 64)
+	return monto
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+type FeriadoService struct {
+	repo         *repositories.FeriadoRepository
+	auditService *AuditService
+}
+
+func NewFeriadoService(repo *repositories.FeriadoRepository, auditService *AuditService) *FeriadoService {
+	return &FeriadoService{
+		repo:         repo,
+		auditService: auditService,
+	}
+}
+
+// FeriadoFecha es un feriado ubicado en un año concreto.
+type FeriadoFecha struct {
+	Fecha   time.Time
+	Feriado models.Feriado
+}
+
+// CargarCalendario actualiza los feriados con que se cuentan los días hábiles de los plazos.
+func (s *FeriadoService) CargarCalendario(ctx context.Context) error {
+	feriados, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	models.SetCalendarioFeriados(feriados)
+	slog.Info("Calendario de feriados cargado", "feriados", len(feriados))
+	return nil
+}
+
+func (s *FeriadoService) GetAll(ctx context.Context) ([]models.Feriado, error) {
+	return s.repo.FindAll(ctx)
+}
+
+// GetCalendario lista las fechas de los feriados activos en el año, en orden.
+func (s *FeriadoService) GetCalendario(ctx context.Context, anio int) ([]FeriadoFecha, error) {
+	feriados, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var fechas []FeriadoFecha
+	for _, f := range feriados {
+		if fecha, ok := f.GetFecha(anio); ok && f.Activo {
+			fechas = append(fechas, FeriadoFecha{Fecha: fecha, Feriado: f})
+		}
+	}
+	sort.SliceStable(fechas, func(i, j int) bool { return fechas[i].Fecha.Before(fechas[j].Fecha) })
+	return fechas, nil
+}
+
+func (s *FeriadoService) Create(ctx context.Context, req dtos.CreateFeriadoRequest, actor *models.Usuario) error {
+	f := &models.Feriado{
+		BaseModel:        models.BaseModel{CreatedBy: &actor.ID},
+		Nombre:           strings.TrimSpace(req.Nombre),
+		Tipo:             req.Tipo,
+		TrasladarDomingo: req.TrasladarDomingo,
+		Activo:           true,
+	}
+	if f.Nombre == "" {
+		return errors.New("el nombre es obligatorio")
+	}
+	if depto := strings.TrimSpace(req.Departamento); depto != "" {
+		f.DepartamentoCodigo = &depto
+	}
+
+	switch req.Tipo {
+	case models.FeriadoFijo:
+		f.Mes = utils.StrToInt(req.Mes, 0)
+		f.Dia = utils.StrToInt(req.Dia, 0)
+		// 2024 es bisiesto: admite el 29 de febrero.
+		if f.Mes < 1 || f.Mes > 12 || f.Dia < 1 || time.Date(2024, time.Month(f.Mes), f.Dia, 0, 0, 0, 0, time.Local).Month() != time.Month(f.Mes) {
+			return errors.New("el día y mes del feriado no son válidos")
+		}
+	case models.FeriadoMovil:
+		f.DiasDesdePascua = utils.StrToInt(req.DiasDesdePascua, 0)
+		if f.DiasDesdePascua < -70 || f.DiasDesdePascua > 70 {
+			return errors.New("los días respecto a Pascua deben estar entre -70 y 70")
+		}
+	case models.FeriadoUnico:
+		f.Fecha = utils.ParseDatePtr("2006-01-02", req.Fecha)
+		if f.Fecha == nil {
+			return errors.New("la fecha no es válida")
+		}
+	default:
+		return fmt.Errorf("tipo de feriado no válido: %s", req.Tipo)
+	}
+
+	if err := s.repo.Create(ctx, f); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "CREAR_FERIADO", "feriado", f.ID, "", fmt.Sprintf("%s (%s, %s)", f.Nombre, f.GetReglaDisplay(), f.GetAmbito()), "", "")
+	return s.CargarCalendario(ctx)
+}
+
+func (s *FeriadoService) SetActivo(ctx context.Context, id string, activo bool) error {
+	f, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateActivo(ctx, id, activo); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ESTADO_FERIADO", "feriado", id, fmt.Sprintf("%t", f.Activo), fmt.Sprintf("%t", activo), "", "")
+	return s.CargarCalendario(ctx)
+}
+
+func (s *FeriadoService) Delete(ctx context.Context, id string) error {
+	f, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ELIMINAR_FERIADO", "feriado", id, f.Nombre, "", "", "")
+	return s.CargarCalendario(ctx)
+}
@@ -0,0 +1,519 @@
+package services
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/hhrutter/pkcs7"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/types"
+)
+
+const (
+	// firmaContentsBytes es el espacio reservado para el CMS; alcanza para el certificado del
+	// firmante con una cadena de varias CA intermedias.
+	firmaContentsBytes = 16384
+	byteRangeReservado = "/ByteRange [0 0000000000 0000000000 0000000000]"
+	mmAPuntos          = 72.0 / 25.4
+)
+
+var (
+	oidSigningCertificateV2 = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 47}
+	reByteRange             = regexp.MustCompile(`/ByteRange\s*\[\s*(\d+)\s+(\d+)\s+(\d+)\s+(\d+)\s*\]`)
+)
+
+// essCertIDv2 y signingCertificateV2 (RFC 5035) atan la firma al certificado del firmante,
+// requisito de PAdES; el algoritmo por omisión es SHA-256.
+type essCertIDv2 struct {
+	CertHash []byte
+}
+
+type signingCertificateV2 struct {
+	Certs []essCertIDv2
+}
+
+// firmaVisible describe la firma que se estampa en el documento.
+type firmaVisible struct {
+	Espacio  EspacioFirma
+	Firmante string
+	Motivo   string
+	Fecha    time.Time
+}
+
+// ResultadoFirma es el resultado de verificar una firma de un PDF.
+type ResultadoFirma struct {
+	Numero      int
+	Firmante    string
+	Emisor      string
+	NumeroSerie string
+	Fecha       *time.Time
+	Integra     bool
+	Confiable   bool
+	CubreTodo   bool
+	Error       string
+}
+
+// IsValida indica que el contenido firmado no fue alterado, que el certificado encadena con
+// una CA de confianza y que la firma cubre el archivo completo.
+func (r ResultadoFirma) IsValida() bool {
+	return r.Integra && r.Confiable && r.CubreTodo
+}
+
+// IsValidaConCambios indica una firma correcta sobre una versión anterior del archivo: lo que
+// se agregó después (otras firmas u otros cambios) no está cubierto por ella.
+func (r ResultadoFirma) IsValidaConCambios() bool {
+	return r.Integra && r.Confiable && !r.CubreTodo
+}
+
+// firmarPDF agrega una firma PAdES (ETSI.CAdES.detached) visible mediante una actualización
+// incremental: los bytes originales no se tocan, por lo que firmas previas siguen siendo válidas.
+func firmarPDF(data []byte, vis firmaVisible, cert *x509.Certificate, key crypto.PrivateKey, cadena []*x509.Certificate) ([]byte, error) {
+	pdfCtx, err := api.ReadAndValidate(bytes.NewReader(data), pdfcpuConfig())
+	if err != nil {
+		return nil, fmt.Errorf("el PDF no es legible: %w", err)
+	}
+	xref := pdfCtx.XRefTable
+	if xref.Size == nil || xref.Root == nil {
+		return nil, errors.New("el PDF no tiene catálogo")
+	}
+
+	pagina := vis.Espacio.Pagina
+	if pagina < 1 || pagina > xref.PageCount {
+		pagina = 1
+	}
+	pageDict, pageRef, attrs, err := xref.PageDict(pagina, false)
+	if err != nil || pageDict == nil || pageRef == nil {
+		return nil, fmt.Errorf("no se encontró la página %d del documento", pagina)
+	}
+	altoPagina := 792.0
+	if attrs != nil && attrs.MediaBox != nil {
+		altoPagina = attrs.MediaBox.UR.Y
+	}
+
+	prevXRef, xrefClasica, err := ultimaTablaXRef(data)
+	if err != nil {
+		return nil, err
+	}
+
+	act := &actualizacionPDF{offsets: map[int]int64{}, gens: map[int]int{}, next: *xref.Size}
+	act.buf.Write(data)
+	if !bytes.HasSuffix(data, []byte("\n")) {
+		act.buf.WriteByte('\n')
+	}
+
+	catalogo, err := xref.Catalog()
+	if err != nil {
+		return nil, err
+	}
+	var acroForm types.Dict
+	acroRef, acroEsRef := catalogo["AcroForm"].(types.IndirectRef)
+	if catalogo["AcroForm"] != nil {
+		if acroForm, err = xref.DereferenceDict(catalogo["AcroForm"]); err != nil {
+			return nil, err
+		}
+	}
+	if acroForm == nil {
+		acroForm = types.NewDict()
+		acroEsRef = false
+	}
+	campos, _ := xref.DereferenceArray(acroForm["Fields"])
+
+	// Objetos nuevos: diccionario de firma, fuente, apariencia y widget.
+	sigNr, fontNr, apNr, widgetNr := act.nuevo(), act.nuevo(), act.nuevo(), act.nuevo()
+
+	sigOffset := act.escribir(sigNr, 0, fmt.Sprintf(
+		"<</Type /Sig /Filter /Adobe.PPKLite /SubFilter /ETSI.CAdES.detached %s /Contents <%s> /M %s /Name %s /Reason %s>>",
+		byteRangeReservado, strings.Repeat("0", 2*firmaContentsBytes), fechaPDF(vis.Fecha), textoPDF(vis.Firmante), textoPDF(vis.Motivo),
+	))
+	act.escribir(fontNr, 0, "<</Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding>>")
+
+	ancho, alto := vis.Espacio.Ancho*mmAPuntos, vis.Espacio.Alto*mmAPuntos
+	if ancho <= 0 || alto <= 0 {
+		ancho, alto = 170, 42
+	}
+	apariencia := aparienciaFirma(vis, alto)
+	act.escribir(apNr, 0, fmt.Sprintf(
+		"<</Type /XObject /Subtype /Form /BBox [0 0 %.2f %.2f] /Resources <</Font <</F1 %d 0 R>>>> /Length %d>>\nstream\n%s\nendstream",
+		ancho, alto, fontNr, len(apariencia), apariencia,
+	))
+
+	llx := vis.Espacio.X * mmAPuntos
+	ury := altoPagina - vis.Espacio.Y*mmAPuntos
+	act.escribir(widgetNr, 0, fmt.Sprintf(
+		"<</Type /Annot /Subtype /Widget /FT /Sig /T %s /V %d 0 R /F 4 /Rect [%.2f %.2f %.2f %.2f] /P %d %d R /AP <</N %d 0 R>>>>",
+		textoPDF(fmt.Sprintf("Firma%d", len(campos)+1)), sigNr, llx, ury-alto, llx+ancho, ury,
+		pageRef.ObjectNumber.Value(), pageRef.GenerationNumber.Value(), apNr,
+	))
+	widgetRef := *types.NewIndirectRef(widgetNr, 0)
+
+	// La página suma el widget a sus anotaciones.
+	anotaciones, _ := xref.DereferenceArray(pageDict["Annots"])
+	pageDict.Update("Annots", append(anotaciones, widgetRef))
+	act.escribir(pageRef.ObjectNumber.Value(), pageRef.GenerationNumber.Value(), pageDict.PDFString())
+
+	// El formulario del catálogo registra el campo de firma.
+	acroForm.Update("Fields", append(campos, widgetRef))
+	acroForm.Update("SigFlags", types.Integer(3))
+	if acroEsRef {
+		act.escribir(acroRef.ObjectNumber.Value(), acroRef.GenerationNumber.Value(), acroForm.PDFString())
+	} else {
+		catalogo.Update("AcroForm", acroForm)
+		act.escribir(xref.Root.ObjectNumber.Value(), xref.Root.GenerationNumber.Value(), catalogo.PDFString())
+	}
+
+	trailer := fmt.Sprintf("/Root %s", xref.Root.PDFString())
+	if xref.Info != nil {
+		trailer += fmt.Sprintf(" /Info %s", xref.Info.PDFString())
+	}
+	if len(xref.ID) > 0 {
+		trailer += " /ID " + xref.ID.PDFString()
+	}
+	trailer += fmt.Sprintf(" /Prev %d", prevXRef)
+	if xrefClasica {
+		act.cerrarConTabla(trailer)
+	} else {
+		act.cerrarConStream(trailer)
+	}
+
+	out := act.buf.Bytes()
+	return completarFirma(out, sigOffset, cert, key, cadena)
+}
+
+// completarFirma calcula el ByteRange definitivo, firma los bytes cubiertos y escribe el CMS en
+// el espacio reservado de /Contents.
+func completarFirma(out []byte, sigOffset int64, cert *x509.Certificate, key crypto.PrivateKey, cadena []*x509.Certificate) ([]byte, error) {
+	rel := bytes.Index(out[sigOffset:], []byte(byteRangeReservado))
+	if rel < 0 {
+		return nil, errors.New("no se encontró el ByteRange reservado")
+	}
+	brPos := int(sigOffset) + rel
+	relContents := bytes.Index(out[brPos:], []byte("/Contents <"))
+	if relContents < 0 {
+		return nil, errors.New("no se encontró el espacio reservado para la firma")
+	}
+	inicio := brPos + relContents + len("/Contents ")
+	fin := inicio + 2*firmaContentsBytes + 2
+
+	byteRange := fmt.Sprintf("/ByteRange [0 %d %d %d]", inicio, fin, len(out)-fin)
+	if len(byteRange) > len(byteRangeReservado) {
+		return nil, errors.New("el documento es demasiado grande para firmarse")
+	}
+	copy(out[brPos:], byteRange+strings.Repeat(" ", len(byteRangeReservado)-len(byteRange)))
+
+	firmado := make([]byte, 0, len(out)-(fin-inicio))
+	firmado = append(firmado, out[:inicio]...)
+	firmado = append(firmado, out[fin:]...)
+
+	cms, err := firmaCMS(firmado, cert, key, cadena)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo generar la firma: %w", err)
+	}
+	if len(cms) > firmaContentsBytes {
+		return nil, errors.New("la firma excede el espacio reservado en el documento")
+	}
+	hex.Encode(out[inicio+1:], cms)
+	return out, nil
+}
+
+func firmaCMS(contenido []byte, cert *x509.Certificate, key crypto.PrivateKey, cadena []*x509.Certificate) ([]byte, error) {
+	sd, err := pkcs7.NewSignedData(contenido)
+	if err != nil {
+		return nil, err
+	}
+	sd.SetDigestAlgorithm(pkcs7.OIDDigestAlgorithmSHA256)
+
+	huella := sha256.Sum256(cert.Raw)
+	attr := pkcs7.Attribute{
+		Type:  oidSigningCertificateV2,
+		Value: signingCertificateV2{Certs: []essCertIDv2{{CertHash: huella[:]}}},
+	}
+	if err := sd.AddSignerChain(cert, key, cadena, pkcs7.SignerInfoConfig{ExtraSignedAttributes: []pkcs7.Attribute{attr}}); err != nil {
+		return nil, err
+	}
+	sd.Detach()
+	return sd.Finish()
+}
+
+// ordenarCadena devuelve las CA del PKCS#12 en orden, desde la emisora del certificado hacia
+// la raíz, como las espera el CMS.
+func ordenarCadena(cert *x509.Certificate, cas []*x509.Certificate) []*x509.Certificate {
+	var cadena []*x509.Certificate
+	actual := cert
+	for len(cadena) < len(cas) {
+		if bytes.Equal(actual.RawIssuer, actual.RawSubject) {
+			break
+		}
+		var emisor *x509.Certificate
+		for _, ca := range cas {
+			if bytes.Equal(ca.RawSubject, actual.RawIssuer) && actual.CheckSignatureFrom(ca) == nil {
+				emisor = ca
+				break
+			}
+		}
+		if emisor == nil {
+			break
+		}
+		cadena = append(cadena, emisor)
+		actual = emisor
+	}
+	return cadena
+}
+
+// verificarFirmasPDF valida cada firma del documento: que los bytes cubiertos no cambiaron, que
+// el certificado encadena con las raíces de confianza y si hubo cambios posteriores a la firma.
+func verificarFirmasPDF(data []byte, raices *x509.CertPool) []ResultadoFirma {
+	var resultados []ResultadoFirma
+	for i, m := range reByteRange.FindAllSubmatch(data, -1) {
+		var br [4]int
+		for j := range br {
+			br[j], _ = strconv.Atoi(string(m[j+1]))
+		}
+		res := verificarFirma(data, br, raices)
+		res.Numero = i + 1
+		resultados = append(resultados, res)
+	}
+	return resultados
+}
+
+func verificarFirma(data []byte, br [4]int, raices *x509.CertPool) ResultadoFirma {
+	var res ResultadoFirma
+	a, b, c, d := br[0], br[1], br[2], br[3]
+	if a != 0 || b <= 0 || c <= b+1 || c+d > len(data) || data[b] != '<' || data[c-1] != '>' {
+		res.Error = "El rango de bytes firmado es inválido"
+		return res
+	}
+	res.CubreTodo = c+d == len(data)
+
+	der, err := hex.DecodeString(strings.Join(strings.Fields(string(data[b+1:c-1])), ""))
+	if err != nil {
+		res.Error = "El contenido de la firma no es legible"
+		return res
+	}
+	p7, err := pkcs7.Parse(recortarDER(der))
+	if err != nil {
+		res.Error = "El contenido de la firma no es un CMS válido"
+		return res
+	}
+
+	firmado := make([]byte, 0, b+d)
+	firmado = append(firmado, data[:b]...)
+	firmado = append(firmado, data[c:c+d]...)
+	p7.Content = firmado
+
+	if signer := p7.GetOnlySigner(); signer != nil {
+		res.Firmante = signer.Subject.CommonName
+		res.Emisor = signer.Issuer.CommonName
+		res.NumeroSerie = strings.ToUpper(signer.SerialNumber.Text(16))
+	}
+	var fecha time.Time
+	if err := p7.UnmarshalSignedAttribute(pkcs7.OIDAttributeSigningTime, &fecha); err == nil {
+		res.Fecha = &fecha
+	}
+
+	if err := p7.Verify(); err != nil {
+		res.Error = "El documento fue alterado después de firmarse o la firma no corresponde al certificado"
+		return res
+	}
+	res.Integra = true
+
+	if err := p7.VerifyWithChain(raices); err != nil {
+		res.Error = "El certificado no fue emitido por una entidad de confianza o no estaba vigente al firmar"
+		return res
+	}
+	res.Confiable = true
+	return res
+}
+
+// recortarDER quita el relleno de ceros que sigue al CMS dentro de /Contents.
+func recortarDER(der []byte) []byte {
+	if len(der) < 2 || der[0] != 0x30 {
+		return der
+	}
+	largo, cab := int(der[1]), 2
+	if largo&0x80 != 0 {
+		n := largo & 0x7f
+		if n == 0 || n > 4 || len(der) < 2+n {
+			return der
+		}
+		largo = 0
+		for _, byt := range der[2 : 2+n] {
+			largo = largo<<8 | int(byt)
+		}
+		cab += n
+	}
+	if cab+largo > len(der) {
+		return der
+	}
+	return der[:cab+largo]
+}
+
+// ultimaTablaXRef devuelve el offset de la última sección de referencias cruzadas y si es una
+// tabla clásica; la actualización debe usar el mismo formato.
+func ultimaTablaXRef(data []byte) (int64, bool, error) {
+	idx := bytes.LastIndex(data, []byte("startxref"))
+	if idx < 0 {
+		return 0, false, errors.New("el PDF no tiene tabla de referencias cruzadas")
+	}
+	campos := bytes.Fields(data[idx+len("startxref"):])
+	if len(campos) == 0 {
+		return 0, false, errors.New("el PDF no tiene tabla de referencias cruzadas")
+	}
+	off, err := strconv.ParseInt(string(campos[0]), 10, 64)
+	if err != nil || off < 0 || off >= int64(len(data)) {
+		return 0, false, errors.New("la tabla de referencias cruzadas del PDF es inválida")
+	}
+	return off, bytes.HasPrefix(bytes.TrimLeft(data[off:], " \r\n"), []byte("xref")), nil
+}
+
+// actualizacionPDF acumula los objetos de una actualización incremental y su tabla de
+// referencias cruzadas.
+type actualizacionPDF struct {
+	buf     bytes.Buffer
+	offsets map[int]int64
+	gens    map[int]int
+	next    int
+}
+
+func (a *actualizacionPDF) nuevo() int {
+	nr := a.next
+	a.next++
+	return nr
+}
+
+func (a *actualizacionPDF) escribir(nr, gen int, cuerpo string) int64 {
+	off := int64(a.buf.Len())
+	a.offsets[nr] = off
+	a.gens[nr] = gen
+	fmt.Fprintf(&a.buf, "%d %d obj\n%s\nendobj\n", nr, gen, cuerpo)
+	return off
+}
+
+// subsecciones agrupa los objetos escritos en rangos consecutivos de números.
+func (a *actualizacionPDF) subsecciones() [][]int {
+	nrs := make([]int, 0, len(a.offsets))
+	for nr := range a.offsets {
+		nrs = append(nrs, nr)
+	}
+	sort.Ints(nrs)
+
+	var grupos [][]int
+	for _, nr := range nrs {
+		if n := len(grupos); n > 0 && grupos[n-1][len(grupos[n-1])-1] == nr-1 {
+			grupos[n-1] = append(grupos[n-1], nr)
+			continue
+		}
+		grupos = append(grupos, []int{nr})
+	}
+	return grupos
+}
+
+func (a *actualizacionPDF) cerrarConTabla(trailer string) {
+	xrefOff := a.buf.Len()
+	a.buf.WriteString("xref\n")
+	for _, g := range a.subsecciones() {
+		fmt.Fprintf(&a.buf, "%d %d\n", g[0], len(g))
+		for _, nr := range g {
+			fmt.Fprintf(&a.buf, "%010d %05d n\r\n", a.offsets[nr], a.gens[nr])
+		}
+	}
+	fmt.Fprintf(&a.buf, "trailer\n<</Size %d %s>>\nstartxref\n%d\n%%%%EOF\n", a.next, trailer, xrefOff)
+}
+
+func (a *actualizacionPDF) cerrarConStream(trailer string) {
+	xrefNr := a.nuevo()
+	xrefOff := int64(a.buf.Len())
+	a.offsets[xrefNr] = xrefOff
+	a.gens[xrefNr] = 0
+
+	var indice []string
+	var filas bytes.Buffer
+	for _, g := range a.subsecciones() {
+		indice = append(indice, fmt.Sprintf("%d %d", g[0], len(g)))
+		for _, nr := range g {
+			fila := make([]byte, 7)
+			fila[0] = 1
+			binary.BigEndian.PutUint32(fila[1:5], uint32(a.offsets[nr]))
+			binary.BigEndian.PutUint16(fila[5:7], uint16(a.gens[nr]))
+			filas.Write(fila)
+		}
+	}
+	fmt.Fprintf(&a.buf, "%d 0 obj\n<</Type /XRef /Size %d /Index [%s] /W [1 4 2] %s /Length %d>>\nstream\n",
+		xrefNr, a.next, strings.Join(indice, " "), trailer, filas.Len())
+	a.buf.Write(filas.Bytes())
+	fmt.Fprintf(&a.buf, "\nendstream\nendobj\nstartxref\n%d\n%%%%EOF\n", xrefOff)
+}
+
+// aparienciaFirma dibuja el texto de la firma visible dentro del recuadro.
+func aparienciaFirma(vis firmaVisible, alto float64) string {
+	lineas := []string{
+		"Firmado digitalmente por:",
+		vis.Firmante,
+		"Fecha: " + vis.Fecha.Format("02/01/2006 15:04:05 -07:00"),
+	}
+	var sb strings.Builder
+	sb.WriteString("q 0.12 0.25 0.55 rg BT /F1 7 Tf 9 TL ")
+	fmt.Fprintf(&sb, "2 %.2f Td ", alto-9)
+	for i, l := range lineas {
+		if i > 0 {
+			sb.WriteString("T* ")
+		}
+		fmt.Fprintf(&sb, "(%s) Tj ", literalWinAnsi(l))
+	}
+	sb.WriteString("ET Q")
+	return sb.String()
+}
+
+// literalWinAnsi escapa el texto para una cadena literal con la codificación WinAnsi de la
+// fuente estándar; los caracteres fuera de Latin-1 se reemplazan.
+func literalWinAnsi(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20:
+			sb.WriteByte(' ')
+		case r < 0x80:
+			sb.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// textoPDF codifica una cadena de texto del documento en UTF-16BE, que admite acentos y ñ.
+func textoPDF(s string) string {
+	var sb strings.Builder
+	sb.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&sb, "%04X", u)
+	}
+	sb.WriteString(">")
+	return sb.String()
+}
+
+func fechaPDF(t time.Time) string {
+	_, off := t.Zone()
+	signo := "+"
+	if off < 0 {
+		signo, off = "-", -off
+	}
+	return fmt.Sprintf("(D:%s%s%02d'%02d')", t.Format("20060102150405"), signo, off/3600, (off%3600)/60)
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gorm.io/gorm"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// FirmaService administra los certificados de firma de los usuarios, firma digitalmente los
+// formularios generados y verifica las firmas de documentos cargados.
+type FirmaService struct {
+	repo         *repositories.CertificadoFirmaRepository
+	auditService *AuditService
+}
+
+func NewFirmaService(repo *repositories.CertificadoFirmaRepository, auditService *AuditService) *FirmaService {
+	return &FirmaService{repo: repo, auditService: auditService}
+}
+
+// directorioCertificados queda fuera de uploads, que se sirve públicamente.
+func directorioCertificados() string {
+	if dir := viper.GetString("FIRMA_CERT_DIR"); dir != "" {
+		return dir
+	}
+	return filepath.Join("storage", "certificados")
+}
+
+// raicesConfianza reúne las CA del sistema y las configuradas en FIRMA_CA_CERTS (PEM), por
+// ejemplo la CA local generada con cmd/firma-ca.
+func raicesConfianza() *x509.CertPool {
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if path := viper.GetString("FIRMA_CA_CERTS"); path != "" {
+		pem, err := os.ReadFile(path)
+		if err != nil || !pool.AppendCertsFromPEM(pem) {
+			slog.Warn("No se pudieron cargar las CA de firma configuradas", "archivo", path)
+		}
+	}
+	return pool
+}
+
+type credencialFirma struct {
+	cert   *x509.Certificate
+	key    crypto.PrivateKey
+	cadena []*x509.Certificate
+}
+
+func leerPKCS12(data []byte, password string) (*credencialFirma, error) {
+	key, cert, cas, err := pkcs12.DecodeChain(data, password)
+	if errors.Is(err, pkcs12.ErrIncorrectPassword) {
+		return nil, errors.New("la contraseña del certificado es incorrecta")
+	}
+	if err != nil {
+		return nil, errors.New("el archivo no es un certificado PKCS#12 (.p12/.pfx) válido")
+	}
+	return &credencialFirma{cert: cert, key: key, cadena: ordenarCadena(cert, cas)}, nil
+}
+
+func (cf *credencialFirma) validar(raices *x509.CertPool) error {
+	now := time.Now()
+	if now.Before(cf.cert.NotBefore) || now.After(cf.cert.NotAfter) {
+		return fmt.Errorf("el certificado no está vigente (válido del %s al %s)",
+			cf.cert.NotBefore.Format("02/01/2006"), cf.cert.NotAfter.Format("02/01/2006"))
+	}
+	if cf.cert.KeyUsage != 0 && cf.cert.KeyUsage&(x509.KeyUsageDigitalSignature|x509.KeyUsageContentCommitment) == 0 {
+		return errors.New("el certificado no está habilitado para firma digital")
+	}
+	intermedias := x509.NewCertPool()
+	for _, ca := range cf.cadena {
+		intermedias.AddCert(ca)
+	}
+	opts := x509.VerifyOptions{Roots: raices, Intermediates: intermedias, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}}
+	if _, err := cf.cert.Verify(opts); err != nil {
+		return errors.New("el certificado no fue emitido por una entidad certificadora de confianza")
+	}
+	return nil
+}
+
+// GetCertificado devuelve el certificado registrado del usuario o nil si no tiene.
+func (s *FirmaService) GetCertificado(ctx context.Context, usuarioID string) (*models.CertificadoFirma, error) {
+	c, err := s.repo.FindByUsuarioID(ctx, usuarioID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return c, err
+}
+
+// RegistrarCertificado valida el PKCS#12 con su contraseña y lo guarda como certificado de firma
+// del usuario, reemplazando el anterior si lo hubiera.
+func (s *FirmaService) RegistrarCertificado(ctx context.Context, usuarioID string, data []byte, password string) (*models.CertificadoFirma, error) {
+	cred, err := leerPKCS12(data, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := cred.validar(raicesConfianza()); err != nil {
+		return nil, err
+	}
+
+	dir := directorioCertificados()
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("no se pudo preparar el almacenamiento de certificados: %w", err)
+	}
+	archivo := filepath.Join(dir, usuarioID+".p12")
+	if err := os.WriteFile(archivo, data, 0600); err != nil {
+		return nil, fmt.Errorf("no se pudo guardar el certificado: %w", err)
+	}
+
+	huella := sha256.Sum256(cred.cert.Raw)
+	cert, err := s.GetCertificado(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	anterior := ""
+	if cert == nil {
+		cert = &models.CertificadoFirma{UsuarioID: usuarioID}
+	} else {
+		anterior = cert.NumeroSerie
+	}
+	cert.Archivo = archivo
+	cert.Sujeto = cred.cert.Subject.CommonName
+	cert.Emisor = cred.cert.Issuer.CommonName
+	cert.NumeroSerie = strings.ToUpper(cred.cert.SerialNumber.Text(16))
+	cert.Huella = hex.EncodeToString(huella[:])
+	cert.ValidoDesde = cred.cert.NotBefore
+	cert.ValidoHasta = cred.cert.NotAfter
+
+	if cert.ID == "" {
+		err = s.repo.Create(ctx, cert)
+	} else {
+		err = s.repo.Update(ctx, cert)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, "REGISTRAR_CERTIFICADO", "certificado_firma", cert.ID, anterior, cert.NumeroSerie+" "+cert.Sujeto, "", "")
+	return cert, nil
+}
+
+func (s *FirmaService) EliminarCertificado(ctx context.Context, usuarioID string) error {
+	cert, err := s.GetCertificado(ctx, usuarioID)
+	if err != nil {
+		return err
+	}
+	if cert == nil {
+		return errors.New("no tiene un certificado registrado")
+	}
+	if err := s.repo.Delete(ctx, cert.ID); err != nil {
+		return err
+	}
+	if err := os.Remove(cert.Archivo); err != nil && !os.IsNotExist(err) {
+		slog.Warn("No se pudo borrar el archivo del certificado", "archivo", cert.Archivo, "error", err)
+	}
+
+	s.auditService.Log(ctx, "ELIMINAR_CERTIFICADO", "certificado_firma", cert.ID, cert.NumeroSerie+" "+cert.Sujeto, "", "", "")
+	return nil
+}
+
+// FirmarFormulario genera el formulario con generar y lo firma con el certificado del usuario,
+// estampando la firma visible en su bloque de firma.
+func (s *FirmaService) FirmarFormulario(ctx context.Context, user *models.Usuario, password, documento string, generar func(context.Context) ([]byte, error)) ([]byte, error) {
+	cert, err := s.GetCertificado(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		return nil, errors.New("no tiene un certificado de firma registrado; regístrelo en Mi Perfil")
+	}
+	data, err := os.ReadFile(cert.Archivo)
+	if err != nil {
+		return nil, errors.New("no se encontró el archivo del certificado; vuelva a registrarlo en Mi Perfil")
+	}
+	cred, err := leerPKCS12(data, password)
+	if err != nil {
+		return nil, err
+	}
+	if err := cred.validar(raicesConfianza()); err != nil {
+		return nil, err
+	}
+
+	genCtx, espacios := conEspaciosFirma(ctx)
+	pdf, err := generar(genCtx)
+	if err != nil {
+		return nil, fmt.Errorf("error generando el documento: %w", err)
+	}
+
+	firmante := cred.cert.Subject.CommonName
+	if firmante == "" {
+		firmante = user.GetNombreCompleto()
+	}
+	espacio, _ := espacios.Elegir(user.GetNombreCompleto())
+	firmado, err := firmarPDF(pdf, firmaVisible{
+		Espacio:  espacio,
+		Firmante: firmante,
+		Motivo:   "Firma de " + documento,
+		Fecha:    time.Now(),
+	}, cred.cert, cred.key, cred.cadena)
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, "FIRMAR_DOCUMENTO", "certificado_firma", cert.ID, "", documento, "", "")
+	return firmado, nil
+}
+
+// Verificar valida las firmas digitales de un PDF cargado.
+func (s *FirmaService) Verificar(data []byte) ([]ResultadoFirma, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return nil, errors.New("el archivo no es un PDF")
+	}
+	resultados := verificarFirmasPDF(data, raicesConfianza())
+	if len(resultados) == 0 {
+		return nil, errors.New("el documento no contiene firmas digitales")
+	}
+	return resultados, nil
+}
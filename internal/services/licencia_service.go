@@ -0,0 +1,203 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+	"time"
+)
+
+// LicenciaService administra las licencias de los titulares y la herencia de sus cupos por
+// parte del suplente mientras dura la licencia.
+type LicenciaService struct {
+	repo         *repositories.LicenciaSenadorRepository
+	userRepo     *repositories.UsuarioRepository
+	cupoService  *CupoService
+	auditService *AuditService
+	notifService *NotificationService
+}
+
+type LicenciasJob struct {
+	Service *LicenciaService
+}
+
+func (j *LicenciasJob) Name() string {
+	return "LicenciasJob"
+}
+
+func (j *LicenciasJob) Run(ctx context.Context) error {
+	return j.Service.ProcesarLicencias(ctx)
+}
+
+func NewLicenciaService(
+	repo *repositories.LicenciaSenadorRepository,
+	userRepo *repositories.UsuarioRepository,
+	cupoService *CupoService,
+	auditService *AuditService,
+	notifService *NotificationService,
+) *LicenciaService {
+	return &LicenciaService{
+		repo:         repo,
+		userRepo:     userRepo,
+		cupoService:  cupoService,
+		auditService: auditService,
+		notifService: notifService,
+	}
+}
+
+func (s *LicenciaService) GetAll(ctx context.Context) ([]models.LicenciaSenador, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *LicenciaService) Crear(ctx context.Context, req dtos.CreateLicenciaRequest, actor *models.Usuario) (*models.LicenciaSenador, error) {
+	titular, err := s.userRepo.FindByID(ctx, req.TitularID)
+	if err != nil {
+		return nil, errors.New("senador titular no encontrado")
+	}
+	if !titular.IsSenadorTitular() {
+		return nil, errors.New("solo se registran licencias de senadores titulares")
+	}
+	suplente, err := s.userRepo.FindSuplenteByTitularID(ctx, titular.ID)
+	if err != nil {
+		return nil, errors.New("el titular no tiene un suplente registrado")
+	}
+
+	desde := utils.ParseDatePtr("2006-01-02", req.FechaDesde)
+	hasta := utils.ParseDatePtr("2006-01-02", req.FechaHasta)
+	if desde == nil || hasta == nil {
+		return nil, errors.New("las fechas de la licencia no son válidas")
+	}
+	if hasta.Before(*desde) {
+		return nil, errors.New("la fecha hasta no puede ser anterior a la fecha desde")
+	}
+	if solapada, _ := s.repo.ExistsSolapada(ctx, titular.ID, *desde, *hasta); solapada {
+		return nil, errors.New("el titular ya tiene una licencia registrada en esas fechas")
+	}
+
+	lic := &models.LicenciaSenador{
+		BaseModel:  models.BaseModel{CreatedBy: &actor.ID},
+		TitularID:  titular.ID,
+		Titular:    titular,
+		SuplenteID: suplente.ID,
+		Suplente:   suplente,
+		FechaDesde: *desde,
+		FechaHasta: *hasta,
+		Motivo:     strings.TrimSpace(req.Motivo),
+		Estado:     models.EstadoLicenciaProgramada,
+	}
+	if err := s.repo.Create(ctx, lic); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, "CREAR_LICENCIA", "licencia_senador", lic.ID, "",
+		fmt.Sprintf("%s: %s al %s", titular.GetNombreCompleto(), desde.Format("02/01/2006"), hasta.Format("02/01/2006")), "", "")
+
+	if err := s.procesar(ctx, lic, hoyLaPaz()); err != nil {
+		return lic, err
+	}
+	return lic, nil
+}
+
+// Anular cancela la licencia y devuelve al titular los cupos heredados que no se usaron.
+func (s *LicenciaService) Anular(ctx context.Context, id string) error {
+	lic, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !lic.IsAbierta() {
+		return errors.New("la licencia ya fue finalizada o anulada")
+	}
+
+	if _, err := s.revertir(ctx, lic); err != nil {
+		return err
+	}
+	if err := s.repo.UpdateEstado(ctx, lic.ID, models.EstadoLicenciaAnulada); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ANULAR_LICENCIA", "licencia_senador", lic.ID, string(lic.Estado), string(models.EstadoLicenciaAnulada), "", "")
+	return nil
+}
+
+// ProcesarLicencias revisa las licencias abiertas: asigna al suplente los cupos generados
+// desde la última ejecución y cierra las que ya terminaron.
+func (s *LicenciaService) ProcesarLicencias(ctx context.Context) error {
+	licencias, err := s.repo.FindAbiertas(ctx)
+	if err != nil {
+		return err
+	}
+
+	hoy := hoyLaPaz()
+	for i := range licencias {
+		if err := s.procesar(ctx, &licencias[i], hoy); err != nil {
+			log.Printf("[Licencias] Error procesando licencia %s: %v", licencias[i].ID, err)
+		}
+	}
+	return nil
+}
+
+func (s *LicenciaService) procesar(ctx context.Context, lic *models.LicenciaSenador, hoy time.Time) error {
+	if hoy.After(lic.FechaHasta) {
+		if _, err := s.revertir(ctx, lic); err != nil {
+			return err
+		}
+		return s.cambiarEstado(ctx, lic, models.EstadoLicenciaFinalizada)
+	}
+
+	asignados, err := s.cupoService.AsignarCuposLicencia(ctx, lic)
+	if err != nil {
+		return err
+	}
+	for _, it := range asignados {
+		s.auditService.Log(ctx, "ASIGNAR_CUPO_LICENCIA", "cupo_derecho_item", it.ID, lic.TitularID, lic.SuplenteID, "", "")
+	}
+	if len(asignados) > 0 {
+		s.notifService.NotifyUser(ctx, lic.SuplenteID,
+			"Cupos asignados por licencia",
+			fmt.Sprintf("Se le asignaron %d cupo(s) de %s por licencia del %s al %s.",
+				len(asignados), lic.Titular.GetNombreCompleto(), lic.FechaDesde.Format("02/01/2006"), lic.FechaHasta.Format("02/01/2006")),
+			"cupo_licencia",
+			fmt.Sprintf("/cupos/derecho/%s/%d", lic.SuplenteID, lic.FechaDesde.Year()),
+		)
+	}
+
+	if lic.Estado == models.EstadoLicenciaProgramada && lic.IsActivaEn(hoy) {
+		return s.cambiarEstado(ctx, lic, models.EstadoLicenciaVigente)
+	}
+	return nil
+}
+
+func (s *LicenciaService) revertir(ctx context.Context, lic *models.LicenciaSenador) ([]models.CupoDerechoItem, error) {
+	revertidos, err := s.cupoService.RevertirCuposLicencia(ctx, lic)
+	if err != nil {
+		return nil, err
+	}
+	for _, it := range revertidos {
+		s.auditService.Log(ctx, "REVERTIR_CUPO_LICENCIA", "cupo_derecho_item", it.ID, lic.SuplenteID, lic.TitularID, "", "")
+	}
+	return revertidos, nil
+}
+
+func (s *LicenciaService) cambiarEstado(ctx context.Context, lic *models.LicenciaSenador, estado models.EstadoLicencia) error {
+	if err := s.repo.UpdateEstado(ctx, lic.ID, estado); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ESTADO_LICENCIA", "licencia_senador", lic.ID, string(lic.Estado), string(estado), "", "")
+	lic.Estado = estado
+	return nil
+}
+
+// hoyLaPaz retorna la fecha actual en La Paz, como fecha (UTC 00:00) comparable con columnas date.
+func hoyLaPaz() time.Time {
+	loc, err := time.LoadLocation("America/La_Paz")
+	if err != nil {
+		loc = time.Local
+	}
+	now := time.Now().In(loc)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Modos de MOROSIDAD_BLOQUEO_MODO.
+const (
+	MorosidadDesactivado = "DESACTIVADO"
+	MorosidadAutorizar   = "AUTORIZAR" // Bloquea salvo justificación de un administrador
+	MorosidadBloquear    = "BLOQUEAR"  // Bloquea sin excepción
+)
+
+// ErrMorosidadDescargo indica que el beneficiario tiene descargos vencidos y la acción no fue autorizada.
+type ErrMorosidadDescargo struct {
+	Vencidas       []models.Solicitud
+	PuedeAutorizar bool
+}
+
+func (e *ErrMorosidadDescargo) Error() string {
+	msg := "el beneficiario tiene descargos vencidos (" + resumenVencidas(e.Vencidas) + ") y no puede recibir nuevos pasajes hasta presentarlos"
+	if e.PuedeAutorizar {
+		msg += ". Un administrador puede autorizarlo registrando una justificación"
+	}
+	return msg
+}
+
+func resumenVencidas(vencidas []models.Solicitud) string {
+	partes := make([]string, 0, len(vencidas))
+	for _, sol := range vencidas {
+		partes = append(partes, fmt.Sprintf("%s: %d días de mora", sol.Codigo, sol.GetDiasMoraDescargo()))
+	}
+	return strings.Join(partes, ", ")
+}
+
+// MorosidadBeneficiario resume los descargos vencidos de un beneficiario para advertirlo en los formularios.
+type MorosidadBeneficiario struct {
+	Modo     string
+	Vencidas []models.Solicitud
+}
+
+func (m MorosidadBeneficiario) Bloquea() bool {
+	return m.Modo != MorosidadDesactivado && len(m.Vencidas) > 0
+}
+
+func (m MorosidadBeneficiario) PermiteAutorizacion() bool {
+	return m.Modo == MorosidadAutorizar
+}
+
+type MorosidadService struct {
+	solicitudRepo *repositories.SolicitudRepository
+	configService *ConfiguracionService
+	auditService  *AuditService
+}
+
+func NewMorosidadService(solicitudRepo *repositories.SolicitudRepository, configService *ConfiguracionService, auditService *AuditService) *MorosidadService {
+	return &MorosidadService{
+		solicitudRepo: solicitudRepo,
+		configService: configService,
+		auditService:  auditService,
+	}
+}
+
+func (s *MorosidadService) GetModo(ctx context.Context) string {
+	switch modo := strings.ToUpper(strings.TrimSpace(s.configService.GetValue(ctx, "MOROSIDAD_BLOQUEO_MODO"))); modo {
+	case MorosidadDesactivado, MorosidadBloquear:
+		return modo
+	default:
+		return MorosidadAutorizar
+	}
+}
+
+// GetDescargosVencidos retorna las solicitudes del beneficiario cuyo descargo superó la fecha límite,
+// sin contar la solicitud excluida (la que se está emitiendo).
+func (s *MorosidadService) GetDescargosVencidos(ctx context.Context, usuarioID, excluirSolicitudID string) ([]models.Solicitud, error) {
+	pendientes, err := s.solicitudRepo.FindPendientesDeDescargoByUsuario(ctx, usuarioID)
+	if err != nil {
+		return nil, err
+	}
+	var vencidas []models.Solicitud
+	for _, sol := range pendientes {
+		if sol.ID != excluirSolicitudID && sol.GetDiasMoraDescargo() > 0 {
+			vencidas = append(vencidas, sol)
+		}
+	}
+	return vencidas, nil
+}
+
+func (s *MorosidadService) GetMorosidad(ctx context.Context, usuarioID string) MorosidadBeneficiario {
+	m := MorosidadBeneficiario{Modo: s.GetModo(ctx)}
+	if m.Modo != MorosidadDesactivado {
+		m.Vencidas, _ = s.GetDescargosVencidos(ctx, usuarioID, "")
+	}
+	return m
+}
+
+// Evaluar bloquea la solicitud o emisión si el beneficiario está en mora, salvo que el modo lo permita
+// y un administrador haya registrado una justificación. Retorna las solicitudes vencidas omitidas.
+func (s *MorosidadService) Evaluar(ctx context.Context, usuarioID, excluirSolicitudID string, user *models.Usuario, justificacion string) ([]models.Solicitud, error) {
+	modo := s.GetModo(ctx)
+	if modo == MorosidadDesactivado {
+		return nil, nil
+	}
+	vencidas, err := s.GetDescargosVencidos(ctx, usuarioID, excluirSolicitudID)
+	if err != nil || len(vencidas) == 0 {
+		return nil, err
+	}
+	if modo == MorosidadAutorizar && user != nil && user.IsAdmin() && strings.TrimSpace(justificacion) != "" {
+		return vencidas, nil
+	}
+	return nil, &ErrMorosidadDescargo{Vencidas: vencidas, PuedeAutorizar: modo == MorosidadAutorizar}
+}
+
+// RegistrarOmision deja constancia en auditoría, dentro de la transacción tx, cuando un
+// administrador autoriza pese a la morosidad.
+func (s *MorosidadService) RegistrarOmision(ctx context.Context, tx *gorm.DB, entidad, id string, vencidas []models.Solicitud, justificacion string) error {
+	if len(vencidas) == 0 {
+		return nil
+	}
+	return s.auditService.LogTx(ctx, tx, "OMITIR_MOROSIDAD", entidad, id, resumenVencidas(vencidas), strings.TrimSpace(justificacion), "", "")
+}
@@ -45,31 +45,39 @@ func (s *NotificationService) NotifyAdmins(ctx context.Context, title, message,
 	}
 
 	for _, admin := range admins {
-		notif := models.Notification{
-			UserID:    admin.ID,
-			Title:     title,
-			Message:   message,
-			Type:      notifType,
-			TargetURL: targetURL,
-		}
-		if err := s.repo.Create(ctx, &notif); err == nil {
-			// Broadcast via WebSocket
-			Hub.Broadcast(map[string]interface{}{
-				"event":       "refresh_notifications",
-				"target_user": admin.ID,
-				"title":       title,
-				"message":     message,
-				"type":        notifType,
-				"url":         targetURL,
-			})
-
-			// Enviar Push a móvil (nuevo)
-			s.pushService.SendToUser(ctx, admin.ID, title, message, targetURL)
-		}
+		s.NotifyUser(ctx, admin.ID, title, message, notifType, targetURL)
 	}
 	return nil
 }
 
+// NotifyUser registra la notificación de un usuario y la difunde por WebSocket y Push.
+func (s *NotificationService) NotifyUser(ctx context.Context, userID, title, message, notifType, targetURL string) error {
+	notif := models.Notification{
+		UserID:    userID,
+		Title:     title,
+		Message:   message,
+		Type:      notifType,
+		TargetURL: targetURL,
+	}
+	if err := s.repo.Create(ctx, &notif); err != nil {
+		return err
+	}
+
+	// Broadcast via WebSocket
+	Hub.Broadcast(map[string]interface{}{
+		"event":       "refresh_notifications",
+		"target_user": userID,
+		"title":       title,
+		"message":     message,
+		"type":        notifType,
+		"url":         targetURL,
+	})
+
+	// Enviar Push a móvil (nuevo)
+	s.pushService.SendToUser(ctx, userID, title, message, targetURL)
+	return nil
+}
+
 func (s *NotificationService) NotifySolicitudCreated(ctx context.Context, sol *models.Solicitud) error {
 	title := "Nueva Solicitud: " + sol.Codigo
 
@@ -22,6 +22,11 @@ type PasajeService struct {
 	rutaRepo          *repositories.RutaRepository
 	emailService      *EmailService
 	auditService      *AuditService
+	tarifaService     *TarifaService
+	tipoCambioService *TipoCambioService
+	billeteService    *BilleteService
+	morosidadService  *MorosidadService
+	adjuntoService    *AdjuntoService
 }
 
 func NewPasajeService(
@@ -31,6 +36,11 @@ func NewPasajeService(
 	rutaRepo *repositories.RutaRepository,
 	emailService *EmailService,
 	auditService *AuditService,
+	tarifaService *TarifaService,
+	tipoCambioService *TipoCambioService,
+	billeteService *BilleteService,
+	morosidadService *MorosidadService,
+	adjuntoService *AdjuntoService,
 ) *PasajeService {
 	return &PasajeService{
 		repo:              repo,
@@ -39,15 +49,88 @@ func NewPasajeService(
 		rutaRepo:          rutaRepo,
 		emailService:      emailService,
 		auditService:      auditService,
+		tarifaService:     tarifaService,
+		tipoCambioService: tipoCambioService,
+		billeteService:    billeteService,
+		morosidadService:  morosidadService,
+		adjuntoService:    adjuntoService,
 	}
 }
 
+// verificarBillete valida el número de billete del pasaje contra los demás pasajes, descargos
+// y open tickets. Si el modo es ADVERTIR deja el mensaje en pasaje.AvisoBillete y devuelve el
+// aviso, que se audita recién cuando el pasaje se guardó.
+func (s *PasajeService) verificarBillete(ctx context.Context, pasaje *models.Pasaje) (*AvisoBillete, error) {
+	pasaje.AvisoBillete = ""
+	if pasaje.NumeroBillete == "" {
+		return nil, nil
+	}
+	solicitud, err := s.solicitudRepo.FindByID(ctx, pasaje.SolicitudID)
+	if err != nil {
+		return nil, err
+	}
+	candidato := repositories.UsoBillete{
+		Fuente:        repositories.UsoBilletePasaje,
+		RegistroID:    pasaje.ID,
+		NumeroBillete: pasaje.NumeroBillete,
+		UsuarioID:     solicitud.UsuarioID,
+		PasajeID:      pasaje.ID,
+	}
+	if pasaje.PasajeOriginalID != nil {
+		candidato.OriginalID = *pasaje.PasajeOriginalID
+	}
+	if pasaje.OpenTicketID != nil {
+		candidato.OpenTicketID = *pasaje.OpenTicketID
+	} else if pasaje.SolicitudItemID != nil && *pasaje.SolicitudItemID != "" {
+		if item, err := s.solicitudItemRepo.FindByID(ctx, *pasaje.SolicitudItemID); err == nil && item.OpenTicketID != nil {
+			candidato.OpenTicketID = *item.OpenTicketID
+		}
+	}
+	aviso, err := s.billeteService.Verificar(ctx, candidato)
+	if err != nil || aviso == nil {
+		return nil, err
+	}
+	pasaje.AvisoBillete = aviso.Mensaje
+	return aviso, nil
+}
+
+// fijarTipoCambio asigna la cotización de la moneda del pasaje a su fecha de emisión (o a hoy
+// si aún no la tiene). Al emitir se vuelve a fijar, por lo que el valor previo es provisional.
+func (s *PasajeService) fijarTipoCambio(ctx context.Context, pasaje *models.Pasaje) error {
+	pasaje.Moneda = pasaje.GetMoneda()
+	fecha := time.Now()
+	if pasaje.FechaEmision != nil {
+		fecha = *pasaje.FechaEmision
+	}
+	tasa, err := s.tipoCambioService.GetTasa(ctx, pasaje.Moneda, fecha)
+	if err != nil {
+		return err
+	}
+	pasaje.TipoCambio = tasa
+	return nil
+}
+
+func normalizarMoneda(moneda string) (string, error) {
+	moneda = strings.ToUpper(strings.TrimSpace(moneda))
+	if moneda == "" {
+		return models.MonedaBOB, nil
+	}
+	if !models.IsMonedaValida(moneda) {
+		return "", fmt.Errorf("moneda no válida: %s", moneda)
+	}
+	return moneda, nil
+}
+
 func (s *PasajeService) Create(ctx context.Context, solicitudID string, req dtos.CreatePasajeRequest, filePath string) (*models.Pasaje, error) {
 	if filePath == "" {
 		return nil, fmt.Errorf("el documento del pasaje (PDF) es obligatorio")
 	}
 
 	costo := utils.ParseFloat(req.Costo)
+	moneda, err := normalizarMoneda(req.Moneda)
+	if err != nil {
+		return nil, err
+	}
 	fechaVueloPtr, err := utils.ParseDateTime(req.FechaVuelo)
 	var fechaVuelo time.Time
 	if err == nil && fechaVueloPtr != nil {
@@ -61,13 +144,6 @@ func (s *PasajeService) Create(ctx context.Context, solicitudID string, req dtos
 
 	status := "REGISTRADO"
 
-	if req.NumeroBillete != "" {
-		existing, _ := s.repo.FindByNumeroBillete(ctx, req.NumeroBillete)
-		if existing != nil && existing.ID != "" {
-			return nil, fmt.Errorf("ya existe un pasaje con el número de billete %s", req.NumeroBillete)
-		}
-	}
-
 	// Rule: One active pasaje per item
 	if req.SolicitudItemID != "" {
 		item, err := s.solicitudItemRepo.FindByID(ctx, req.SolicitudItemID)
@@ -92,6 +168,7 @@ func (s *PasajeService) Create(ctx context.Context, solicitudID string, req dtos
 		Glosa:              req.Glosa,
 		Costo:              costo,
 		CostoUtilizado:     costo,
+		Moneda:             moneda,
 		Archivo:            filePath,
 	}
 
@@ -99,6 +176,16 @@ func (s *PasajeService) Create(ctx context.Context, solicitudID string, req dtos
 		fe := utils.ParseDate("2006-01-02", req.FechaEmision)
 		pasaje.FechaEmision = &fe
 	}
+	avisoBillete, err := s.verificarBillete(ctx, pasaje)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fijarTipoCambio(ctx, pasaje); err != nil {
+		return nil, err
+	}
+	if err := s.adjuntoService.VersionarPasaje(ctx, pasaje); err != nil {
+		return nil, err
+	}
 
 	err = s.repo.RunTransaction(func(repo *repositories.PasajeRepository, tx *gorm.DB) error {
 		if err := repo.Create(ctx, pasaje); err != nil {
@@ -116,6 +203,7 @@ func (s *PasajeService) Create(ctx context.Context, solicitudID string, req dtos
 	if err != nil {
 		return nil, err
 	}
+	s.billeteService.RegistrarAviso(ctx, avisoBillete, pasaje.ID)
 
 	// Email notifications are now handled upon "EMITIDO" status update, not creation.
 
@@ -142,18 +230,10 @@ func (s *PasajeService) GetByID(ctx context.Context, id string) (*models.Pasaje,
 	return s.repo.FindByID(ctx, id)
 }
 
-func (s *PasajeService) UpdateFromRequest(ctx context.Context, req dtos.UpdatePasajeRequest, archivo string, paseAbordo string) error {
+func (s *PasajeService) UpdateFromRequest(ctx context.Context, req dtos.UpdatePasajeRequest, archivo string, paseAbordo string) (*models.Pasaje, error) {
 	pasaje, err := s.repo.FindByID(ctx, req.ID)
 	if err != nil {
-		return err
-	}
-
-	// Validate duplicate ticket number
-	if req.NumeroBillete != "" {
-		existing, _ := s.repo.FindByNumeroBillete(ctx, req.NumeroBillete)
-		if existing != nil && existing.ID != "" && existing.ID != req.ID {
-			return fmt.Errorf("ya existe un pasaje con el número de billete %s", req.NumeroBillete)
-		}
+		return nil, err
 	}
 
 	pasaje.NumeroVuelo = req.NumeroVuelo
@@ -171,6 +251,9 @@ func (s *PasajeService) UpdateFromRequest(ctx context.Context, req dtos.UpdatePa
 
 	pasaje.Costo = utils.ParseFloat(req.Costo)
 	pasaje.CostoUtilizado = pasaje.Costo
+	if pasaje.Moneda, err = normalizarMoneda(req.Moneda); err != nil {
+		return nil, err
+	}
 
 	if fvPtr, err := utils.ParseDateTime(req.FechaVuelo); err == nil && fvPtr != nil {
 		pasaje.FechaVuelo = *fvPtr
@@ -192,10 +275,26 @@ func (s *PasajeService) UpdateFromRequest(ctx context.Context, req dtos.UpdatePa
 
 	// Ensure pasaje has an archive
 	if pasaje.Archivo == "" {
-		return fmt.Errorf("el pasaje debe tener un archivo PDF asociado")
+		return nil, fmt.Errorf("el pasaje debe tener un archivo PDF asociado")
+	}
+	var avisoBillete *AvisoBillete
+	if req.NumeroBillete != "" {
+		if avisoBillete, err = s.verificarBillete(ctx, pasaje); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.fijarTipoCambio(ctx, pasaje); err != nil {
+		return nil, err
+	}
+	if err := s.adjuntoService.VersionarPasaje(ctx, pasaje); err != nil {
+		return nil, err
 	}
 
-	return s.repo.Update(ctx, pasaje)
+	if err := s.repo.Update(ctx, pasaje); err != nil {
+		return nil, err
+	}
+	s.billeteService.RegistrarAviso(ctx, avisoBillete, pasaje.ID)
+	return pasaje, nil
 }
 
 func (s *PasajeService) Update(ctx context.Context, pasaje *models.Pasaje) error {
@@ -221,26 +320,60 @@ func (s *PasajeService) DevolverPasaje(ctx context.Context, req dtos.DevolverPas
 	return s.repo.Update(ctx, pasaje)
 }
 
-func (s *PasajeService) UpdateStatus(ctx context.Context, id string, status string, ticketPath string, pasePath string) error {
+// UpdateStatus cambia el estado del pasaje. Al emitir verifica la tarifa y la morosidad del
+// beneficiario; justificacion es la autorización de un administrador para emitir pese a la mora.
+func (s *PasajeService) UpdateStatus(ctx context.Context, id string, status string, ticketPath string, pasePath string, actor *models.Usuario, justificacion string) error {
 	pasaje, err := s.repo.FindByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	oldStatus := pasaje.EstadoPasajeCodigo
+	if oldStatus == models.EstadoPasajeReemitido {
+		return fmt.Errorf("el billete fue reemitido; gestione el pasaje vigente de la reemisión")
+	}
+	if oldStatus == models.EstadoPasajeEmitido && status == models.EstadoPasajeRegistrado && pasaje.IsReemision() {
+		return fmt.Errorf("no se puede revertir la emisión de una reemisión")
+	}
+	emision := &emisionVerificada{}
+	if status == models.EstadoPasajeEmitido && oldStatus != models.EstadoPasajeEmitido {
+		if emision, err = s.verificarEmision(ctx, pasaje, actor, justificacion, ""); err != nil {
+			return err
+		}
+	}
+
 	pasaje.EstadoPasajeCodigo = status
+	if status == models.EstadoPasajeEmitido && pasaje.FechaEmitido == nil {
+		ahora := time.Now()
+		pasaje.FechaEmitido = &ahora
+	}
+	if oldStatus == models.EstadoPasajeEmitido && status == models.EstadoPasajeRegistrado {
+		pasaje.CorreccionesEmision++
+	}
 	if ticketPath != "" {
 		pasaje.Archivo = ticketPath
 	}
 	if pasePath != "" {
 		pasaje.ArchivoPaseAbordo = pasePath
 	}
+	if err := s.adjuntoService.VersionarPasaje(ctx, pasaje); err != nil {
+		return err
+	}
 
-	if err := s.repo.Update(ctx, pasaje); err != nil {
+	err = s.repo.RunTransaction(func(repo *repositories.PasajeRepository, tx *gorm.DB) error {
+		if err := repo.Update(ctx, pasaje); err != nil {
+			return err
+		}
+		if err := s.tarifaService.RegistrarTx(ctx, tx, pasaje, emision.desviacion); err != nil {
+			return err
+		}
+		return s.morosidadService.RegistrarOmision(ctx, tx, "pasaje", id, emision.vencidas, justificacion)
+	})
+	if err != nil {
 		return err
 	}
 
-	s.auditService.Log(ctx, "CAMBIAR_ESTADO_PASAJE", "pasaje", id, "", status, "", "")
+	s.auditService.Log(ctx, "CAMBIAR_ESTADO_PASAJE", "pasaje", id, oldStatus, status, "", "")
 
 	// If Pasaje is EMITIDO, also update Request Item state to EMITIDO
 	if status == models.EstadoPasajeEmitido && pasaje.SolicitudItemID != nil {
@@ -303,6 +436,180 @@ func (s *PasajeService) UpdateStatus(ctx context.Context, id string, status stri
 	return nil
 }
 
+// emisionVerificada es lo que dejan los controles de emisión para registrar junto con ella.
+type emisionVerificada struct {
+	vencidas   []models.Solicitud
+	desviacion *models.DesviacionTarifa
+}
+
+// verificarEmision aplica los controles previos a emitir, comunes a la emisión y a la reemisión:
+// mora del beneficiario (un administrador la autoriza con justificacion), cotización a la fecha
+// de emisión y tarifa contratada (en una reemisión, justificacionSobreprecio autoriza el exceso).
+func (s *PasajeService) verificarEmision(ctx context.Context, pasaje *models.Pasaje, actor *models.Usuario, justificacion, justificacionSobreprecio string) (*emisionVerificada, error) {
+	sol, err := s.solicitudRepo.FindByID(ctx, pasaje.SolicitudID)
+	if err != nil {
+		return nil, err
+	}
+	vencidas, err := s.morosidadService.Evaluar(ctx, sol.UsuarioID, sol.ID, actor, justificacion)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.fijarTipoCambio(ctx, pasaje); err != nil {
+		return nil, err
+	}
+	desviacion, err := s.tarifaService.VerificarEmision(ctx, pasaje, actor, justificacionSobreprecio)
+	if err != nil {
+		return nil, err
+	}
+	return &emisionVerificada{vencidas: vencidas, desviacion: desviacion}, nil
+}
+
+// GetMorosidad resume los descargos vencidos del beneficiario del pasaje sin contar su propia
+// solicitud, como al emitir.
+func (s *PasajeService) GetMorosidad(ctx context.Context, pasaje *models.Pasaje) MorosidadBeneficiario {
+	m := MorosidadBeneficiario{Modo: s.morosidadService.GetModo(ctx)}
+	if m.Modo == MorosidadDesactivado {
+		return m
+	}
+	if sol, err := s.solicitudRepo.FindByID(ctx, pasaje.SolicitudID); err == nil {
+		m.Vencidas, _ = s.morosidadService.GetDescargosVencidos(ctx, sol.UsuarioID, sol.ID)
+	}
+	return m
+}
+
+// AutorizarSobreprecio permite emitir un pasaje cuyo costo excede la tarifa contratada.
+func (s *PasajeService) AutorizarSobreprecio(ctx context.Context, id string, justificacion string, actor *models.Usuario) error {
+	pasaje, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if pasaje.GetEstado() != models.EstadoPasajeRegistrado {
+		return fmt.Errorf("solo se autoriza el sobreprecio de pasajes en estado REGISTRADO")
+	}
+	if err := s.fijarTipoCambio(ctx, pasaje); err != nil {
+		return err
+	}
+	return s.tarifaService.AutorizarSobreprecio(ctx, pasaje, justificacion, actor)
+}
+
+// Reemitir registra el billete que la agencia emite al cambiar la fecha, ruta o vuelo de un
+// pasaje emitido. El nuevo pasaje queda EMITIDO y enlazado al original, que pasa a REEMITIDO;
+// pasa por los mismos controles que una emisión. Su costo es la tarifa del nuevo billete y lo
+// cobrado por el cambio queda en DiferenciaTarifa y PenalidadReemision, en la moneda del original.
+func (s *PasajeService) Reemitir(ctx context.Context, req dtos.ReemitirPasajeRequest, filePath string, actor *models.Usuario) (*models.Pasaje, error) {
+	if filePath == "" {
+		return nil, fmt.Errorf("el documento del nuevo billete (PDF) es obligatorio")
+	}
+	original, err := s.repo.FindByID(ctx, req.PasajeID)
+	if err != nil {
+		return nil, err
+	}
+	if original.GetEstado() != models.EstadoPasajeEmitido {
+		return nil, fmt.Errorf("solo se pueden reemitir pasajes en estado EMITIDO")
+	}
+	switch req.Motivo {
+	case models.MotivoReemisionFecha, models.MotivoReemisionRuta, models.MotivoReemisionVuelo:
+	default:
+		return nil, fmt.Errorf("motivo de reemisión no válido: %s", req.Motivo)
+	}
+
+	fechaVuelo, err := utils.ParseDateTime(req.FechaVuelo)
+	if err != nil || fechaVuelo == nil {
+		return nil, fmt.Errorf("fecha de vuelo inválida")
+	}
+	fechaEmision := utils.ParseDate("2006-01-02", req.FechaEmision)
+	costo := utils.ParseFloat(req.Costo)
+	if costo <= 0 {
+		return nil, fmt.Errorf("el costo del nuevo billete debe ser mayor a cero")
+	}
+	diferencia := utils.ParseFloat(req.DiferenciaTarifa)
+	penalidad := utils.ParseFloat(req.Penalidad)
+	if diferencia < 0 || penalidad < 0 {
+		return nil, fmt.Errorf("la diferencia de tarifa y la penalidad no pueden ser negativas")
+	}
+
+	rutaID := original.RutaID
+	if req.RutaID != "" {
+		rutaID = &req.RutaID
+	}
+	aerolineaID := original.AerolineaID
+	if req.AerolineaID != "" {
+		aerolineaID = &req.AerolineaID
+	}
+	originalID := original.ID
+	ahora := time.Now()
+
+	nuevo := &models.Pasaje{
+		SolicitudID:        original.SolicitudID,
+		SolicitudItemID:    original.SolicitudItemID,
+		EstadoPasajeCodigo: models.EstadoPasajeEmitido,
+		AerolineaID:        aerolineaID,
+		AgenciaID:          original.AgenciaID,
+		NumeroVuelo:        req.NumeroVuelo,
+		RutaID:             rutaID,
+		FechaVuelo:         *fechaVuelo,
+		FechaEmision:       &fechaEmision,
+		FechaEmitido:       &ahora,
+		NumeroBillete:      req.NumeroBillete,
+		NumeroFactura:      req.NumeroFactura,
+		Glosa:              req.Glosa,
+		Costo:              costo,
+		CostoUtilizado:     costo,
+		Moneda:             original.GetMoneda(),
+		Archivo:            filePath,
+		OpenTicketID:       original.OpenTicketID,
+		PasajeOriginalID:   &originalID,
+		MotivoReemision:    req.Motivo,
+		DiferenciaTarifa:   diferencia,
+		PenalidadReemision: penalidad,
+	}
+	avisoBillete, err := s.verificarBillete(ctx, nuevo)
+	if err != nil {
+		return nil, err
+	}
+	emision, err := s.verificarEmision(ctx, nuevo, actor, req.JustificacionMorosidad, req.JustificacionSobreprecio)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.adjuntoService.VersionarPasaje(ctx, nuevo); err != nil {
+		return nil, err
+	}
+
+	err = s.repo.RunTransaction(func(repo *repositories.PasajeRepository, tx *gorm.DB) error {
+		// El estado se vuelve a exigir en el UPDATE: dos reemisiones simultáneas del mismo
+		// billete bifurcarían la cadena (cada pasaje tiene a lo sumo un reemplazo).
+		res := tx.Model(&models.Pasaje{}).
+			Where("id = ? AND estado_pasaje_codigo = ?", original.ID, models.EstadoPasajeEmitido).
+			Updates(map[string]interface{}{"estado_pasaje_codigo": models.EstadoPasajeReemitido, "updated_by": actor.ID})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected != 1 {
+			return fmt.Errorf("el pasaje ya fue reemitido o cambió de estado; recargue la página")
+		}
+		if err := repo.Create(ctx, nuevo); err != nil {
+			return err
+		}
+		if err := s.tarifaService.RegistrarTx(ctx, tx, nuevo, emision.desviacion); err != nil {
+			return err
+		}
+		return s.morosidadService.RegistrarOmision(ctx, tx, "pasaje", nuevo.ID, emision.vencidas, req.JustificacionMorosidad)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, "REEMITIR_PASAJE", "pasaje", nuevo.ID, original.NumeroBillete, nuevo.NumeroBillete, "", "")
+	s.billeteService.RegistrarAviso(ctx, avisoBillete, nuevo.ID)
+
+	worker.GetPool().Submit(&EmissionEmailJob{
+		Service:  s,
+		PasajeID: nuevo.ID,
+	})
+
+	return nuevo, nil
+}
+
 // EmissionEmailJob encapsula la tarea de enviar un correo de emisión.
 type EmissionEmailJob struct {
 	Service  *PasajeService
@@ -517,18 +824,45 @@ func (s *PasajeService) UpdateServicioEmision(ctx context.Context, req dtos.Upda
 	if filePath != "" {
 		pasaje.ServicioArchivo = filePath
 	}
+	if err := s.adjuntoService.VersionarPasaje(ctx, pasaje); err != nil {
+		return err
+	}
 
 	return s.repo.Update(ctx, pasaje)
 }
 func (s *PasajeService) CreateCargo(ctx context.Context, req dtos.CreatePasajeCargoRequest, filePath string) error {
+	pasaje, err := s.repo.FindByID(ctx, req.PasajeID)
+	if err != nil {
+		return err
+	}
+	moneda, err := normalizarMoneda(req.Moneda)
+	if err != nil {
+		return err
+	}
+
+	// El cargo se convierte con la cotización de la emisión del pasaje.
+	fecha := time.Now()
+	if pasaje.FechaEmision != nil {
+		fecha = *pasaje.FechaEmision
+	}
+	tasa, err := s.tipoCambioService.GetTasa(ctx, moneda, fecha)
+	if err != nil {
+		return err
+	}
+
 	monto := utils.ParseFloat(req.Monto)
 	cargo := &models.PasajeCargo{
-		PasajeID: req.PasajeID,
-		Tipo:     req.Tipo,
-		Factura:  req.Factura,
-		Monto:    monto,
-		Archivo:  filePath,
-		Glosa:    req.Glosa,
+		PasajeID:   req.PasajeID,
+		Tipo:       req.Tipo,
+		Factura:    req.Factura,
+		Monto:      monto,
+		Moneda:     moneda,
+		TipoCambio: tasa,
+		Archivo:    filePath,
+		Glosa:      req.Glosa,
+	}
+	if cargo.AdjuntoID, err = s.adjuntoService.Versionar(ctx, models.AdjuntoCargo, nil, filePath); err != nil {
+		return err
 	}
 
 	return s.repo.GetDB().WithContext(ctx).Create(cargo).Error
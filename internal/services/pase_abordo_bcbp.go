@@ -0,0 +1,231 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sistema-pasajes/internal/models"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+var ErrNoEsBCBP = errors.New("el código no tiene el formato IATA BCBP")
+
+var (
+	reBCBPVuelo = regexp.MustCompile(`^([A-Z0-9]{2}[A-Z]?)?0*([0-9]+[A-Z]?)$`)
+	reNoLetras  = regexp.MustCompile(`[^A-Z ]`)
+)
+
+// PaseBCBP son los datos del código de barras de un pase de abordar (IATA Res. 792).
+type PaseBCBP struct {
+	Pasajero string // APELLIDO/NOMBRE, truncado a 20 caracteres
+	Tramos   []TramoBCBP
+}
+
+type TramoBCBP struct {
+	PNR           string
+	Origen        string
+	Destino       string
+	Aerolinea     string
+	Vuelo         string
+	DiaJuliano    int
+	Asiento       string
+	NumeroBillete string // Solo si el código incluye la sección condicional
+}
+
+func (t TramoBCBP) GetVueloDisplay() string {
+	return t.Aerolinea + " " + t.Vuelo
+}
+
+// ParseBCBP interpreta los campos obligatorios de cada tramo y, si está presente, el número
+// de billete de la sección condicional.
+func ParseBCBP(s string) (*PaseBCBP, error) {
+	if len(s) < 60 || s[0] != 'M' || s[1] < '1' || s[1] > '4' {
+		return nil, ErrNoEsBCBP
+	}
+	pase := &PaseBCBP{Pasajero: strings.TrimSpace(s[2:22])}
+	legs := int(s[1] - '0')
+
+	pos := 23
+	for i := 0; i < legs; i++ {
+		if pos+37 > len(s) {
+			return nil, ErrNoEsBCBP
+		}
+		f := s[pos : pos+37]
+		dia, _ := strconv.Atoi(strings.TrimSpace(f[21:24]))
+		tramo := TramoBCBP{
+			PNR:        strings.TrimSpace(f[0:7]),
+			Origen:     strings.TrimSpace(f[7:10]),
+			Destino:    strings.TrimSpace(f[10:13]),
+			Aerolinea:  strings.TrimSpace(f[13:16]),
+			Vuelo:      strings.TrimLeft(strings.TrimSpace(f[16:21]), "0"),
+			DiaJuliano: dia,
+			Asiento:    strings.TrimLeft(strings.TrimSpace(f[25:29]), "0"),
+		}
+		size, err := strconv.ParseUint(f[35:37], 16, 8)
+		if err != nil {
+			return nil, ErrNoEsBCBP
+		}
+		pos += 37
+		end := min(pos+int(size), len(s))
+		tramo.NumeroBillete = billeteCondicionalBCBP(s[pos:end], i == 0)
+		pase.Tramos = append(pase.Tramos, tramo)
+		pos = end
+	}
+	return pase, nil
+}
+
+// billeteCondicionalBCBP extrae código numérico de aerolínea + número de documento de la
+// sección repetida. El primer tramo la antecede con la sección única ('>' versión tamaño).
+func billeteCondicionalBCBP(cond string, primero bool) string {
+	if primero {
+		if len(cond) < 4 || cond[0] != '>' {
+			return ""
+		}
+		unica, err := strconv.ParseUint(cond[2:4], 16, 8)
+		if err != nil || 4+int(unica) > len(cond) {
+			return ""
+		}
+		cond = cond[4+int(unica):]
+	}
+	if len(cond) < 2+13 {
+		return ""
+	}
+	return models.NormalizarBillete(cond[2:5] + cond[5:15])
+}
+
+// TramoCorrespondiente retorna el tramo del código con el mismo origen y destino, o el
+// primero si ninguno coincide.
+func (p *PaseBCBP) TramoCorrespondiente(origen, destino string) TramoBCBP {
+	for _, t := range p.Tramos {
+		if t.Origen == origen && t.Destino == destino {
+			return t
+		}
+	}
+	return p.Tramos[0]
+}
+
+// CompararPaseBCBP lista las diferencias entre el pase leído y lo declarado en el tramo (o,
+// en su defecto, en el pasaje) y el viajero.
+func CompararPaseBCBP(pase *PaseBCBP, tramo models.DescargoTramo, pasaje *models.Pasaje, viajero *models.Usuario) []string {
+	var difs []string
+	t := pase.TramoCorrespondiente(tramo.GetOrigenIATA(), tramo.GetDestinoIATA())
+
+	if viajero != nil && !coincideNombreBCBP(pase.Pasajero, viajero.GetNombreCompleto()) {
+		difs = append(difs, fmt.Sprintf("Pasajero del pase %q no coincide con el viajero %s.", pase.Pasajero, viajero.GetNombreCompleto()))
+	}
+
+	if tramo.GetOrigenIATA() != "" && (t.Origen != tramo.GetOrigenIATA() || t.Destino != tramo.GetDestinoIATA()) {
+		difs = append(difs, fmt.Sprintf("Ruta del pase %s-%s distinta al tramo %s-%s.", t.Origen, t.Destino, tramo.GetOrigenIATA(), tramo.GetDestinoIATA()))
+	}
+
+	vuelo := tramo.NumeroVuelo
+	if vuelo == "" && pasaje != nil {
+		vuelo = pasaje.NumeroVuelo
+	}
+	if vuelo != "" && !coincideVueloBCBP(t, vuelo) {
+		difs = append(difs, fmt.Sprintf("Vuelo del pase %s distinto al declarado %s.", t.GetVueloDisplay(), vuelo))
+	}
+
+	fecha := tramo.Fecha
+	if fecha == nil && pasaje != nil && !pasaje.FechaVuelo.IsZero() {
+		fecha = &pasaje.FechaVuelo
+	}
+	if fecha != nil && t.DiaJuliano > 0 {
+		// El día juliano del pase es local; la fecha guardada puede venir en UTC.
+		local := fecha.In(locLaPaz)
+		if t.DiaJuliano != local.YearDay() {
+			difs = append(difs, fmt.Sprintf("Fecha del pase %s distinta a la del tramo %s.",
+				fechaJulianaBCBP(t.DiaJuliano, local).Format("02/01/2006"), local.Format("02/01/2006")))
+		}
+	}
+
+	billete := tramo.Billete
+	if billete == "" && pasaje != nil {
+		billete = pasaje.NumeroBillete
+	}
+	if b := models.NormalizarBillete(billete); t.NumeroBillete != "" && b != "" &&
+		!strings.HasSuffix(t.NumeroBillete, b) && !strings.HasSuffix(b, t.NumeroBillete) {
+		difs = append(difs, fmt.Sprintf("Billete del pase %s distinto al declarado %s.", t.NumeroBillete, billete))
+	}
+	return difs
+}
+
+// coincideNombreBCBP acepta el nombre del pase si cada apellido y al menos un nombre están
+// en el nombre del viajero. El último componente puede venir truncado.
+func coincideNombreBCBP(pasajero, nombreCompleto string) bool {
+	partes := strings.SplitN(normalizarNombreBCBP(pasajero), "/", 2)
+	viajero := strings.Fields(reNoLetras.ReplaceAllString(normalizarNombreBCBP(nombreCompleto), " "))
+	contiene := func(token string) bool {
+		for _, v := range viajero {
+			if strings.HasPrefix(v, token) {
+				return true
+			}
+		}
+		return false
+	}
+
+	apellidos := strings.Fields(reNoLetras.ReplaceAllString(partes[0], " "))
+	if len(apellidos) == 0 {
+		return false
+	}
+	for _, a := range apellidos {
+		if !contiene(a) {
+			return false
+		}
+	}
+	if len(partes) < 2 {
+		return true
+	}
+	nombres := strings.Fields(reNoLetras.ReplaceAllString(partes[1], " "))
+	if len(nombres) == 0 {
+		return true
+	}
+	for _, n := range nombres {
+		// Títulos que algunas aerolíneas agregan al nombre
+		if n == "MR" || n == "MRS" || n == "MS" || n == "SR" || n == "SRA" {
+			continue
+		}
+		if contiene(n) {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizarNombreBCBP(s string) string {
+	var sb strings.Builder
+	for _, r := range norm.NFD.String(strings.ToUpper(s)) {
+		if r < 0x300 || r > 0x36f {
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+func coincideVueloBCBP(t TramoBCBP, declarado string) bool {
+	v := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(declarado))
+	m := reBCBPVuelo.FindStringSubmatch(v)
+	if m == nil {
+		return strings.HasSuffix(v, t.Vuelo)
+	}
+	if m[2] != t.Vuelo {
+		return false
+	}
+	return m[1] == "" || m[1] == t.Aerolinea
+}
+
+// fechaJulianaBCBP ubica el día del año del pase en el año más cercano a la fecha de referencia.
+func fechaJulianaBCBP(dia int, ref time.Time) time.Time {
+	mejor := time.Date(ref.Year(), 1, 1, 0, 0, 0, 0, ref.Location()).AddDate(0, 0, dia-1)
+	for _, y := range []int{ref.Year() - 1, ref.Year() + 1} {
+		f := time.Date(y, 1, 1, 0, 0, 0, 0, ref.Location()).AddDate(0, 0, dia-1)
+		if f.Sub(ref).Abs() < mejor.Sub(ref).Abs() {
+			mejor = f
+		}
+	}
+	return mejor
+}
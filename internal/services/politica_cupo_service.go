@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PoliticaCupoService struct {
+	repo         *repositories.PoliticaCupoRepository
+	cupoService  *CupoService
+	auditService *AuditService
+}
+
+func NewPoliticaCupoService(repo *repositories.PoliticaCupoRepository, cupoService *CupoService, auditService *AuditService) *PoliticaCupoService {
+	return &PoliticaCupoService{
+		repo:         repo,
+		cupoService:  cupoService,
+		auditService: auditService,
+	}
+}
+
+func (s *PoliticaCupoService) GetAll(ctx context.Context) ([]models.PoliticaCupo, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *PoliticaCupoService) GetByID(ctx context.Context, id string) (*models.PoliticaCupo, error) {
+	return s.repo.FindByID(ctx, id)
+}
+
+// GetVigenteActual retorna la política en vigor hoy.
+func (s *PoliticaCupoService) GetVigenteActual(ctx context.Context) *models.PoliticaCupo {
+	return s.cupoService.GetPoliticaEn(ctx, time.Now())
+}
+
+// CrearVersion registra una política como nueva versión. Las versiones anteriores con el
+// mismo nombre se conservan para trazabilidad de los periodos ya generados.
+func (s *PoliticaCupoService) CrearVersion(ctx context.Context, req dtos.CreatePoliticaCupoRequest, user *models.Usuario) (*models.PoliticaCupo, error) {
+	nombre := strings.TrimSpace(req.Nombre)
+	if nombre == "" {
+		return nil, errors.New("el nombre de la política es obligatorio")
+	}
+
+	desde := utils.ParseDatePtr("2006-01-02", req.VigenteDesde)
+	if desde == nil {
+		return nil, errors.New("la fecha de vigencia desde no es válida")
+	}
+	hasta := utils.ParseDatePtr("2006-01-02", req.VigenteHasta)
+	if hasta != nil && hasta.Before(*desde) {
+		return nil, errors.New("la vigencia hasta no puede ser anterior a la vigencia desde")
+	}
+
+	meses, err := normalizarMeses(req.MesesReceso)
+	if err != nil {
+		return nil, err
+	}
+	semanas, err := normalizarLunes(req.SemanasBloqueadas)
+	if err != nil {
+		return nil, err
+	}
+
+	semanasPorMes, _ := strconv.Atoi(strings.TrimSpace(req.SemanasPorMes))
+	arrastreMax, _ := strconv.Atoi(strings.TrimSpace(req.ArrastreMaximo))
+	if semanasPorMes < 0 || arrastreMax < 0 {
+		return nil, errors.New("los límites de semanas y arrastre no pueden ser negativos")
+	}
+
+	politica := &models.PoliticaCupo{
+		BaseModel:         models.BaseModel{CreatedBy: &user.ID},
+		Nombre:            nombre,
+		VigenteDesde:      *desde,
+		VigenteHasta:      hasta,
+		Activa:            true,
+		SemanasPorMes:     semanasPorMes,
+		MesesReceso:       meses,
+		SemanasBloqueadas: semanas,
+		PermiteArrastre:   req.PermiteArrastre,
+		ArrastreMaximo:    arrastreMax,
+		Observacion:       strings.TrimSpace(req.Observacion),
+	}
+
+	for i, d := range req.AjusteDeltas {
+		delta, err := strconv.Atoi(strings.TrimSpace(d))
+		if err != nil || delta == 0 {
+			continue
+		}
+		ajuste := models.PoliticaCupoAjuste{Delta: delta}
+		if i < len(req.AjusteTipos) {
+			ajuste.TipoSenador = strings.TrimSpace(req.AjusteTipos[i])
+		}
+		if i < len(req.AjusteDepartamentos) {
+			ajuste.DepartamentoCode = strings.TrimSpace(req.AjusteDepartamentos[i])
+		}
+		if i < len(req.AjusteMotivos) {
+			ajuste.Motivo = strings.TrimSpace(req.AjusteMotivos[i])
+		}
+		politica.Ajustes = append(politica.Ajustes, ajuste)
+	}
+
+	err = s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.PoliticaCupoRepository, tx *gorm.DB) error {
+		version, err := repoTx.MaxVersionByNombre(ctx, nombre)
+		if err != nil {
+			return err
+		}
+		politica.Version = version + 1
+		return repoTx.Create(ctx, politica)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, "CREAR_POLITICA_CUPO", "politica_cupo", politica.ID, "", politica.GetLabel()+": "+politica.GetResumen(), "", "")
+	return politica, nil
+}
+
+func (s *PoliticaCupoService) SetActiva(ctx context.Context, id string, activa bool) error {
+	politica, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateActiva(ctx, id, activa); err != nil {
+		return err
+	}
+
+	accion := "DESACTIVAR_POLITICA_CUPO"
+	if activa {
+		accion = "ACTIVAR_POLITICA_CUPO"
+	}
+	s.auditService.Log(ctx, accion, "politica_cupo", id, strconv.FormatBool(politica.Activa), strconv.FormatBool(activa), "", "")
+	return nil
+}
+
+func normalizarMeses(csv string) (string, error) {
+	var meses []string
+	for _, part := range strings.Split(csv, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		m, err := strconv.Atoi(part)
+		if err != nil || m < 1 || m > 12 {
+			return "", fmt.Errorf("mes de receso inválido: %s", part)
+		}
+		meses = append(meses, strconv.Itoa(m))
+	}
+	return strings.Join(meses, ","), nil
+}
+
+func normalizarLunes(csv string) (string, error) {
+	var fechas []string
+	for _, part := range strings.FieldsFunc(csv, func(r rune) bool { return r == ',' || r == '\n' || r == ' ' }) {
+		t, err := time.Parse("2006-01-02", strings.TrimSpace(part))
+		if err != nil {
+			return "", fmt.Errorf("fecha de semana bloqueada inválida: %s (use AAAA-MM-DD)", part)
+		}
+		if t.Weekday() != time.Monday {
+			return "", fmt.Errorf("la semana bloqueada %s debe indicarse por su lunes", part)
+		}
+		fechas = append(fechas, t.Format("2006-01-02"))
+	}
+	return strings.Join(fechas, ","), nil
+}
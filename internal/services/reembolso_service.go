@@ -0,0 +1,271 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const reembolsoPlazoDiasDefault = 10
+
+// ReembolsoService sigue los montos a devolver de los pasajes (PENDIENTE → DEPOSITADO →
+// VERIFICADO) y los concilia con los extractos de la cuenta de devoluciones.
+type ReembolsoService struct {
+	repo          *repositories.ExtractoBancarioRepository
+	configService *ConfiguracionService
+	auditService  *AuditService
+}
+
+func NewReembolsoService(repo *repositories.ExtractoBancarioRepository, configService *ConfiguracionService, auditService *AuditService) *ReembolsoService {
+	return &ReembolsoService{
+		repo:          repo,
+		configService: configService,
+		auditService:  auditService,
+	}
+}
+
+// ReembolsoViajero agrupa los reembolsos no verificados de un viajero.
+type ReembolsoViajero struct {
+	UsuarioID   string
+	Nombre      string
+	Pasajes     []ReembolsoPendiente
+	TotalBs     float64
+	Vencidos    int
+	MaxDiasMora int
+}
+
+type ReembolsoPendiente struct {
+	Pasaje      models.Pasaje
+	Estado      string
+	Dias        int
+	Vencido     bool
+	Observacion string
+}
+
+// GetPlazoDias retorna los días que tiene el viajero para depositar y que el depósito
+// aparezca en el extracto (REEMBOLSO_PLAZO_DIAS).
+func (s *ReembolsoService) GetPlazoDias(ctx context.Context) int {
+	if dias, err := strconv.Atoi(strings.TrimSpace(s.configService.GetValue(ctx, "REEMBOLSO_PLAZO_DIAS"))); err == nil && dias > 0 {
+		return dias
+	}
+	return reembolsoPlazoDiasDefault
+}
+
+func (s *ReembolsoService) GetExtractos(ctx context.Context) ([]models.ExtractoBancario, error) {
+	return s.repo.FindRecientes(ctx, 20)
+}
+
+func (s *ReembolsoService) GetMovimientosSinConciliar(ctx context.Context) ([]models.MovimientoBancario, error) {
+	return s.repo.FindMovimientosSinConciliar(ctx)
+}
+
+// ImportarExtracto registra los abonos del archivo (omitiendo los ya importados) y concilia.
+// Retorna el extracto guardado y cuántos reembolsos quedaron verificados.
+func (s *ReembolsoService) ImportarExtracto(ctx context.Context, nombre string, data []byte, archivo string, actorID string) (*models.ExtractoBancario, int, error) {
+	movimientos, err := parsearExtractoBancario(nombre, data)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(movimientos) == 0 {
+		return nil, 0, errors.New("el extracto no contiene abonos")
+	}
+
+	extracto := &models.ExtractoBancario{
+		BaseModel:     models.BaseModel{CreatedBy: &actorID},
+		Cuenta:        s.configService.GetValue(ctx, "BANCO_CUENTA_DEVOLUCION"),
+		ArchivoNombre: nombre,
+		Archivo:       archivo,
+	}
+	var nuevos []models.MovimientoBancario
+	for _, m := range movimientos {
+		if extracto.FechaDesde == nil || m.Fecha.Before(*extracto.FechaDesde) {
+			f := m.Fecha
+			extracto.FechaDesde = &f
+		}
+		if extracto.FechaHasta == nil || m.Fecha.After(*extracto.FechaHasta) {
+			f := m.Fecha
+			extracto.FechaHasta = &f
+		}
+		if s.repo.ExisteMovimiento(ctx, m.Fecha, m.Referencia, m.Monto) {
+			extracto.Duplicados++
+			continue
+		}
+		m.CreatedBy = &actorID
+		nuevos = append(nuevos, m)
+	}
+	extracto.Movimientos = len(nuevos)
+
+	if err := s.repo.Create(ctx, extracto, nuevos); err != nil {
+		return nil, 0, err
+	}
+	s.auditService.Log(ctx, "IMPORTAR_EXTRACTO", "reembolso", extracto.ID, "",
+		fmt.Sprintf("%s: %d abonos nuevos, %d duplicados", nombre, extracto.Movimientos, extracto.Duplicados), "", "")
+
+	verificados, err := s.Conciliar(ctx)
+	return extracto, verificados, err
+}
+
+// minBoletaEnDescripcion es el largo mínimo de una boleta para buscarla entre las palabras de
+// la descripción; una más corta se confunde con fechas, montos o números de cuenta.
+const minBoletaEnDescripcion = 6
+
+// Conciliar asocia los abonos sin conciliar con los reembolsos no verificados cuya boleta
+// coincide con la referencia (o es una palabra completa de la descripción) y cuyo monto en Bs
+// es el mismo. Si la boleta coincide pero el monto no, o si el abono corresponde a más de un
+// reembolso, lo deja sin conciliar con una observación.
+func (s *ReembolsoService) Conciliar(ctx context.Context) (int, error) {
+	if err := s.repo.LiberarHuerfanos(ctx); err != nil {
+		return 0, err
+	}
+	movimientos, err := s.repo.FindMovimientosSinConciliar(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pasajes, err := s.repo.FindReembolsosNoVerificados(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	verificados := 0
+	usados := make(map[string]bool)
+	for _, m := range movimientos {
+		referencia := models.NormalizarBillete(m.Referencia)
+		palabras := palabrasDescripcion(m.Descripcion)
+		observacion := ""
+
+		var candidatos []*models.Pasaje
+		for i := range pasajes {
+			p := &pasajes[i]
+			boleta := models.NormalizarBillete(p.NroBoletaDeposito)
+			if boleta == "" || usados[p.ID] {
+				continue
+			}
+			if boleta != referencia && (len(boleta) < minBoletaEnDescripcion || !palabras[boleta]) {
+				continue
+			}
+			if math.Abs(m.Monto-p.GetMontoReembolsoBs()) > 0.01 {
+				observacion = fmt.Sprintf("Boleta %s coincide, pero el reembolso es %.2f Bs", p.NroBoletaDeposito, p.GetMontoReembolsoBs())
+				continue
+			}
+			candidatos = append(candidatos, p)
+		}
+
+		switch len(candidatos) {
+		case 0:
+		case 1:
+			p := candidatos[0]
+			if err := s.repo.Vincular(ctx, m.ID, p.ID); err != nil {
+				return verificados, err
+			}
+			usados[p.ID] = true
+			observacion = ""
+			verificados++
+			s.auditService.Log(ctx, "VERIFICAR_REEMBOLSO", "reembolso", p.ID, p.GetEstadoReembolso(),
+				fmt.Sprintf("Boleta %s · %.2f Bs · %s", p.NroBoletaDeposito, m.Monto, m.Fecha.Format("02/01/2006")), "", "")
+		default:
+			observacion = fmt.Sprintf("Coincide con %d reembolsos; concílielo manualmente", len(candidatos))
+		}
+
+		if observacion != m.Observacion {
+			_ = s.repo.UpdateObservacion(ctx, m.ID, observacion)
+		}
+	}
+	return verificados, nil
+}
+
+// palabrasDescripcion normaliza las palabras de la descripción de un abono, tanto enteras
+// ("123-456" → "123456") como partidas en sus separadores ("123", "456").
+func palabrasDescripcion(descripcion string) map[string]bool {
+	palabras := make(map[string]bool)
+	for _, f := range strings.Fields(descripcion) {
+		if n := models.NormalizarBillete(f); n != "" {
+			palabras[n] = true
+		}
+		for _, parte := range strings.FieldsFunc(f, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+			palabras[models.NormalizarBillete(parte)] = true
+		}
+	}
+	return palabras
+}
+
+// VincularManual concilia un abono con un reembolso cuando la boleta se registró con otro
+// número (depósito de un tercero, referencia del banco distinta, etc.).
+func (s *ReembolsoService) VincularManual(ctx context.Context, movimientoID, pasajeID string) error {
+	m, err := s.repo.FindMovimientoByID(ctx, movimientoID)
+	if err != nil {
+		return errors.New("movimiento no encontrado")
+	}
+	if m.IsConciliado() {
+		return errors.New("el movimiento ya está conciliado")
+	}
+	if err := s.repo.Vincular(ctx, movimientoID, pasajeID); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "VERIFICAR_REEMBOLSO", "reembolso", pasajeID, "",
+		fmt.Sprintf("Manual · %s · %.2f Bs · %s", m.Referencia, m.Monto, m.Fecha.Format("02/01/2006")), "", "")
+	return nil
+}
+
+// GetResumenPorViajero agrupa los reembolsos no verificados por viajero, primero los que
+// tienen reembolsos vencidos.
+func (s *ReembolsoService) GetResumenPorViajero(ctx context.Context) ([]ReembolsoViajero, error) {
+	pasajes, err := s.repo.FindReembolsosNoVerificados(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plazo := s.GetPlazoDias(ctx)
+	hoy := time.Now()
+
+	index := make(map[string]int)
+	var resumen []ReembolsoViajero
+	for _, p := range pasajes {
+		if p.Solicitud == nil {
+			continue
+		}
+		dias := int(hoy.Sub(p.GetFechaReferenciaReembolso()).Hours() / 24)
+		if dias < 0 {
+			dias = 0
+		}
+		item := ReembolsoPendiente{
+			Pasaje:  p,
+			Estado:  p.GetEstadoReembolso(),
+			Dias:    dias,
+			Vencido: dias > plazo,
+		}
+
+		i, ok := index[p.Solicitud.UsuarioID]
+		if !ok {
+			i = len(resumen)
+			index[p.Solicitud.UsuarioID] = i
+			resumen = append(resumen, ReembolsoViajero{
+				UsuarioID: p.Solicitud.UsuarioID,
+				Nombre:    p.Solicitud.Usuario.GetNombreCompleto(),
+			})
+		}
+		v := &resumen[i]
+		v.Pasajes = append(v.Pasajes, item)
+		v.TotalBs += p.GetMontoReembolsoBs()
+		if item.Vencido {
+			v.Vencidos++
+		}
+		if dias > v.MaxDiasMora {
+			v.MaxDiasMora = dias
+		}
+	}
+
+	sort.SliceStable(resumen, func(i, j int) bool {
+		if (resumen[i].Vencidos > 0) != (resumen[j].Vencidos > 0) {
+			return resumen[i].Vencidos > 0
+		}
+		return resumen[i].MaxDiasMora > resumen[j].MaxDiasMora
+	})
+	return resumen, nil
+}
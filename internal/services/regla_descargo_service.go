@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+)
+
+type ReglaDescargoService struct {
+	repo              *repositories.ReglaDescargoRepository
+	tipoSolicitudRepo *repositories.TipoSolicitudRepository
+	auditService      *AuditService
+}
+
+func NewReglaDescargoService(repo *repositories.ReglaDescargoRepository, tipoSolicitudRepo *repositories.TipoSolicitudRepository, auditService *AuditService) *ReglaDescargoService {
+	return &ReglaDescargoService{
+		repo:              repo,
+		tipoSolicitudRepo: tipoSolicitudRepo,
+		auditService:      auditService,
+	}
+}
+
+// CargarReglas actualiza las reglas con que se evalúa la completitud de los descargos.
+func (s *ReglaDescargoService) CargarReglas(ctx context.Context) error {
+	reglas, err := s.repo.FindAll(ctx)
+	if err != nil {
+		return err
+	}
+	models.SetReglasDescargo(reglas)
+	slog.Info("Reglas de descargo cargadas", "reglas", len(reglas))
+	return nil
+}
+
+func (s *ReglaDescargoService) GetAll(ctx context.Context) ([]models.ReglaDescargo, error) {
+	return s.repo.FindAll(ctx)
+}
+
+func (s *ReglaDescargoService) Create(ctx context.Context, req dtos.CreateReglaDescargoRequest, actor *models.Usuario) error {
+	requisito, ok := models.GetRequisitoDescargo(req.Requisito)
+	if !ok {
+		return fmt.Errorf("requisito no válido: %s", req.Requisito)
+	}
+	regla := &models.ReglaDescargo{
+		BaseModel:   models.BaseModel{CreatedBy: &actor.ID},
+		Requisito:   requisito.Codigo,
+		Etiqueta:    strings.TrimSpace(req.Etiqueta),
+		MinLongitud: utils.StrToInt(req.MinLongitud, 0),
+		Activo:      true,
+	}
+	if regla.Etiqueta == "" {
+		regla.Etiqueta = requisito.Nombre
+	}
+	if regla.MinLongitud < 0 || (requisito.Minimo == "" && regla.MinLongitud != 0) {
+		return errors.New("el mínimo no aplica o no es válido para este requisito")
+	}
+
+	if tipo := strings.TrimSpace(req.TipoSolicitud); tipo != "" {
+		ts, err := s.tipoSolicitudRepo.FindByCodigo(ctx, tipo)
+		if err != nil {
+			return errors.New("el tipo de solicitud no existe")
+		}
+		if concepto := strings.TrimSpace(req.Concepto); concepto != "" && concepto != ts.ConceptoViajeCodigo {
+			return errors.New("el tipo de solicitud no pertenece al concepto elegido")
+		}
+		regla.TipoSolicitudCodigo = &ts.Codigo
+		regla.ConceptoCodigo = &ts.ConceptoViajeCodigo
+	} else if concepto := strings.TrimSpace(req.Concepto); concepto != "" {
+		regla.ConceptoCodigo = &concepto
+	}
+
+	if err := s.repo.Create(ctx, regla); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "CREAR_REGLA_DESCARGO", "regla_descargo", regla.ID, "", fmt.Sprintf("%s (%s)", regla.Etiqueta, regla.GetAmbito()), "", "")
+	return s.CargarReglas(ctx)
+}
+
+func (s *ReglaDescargoService) Update(ctx context.Context, id string, req dtos.UpdateReglaDescargoRequest) error {
+	regla, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	requisito, _ := models.GetRequisitoDescargo(regla.Requisito)
+	anterior := fmt.Sprintf("%s (mín. %d)", regla.Etiqueta, regla.MinLongitud)
+
+	regla.Etiqueta = strings.TrimSpace(req.Etiqueta)
+	regla.MinLongitud = utils.StrToInt(req.MinLongitud, 0)
+	if regla.Etiqueta == "" {
+		return errors.New("la etiqueta es obligatoria")
+	}
+	if regla.MinLongitud < 0 || (requisito.Minimo == "" && regla.MinLongitud != 0) {
+		return errors.New("el mínimo no aplica o no es válido para este requisito")
+	}
+
+	if err := s.repo.Update(ctx, regla); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "EDITAR_REGLA_DESCARGO", "regla_descargo", id, anterior, fmt.Sprintf("%s (mín. %d)", regla.Etiqueta, regla.MinLongitud), "", "")
+	return s.CargarReglas(ctx)
+}
+
+func (s *ReglaDescargoService) SetActivo(ctx context.Context, id string, activo bool) error {
+	regla, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdateActivo(ctx, id, activo); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ESTADO_REGLA_DESCARGO", "regla_descargo", id, fmt.Sprintf("%t", regla.Activo), fmt.Sprintf("%t", activo), "", "")
+	return s.CargarReglas(ctx)
+}
+
+func (s *ReglaDescargoService) Delete(ctx context.Context, id string) error {
+	regla, err := s.repo.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ELIMINAR_REGLA_DESCARGO", "regla_descargo", id, regla.Etiqueta, "", "", "")
+	return s.CargarReglas(ctx)
+}
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sistema-pasajes/internal/utils"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const agenciaPlazoEmisionHorasDefault = 24
+
+// MetricasAgencia resume el servicio de una agencia en un mes (Mes 0 = todo el año).
+type MetricasAgencia struct {
+	AgenciaID string
+	Agencia   string
+	Mes       int
+
+	Emitidos     int // Emisiones nuevas; las reemisiones se cuentan aparte
+	ConTiempo    int // Emisiones con fecha de aprobación conocida
+	HorasTotal   float64
+	HorasMaximo  float64
+	DentroPlazo  int
+	Reemisiones  int
+	Penalidades  float64 // Bs
+	Correcciones int
+	PlazoHoras   int
+}
+
+func (m MetricasAgencia) GetHorasPromedio() float64 {
+	if m.ConTiempo == 0 {
+		return 0
+	}
+	return m.HorasTotal / float64(m.ConTiempo)
+}
+
+func (m MetricasAgencia) GetPorcentajeDentroPlazo() float64 {
+	if m.ConTiempo == 0 {
+		return 0
+	}
+	return float64(m.DentroPlazo) / float64(m.ConTiempo) * 100
+}
+
+func (m MetricasAgencia) GetMesNombre() string {
+	if m.Mes == 0 {
+		return "Total"
+	}
+	return utils.GetMonthNames()[m.Mes]
+}
+
+func (m MetricasAgencia) GetCumplimientoBadgeClass() string {
+	switch pct := m.GetPorcentajeDentroPlazo(); {
+	case m.ConTiempo == 0:
+		return "bg-neutral-50 text-neutral-500 border-neutral-200"
+	case pct >= 90:
+		return "bg-success-50 text-success-700 border-success-200"
+	case pct >= 70:
+		return "bg-warning-50 text-warning-700 border-warning-200"
+	default:
+		return "bg-danger-50 text-danger-700 border-danger-200"
+	}
+}
+
+func (m *MetricasAgencia) acumular(o MetricasAgencia) {
+	m.Emitidos += o.Emitidos
+	m.ConTiempo += o.ConTiempo
+	m.HorasTotal += o.HorasTotal
+	m.HorasMaximo = max(m.HorasMaximo, o.HorasMaximo)
+	m.DentroPlazo += o.DentroPlazo
+	m.Reemisiones += o.Reemisiones
+	m.Penalidades += o.Penalidades
+	m.Correcciones += o.Correcciones
+}
+
+// ScorecardAgencia agrupa el total anual de una agencia con el detalle de los meses con actividad.
+type ScorecardAgencia struct {
+	Total MetricasAgencia
+	Meses []MetricasAgencia
+}
+
+// GetPlazoEmisionHoras es el tiempo esperado entre la aprobación del tramo y la emisión del billete.
+func (s *ReportService) GetPlazoEmisionHoras(ctx context.Context) int {
+	if horas, err := strconv.Atoi(strings.TrimSpace(s.configService.GetValue(ctx, "AGENCIA_PLAZO_EMISION_HORAS"))); err == nil && horas > 0 {
+		return horas
+	}
+	return agenciaPlazoEmisionHorasDefault
+}
+
+// GetScorecardAgencias calcula, por agencia y mes de emisión, el tiempo desde la aprobación del
+// tramo hasta la emisión, las reemisiones, las penalidades cobradas y las emisiones corregidas.
+func (s *ReportService) GetScorecardAgencias(ctx context.Context, anio int) ([]ScorecardAgencia, error) {
+	desde := time.Date(anio, 1, 1, 0, 0, 0, 0, time.Local)
+	emisiones, err := s.pasajeRepo.FindEmisionesAgencia(ctx, desde, desde.AddDate(1, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+	plazo := s.GetPlazoEmisionHoras(ctx)
+
+	porMes := make(map[string]map[int]*MetricasAgencia)
+	nombres := make(map[string]string)
+	for _, e := range emisiones {
+		nombres[e.AgenciaID] = e.AgenciaNombre
+		if porMes[e.AgenciaID] == nil {
+			porMes[e.AgenciaID] = make(map[int]*MetricasAgencia)
+		}
+		mes := int(e.Emitido.Month())
+		m, ok := porMes[e.AgenciaID][mes]
+		if !ok {
+			m = &MetricasAgencia{AgenciaID: e.AgenciaID, Agencia: e.AgenciaNombre, Mes: mes, PlazoHoras: plazo}
+			porMes[e.AgenciaID][mes] = m
+		}
+
+		m.Penalidades += e.Penalidad
+		m.Correcciones += e.Correcciones
+		if e.EsReemision {
+			m.Reemisiones++
+			continue
+		}
+		m.Emitidos++
+		// Las aprobaciones posteriores a la emisión (regularizaciones) no miden a la agencia.
+		if e.Aprobado == nil || e.Aprobado.After(e.Emitido) {
+			continue
+		}
+		horas := e.Emitido.Sub(*e.Aprobado).Hours()
+		m.ConTiempo++
+		m.HorasTotal += horas
+		m.HorasMaximo = max(m.HorasMaximo, horas)
+		if horas <= float64(plazo) {
+			m.DentroPlazo++
+		}
+	}
+
+	scorecards := make([]ScorecardAgencia, 0, len(porMes))
+	for agenciaID, meses := range porMes {
+		sc := ScorecardAgencia{Total: MetricasAgencia{AgenciaID: agenciaID, Agencia: nombres[agenciaID], PlazoHoras: plazo}}
+		for _, m := range meses {
+			sc.Total.acumular(*m)
+			sc.Meses = append(sc.Meses, *m)
+		}
+		sort.Slice(sc.Meses, func(i, j int) bool { return sc.Meses[i].Mes < sc.Meses[j].Mes })
+		scorecards = append(scorecards, sc)
+	}
+	sort.Slice(scorecards, func(i, j int) bool { return scorecards[i].Total.Agencia < scorecards[j].Total.Agencia })
+	return scorecards, nil
+}
+
+func (s *ReportService) GenerateScorecardAgenciasExcel(ctx context.Context, anio int) (*excelize.File, error) {
+	scorecards, err := s.GetScorecardAgencias(ctx, anio)
+	if err != nil {
+		return nil, err
+	}
+
+	f := excelize.NewFile()
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"0F7654"}, Pattern: 1},
+	})
+	alertStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "B91C1C"},
+	})
+
+	plazo := s.GetPlazoEmisionHoras(ctx)
+	headers := []string{"EMISIONES", "CON FECHA DE APROBACIÓN", "HORAS PROMEDIO", "HORAS MÁXIMO",
+		fmt.Sprintf("DENTRO DE %dH", plazo), "% CUMPLIMIENTO", "REEMISIONES", "PENALIDADES (BS)", "EMISIONES CORREGIDAS"}
+	writeHeaders := func(sheet string, prefijo []string) {
+		for i, h := range append(prefijo, headers...) {
+			cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+			f.SetCellValue(sheet, cell, h)
+			f.SetCellStyle(sheet, cell, cell, headerStyle)
+		}
+	}
+	writeMetricas := func(sheet string, row, col int, m MetricasAgencia) {
+		valores := []any{m.Emitidos, m.ConTiempo, fmt.Sprintf("%.1f", m.GetHorasPromedio()), fmt.Sprintf("%.1f", m.HorasMaximo),
+			m.DentroPlazo, fmt.Sprintf("%.1f", m.GetPorcentajeDentroPlazo()), m.Reemisiones, m.Penalidades, m.Correcciones}
+		for i, v := range valores {
+			cell, _ := excelize.CoordinatesToCellName(col+i, row)
+			f.SetCellValue(sheet, cell, v)
+		}
+		if m.ConTiempo > 0 && m.GetPorcentajeDentroPlazo() < 70 {
+			cell, _ := excelize.CoordinatesToCellName(col+5, row)
+			f.SetCellStyle(sheet, cell, cell, alertStyle)
+		}
+	}
+
+	sheetResumen := "Por Agencia"
+	f.SetSheetName("Sheet1", sheetResumen)
+	writeHeaders(sheetResumen, []string{"AGENCIA"})
+	for i, sc := range scorecards {
+		f.SetCellValue(sheetResumen, fmt.Sprintf("A%d", i+2), sc.Total.Agencia)
+		writeMetricas(sheetResumen, i+2, 2, sc.Total)
+	}
+	f.SetColWidth(sheetResumen, "A", "A", 35)
+	f.SetColWidth(sheetResumen, "B", "J", 18)
+
+	sheetMes := "Por Mes"
+	f.NewSheet(sheetMes)
+	writeHeaders(sheetMes, []string{"AGENCIA", "MES"})
+	row := 2
+	for _, sc := range scorecards {
+		for _, m := range sc.Meses {
+			f.SetCellValue(sheetMes, fmt.Sprintf("A%d", row), m.Agencia)
+			f.SetCellValue(sheetMes, fmt.Sprintf("B%d", row), m.GetMesNombre())
+			writeMetricas(sheetMes, row, 3, m)
+			row++
+		}
+	}
+	f.SetColWidth(sheetMes, "A", "A", 35)
+	f.SetColWidth(sheetMes, "B", "K", 18)
+
+	return f, nil
+}
@@ -1,11 +1,9 @@
 package services
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"os"
-	"os/exec"
 
 	"sistema-pasajes/internal/models"
 	"sistema-pasajes/internal/utils"
@@ -259,9 +257,9 @@ func (s *ReportService) GeneratePV05(ctx context.Context, descargo *models.Desca
 	if descargo.Solicitud != nil {
 		for _, item := range descargo.Solicitud.Items {
 			for _, p := range item.Pasajes {
-				totalEmitido += p.Costo
-				totalUtilizado += p.CostoUtilizado
-				totalEfectivo += p.MontoReembolso
+				totalEmitido += p.GetCostoBs()
+				totalUtilizado += p.GetCostoUtilizadoBs()
+				totalEfectivo += p.GetMontoReembolsoBs()
 			}
 		}
 	}
@@ -280,15 +278,15 @@ func (s *ReportService) GeneratePV05(ctx context.Context, descargo *models.Desca
 		for _, item := range descargo.Solicitud.Items {
 			for _, p := range item.Pasajes {
 				pdf.CellFormat(25, 6, tr(p.NumeroBillete), "1", 0, "C", false, 0, "")
-				pdf.CellFormat(70, 6, tr(p.GetRutaDisplay()), "1", 0, "L", false, 0, "")
-				pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", p.Costo), "1", 0, "R", false, 0, "")
-				pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", p.CostoUtilizado), "1", 0, "R", false, 0, "")
+				pdf.CellFormat(70, 6, tr(detalleRutaMoneda(p)), "1", 0, "L", false, 0, "")
+				pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", p.GetCostoBs()), "1", 0, "R", false, 0, "")
+				pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", p.GetCostoUtilizadoBs()), "1", 0, "R", false, 0, "")
 
 				if p.MontoReembolso > 0 {
 					pdf.SetTextColor(150, 0, 0)
 					pdf.SetFont("Arial", "B", 7)
 				}
-				pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", p.MontoReembolso), "1", 0, "R", false, 0, "")
+				pdf.CellFormat(25, 6, fmt.Sprintf("%.2f", p.GetMontoReembolsoBs()), "1", 0, "R", false, 0, "")
 				pdf.SetTextColor(0, 0, 0)
 				pdf.SetFont("Arial", "", 7)
 
@@ -319,7 +317,7 @@ func (s *ReportService) GeneratePV05(ctx context.Context, descargo *models.Desca
 		pdf.AddPage()
 		sigY = 30
 	}
-	s.drawSignatureBlock(pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA/RESPONSABLE PRESENTACION DEL DESCARGO", "", "")
+	s.drawSignatureBlock(ctx, pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA/RESPONSABLE PRESENTACION DEL DESCARGO", "", "")
 
 	// --- ANEXO AUTOMÁTICO DEL COMPROBANTE DE DEPÓSITO ---
 	// Se coloca al final
@@ -500,6 +498,10 @@ func (s *ReportService) GeneratePV06(ctx context.Context, descargo *models.Desca
 		pdf.CellFormat(80, 6, tr(hRetorno), "RB", 1, "L", false, 0, "")
 	}
 
+	if descargo.Viatico != nil {
+		s.drawViaticoBlock(pdf, tr, descargo.Viatico)
+	}
+
 	pdf.Ln(4)
 
 	// INFORME DETALLADO PV-06
@@ -787,9 +789,9 @@ func (s *ReportService) GeneratePV06(ctx context.Context, descargo *models.Desca
 		if descargo.Solicitud != nil {
 			for _, item := range descargo.Solicitud.Items {
 				for _, p := range item.Pasajes {
-					totalEmitido += p.Costo
-					totalUtilizado += p.CostoUtilizado
-					totalEfectivo += p.MontoReembolso
+					totalEmitido += p.GetCostoBs()
+					totalUtilizado += p.GetCostoUtilizadoBs()
+					totalEfectivo += p.GetMontoReembolsoBs()
 				}
 			}
 		}
@@ -811,15 +813,15 @@ func (s *ReportService) GeneratePV06(ctx context.Context, descargo *models.Desca
 				for _, p := range item.Pasajes {
 					pdf.SetX(3)
 					pdf.CellFormat(30, 7, tr(p.NumeroBillete), "1", 0, "C", false, 0, "")
-					pdf.CellFormat(75, 7, tr(p.GetRutaDisplay()), "1", 0, "L", false, 0, "")
-					pdf.CellFormat(25, 7, fmt.Sprintf("%.2f", p.Costo), "1", 0, "R", false, 0, "")
-					pdf.CellFormat(25, 7, fmt.Sprintf("%.2f", p.CostoUtilizado), "1", 0, "R", false, 0, "")
+					pdf.CellFormat(75, 7, tr(detalleRutaMoneda(p)), "1", 0, "L", false, 0, "")
+					pdf.CellFormat(25, 7, fmt.Sprintf("%.2f", p.GetCostoBs()), "1", 0, "R", false, 0, "")
+					pdf.CellFormat(25, 7, fmt.Sprintf("%.2f", p.GetCostoUtilizadoBs()), "1", 0, "R", false, 0, "")
 
 					if p.MontoReembolso > 0 {
 						pdf.SetTextColor(150, 0, 0)
 						pdf.SetFont("Arial", "B", 8)
 					}
-					pdf.CellFormat(25, 7, fmt.Sprintf("%.2f", p.MontoReembolso), "1", 0, "R", false, 0, "")
+					pdf.CellFormat(25, 7, fmt.Sprintf("%.2f", p.GetMontoReembolsoBs()), "1", 0, "R", false, 0, "")
 					pdf.SetTextColor(0, 0, 0)
 					pdf.SetFont("Arial", "", 8)
 
@@ -871,9 +873,9 @@ func (s *ReportService) GeneratePV06(ctx context.Context, descargo *models.Desca
 	}
 
 	if solicitud.Usuario.IsSenador() {
-		s.drawSignatureBlock(pdf, tr, sigY+10, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA Y SELLO SENADOR(A)", solicitud.Usuario.GetNombreCompleto(), cargo)
+		s.drawSignatureBlock(ctx, pdf, tr, sigY+10, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA Y SELLO SENADOR(A)", solicitud.Usuario.GetNombreCompleto(), cargo)
 	} else {
-		s.drawSignatureBlock(pdf, tr, sigY+10, "FIRMA Y SELLO SERVIDOR PÚBLICO", solicitud.Usuario.GetNombreCompleto(), cargo, "Vo.Bo. Inmediato Superior", "", "")
+		s.drawSignatureBlock(ctx, pdf, tr, sigY+10, "FIRMA Y SELLO SERVIDOR PÚBLICO", solicitud.Usuario.GetNombreCompleto(), cargo, "Vo.Bo. Inmediato Superior", "", "")
 	}
 
 	if pdf.Err() {
@@ -889,111 +891,35 @@ func (s *ReportService) GeneratePV05Complete(ctx context.Context, descargo *mode
 		return nil, err
 	}
 
-	// Crear archivos temporales para la unión
-	tmpBase, err := os.CreateTemp("", "pv05_base_*.pdf")
+	doc, err := newEnsambladoPDF(pdf)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tmpBase.Name())
-
-	if err := pdf.OutputFileAndClose(tmpBase.Name()); err != nil {
-		return nil, fmt.Errorf("error generating base PDF: %w", err)
-	}
-
-	// 2. Recolectar rutas de archivos que existan
-	var filesToMerge []string
-	filesToMerge = append(filesToMerge, tmpBase.Name())
-
-	// Mapa para evitar duplicados
-	seenFiles := make(map[string]bool)
-	seenFiles[tmpBase.Name()] = true
 
 	// 2.1 Billetes Electrónicos (Pasajes emitidos) y Facturas de Servicio
 	if descargo.Solicitud != nil {
 		for _, item := range descargo.Solicitud.Items {
 			for _, pasaje := range item.Pasajes {
-				if pasaje.Archivo != "" && !seenFiles[pasaje.Archivo] {
-					if s.isValidPDF(pasaje.Archivo) {
-						filesToMerge = append(filesToMerge, pasaje.Archivo)
-						seenFiles[pasaje.Archivo] = true
-					}
-				}
-				if pasaje.ServicioArchivo != "" && !seenFiles[pasaje.ServicioArchivo] {
-					if s.isValidPDF(pasaje.ServicioArchivo) {
-						filesToMerge = append(filesToMerge, pasaje.ServicioArchivo)
-						seenFiles[pasaje.ServicioArchivo] = true
-					}
-				}
+				doc.Agregar(pasaje.Archivo)
+				doc.Agregar(pasaje.ServicioArchivo)
 			}
 		}
 	}
 
-	// 2.2 Pases a Bordo (Solo de tramos que NO son de reutilizaci�n y NO son Open Ticket)
+	// 2.2 Pases a Bordo (Solo de tramos que NO son de reutilización y NO son Open Ticket)
 	for _, det := range descargo.Tramos {
-		// En el descargo normal, solo queremos los pases de lo que se vol� originalmente
-		if !det.IsReutilizacion() && !det.EsOpenTicket && det.ArchivoPaseAbordo != "" && !seenFiles[det.ArchivoPaseAbordo] {
-			if s.isValidPDF(det.ArchivoPaseAbordo) {
-				filesToMerge = append(filesToMerge, det.ArchivoPaseAbordo)
-				seenFiles[det.ArchivoPaseAbordo] = true
-			}
+		// En el descargo normal, solo queremos los pases de lo que se voló originalmente
+		if !det.IsReutilizacion() && !det.EsOpenTicket {
+			doc.Agregar(det.ArchivoPaseAbordo)
 		}
 	}
 
-	// 2.3 Comprobante de Depósito (Si es PDF se une)
-	compPath := ""
-	if descargo.Solicitud != nil {
-		for _, item := range descargo.Solicitud.Items {
-			for _, p := range item.Pasajes {
-				if p.ArchivoComprobante != "" {
-					compPath = p.ArchivoComprobante
-					break
-				}
-			}
-			if compPath != "" {
-				break
-			}
-		}
-	}
-
-	if descargo.GetTotalDevolucionPasajes() > 0 && compPath != "" {
-		if strings.HasSuffix(strings.ToLower(compPath), ".pdf") && !seenFiles[compPath] {
-			if s.isValidPDF(compPath) {
-				filesToMerge = append(filesToMerge, compPath)
-				seenFiles[compPath] = true
-			}
-		}
+	// 2.3 Comprobante de Depósito (si es imagen ya va dibujado en el formulario)
+	if descargo.GetTotalDevolucionPasajes() > 0 {
+		doc.AgregarPDF(primerComprobante(descargo))
 	}
 
-	// 3. Si solo hay un archivo (el base), retornarlo directamente
-	if len(filesToMerge) == 1 {
-		data, err := os.ReadFile(tmpBase.Name())
-		if err != nil {
-			return nil, fmt.Errorf("error reading base PDF: %w", err)
-		}
-		return data, nil
-	}
-
-	// 4. Unir usando pdftk
-	tmpFinal, err := os.CreateTemp("", "pv05_final_*.pdf")
-	if err != nil {
-		return nil, err
-	}
-	defer os.Remove(tmpFinal.Name())
-
-	args := append(filesToMerge, "cat", "output", tmpFinal.Name())
-	cmd := exec.CommandContext(ctx, "pdftk", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("error merging PDFs with pdftk: %w - %s", err, stderr.String())
-	}
-
-	data, err := os.ReadFile(tmpFinal.Name())
-	if err != nil {
-		return nil, fmt.Errorf("error reading final merged PDF: %w", err)
-	}
-	return data, nil
+	return doc.Bytes()
 }
 
 func (s *ReportService) GeneratePV05OpenTicket(ctx context.Context, descargo *models.Descargo) *gofpdf.Fpdf {
@@ -1167,19 +1093,19 @@ func (s *ReportService) GeneratePV05OpenTicket(ctx context.Context, descargo *mo
 				// Mostramos todos los pasajes para conciliación, no solo los que tienen reembolso
 				hasFinances = true
 
-				emitido := p.Costo
-				consumo := p.CostoUtilizado
+				emitido := p.GetCostoBs()
+				consumo := p.GetCostoUtilizadoBs()
 				if consumo == 0 && p.MontoReembolso == 0 {
 					consumo = emitido // Si no se especificó consumo, asumimos consumo total
 				}
-				devolucion := p.MontoReembolso
+				devolucion := p.GetMontoReembolsoBs()
 
 				totalEmitido += emitido
 				totalConsumo += consumo
 				totalDevolucion += devolucion
 
 				pdf.CellFormat(25, 7, tr(p.NumeroBillete), "1", 0, "C", false, 0, "")
-				pdf.CellFormat(70, 7, tr(p.GetRutaDisplay()), "1", 0, "L", false, 0, "")
+				pdf.CellFormat(70, 7, tr(detalleRutaMoneda(p)), "1", 0, "L", false, 0, "")
 				pdf.CellFormat(22, 7, fmt.Sprintf("%.2f", emitido), "1", 0, "R", false, 0, "")
 				pdf.CellFormat(22, 7, fmt.Sprintf("%.2f", consumo), "1", 0, "R", false, 0, "")
 
@@ -1223,7 +1149,7 @@ func (s *ReportService) GeneratePV05OpenTicket(ctx context.Context, descargo *mo
 		pdf.AddPage()
 		sigY = 40
 	}
-	s.drawSignatureBlock(pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA/RESPONSABLE PRESENTACION DEL DESCARGO", "", "")
+	s.drawSignatureBlock(ctx, pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA/RESPONSABLE PRESENTACION DEL DESCARGO", "", "")
 
 	return pdf
 }
@@ -1235,39 +1161,19 @@ func (s *ReportService) GeneratePV05OpenTicketComplete(ctx context.Context, desc
 		return nil, pdf.Error()
 	}
 
-	// Crear archivos temporales para la unión
-	tmpBase, err := os.CreateTemp("", "pv05_ot_base_*.pdf")
+	doc, err := newEnsambladoPDF(pdf)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tmpBase.Name())
-
-	if err := pdf.OutputFileAndClose(tmpBase.Name()); err != nil {
-		return nil, fmt.Errorf("error generating base OT PDF: %w", err)
-	}
-
-	// 2. Recolectar rutas de archivos que existan
-	var filesToMerge []string
-	filesToMerge = append(filesToMerge, tmpBase.Name())
-
-	// Mapa para evitar duplicados
-	seenFiles := make(map[string]bool)
-	seenFiles[tmpBase.Name()] = true
 
 	// 2.1 Billetes Electrónicos de los tramos marcados como Open Ticket
 	for _, tramo := range descargo.Tramos {
-		if tramo.EsOpenTicket {
+		if tramo.EsOpenTicket && descargo.Solicitud != nil {
 			// Buscar el pasaje correspondiente en la solicitud para obtener el archivo del billete
-			if descargo.Solicitud != nil {
-				for _, item := range descargo.Solicitud.Items {
-					for _, pasaje := range item.Pasajes {
-						// Si el número de billete coincide, intentamos adjuntarlo
-						if pasaje.NumeroBillete == tramo.Billete && pasaje.Archivo != "" && !seenFiles[pasaje.Archivo] {
-							if s.isValidPDF(pasaje.Archivo) {
-								filesToMerge = append(filesToMerge, pasaje.Archivo)
-								seenFiles[pasaje.Archivo] = true
-							}
-						}
+			for _, item := range descargo.Solicitud.Items {
+				for _, pasaje := range item.Pasajes {
+					if pasaje.NumeroBillete == tramo.Billete {
+						doc.Agregar(pasaje.Archivo)
 					}
 				}
 			}
@@ -1276,61 +1182,21 @@ func (s *ReportService) GeneratePV05OpenTicketComplete(ctx context.Context, desc
 
 	// 2.2 Pases a Bordo de los tramos de REUTILIZACIÓN
 	for _, tramo := range descargo.Tramos {
-		// En el reporte OT, solo nos interesan los pases de los nuevos vuelos (REUT)
-		if tramo.IsReutilizacion() && tramo.ArchivoPaseAbordo != "" && !seenFiles[tramo.ArchivoPaseAbordo] {
-			if s.isValidPDF(tramo.ArchivoPaseAbordo) {
-				filesToMerge = append(filesToMerge, tramo.ArchivoPaseAbordo)
-				seenFiles[tramo.ArchivoPaseAbordo] = true
-			}
+		if tramo.IsReutilizacion() {
+			doc.Agregar(tramo.ArchivoPaseAbordo)
 		}
 	}
 
-	// 2.2 Comprobantes de Depósito (Devoluciones)
-	compPath := ""
+	// 2.3 Comprobantes de Depósito (Devoluciones)
 	if descargo.Solicitud != nil {
 		for _, item := range descargo.Solicitud.Items {
 			for _, p := range item.Pasajes {
-				if p.ArchivoComprobante != "" {
-					compPath = p.ArchivoComprobante
-					if !seenFiles[compPath] && s.isValidPDF(compPath) {
-						filesToMerge = append(filesToMerge, compPath)
-						seenFiles[compPath] = true
-					}
-				}
+				doc.AgregarPDF(p.ArchivoComprobante)
 			}
 		}
 	}
 
-	// 3. Si solo hay un archivo (el base), retornarlo directamente
-	if len(filesToMerge) == 1 {
-		data, err := os.ReadFile(tmpBase.Name())
-		if err != nil {
-			return nil, fmt.Errorf("error reading base OT PDF: %w", err)
-		}
-		return data, nil
-	}
-
-	// 4. Unir usando pdftk
-	tmpFinal, err := os.CreateTemp("", "pv05_ot_final_*.pdf")
-	if err != nil {
-		return nil, err
-	}
-	defer os.Remove(tmpFinal.Name())
-
-	args := append(filesToMerge, "cat", "output", tmpFinal.Name())
-	cmd := exec.CommandContext(ctx, "pdftk", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("error merging OT PDFs with pdftk: %w - %s", err, stderr.String())
-	}
-
-	data, err := os.ReadFile(tmpFinal.Name())
-	if err != nil {
-		return nil, fmt.Errorf("error reading final merged OT PDF: %w", err)
-	}
-	return data, nil
+	return doc.Bytes()
 }
 
 func (s *ReportService) GeneratePV06Complete(ctx context.Context, descargo *models.Descargo, personaView *models.MongoPersonaView) ([]byte, error) {
@@ -1340,121 +1206,86 @@ func (s *ReportService) GeneratePV06Complete(ctx context.Context, descargo *mode
 		return nil, err
 	}
 
-	// Crear archivos temporales para la unión
-	tmpBase, err := os.CreateTemp("", "pv06_base_*.pdf")
+	doc, err := newEnsambladoPDF(pdf)
 	if err != nil {
 		return nil, err
 	}
-	defer os.Remove(tmpBase.Name())
 
-	if err := pdf.OutputFileAndClose(tmpBase.Name()); err != nil {
-		return nil, fmt.Errorf("error saving base PDF: %w", err)
-	}
-
-	// 2. Recolectar rutas de archivos que existan (Billetes Electrónicos + Pases a Bordo)
-	var filesToMerge []string
-	filesToMerge = append(filesToMerge, tmpBase.Name())
-
-	// Mapa para evitar duplicados (ej: mismo billete para ida y vuelta)
-	seenFiles := make(map[string]bool)
-	seenFiles[tmpBase.Name()] = true
-
-	// 2.0 Memorandum PDF (If uploaded)
-	if descargo.Oficial != nil && descargo.Oficial.ArchivoMemorandum != "" {
-		path := descargo.Oficial.ArchivoMemorandum
-		if !seenFiles[path] {
-			if s.isValidPDF(path) {
-				filesToMerge = append(filesToMerge, path)
-				seenFiles[path] = true
-			}
-		}
+	// 2.0 Memorándum de designación
+	if descargo.Oficial != nil {
+		doc.Agregar(descargo.Oficial.ArchivoMemorandum)
 	}
 
 	// 2.1 Billetes Electrónicos (Pasajes emitidos) y Facturas de Servicio
 	if descargo.Solicitud != nil {
 		for _, item := range descargo.Solicitud.Items {
 			for _, pasaje := range item.Pasajes {
-				if pasaje.Archivo != "" && !seenFiles[pasaje.Archivo] {
-					if s.isValidPDF(pasaje.Archivo) {
-						filesToMerge = append(filesToMerge, pasaje.Archivo)
-						seenFiles[pasaje.Archivo] = true
-					}
-				}
-				if pasaje.ServicioArchivo != "" && !seenFiles[pasaje.ServicioArchivo] {
-					if s.isValidPDF(pasaje.ServicioArchivo) {
-						filesToMerge = append(filesToMerge, pasaje.ServicioArchivo)
-						seenFiles[pasaje.ServicioArchivo] = true
-					}
-				}
+				doc.Agregar(pasaje.Archivo)
+				doc.Agregar(pasaje.ServicioArchivo)
 			}
 		}
 	}
 
 	// 2.2 Pases a Bordo (Cargados en el descargo)
 	for _, det := range descargo.Tramos {
-		if det.ArchivoPaseAbordo != "" && !seenFiles[det.ArchivoPaseAbordo] {
-			if s.isValidPDF(det.ArchivoPaseAbordo) {
-				filesToMerge = append(filesToMerge, det.ArchivoPaseAbordo)
-				seenFiles[det.ArchivoPaseAbordo] = true
-			}
-		}
+		doc.Agregar(det.ArchivoPaseAbordo)
 	}
 
-	// 2.3 Comprobante de Depósito (Si es PDF se une)
-	compPath := ""
-	if descargo.Solicitud != nil {
-		for _, item := range descargo.Solicitud.Items {
-			for _, p := range item.Pasajes {
-				if p.ArchivoComprobante != "" {
-					compPath = p.ArchivoComprobante
-					break
-				}
-			}
-			if compPath != "" {
-				break
-			}
-		}
+	// 2.3 Comprobante de Depósito (si es imagen ya va dibujado en el formulario)
+	if descargo.GetTotalDevolucionPasajes() > 0 {
+		doc.AgregarPDF(primerComprobante(descargo))
 	}
 
-	if descargo.GetTotalDevolucionPasajes() > 0 && compPath != "" {
-		if strings.HasSuffix(strings.ToLower(compPath), ".pdf") && !seenFiles[compPath] {
-			if s.isValidPDF(compPath) {
-				filesToMerge = append(filesToMerge, compPath)
-				seenFiles[compPath] = true
-			}
-		}
+	// 2.4 Boleta de depósito del saldo de anticipo
+	if descargo.Oficial != nil {
+		doc.Agregar(descargo.Oficial.ArchivoBoletaDeposito)
 	}
 
-	// 3. Si solo hay un archivo (el base), retornarlo directamente
-	if len(filesToMerge) == 1 {
-		data, err := os.ReadFile(tmpBase.Name())
-		if err != nil {
-			return nil, fmt.Errorf("error reading base PDF: %w", err)
-		}
-		return data, nil
-	}
+	return doc.Bytes()
+}
 
-	// 4. Unir usando pdftk
-	tmpFinal, err := os.CreateTemp("", "pv06_final_*.pdf")
-	if err != nil {
-		return nil, err
+// primerComprobante devuelve el primer comprobante de depósito cargado en los pasajes.
+func primerComprobante(descargo *models.Descargo) string {
+	if descargo.Solicitud == nil {
+		return ""
+	}
+	for _, item := range descargo.Solicitud.Items {
+		for _, p := range item.Pasajes {
+			if p.ArchivoComprobante != "" {
+				return p.ArchivoComprobante
+			}
+		}
 	}
-	defer os.Remove(tmpFinal.Name())
+	return ""
+}
 
-	args := append(filesToMerge, "cat", "output", tmpFinal.Name())
-	cmd := exec.CommandContext(ctx, "pdftk", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// drawViaticoBlock imprime la liquidación de viáticos de la comisión en el PV-06.
+func (s *ReportService) drawViaticoBlock(pdf *gofpdf.Fpdf, tr func(string) string, v *models.Viatico) {
+	pdf.SetX(10)
+	pdf.Ln(4)
+	pdf.SetFillColor(245, 245, 245)
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(190, 6, tr("LIQUIDACIÓN DE VIÁTICOS"), "1", 1, "C", true, 0, "")
 
-	if err := cmd.Run(); err != nil {
-		return nil, fmt.Errorf("error merging PDFs with pdftk: %w - %s", err, stderr.String())
+	escala := fmt.Sprintf("%s %.2f", v.GetMonedaSimbolo(), v.MontoDiario)
+	if v.Moneda != models.MonedaBOB {
+		escala += fmt.Sprintf(" (T/C %.2f)", v.TipoCambio)
 	}
+	headers := []string{"CATEGORÍA", "ZONA", "DÍAS", "ESCALA DIARIA", "TOTAL Bs", fmt.Sprintf("RC-IVA %.0f%%", v.GetTasaRCIVAPorcentaje()), "LÍQUIDO Bs"}
+	values := []string{fmt.Sprintf("%d - %s", v.Categoria, v.CategoriaNombre), v.ZonaNombre, fmt.Sprintf("%d", v.Dias), escala,
+		fmt.Sprintf("%.2f", v.Total), fmt.Sprintf("%.2f", v.Retencion), fmt.Sprintf("%.2f", v.Liquido)}
+	widths := []float64{38, 32, 14, 36, 24, 22, 24}
 
-	data, err := os.ReadFile(tmpFinal.Name())
-	if err != nil {
-		return nil, fmt.Errorf("error reading final merged PDF: %w", err)
+	pdf.SetFont("Arial", "B", 7)
+	for i, h := range headers {
+		pdf.CellFormat(widths[i], 5, tr(h), "1", 0, "C", true, 0, "")
+	}
+	pdf.Ln(-1)
+	pdf.SetFont("Arial", "", 8)
+	for i, val := range values {
+		pdf.CellFormat(widths[i], 6, tr(val), "1", 0, "C", false, 0, "")
 	}
-	return data, nil
+	pdf.Ln(-1)
 }
 
 func (s *ReportService) drawSubTable(pdf *gofpdf.Fpdf, tr func(string) string, subTitle string, headerBillete string, rows []models.DescargoTramo) {
@@ -1557,3 +1388,13 @@ func (s *ReportService) drawReturnTableSummarized(pdf *gofpdf.Fpdf, tr func(stri
 		pdf.CellFormat(70, 8, "", "1", 1, "C", false, 0, "")
 	}
 }
+
+// detalleRutaMoneda agrega a la ruta el costo original y el tipo de cambio cuando el
+// pasaje se emitió en moneda extranjera; las columnas de montos quedan en Bs.
+func detalleRutaMoneda(p models.Pasaje) string {
+	detalle := p.GetRutaDisplay()
+	if p.IsMonedaExtranjera() {
+		detalle += fmt.Sprintf(" (%.2f %s, T/C %.2f)", p.Costo, p.GetMoneda(), p.GetTipoCambio())
+	}
+	return detalle
+}
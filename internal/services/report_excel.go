@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sistema-pasajes/internal/dtos"
 	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
 	"sistema-pasajes/internal/utils"
 	"strings"
 	"time"
@@ -47,7 +48,7 @@ func (s *ReportService) GenerateConsolidadoPasajesExcel(ctx context.Context, fil
 	// Encabezados
 	headers := []string{
 		"N°", "FECHA EMISIÓN", "FECHA VUELO", "HORA VUELO", "N° VUELO", "IDA/VUELTA", "CÓDIGO SOL.", "CONCEPTO", "TIPO DE SOLICITUD", "ÁMBITO", "BENEFICIARIO", "TIPO PASAJERO", "UNIDAD ORGANIZACIONAL", "CARGO", "ORIGEN", "DESTINO", "RUTA / TRAMOS", "AEROLÍNEA", "AGENCIA", "NRO. BILLETE",
-		"COSTO ORIGEN (BS)", "DEV DIF TARIFA", "COSTO CONSUMO", "CARGOS ASOCIADOS", "COSTO TOTAL",
+		"MONEDA", "COSTO ORIGINAL", "T/C", "COSTO ORIGEN (BS)", "DEV DIF TARIFA (BS)", "COSTO CONSUMO (BS)", "CARGOS ASOCIADOS (BS)", "COSTO TOTAL (BS)",
 		"ESTADO PASAJE", "ESTADO SOLICITUD", "ESTADO DESCARGO",
 	}
 	for i, h := range headers {
@@ -103,7 +104,8 @@ func (s *ReportService) GenerateConsolidadoPasajesExcel(ctx context.Context, fil
 		}
 
 		montoCargos := p.GetMontoCargos()
-		costoTotalPasaje := p.Costo + montoCargos
+		costoBs := p.GetCostoBs()
+		costoTotalPasaje := costoBs + montoCargos
 
 		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), i+1)
 		if p.FechaEmision != nil {
@@ -181,16 +183,19 @@ func (s *ReportService) GenerateConsolidadoPasajesExcel(ctx context.Context, fil
 		}
 
 		f.SetCellValue(sheet, fmt.Sprintf("T%d", row), p.NumeroBillete)
-		f.SetCellValue(sheet, fmt.Sprintf("U%d", row), p.Costo)
-		f.SetCellValue(sheet, fmt.Sprintf("V%d", row), p.MontoReembolso)
-		f.SetCellValue(sheet, fmt.Sprintf("W%d", row), p.CostoUtilizado)
-		f.SetCellValue(sheet, fmt.Sprintf("X%d", row), montoCargos)
-		f.SetCellValue(sheet, fmt.Sprintf("Y%d", row), costoTotalPasaje)
-		f.SetCellValue(sheet, fmt.Sprintf("Z%d", row), p.GetEstado())
-		f.SetCellValue(sheet, fmt.Sprintf("AA%d", row), solicitudEstado)
-		f.SetCellValue(sheet, fmt.Sprintf("AB%d", row), descargoEstado)
-
-		totalCostoOrigen += p.Costo
+		f.SetCellValue(sheet, fmt.Sprintf("U%d", row), p.GetMoneda())
+		f.SetCellValue(sheet, fmt.Sprintf("V%d", row), p.Costo)
+		f.SetCellValue(sheet, fmt.Sprintf("W%d", row), p.GetTipoCambio())
+		f.SetCellValue(sheet, fmt.Sprintf("X%d", row), costoBs)
+		f.SetCellValue(sheet, fmt.Sprintf("Y%d", row), p.GetMontoReembolsoBs())
+		f.SetCellValue(sheet, fmt.Sprintf("Z%d", row), p.GetCostoUtilizadoBs())
+		f.SetCellValue(sheet, fmt.Sprintf("AA%d", row), montoCargos)
+		f.SetCellValue(sheet, fmt.Sprintf("AB%d", row), costoTotalPasaje)
+		f.SetCellValue(sheet, fmt.Sprintf("AC%d", row), p.GetEstado())
+		f.SetCellValue(sheet, fmt.Sprintf("AD%d", row), solicitudEstado)
+		f.SetCellValue(sheet, fmt.Sprintf("AE%d", row), descargoEstado)
+
+		totalCostoOrigen += costoBs
 		totalCargos += montoCargos
 		totalGeneral += costoTotalPasaje
 
@@ -202,16 +207,16 @@ func (s *ReportService) GenerateConsolidadoPasajesExcel(ctx context.Context, fil
 	// Fila de Totales
 	totalRow := len(pasajes) + 2
 	f.SetCellValue(sheet, fmt.Sprintf("T%d", totalRow), "TOTALES:")
-	f.SetCellValue(sheet, fmt.Sprintf("U%d", totalRow), totalCostoOrigen)
-	f.SetCellValue(sheet, fmt.Sprintf("X%d", totalRow), totalCargos)
-	f.SetCellValue(sheet, fmt.Sprintf("Y%d", totalRow), totalGeneral)
+	f.SetCellValue(sheet, fmt.Sprintf("X%d", totalRow), totalCostoOrigen)
+	f.SetCellValue(sheet, fmt.Sprintf("AA%d", totalRow), totalCargos)
+	f.SetCellValue(sheet, fmt.Sprintf("AB%d", totalRow), totalGeneral)
 
 	totalStyle, _ := f.NewStyle(&excelize.Style{
 		Font:      &excelize.Font{Bold: true},
 		Fill:      excelize.Fill{Type: "pattern", Color: []string{"F3F4F6"}, Pattern: 1},
 		Alignment: &excelize.Alignment{Horizontal: "right"},
 	})
-	f.SetCellStyle(sheet, fmt.Sprintf("T%d", totalRow), fmt.Sprintf("Y%d", totalRow), totalStyle)
+	f.SetCellStyle(sheet, fmt.Sprintf("T%d", totalRow), fmt.Sprintf("AB%d", totalRow), totalStyle)
 
 	// Autoajustar anchos (aproximado)
 	widths := []float64{
@@ -235,14 +240,17 @@ func (s *ReportService) GenerateConsolidadoPasajesExcel(ctx context.Context, fil
 		12, // R: AEROLÍNEA
 		25, // S: AGENCIA
 		18, // T: NRO. BILLETE
-		18, // U: COSTO ORIGEN (BS)
-		15, // V: DEV DIF TARIFA
-		15, // W: COSTO CONSUMO
-		18, // X: CARGOS ASOCIADOS
-		18, // Y: COSTO TOTAL
-		15, // Z: ESTADO PASAJE
-		18, // AA: ESTADO SOLICITUD
-		18, // AB: ESTADO DESCARGO
+		10, // U: MONEDA
+		16, // V: COSTO ORIGINAL
+		10, // W: T/C
+		18, // X: COSTO ORIGEN (BS)
+		18, // Y: DEV DIF TARIFA (BS)
+		18, // Z: COSTO CONSUMO (BS)
+		20, // AA: CARGOS ASOCIADOS (BS)
+		18, // AB: COSTO TOTAL (BS)
+		15, // AC: ESTADO PASAJE
+		18, // AD: ESTADO SOLICITUD
+		18, // AE: ESTADO DESCARGO
 	}
 	for i, w := range widths {
 		col, _ := excelize.ColumnNumberToName(i + 1)
@@ -335,6 +343,45 @@ func (s *ReportService) GenerateUsoCuposExcel(ctx context.Context, anio, mes int
 	f.SetColWidth(sheet, "A", "A", 40)
 	f.SetColWidth(sheet, "B", "F", 15)
 
+	movimientos, err := s.transferRepo.FindByPeriodo(ctx, anio, mes)
+	if err != nil {
+		return nil, err
+	}
+
+	sheetTransf := "Transferencias"
+	f.NewSheet(sheetTransf)
+
+	headersTransf := []string{"SEMANA", "TITULAR", "DE", "PARA", "TIPO", "ESTADO", "SOLICITADO POR", "APROBADO POR", "FECHA SOLICITUD", "FECHA RESOLUCIÓN", "MOTIVO"}
+	for i, h := range headersTransf {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheetTransf, cell, h)
+		f.SetCellStyle(sheetTransf, cell, cell, headerStyle)
+	}
+
+	for i, m := range movimientos {
+		row := i + 2
+		if m.CupoDerechoItem != nil {
+			f.SetCellValue(sheetTransf, fmt.Sprintf("A%d", row), m.CupoDerechoItem.GetWeekLabel())
+			f.SetCellValue(sheetTransf, fmt.Sprintf("B%d", row), m.CupoDerechoItem.SenTitular.GetNombreCompleto())
+		}
+		f.SetCellValue(sheetTransf, fmt.Sprintf("C%d", row), m.DeUsuario.GetNombreCompleto())
+		f.SetCellValue(sheetTransf, fmt.Sprintf("D%d", row), m.ParaUsuario.GetNombreCompleto())
+		f.SetCellValue(sheetTransf, fmt.Sprintf("E%d", row), string(m.Tipo))
+		f.SetCellValue(sheetTransf, fmt.Sprintf("F%d", row), string(m.Estado))
+		f.SetCellValue(sheetTransf, fmt.Sprintf("G%d", row), m.Solicitante.GetNombreCompleto())
+		f.SetCellValue(sheetTransf, fmt.Sprintf("H%d", row), m.GetAprobadorNombre())
+		f.SetCellValue(sheetTransf, fmt.Sprintf("I%d", row), m.FechaSolicitud.Format("02/01/2006 15:04"))
+		if m.FechaResolucion != nil {
+			f.SetCellValue(sheetTransf, fmt.Sprintf("J%d", row), m.FechaResolucion.Format("02/01/2006 15:04"))
+		}
+		f.SetCellValue(sheetTransf, fmt.Sprintf("K%d", row), m.Motivo)
+	}
+
+	f.SetColWidth(sheetTransf, "A", "A", 25)
+	f.SetColWidth(sheetTransf, "B", "D", 35)
+	f.SetColWidth(sheetTransf, "E", "J", 18)
+	f.SetColWidth(sheetTransf, "K", "K", 40)
+
 	return f, nil
 }
 
@@ -356,7 +403,7 @@ func (s *ReportService) GenerateEstadisticasAerolineaExcel(ctx context.Context,
 		}
 		curr := stats[name]
 		curr.Count++
-		curr.Total += p.Costo
+		curr.Total += p.GetCostoBs()
 		stats[name] = curr
 	}
 
@@ -462,13 +509,13 @@ func (s *ReportService) GenerateOficialesExcel(ctx context.Context, filter dtos.
 
 		fLimite := ""
 		if !fRetorno.IsZero() {
-			fLimite = utils.CalcularFechaLimiteDescargo(fRetorno).Format("02/01/2006")
+			fLimite = utils.CalcularFechaLimiteDescargo(fRetorno, sol.Usuario.GetDepartamentoCodigo()).Format("02/01/2006")
 		}
 
 		montoTotal := 0.0
 		for _, item := range sol.Items {
 			for _, p := range item.Pasajes {
-				montoTotal += p.Costo
+				montoTotal += p.GetCostoBs()
 			}
 		}
 
@@ -507,3 +554,221 @@ func (s *ReportService) GenerateOficialesExcel(ctx context.Context, filter dtos.
 
 	return f, nil
 }
+
+// GenerateSobrepreciosExcel resume las desviaciones de costo frente a la tarifa contratada,
+// agrupadas por agencia y por aerolínea, con el detalle por pasaje.
+func (s *ReportService) GenerateSobrepreciosExcel(ctx context.Context, filter dtos.ReportFilterRequest) (*excelize.File, error) {
+	desviaciones, err := s.desviacionRepo.FindForReport(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	type resumen struct {
+		Pasajes     int
+		Excedidos   int
+		Referencial float64
+		Costo       float64
+		Sobreprecio float64
+	}
+	porAgencia := make(map[string]*resumen)
+	porAerolinea := make(map[string]*resumen)
+	acumular := func(m map[string]*resumen, key string, d models.DesviacionTarifa) {
+		r, ok := m[key]
+		if !ok {
+			r = &resumen{}
+			m[key] = r
+		}
+		r.Pasajes++
+		if d.ExcedeTolerancia() {
+			r.Excedidos++
+		}
+		r.Referencial += d.MontoReferencial
+		r.Costo += d.Costo
+		r.Sobreprecio += d.GetSobreprecio()
+	}
+
+	for _, d := range desviaciones {
+		agencia := "SIN AGENCIA"
+		if d.Agencia != nil {
+			agencia = d.Agencia.Nombre
+		}
+		aerolinea := "OTRA / DESCONOCIDA"
+		if d.Aerolinea != nil {
+			aerolinea = d.Aerolinea.Nombre
+		}
+		acumular(porAgencia, agencia, d)
+		acumular(porAerolinea, aerolinea, d)
+	}
+
+	f := excelize.NewFile()
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"0F7654"}, Pattern: 1},
+	})
+	alertStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "B91C1C"},
+	})
+
+	writeHeaders := func(sheet string, headers []string) {
+		for i, h := range headers {
+			cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+			f.SetCellValue(sheet, cell, h)
+			f.SetCellStyle(sheet, cell, cell, headerStyle)
+		}
+	}
+
+	writeResumen := func(sheet, titulo string, m map[string]*resumen) {
+		writeHeaders(sheet, []string{titulo, "PASAJES", "FUERA DE TOLERANCIA", "TARIFA CONTRATADA (BS)", "COSTO PAGADO (BS)", "SOBREPRECIO (BS)", "DESVIACIÓN PROMEDIO %"})
+		row := 2
+		for name, r := range m {
+			promedio := 0.0
+			if r.Referencial > 0 {
+				promedio = (r.Costo - r.Referencial) / r.Referencial * 100
+			}
+			f.SetCellValue(sheet, fmt.Sprintf("A%d", row), name)
+			f.SetCellValue(sheet, fmt.Sprintf("B%d", row), r.Pasajes)
+			f.SetCellValue(sheet, fmt.Sprintf("C%d", row), r.Excedidos)
+			f.SetCellValue(sheet, fmt.Sprintf("D%d", row), r.Referencial)
+			f.SetCellValue(sheet, fmt.Sprintf("E%d", row), r.Costo)
+			f.SetCellValue(sheet, fmt.Sprintf("F%d", row), r.Sobreprecio)
+			f.SetCellValue(sheet, fmt.Sprintf("G%d", row), fmt.Sprintf("%.2f", promedio))
+			if r.Excedidos > 0 {
+				f.SetCellStyle(sheet, fmt.Sprintf("C%d", row), fmt.Sprintf("C%d", row), alertStyle)
+			}
+			row++
+		}
+		f.SetColWidth(sheet, "A", "A", 35)
+		f.SetColWidth(sheet, "B", "G", 20)
+	}
+
+	sheetAgencia := "Por Agencia"
+	f.SetSheetName("Sheet1", sheetAgencia)
+	writeResumen(sheetAgencia, "AGENCIA", porAgencia)
+
+	sheetAerolinea := "Por Aerolínea"
+	f.NewSheet(sheetAerolinea)
+	writeResumen(sheetAerolinea, "AEROLÍNEA", porAerolinea)
+
+	sheetDetalle := "Detalle"
+	f.NewSheet(sheetDetalle)
+	writeHeaders(sheetDetalle, []string{"BILLETE", "SOLICITUD", "BENEFICIARIO", "RUTA", "AEROLÍNEA", "AGENCIA", "F. EMISIÓN", "TARIFA (BS)", "COSTO (BS)", "DESVIACIÓN %", "TOLERANCIA %", "ESTADO", "JUSTIFICACIÓN", "AUTORIZADO POR"})
+	for i, d := range desviaciones {
+		row := i + 2
+		billete, codigo, beneficiario, emision := "-", "-", "-", "-"
+		if d.Pasaje != nil {
+			billete = d.Pasaje.NumeroBillete
+			if d.Pasaje.FechaEmision != nil {
+				emision = d.Pasaje.FechaEmision.Format("02/01/2006")
+			}
+			if d.Pasaje.Solicitud != nil {
+				codigo = d.Pasaje.Solicitud.Codigo
+				beneficiario = d.Pasaje.Solicitud.Usuario.GetNombreCompleto()
+			}
+		}
+		ruta := "-"
+		if d.Ruta != nil {
+			ruta = d.Ruta.Tramo
+		}
+		aerolinea, agencia, autorizado := "-", "-", "-"
+		if d.Aerolinea != nil {
+			aerolinea = d.Aerolinea.Nombre
+		}
+		if d.Agencia != nil {
+			agencia = d.Agencia.Nombre
+		}
+		if d.AutorizadoPor != nil {
+			autorizado = d.AutorizadoPor.GetNombreCompleto()
+		}
+
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("A%d", row), billete)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("B%d", row), codigo)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("C%d", row), beneficiario)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("D%d", row), ruta)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("E%d", row), aerolinea)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("F%d", row), agencia)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("G%d", row), emision)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("H%d", row), d.MontoReferencial)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("I%d", row), d.Costo)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("J%d", row), d.DesviacionPct)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("K%d", row), d.ToleranciaPct)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("L%d", row), string(d.Estado))
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("M%d", row), d.Justificacion)
+		f.SetCellValue(sheetDetalle, fmt.Sprintf("N%d", row), autorizado)
+		if d.ExcedeTolerancia() {
+			f.SetCellStyle(sheetDetalle, fmt.Sprintf("J%d", row), fmt.Sprintf("J%d", row), alertStyle)
+		}
+	}
+	f.SetColWidth(sheetDetalle, "A", "F", 22)
+	f.SetColWidth(sheetDetalle, "G", "L", 15)
+	f.SetColWidth(sheetDetalle, "M", "N", 40)
+
+	return f, nil
+}
+
+// GenerateColisionesBilleteExcel lista los números de billete que aparecen en más de un pasaje
+// o con más de un viajero, marcando cuáles corresponden a una reemisión.
+func (s *ReportService) GenerateColisionesBilleteExcel(ctx context.Context) (*excelize.File, error) {
+	usos, err := s.billeteRepo.FindColisiones(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	f := excelize.NewFile()
+	sheet := "Colisiones"
+	f.SetSheetName("Sheet1", sheet)
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"0F7654"}, Pattern: 1},
+	})
+	alertStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "B91C1C"},
+	})
+
+	headers := []string{"BILLETE NORMALIZADO", "BILLETE REGISTRADO", "FUENTE", "VIAJERO", "SOLICITUD", "FECHA REGISTRO", "OBSERVACIÓN"}
+	for i, h := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+		f.SetCellStyle(sheet, cell, cell, headerStyle)
+	}
+
+	// Viajeros distintos por número, para señalar los usos cruzados.
+	viajeros := make(map[string]map[string]bool)
+	for _, u := range usos {
+		if viajeros[u.Normalizado] == nil {
+			viajeros[u.Normalizado] = make(map[string]bool)
+		}
+		viajeros[u.Normalizado][u.UsuarioID] = true
+	}
+
+	for i, u := range usos {
+		row := i + 2
+		observacion := ""
+		switch {
+		case len(viajeros[u.Normalizado]) > 1:
+			observacion = "USADO POR OTRO VIAJERO"
+		case u.Fuente == repositories.UsoBilletePasaje && u.OriginalID != "":
+			observacion = "REEMISIÓN"
+		case u.Fuente == repositories.UsoBilletePasaje && u.OpenTicketID != "":
+			observacion = "REEMISIÓN DESDE OPEN TICKET"
+		case u.Fuente == repositories.UsoBilletePasaje:
+			observacion = "PASAJE REPETIDO"
+		}
+		f.SetCellValue(sheet, fmt.Sprintf("A%d", row), u.Normalizado)
+		f.SetCellValue(sheet, fmt.Sprintf("B%d", row), u.NumeroBillete)
+		f.SetCellValue(sheet, fmt.Sprintf("C%d", row), u.Fuente)
+		f.SetCellValue(sheet, fmt.Sprintf("D%d", row), u.UsuarioNombre)
+		f.SetCellValue(sheet, fmt.Sprintf("E%d", row), u.SolicitudCodigo)
+		f.SetCellValue(sheet, fmt.Sprintf("F%d", row), u.CreatedAt.Format("02/01/2006 15:04"))
+		f.SetCellValue(sheet, fmt.Sprintf("G%d", row), observacion)
+		if observacion == "USADO POR OTRO VIAJERO" || observacion == "PASAJE REPETIDO" {
+			f.SetCellStyle(sheet, fmt.Sprintf("G%d", row), fmt.Sprintf("G%d", row), alertStyle)
+		}
+	}
+	f.SetColWidth(sheet, "A", "B", 22)
+	f.SetColWidth(sheet, "C", "C", 14)
+	f.SetColWidth(sheet, "D", "D", 35)
+	f.SetColWidth(sheet, "E", "F", 18)
+	f.SetColWidth(sheet, "G", "G", 30)
+
+	return f, nil
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// altoEspacioFirma es el alto (mm) del recuadro sobre la línea de firma donde se estampa la
+// firma digital visible.
+const altoEspacioFirma = 15.0
+
+// EspacioFirma es el recuadro sobre una línea de firma de un formulario. Las coordenadas están
+// en mm desde la esquina superior izquierda de la página, como las usa gofpdf.
+type EspacioFirma struct {
+	Pagina   int
+	X        float64
+	Y        float64
+	Ancho    float64
+	Alto     float64
+	Etiqueta string
+	Nombre   string
+}
+
+type espaciosFirmaKey struct{}
+
+type espaciosFirma struct {
+	lista []EspacioFirma
+}
+
+// conEspaciosFirma prepara el contexto para que los generadores registren dónde dibujan sus
+// bloques de firma; fuera de una firma digital el registro no se hace.
+func conEspaciosFirma(ctx context.Context) (context.Context, *espaciosFirma) {
+	reg := &espaciosFirma{}
+	return context.WithValue(ctx, espaciosFirmaKey{}, reg), reg
+}
+
+func registrarEspacioFirma(ctx context.Context, pdf *gofpdf.Fpdf, x, y, ancho float64, etiqueta, nombre string) {
+	reg, ok := ctx.Value(espaciosFirmaKey{}).(*espaciosFirma)
+	if !ok || strings.TrimSpace(etiqueta) == "" {
+		return
+	}
+	reg.lista = append(reg.lista, EspacioFirma{
+		Pagina:   pdf.PageNo(),
+		X:        x,
+		Y:        y - altoEspacioFirma - 1,
+		Ancho:    ancho,
+		Alto:     altoEspacioFirma,
+		Etiqueta: etiqueta,
+		Nombre:   nombre,
+	})
+}
+
+// Elegir devuelve el espacio del firmante: el que lleva su nombre impreso, si no el primero
+// destinado a una firma (los de "SELLO" quedan para el sello físico) y, en último caso, el
+// primero registrado.
+func (e *espaciosFirma) Elegir(nombreFirmante string) (EspacioFirma, bool) {
+	if len(e.lista) == 0 {
+		return EspacioFirma{}, false
+	}
+	for _, esp := range e.lista {
+		if esp.Nombre != "" && strings.EqualFold(strings.TrimSpace(esp.Nombre), strings.TrimSpace(nombreFirmante)) {
+			return esp, true
+		}
+	}
+	for _, esp := range e.lista {
+		if strings.Contains(strings.ToUpper(esp.Etiqueta), "FIRMA") {
+			return esp, true
+		}
+	}
+	return e.lista[0], true
+}
+
+// Bytes serializa un formulario generado con gofpdf, para firmarlo o anexarlo.
+func (s *ReportService) Bytes(pdf *gofpdf.Fpdf) ([]byte, error) {
+	return pdfBytes(pdf)
+}
@@ -0,0 +1,207 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/pdfcpu/pdfcpu/pkg/api"
+	"github.com/pdfcpu/pdfcpu/pkg/pdfcpu/model"
+)
+
+var pdfcpuConfigOnce sync.Once
+
+// pdfcpuConfig evita que pdfcpu cree su directorio de configuración en el servidor.
+func pdfcpuConfig() *model.Configuration {
+	pdfcpuConfigOnce.Do(api.DisableConfigDir)
+	conf := model.NewDefaultConfiguration()
+	conf.ValidationMode = model.ValidationRelaxed
+	return conf
+}
+
+type adjuntoOmitido struct {
+	Archivo string
+	Motivo  string
+}
+
+// ensambladoPDF une en memoria el formulario generado con sus adjuntos: los PDF se anexan tal
+// cual y las imágenes en una hoja A4. Un adjunto ilegible no detiene la impresión; se omite y
+// se lista en una hoja final.
+type ensambladoPDF struct {
+	partes   [][]byte
+	vistos   map[string]bool
+	omitidos []adjuntoOmitido
+}
+
+func newEnsambladoPDF(base *gofpdf.Fpdf) (*ensambladoPDF, error) {
+	data, err := pdfBytes(base)
+	if err != nil {
+		return nil, fmt.Errorf("error generando el PDF base: %w", err)
+	}
+	return &ensambladoPDF{partes: [][]byte{data}, vistos: map[string]bool{}}, nil
+}
+
+func pdfBytes(pdf *gofpdf.Fpdf) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Agregar anexa un PDF o una imagen.
+func (e *ensambladoPDF) Agregar(path string) {
+	e.agregar(path, true)
+}
+
+// AgregarPDF anexa el archivo solo si es PDF; las imágenes ya se dibujan dentro del formulario.
+func (e *ensambladoPDF) AgregarPDF(path string) {
+	e.agregar(path, false)
+}
+
+func (e *ensambladoPDF) agregar(path string, imagenes bool) {
+	if path == "" || e.vistos[path] {
+		return
+	}
+	e.vistos[path] = true
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		e.omitir(path, "archivo no encontrado")
+		return
+	}
+
+	if bytes.HasPrefix(data, []byte("%PDF-")) {
+		if _, err := api.ReadAndValidate(bytes.NewReader(data), pdfcpuConfig()); err != nil {
+			e.omitir(path, "PDF dañado o ilegible")
+			slog.Warn("Adjunto PDF omitido", "archivo", path, "error", err.Error())
+			return
+		}
+		e.partes = append(e.partes, data)
+		return
+	}
+	if !imagenes {
+		return
+	}
+
+	hoja, err := imagenEnA4(data)
+	if err != nil {
+		e.omitir(path, "no es un PDF ni una imagen legible")
+		slog.Warn("Adjunto de imagen omitido", "archivo", path, "error", err.Error())
+		return
+	}
+	e.partes = append(e.partes, hoja)
+}
+
+func (e *ensambladoPDF) omitir(path, motivo string) {
+	e.omitidos = append(e.omitidos, adjuntoOmitido{Archivo: filepath.Base(path), Motivo: motivo})
+}
+
+// Bytes devuelve el documento final. Si la unión falla se entrega el formulario con la hoja de
+// adjuntos omitidos, para no dejar al usuario sin imprimir.
+func (e *ensambladoPDF) Bytes() ([]byte, error) {
+	partes := e.partes
+	if len(e.omitidos) > 0 {
+		if aviso, err := hojaAdjuntosOmitidos(e.omitidos); err == nil {
+			partes = append(partes, aviso)
+		}
+	}
+	if len(partes) == 1 {
+		return partes[0], nil
+	}
+
+	readers := make([]io.ReadSeeker, len(partes))
+	for i, p := range partes {
+		readers[i] = bytes.NewReader(p)
+	}
+	var out bytes.Buffer
+	if err := api.MergeRaw(readers, &out, false, pdfcpuConfig()); err != nil {
+		slog.Error("No se pudieron unir los adjuntos; se entrega solo el formulario", "error", err.Error())
+		e.omitidos = append(e.omitidos, adjuntoOmitido{Archivo: "todos los adjuntos", Motivo: "error al unir los documentos"})
+		aviso, avisoErr := hojaAdjuntosOmitidos(e.omitidos)
+		if avisoErr != nil {
+			return e.partes[0], nil
+		}
+		out.Reset()
+		if err := api.MergeRaw([]io.ReadSeeker{bytes.NewReader(e.partes[0]), bytes.NewReader(aviso)}, &out, false, pdfcpuConfig()); err != nil {
+			return e.partes[0], nil
+		}
+	}
+	return out.Bytes(), nil
+}
+
+// imagenEnA4 coloca la imagen en una hoja A4 (horizontal si la imagen es apaisada), escalada
+// para caber en el margen sin deformarse.
+func imagenEnA4(data []byte) ([]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width == 0 || cfg.Height == 0 {
+		return nil, fmt.Errorf("imagen vacía")
+	}
+
+	tipo := map[string]string{"jpeg": "JPG", "png": "PNG", "gif": "GIF"}[format]
+	if tipo == "" {
+		// gofpdf no lee webp/bmp: se recodifica a PNG en memoria.
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		var buf bytes.Buffer
+		if err := png.Encode(&buf, img); err != nil {
+			return nil, err
+		}
+		data, tipo = buf.Bytes(), "PNG"
+	}
+
+	orientacion, anchoHoja, altoHoja := "P", 210.0, 297.0
+	if cfg.Width > cfg.Height {
+		orientacion, anchoHoja, altoHoja = "L", 297.0, 210.0
+	}
+	const margen = 10.0
+	maxAncho, maxAlto := anchoHoja-2*margen, altoHoja-2*margen
+	ancho, alto := maxAncho, maxAncho*float64(cfg.Height)/float64(cfg.Width)
+	if alto > maxAlto {
+		alto, ancho = maxAlto, maxAlto*float64(cfg.Width)/float64(cfg.Height)
+	}
+
+	pdf := gofpdf.New(orientacion, "mm", "A4", "")
+	pdf.AddPage()
+	opt := gofpdf.ImageOptions{ImageType: tipo}
+	pdf.RegisterImageOptionsReader("adjunto", opt, bytes.NewReader(data))
+	pdf.ImageOptions("adjunto", (anchoHoja-ancho)/2, (altoHoja-alto)/2, ancho, alto, false, opt, 0, "")
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+	return pdfBytes(pdf)
+}
+
+func hojaAdjuntosOmitidos(omitidos []adjuntoOmitido) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 11)
+	pdf.CellFormat(190, 8, tr("ADJUNTOS NO INCLUIDOS"), "B", 1, "C", false, 0, "")
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "", 9)
+	pdf.MultiCell(190, 5, tr("Los siguientes archivos no pudieron incorporarse a la impresión. Vuelva a cargarlos en el sistema."), "", "L", false)
+	pdf.Ln(2)
+	for _, o := range omitidos {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(110, 6, tr(o.Archivo), "1", 0, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 9)
+		pdf.CellFormat(80, 6, tr(o.Motivo), "1", 1, "L", false, 0, "")
+	}
+	if pdf.Err() {
+		return nil, pdf.Error()
+	}
+	return pdfBytes(pdf)
+}
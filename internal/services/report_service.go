@@ -1,6 +1,7 @@
 package services
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -25,6 +26,9 @@ type ReportService struct {
 	cupoRepo       *repositories.CupoDerechoRepository
 	openTicketRepo *repositories.OpenTicketRepository
 	configService  *ConfiguracionService
+	transferRepo   *repositories.TransferenciaCupoRepository
+	desviacionRepo *repositories.DesviacionTarifaRepository
+	billeteRepo    *repositories.BilleteRepository
 }
 
 func NewReportService(
@@ -35,6 +39,9 @@ func NewReportService(
 	cupoRepo *repositories.CupoDerechoRepository,
 	openTicketRepo *repositories.OpenTicketRepository,
 	configService *ConfiguracionService,
+	transferRepo *repositories.TransferenciaCupoRepository,
+	desviacionRepo *repositories.DesviacionTarifaRepository,
+	billeteRepo *repositories.BilleteRepository,
 ) *ReportService {
 	return &ReportService{
 		solicitudRepo:  solicitudRepo,
@@ -43,6 +50,9 @@ func NewReportService(
 		agenciaRepo:    agenciaRepo,
 		cupoRepo:       cupoRepo,
 		openTicketRepo: openTicketRepo,
+		transferRepo:   transferRepo,
+		desviacionRepo: desviacionRepo,
+		billeteRepo:    billeteRepo,
 		configService:  configService,
 	}
 }
@@ -106,7 +116,10 @@ func (s *ReportService) drawMemoRow(pdf *gofpdf.Fpdf, tr func(string) string, la
 	pdf.CellFormat(160, h, "  "+tr(value), "1", 1, "L", false, 0, "")
 }
 
-func (s *ReportService) drawSignatureBlock(pdf *gofpdf.Fpdf, tr func(string) string, y float64, leftLabel, leftName, leftTitle, rightLabel, rightName, rightTitle string) {
+func (s *ReportService) drawSignatureBlock(ctx context.Context, pdf *gofpdf.Fpdf, tr func(string) string, y float64, leftLabel, leftName, leftTitle, rightLabel, rightName, rightTitle string) {
+	registrarEspacioFirma(ctx, pdf, 35, y, 60, leftLabel, leftName)
+	registrarEspacioFirma(ctx, pdf, 110, y, 75, rightLabel, rightName)
+
 	pdf.SetLineWidth(0.2)
 	// Left side
 	pdf.Line(35, y, 95, y)
@@ -148,26 +161,6 @@ func (s *ReportService) drawPageBorder(pdf *gofpdf.Fpdf) {
 	pdf.Rect(3, 268.5, 210, 9.3, "D")
 }
 
-// isValidPDF verifica si un archivo existe y tiene la cabecera mágica de un PDF (%PDF-)
-func (s *ReportService) isValidPDF(filePath string) bool {
-	if filePath == "" {
-		return false
-	}
-	f, err := os.Open(filePath)
-	if err != nil {
-		return false
-	}
-	defer f.Close()
-
-	header := make([]byte, 5)
-	n, err := f.Read(header)
-	if err != nil || n < 5 {
-		return false
-	}
-
-	return string(header) == "%PDF-"
-}
-
 // getValidImage verifica si un archivo es una imagen y lo convierte a PNG si el formato no es soportado por gofpdf (ej: webp, bmp)
 func (s *ReportService) getValidImage(filePath string) (string, bool, error) {
 	if filePath == "" {
@@ -27,7 +27,7 @@ func (s *ReportService) GeneratePV01(ctx context.Context, solicitud *models.Soli
 
 	fechaLimiteStr := ""
 	if !masReciente.IsZero() {
-		limite := utils.CalcularFechaLimiteDescargo(masReciente)
+		limite := utils.CalcularFechaLimiteDescargo(masReciente, solicitud.Usuario.GetDepartamentoCodigo())
 		fechaLimiteStr = limite.Format("02/01/2006")
 	}
 
@@ -191,7 +191,7 @@ func (s *ReportService) GeneratePV01(ctx context.Context, solicitud *models.Soli
 		pdf.AddPage()
 		sigY = 40
 	}
-	s.drawSignatureBlock(pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA / SELLO SOLICITANTE", "", "")
+	s.drawSignatureBlock(ctx, pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA / SELLO SOLICITANTE", "", "")
 
 	return pdf
 }
@@ -214,7 +214,7 @@ func (s *ReportService) GeneratePV02(ctx context.Context, solicitud *models.Soli
 
 	fechaLimiteStr := ""
 	if !masReciente.IsZero() {
-		limite := utils.CalcularFechaLimiteDescargo(masReciente)
+		limite := utils.CalcularFechaLimiteDescargo(masReciente, solicitud.Usuario.GetDepartamentoCodigo())
 		fechaLimiteStr = limite.Format("02/01/2006")
 	}
 
@@ -425,7 +425,7 @@ func (s *ReportService) GeneratePV02(ctx context.Context, solicitud *models.Soli
 		pdf.AddPage()
 		sigY = 40
 	}
-	s.drawSignatureBlock(pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA / SELLO SOLICITANTE", "", "")
+	s.drawSignatureBlock(ctx, pdf, tr, sigY, "SELLO UNIDAD SOLICITANTE", "", "", "FIRMA / SELLO SOLICITANTE", "", "")
 
 	pdf.SetFont("Arial", "I", 8)
 	return pdf
@@ -28,6 +28,7 @@ type SolicitudDerechoService struct {
 	destinoService      *DestinoService
 	openTicketRepo      *repositories.OpenTicketRepository
 	configService       *ConfiguracionService
+	cupoService         *CupoService
 }
 
 func NewSolicitudDerechoService(
@@ -43,6 +44,7 @@ func NewSolicitudDerechoService(
 	destinoService *DestinoService,
 	openTicketRepo *repositories.OpenTicketRepository,
 	configService *ConfiguracionService,
+	cupoService *CupoService,
 ) *SolicitudDerechoService {
 	return &SolicitudDerechoService{
 		repo:                repo,
@@ -57,6 +59,7 @@ func NewSolicitudDerechoService(
 		destinoService:      destinoService,
 		openTicketRepo:      openTicketRepo,
 		configService:       configService,
+		cupoService:         cupoService,
 	}
 }
 
@@ -199,11 +202,27 @@ func (s *SolicitudDerechoService) CreateDerecho(ctx context.Context, req dtos.Cr
 
 	solicitud.Items = items
 
+	conflictos, err := s.baseService.conflictoService.Evaluar(ctx, solicitud.UsuarioID, "", items, currentUser, req.JustificacionConflicto)
+	if err != nil {
+		return nil, err
+	}
+	vencidas, err := s.baseService.morosidadService.Evaluar(ctx, solicitud.UsuarioID, "", currentUser, req.JustificacionMorosidad)
+	if err != nil {
+		return nil, err
+	}
+
 	err = s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.SolicitudRepository, tx *gorm.DB) error {
 		if err := repoTx.CreateWithSequenceCode(ctx, solicitud, "SPD", s.codigoSecuenciaRepo); err != nil {
 			return err
 		}
 
+		if err := s.baseService.conflictoService.RegistrarOmision(ctx, solicitud.ID, conflictos, req.JustificacionConflicto); err != nil {
+			return err
+		}
+		if err := s.baseService.morosidadService.RegistrarOmision(ctx, tx, "solicitud", solicitud.ID, vencidas, req.JustificacionMorosidad); err != nil {
+			return err
+		}
+
 		if solicitud.CupoDerechoItemID != nil {
 			itemRepoTx := s.itemRepo.WithTx(tx)
 			cupoItem, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
@@ -211,11 +230,18 @@ func (s *SolicitudDerechoService) CreateDerecho(ctx context.Context, req dtos.Cr
 				if cupoItem.IsVencido() && !currentUser.IsAdminOrResponsable() {
 					return errors.New("el periodo de este cupo ha vencido. Solo personal administrativo puede registrar solicitudes en periodos anteriores")
 				}
+				if s.cupoService.GetPoliticaItem(ctx, *cupoItem).BloqueaItem(*cupoItem) {
+					return errors.New("el cupo corresponde a un receso o semana bloqueada por la política de cupos vigente")
+				}
 
+				antes := *cupoItem
 				cupoItem.EstadoCupoDerechoCodigo = "RESERVADO"
 				if err := itemRepoTx.Update(ctx, cupoItem); err != nil {
 					return err
 				}
+				if err := s.baseService.ledgerService.RegistrarTx(ctx, tx, antes, *cupoItem, "CREAR_SOLICITUD", &solicitud.ID, nil); err != nil {
+					return err
+				}
 			}
 		}
 
@@ -362,13 +388,18 @@ func (s *SolicitudDerechoService) UpdateDerecho(ctx context.Context, id string,
 		}
 	}
 
+	conflictos, err := s.baseService.conflictoService.Evaluar(ctx, solicitud.UsuarioID, solicitud.ID, solicitud.Items, currentUser, req.JustificacionConflicto)
+	if err != nil {
+		return nil, err
+	}
+
 	// El Hook BeforeUpdate en el modelo se encargará de recalcular TipoItinerarioCodigo y EstadoSolicitudCodigo
 	err = s.repo.RunTransaction(func(repoTx *repositories.SolicitudRepository, tx *gorm.DB) error {
 
 		if err := repoTx.Update(ctx, solicitud); err != nil {
 			return err
 		}
-		return nil
+		return s.baseService.conflictoService.RegistrarOmision(ctx, solicitud.ID, conflictos, req.JustificacionConflicto)
 	})
 
 	if err != nil {
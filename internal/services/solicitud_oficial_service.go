@@ -101,6 +101,15 @@ func (s *SolicitudOficialService) CreateOficial(ctx context.Context, req dtos.Cr
 
 	solicitud.Items = items
 
+	conflictos, err := s.baseService.conflictoService.Evaluar(ctx, realSolicitanteID, "", items, currentUser, req.JustificacionConflicto)
+	if err != nil {
+		return nil, err
+	}
+	vencidas, err := s.baseService.morosidadService.Evaluar(ctx, realSolicitanteID, "", currentUser, req.JustificacionMorosidad)
+	if err != nil {
+		return nil, err
+	}
+
 	err = s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.SolicitudRepository, tx *gorm.DB) error {
 		currentYear := time.Now().Year()
 		for {
@@ -121,7 +130,10 @@ func (s *SolicitudOficialService) CreateOficial(ctx context.Context, req dtos.Cr
 			return err
 		}
 
-		return nil
+		if err := s.baseService.conflictoService.RegistrarOmision(ctx, solicitud.ID, conflictos, req.JustificacionConflicto); err != nil {
+			return err
+		}
+		return s.baseService.morosidadService.RegistrarOmision(ctx, tx, "solicitud", solicitud.ID, vencidas, req.JustificacionMorosidad)
 	})
 
 	if err != nil {
@@ -254,6 +266,14 @@ func (s *SolicitudOficialService) UpdateOficial(ctx context.Context, id string,
 		}
 		solicitud.UpdateStatusBasedOnItems()
 
+		conflictos, err := s.baseService.conflictoService.Evaluar(ctx, solicitud.UsuarioID, solicitud.ID, solicitud.Items, currentUser, req.JustificacionConflicto)
+		if err != nil {
+			return err
+		}
+		if err := s.baseService.conflictoService.RegistrarOmision(ctx, solicitud.ID, conflictos, req.JustificacionConflicto); err != nil {
+			return err
+		}
+
 		if err := tx.Model(solicitud).Update("estado_solicitud_codigo", solicitud.EstadoSolicitudCodigo).Error; err != nil {
 			return err
 		}
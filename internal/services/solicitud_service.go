@@ -20,6 +20,9 @@ func NewSolicitudService(
 	emailService *EmailService,
 	auditService *AuditService,
 	openTicketRepo *repositories.OpenTicketRepository,
+	conflictoService *ConflictoViajeService,
+	ledgerService *CupoLedgerService,
+	morosidadService *MorosidadService,
 ) *SolicitudService {
 	return &SolicitudService{
 		repo:              repo,
@@ -28,6 +31,9 @@ func NewSolicitudService(
 		emailService:      emailService,
 		auditService:      auditService,
 		openTicketRepo:    openTicketRepo,
+		conflictoService:  conflictoService,
+		ledgerService:     ledgerService,
+		morosidadService:  morosidadService,
 	}
 }
 
@@ -38,6 +44,9 @@ type SolicitudService struct {
 	emailService      *EmailService
 	auditService      *AuditService
 	openTicketRepo    *repositories.OpenTicketRepository
+	conflictoService  *ConflictoViajeService
+	ledgerService     *CupoLedgerService
+	morosidadService  *MorosidadService
 }
 
 // CreateDerecho and CreateOficial moved to specialized services.
@@ -248,11 +257,25 @@ func (s *SolicitudService) GetByID(ctx context.Context, id string) (*models.Soli
 	return s.repo.FindByID(ctx, id)
 }
 
+// GetConflictos retorna los choques de fechas de la solicitud con otros viajes del beneficiario.
+func (s *SolicitudService) GetConflictos(ctx context.Context, solicitud *models.Solicitud) models.ConflictosViaje {
+	conflictos, err := s.conflictoService.Detectar(ctx, solicitud.UsuarioID, solicitud.ID, solicitud.Items)
+	if err != nil {
+		return nil
+	}
+	return conflictos
+}
+
+// GetMorosidad retorna los descargos vencidos del beneficiario según la regla de bloqueo vigente.
+func (s *SolicitudService) GetMorosidad(ctx context.Context, usuarioID string) MorosidadBeneficiario {
+	return s.morosidadService.GetMorosidad(ctx, usuarioID)
+}
+
 func (s *SolicitudService) GetItemByID(ctx context.Context, id string) (*models.SolicitudItem, error) {
 	return s.solicitudItemRepo.FindByID(ctx, id)
 }
 
-func (s *SolicitudService) Approve(ctx context.Context, id string, user *models.Usuario) error {
+func (s *SolicitudService) Approve(ctx context.Context, id string, user *models.Usuario, justificacion string) error {
 	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.SolicitudRepository, tx *gorm.DB) error {
 		solicitud, err := repoTx.FindByID(ctx, id)
 		if err != nil {
@@ -264,6 +287,14 @@ func (s *SolicitudService) Approve(ctx context.Context, id string, user *models.
 			return errors.New("no tiene permisos para aprobar esta solicitud en su estado actual")
 		}
 
+		conflictos, err := s.conflictoService.Evaluar(ctx, solicitud.UsuarioID, solicitud.ID, solicitud.Items, user, justificacion)
+		if err != nil {
+			return err
+		}
+		if err := s.conflictoService.RegistrarOmision(ctx, solicitud.ID, conflictos, justificacion); err != nil {
+			return err
+		}
+
 		if err := solicitud.Approve(); err != nil {
 			return err
 		}
@@ -272,15 +303,8 @@ func (s *SolicitudService) Approve(ctx context.Context, id string, user *models.
 			return err
 		}
 
-		if solicitud.CupoDerechoItemID != nil {
-			itemRepoTx := s.itemRepo.WithTx(tx)
-			item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
-			if err == nil && item != nil {
-				item.EstadoCupoDerechoCodigo = "RESERVADO"
-				if err := itemRepoTx.Update(ctx, item); err != nil {
-					return err
-				}
-			}
+		if err := s.actualizarEstadoCupoTx(ctx, tx, solicitud, "RESERVADO", "APROBAR_SOLICITUD"); err != nil {
+			return err
 		}
 
 		return s.auditService.Log(ctx, "APROBAR_SOLICITUD", "solicitud", solicitud.ID, "SOLICITADO", "APROBADO", "", "")
@@ -332,8 +356,6 @@ func (s *SolicitudService) Finalize(ctx context.Context, id string, user *models
 			return errors.New("no tiene permisos para realizar esta acción")
 		}
 
-		itemRepoTx := s.itemRepo.WithTx(tx)
-
 		if err := solicitud.Finalize(); err != nil {
 			return err
 		}
@@ -345,14 +367,8 @@ func (s *SolicitudService) Finalize(ctx context.Context, id string, user *models
 			return err
 		}
 
-		if solicitud.CupoDerechoItemID != nil {
-			item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
-			if err == nil && item != nil {
-				item.EstadoCupoDerechoCodigo = "USADO"
-				if err := itemRepoTx.Update(ctx, item); err != nil {
-					return err
-				}
-			}
+		if err := s.actualizarEstadoCupoTx(ctx, tx, solicitud, "USADO", "FINALIZAR_SOLICITUD"); err != nil {
+			return err
 		}
 
 		for _, sit := range solicitud.Items {
@@ -389,15 +405,8 @@ func (s *SolicitudService) Reject(ctx context.Context, id string, user *models.U
 			return err
 		}
 
-		if solicitud.CupoDerechoItemID != nil {
-			itemRepoTx := s.itemRepo.WithTx(tx)
-			item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
-			if err == nil && item != nil {
-				item.EstadoCupoDerechoCodigo = "DISPONIBLE"
-				if err := itemRepoTx.Update(ctx, item); err != nil {
-					return err
-				}
-			}
+		if err := s.actualizarEstadoCupoTx(ctx, tx, solicitud, "DISPONIBLE", "RECHAZAR_SOLICITUD"); err != nil {
+			return err
 		}
 
 		return s.auditService.Log(ctx, "RECHAZAR_SOLICITUD", "solicitud", solicitud.ID, "SOLICITADO", "RECHAZADO", "", "")
@@ -496,22 +505,15 @@ func (s *SolicitudService) Delete(ctx context.Context, id string, user *models.U
 			return errors.New("solo se pueden eliminar solicitudes en estado SOLICITADO. El estado actual es: " + solicitud.GetEstado())
 		}
 
-		itemRepoTx := s.itemRepo.WithTx(tx)
-		if solicitud.CupoDerechoItemID != nil {
-			item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
-			if err == nil && item != nil {
-				item.EstadoCupoDerechoCodigo = "DISPONIBLE"
-				if err := itemRepoTx.Update(ctx, item); err != nil {
-					return err
-				}
-			}
+		if err := s.actualizarEstadoCupoTx(ctx, tx, solicitud, "DISPONIBLE", "ELIMINAR_SOLICITUD"); err != nil {
+			return err
 		}
 
 		return repoTx.Delete(ctx, id, user.ID)
 	})
 }
 
-func (s *SolicitudService) ApproveItem(ctx context.Context, solicitudID, itemID string, user *models.Usuario) error {
+func (s *SolicitudService) ApproveItem(ctx context.Context, solicitudID, itemID string, user *models.Usuario, justificacion string) error {
 	return s.repo.WithContext(ctx).RunTransaction(func(repoTx *repositories.SolicitudRepository, tx *gorm.DB) error {
 		solicitud, err := repoTx.FindByID(ctx, solicitudID)
 		if err != nil {
@@ -523,6 +525,16 @@ func (s *SolicitudService) ApproveItem(ctx context.Context, solicitudID, itemID
 			return errors.New("no tiene permisos para realizar esta acción")
 		}
 
+		if item := solicitud.GetItemByID(itemID); item != nil {
+			conflictos, err := s.conflictoService.Evaluar(ctx, solicitud.UsuarioID, solicitud.ID, []models.SolicitudItem{*item}, user, justificacion)
+			if err != nil {
+				return err
+			}
+			if err := s.conflictoService.RegistrarOmision(ctx, solicitud.ID, conflictos, justificacion); err != nil {
+				return err
+			}
+		}
+
 		if !solicitud.ApproveItem(itemID) {
 			return fmt.Errorf("item no encontrado")
 		}
@@ -531,15 +543,7 @@ func (s *SolicitudService) ApproveItem(ctx context.Context, solicitudID, itemID
 			return err
 		}
 
-		if solicitud.CupoDerechoItemID != nil {
-			itemRepoTx := s.itemRepo.WithTx(tx)
-			item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
-			if err == nil && item != nil {
-				item.EstadoCupoDerechoCodigo = "RESERVADO"
-				itemRepoTx.Update(ctx, item)
-			}
-		}
-		return nil
+		return s.actualizarEstadoCupoTx(ctx, tx, solicitud, "RESERVADO", "APROBAR_TRAMO")
 	})
 }
 
@@ -563,13 +567,8 @@ func (s *SolicitudService) RejectItem(ctx context.Context, solicitudID, itemID s
 			return err
 		}
 
-		if solicitud.AreAllItemsInactive() && solicitud.CupoDerechoItemID != nil {
-			itemRepoTx := s.itemRepo.WithTx(tx)
-			item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
-			if err == nil && item != nil {
-				item.EstadoCupoDerechoCodigo = "DISPONIBLE"
-				itemRepoTx.Update(ctx, item)
-			}
+		if solicitud.AreAllItemsInactive() {
+			return s.actualizarEstadoCupoTx(ctx, tx, solicitud, "DISPONIBLE", "RECHAZAR_TRAMO")
 		}
 		return nil
 	})
@@ -620,8 +619,6 @@ func (s *SolicitudService) RevertFinalize(ctx context.Context, id string, user *
 			return errors.New("no tiene permisos para realizar esta acción")
 		}
 
-		itemRepoTx := s.itemRepo.WithTx(tx)
-
 		if err := solicitud.RevertFinalize(); err != nil {
 			return err
 		}
@@ -633,14 +630,8 @@ func (s *SolicitudService) RevertFinalize(ctx context.Context, id string, user *
 			return err
 		}
 
-		if solicitud.CupoDerechoItemID != nil {
-			item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
-			if err == nil && item != nil {
-				item.EstadoCupoDerechoCodigo = "RESERVADO"
-				if err := itemRepoTx.Update(ctx, item); err != nil {
-					return err
-				}
-			}
+		if err := s.actualizarEstadoCupoTx(ctx, tx, solicitud, "RESERVADO", "REVERTIR_FINALIZACION"); err != nil {
+			return err
 		}
 
 		for _, sit := range solicitud.Items {
@@ -657,6 +648,28 @@ func (s *SolicitudService) RevertFinalize(ctx context.Context, id string, user *
 	})
 }
 
+// actualizarEstadoCupoTx cambia el estado del cupo de derecho asociado a la solicitud y
+// asienta el cambio en el libro de consumo.
+func (s *SolicitudService) actualizarEstadoCupoTx(ctx context.Context, tx *gorm.DB, solicitud *models.Solicitud, estado, origen string) error {
+	if solicitud.CupoDerechoItemID == nil {
+		return nil
+	}
+
+	itemRepoTx := s.itemRepo.WithTx(tx)
+	item, err := itemRepoTx.FindByID(ctx, *solicitud.CupoDerechoItemID)
+	if err != nil || item == nil {
+		return nil
+	}
+
+	antes := *item
+	item.EstadoCupoDerechoCodigo = estado
+	if err := itemRepoTx.Update(ctx, item); err != nil {
+		return err
+	}
+
+	return s.ledgerService.RegistrarTx(ctx, tx, antes, *item, origen, &solicitud.ID, solicitud.GetPasajeActivoID())
+}
+
 type PendingStats struct {
 	PendingRequests         int64
 	PendingDescargos        int
@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// toleranciaTarifaDefault se usa si TARIFA_TOLERANCIA_PORCENTAJE no está configurada.
+const toleranciaTarifaDefault = 10.0
+
+// ErrTarifaExcedida indica que el costo del pasaje supera la tarifa contratada más la
+// tolerancia y no cuenta con una autorización vigente.
+type ErrTarifaExcedida struct {
+	Desviacion *models.DesviacionTarifa
+}
+
+func (e *ErrTarifaExcedida) Error() string {
+	d := e.Desviacion
+	return fmt.Sprintf("el costo del pasaje (%.2f Bs) supera en %.2f%% la tarifa contratada (%.2f Bs); la tolerancia es %.2f%%. Requiere justificación y autorización de un responsable",
+		d.Costo, d.DesviacionPct, d.MontoReferencial, d.ToleranciaPct)
+}
+
+// TarifaService compara el costo de los pasajes con las tarifas contratadas por ruta y
+// aerolínea (RutaContrato) y registra las desviaciones.
+type TarifaService struct {
+	repo          *repositories.DesviacionTarifaRepository
+	rutaRepo      *repositories.RutaRepository
+	configService *ConfiguracionService
+	auditService  *AuditService
+}
+
+func NewTarifaService(
+	repo *repositories.DesviacionTarifaRepository,
+	rutaRepo *repositories.RutaRepository,
+	configService *ConfiguracionService,
+	auditService *AuditService,
+) *TarifaService {
+	return &TarifaService{
+		repo:          repo,
+		rutaRepo:      rutaRepo,
+		configService: configService,
+		auditService:  auditService,
+	}
+}
+
+func (s *TarifaService) GetToleranciaPct(ctx context.Context) float64 {
+	valor := s.configService.GetValue(ctx, "TARIFA_TOLERANCIA_PORCENTAJE")
+	if valor == "" {
+		return toleranciaTarifaDefault
+	}
+	return utils.ParseFloat(valor)
+}
+
+// Evaluar calcula la desviación del pasaje respecto a la tarifa contratada. Retorna nil si el
+// pasaje no tiene ruta/aerolínea o la combinación no tiene contrato (no hay con qué comparar).
+// Si ya existía un registro para el pasaje, lo reutiliza para conservar la autorización.
+func (s *TarifaService) Evaluar(ctx context.Context, pasaje *models.Pasaje) (*models.DesviacionTarifa, error) {
+	if pasaje.RutaID == nil || pasaje.AerolineaID == nil {
+		return nil, nil
+	}
+	contrato, err := s.rutaRepo.FindContract(ctx, *pasaje.RutaID, *pasaje.AerolineaID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if contrato.MontoReferencial <= 0 {
+		return nil, nil
+	}
+
+	d, err := s.repo.FindByPasajeID(ctx, pasaje.ID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		d = &models.DesviacionTarifa{PasajeID: pasaje.ID}
+	}
+
+	// Las tarifas contratadas están en bolivianos.
+	costo := pasaje.GetCostoBs()
+	desviacion := (costo - contrato.MontoReferencial) / contrato.MontoReferencial * 100
+	d.RutaID = *pasaje.RutaID
+	d.AerolineaID = *pasaje.AerolineaID
+	d.AgenciaID = pasaje.AgenciaID
+	d.MontoReferencial = contrato.MontoReferencial
+	d.DesviacionPct = math.Round(desviacion*100) / 100
+	d.ToleranciaPct = s.GetToleranciaPct(ctx)
+	if d.Estado == models.EstadoDesviacionAutorizada && !d.IsAutorizadaPara(costo) {
+		// El costo cambió después de autorizar: la autorización ya no aplica.
+		d.Estado = ""
+		d.Justificacion = ""
+		d.AutorizadoPorID = nil
+		d.FechaAutorizacion = nil
+	}
+	d.Costo = costo
+	return d, nil
+}
+
+// VerificarEmision se llama antes de emitir: bloquea la emisión si el costo excede la tolerancia
+// sin autorización vigente y retorna la desviación, que se guarda con RegistrarTx junto con la
+// emisión. Una reemisión no pasa por REGISTRADO, así que un responsable autoriza el exceso en el
+// mismo paso con la justificación.
+func (s *TarifaService) VerificarEmision(ctx context.Context, pasaje *models.Pasaje, actor *models.Usuario, justificacion string) (*models.DesviacionTarifa, error) {
+	d, err := s.Evaluar(ctx, pasaje)
+	if err != nil || d == nil {
+		return nil, err
+	}
+
+	if !d.ExcedeTolerancia() {
+		d.Estado = models.EstadoDesviacionDentroTolerancia
+		d.Justificacion = ""
+		d.AutorizadoPorID = nil
+		d.FechaAutorizacion = nil
+		return d, nil
+	}
+	if d.Estado == models.EstadoDesviacionAutorizada {
+		return d, nil
+	}
+	justificacion = strings.TrimSpace(justificacion)
+	if !pasaje.IsReemision() || justificacion == "" || actor == nil || !actor.IsAdminOrResponsable() {
+		return nil, &ErrTarifaExcedida{Desviacion: d}
+	}
+	autorizarDesviacion(d, justificacion, actor)
+	return d, nil
+}
+
+// RegistrarTx guarda la desviación verificada del pasaje, ya guardado, en la transacción de la
+// emisión. Si la autorización se dio al verificar, la deja en auditoría.
+func (s *TarifaService) RegistrarTx(ctx context.Context, tx *gorm.DB, pasaje *models.Pasaje, d *models.DesviacionTarifa) error {
+	if d == nil {
+		return nil
+	}
+	autorizadaAhora := d.ID == "" && d.Estado == models.EstadoDesviacionAutorizada
+	d.PasajeID = pasaje.ID
+	if err := s.repo.WithTx(tx).Save(ctx, d); err != nil {
+		return err
+	}
+	if autorizadaAhora {
+		return s.logAutorizacion(ctx, tx, pasaje, d)
+	}
+	return nil
+}
+
+// AutorizarSobreprecio registra la justificación y la autorización del responsable para emitir
+// un pasaje cuyo costo excede la tolerancia.
+func (s *TarifaService) AutorizarSobreprecio(ctx context.Context, pasaje *models.Pasaje, justificacion string, actor *models.Usuario) error {
+	justificacion = strings.TrimSpace(justificacion)
+	if justificacion == "" {
+		return errors.New("la justificación es obligatoria")
+	}
+	if actor == nil || !actor.IsAdminOrResponsable() {
+		return errors.New("solo un responsable puede autorizar un sobreprecio")
+	}
+
+	d, err := s.Evaluar(ctx, pasaje)
+	if err != nil {
+		return err
+	}
+	if d == nil || !d.ExcedeTolerancia() {
+		return errors.New("el pasaje no excede la tarifa contratada")
+	}
+
+	autorizarDesviacion(d, justificacion, actor)
+	return s.repo.RunTransaction(func(repoTx *repositories.DesviacionTarifaRepository, tx *gorm.DB) error {
+		if err := repoTx.Save(ctx, d); err != nil {
+			return err
+		}
+		return s.logAutorizacion(ctx, tx, pasaje, d)
+	})
+}
+
+func autorizarDesviacion(d *models.DesviacionTarifa, justificacion string, actor *models.Usuario) {
+	now := time.Now()
+	d.Estado = models.EstadoDesviacionAutorizada
+	d.Justificacion = justificacion
+	d.AutorizadoPorID = &actor.ID
+	d.FechaAutorizacion = &now
+}
+
+func (s *TarifaService) logAutorizacion(ctx context.Context, tx *gorm.DB, pasaje *models.Pasaje, d *models.DesviacionTarifa) error {
+	return s.auditService.LogTx(ctx, tx, "AUTORIZAR_SOBREPRECIO", "pasaje", pasaje.ID,
+		fmt.Sprintf("%.2f", d.MontoReferencial), fmt.Sprintf("%.2f (%+.2f%%): %s", d.Costo, d.DesviacionPct, d.Justificacion), "", "")
+}
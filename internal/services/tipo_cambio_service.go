@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TipoCambioService struct {
+	repo          *repositories.TipoCambioRepository
+	configService *ConfiguracionService
+	auditService  *AuditService
+}
+
+func NewTipoCambioService(repo *repositories.TipoCambioRepository, configService *ConfiguracionService, auditService *AuditService) *TipoCambioService {
+	return &TipoCambioService{
+		repo:          repo,
+		configService: configService,
+		auditService:  auditService,
+	}
+}
+
+func (s *TipoCambioService) GetRecientes(ctx context.Context) ([]models.TipoCambio, error) {
+	return s.repo.FindAll(ctx, 200)
+}
+
+func (s *TipoCambioService) Create(ctx context.Context, req dtos.CreateTipoCambioRequest, actor *models.Usuario) error {
+	moneda := strings.ToUpper(strings.TrimSpace(req.Moneda))
+	if !models.IsMonedaValida(moneda) || moneda == models.MonedaBOB {
+		return errors.New("moneda no válida")
+	}
+	fecha := utils.ParseDatePtr("2006-01-02", req.Fecha)
+	if fecha == nil {
+		return errors.New("la fecha no es válida")
+	}
+	valor := utils.ParseFloat(req.Valor)
+	if valor <= 0 {
+		return errors.New("el tipo de cambio debe ser mayor a cero")
+	}
+	if existe, _ := s.repo.ExistsByMonedaFecha(ctx, moneda, *fecha); existe {
+		return fmt.Errorf("ya existe un tipo de cambio %s para el %s", moneda, fecha.Format("02/01/2006"))
+	}
+
+	tc := &models.TipoCambio{
+		BaseModel: models.BaseModel{CreatedBy: &actor.ID},
+		Moneda:    moneda,
+		Fecha:     *fecha,
+		Valor:     valor,
+		Fuente:    strings.TrimSpace(req.Fuente),
+	}
+	if err := s.repo.Create(ctx, tc); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "CREAR_TIPO_CAMBIO", "tipo_cambio", tc.ID, "", fmt.Sprintf("%s %s = %.4f Bs", moneda, fecha.Format("02/01/2006"), valor), "", "")
+	return nil
+}
+
+func (s *TipoCambioService) Delete(ctx context.Context, id string) error {
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ELIMINAR_TIPO_CAMBIO", "tipo_cambio", id, "", "", "", "")
+	return nil
+}
+
+// GetTasa retorna la cotización en bolivianos de la moneda a la fecha dada. Si la tabla no
+// tiene cotizaciones previas para USD, usa TC_USD_OFICIAL de la configuración.
+func (s *TipoCambioService) GetTasa(ctx context.Context, moneda string, fecha time.Time) (float64, error) {
+	if moneda == "" || moneda == models.MonedaBOB {
+		return 1, nil
+	}
+
+	tc, err := s.repo.FindVigente(ctx, moneda, fecha)
+	if err == nil {
+		return tc.Valor, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	if moneda == models.MonedaUSD {
+		if valor := utils.ParseFloat(s.configService.GetValue(ctx, "TC_USD_OFICIAL")); valor > 0 {
+			return valor, nil
+		}
+	}
+	return 0, fmt.Errorf("no hay tipo de cambio %s registrado al %s", moneda, fecha.Format("02/01/2006"))
+}
@@ -0,0 +1,293 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sistema-pasajes/internal/dtos"
+	"sistema-pasajes/internal/models"
+	"sistema-pasajes/internal/repositories"
+	"sistema-pasajes/internal/utils"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// TasaRCIVADefault se aplica si RC_IVA_TASA no está configurada.
+const TasaRCIVADefault = 0.13
+
+type ViaticoService struct {
+	repo              *repositories.ViaticoRepository
+	descargoRepo      *repositories.DescargoRepository
+	tipoCambioService *TipoCambioService
+	configService     *ConfiguracionService
+	auditService      *AuditService
+}
+
+func NewViaticoService(
+	repo *repositories.ViaticoRepository,
+	descargoRepo *repositories.DescargoRepository,
+	tipoCambioService *TipoCambioService,
+	configService *ConfiguracionService,
+	auditService *AuditService,
+) *ViaticoService {
+	return &ViaticoService{
+		repo:              repo,
+		descargoRepo:      descargoRepo,
+		tipoCambioService: tipoCambioService,
+		configService:     configService,
+		auditService:      auditService,
+	}
+}
+
+func (s *ViaticoService) GetTasaRCIVA(ctx context.Context) float64 {
+	valor := strings.TrimSpace(s.configService.GetValue(ctx, "RC_IVA_TASA"))
+	if valor == "" {
+		return TasaRCIVADefault
+	}
+	if tasa := utils.ParseFloat(valor); tasa >= 0 && tasa < 1 {
+		return tasa
+	}
+	return TasaRCIVADefault
+}
+
+func (s *ViaticoService) GetZonas(ctx context.Context) ([]models.ZonaViatico, error) {
+	return s.repo.FindZonas(ctx)
+}
+
+func (s *ViaticoService) GetCategorias(ctx context.Context) ([]models.CategoriaViatico, error) {
+	return s.repo.FindCategorias(ctx)
+}
+
+func (s *ViaticoService) CreateZona(ctx context.Context, nombre string) error {
+	zona := &models.ZonaViatico{Nombre: strings.ToUpper(strings.TrimSpace(nombre))}
+	if zona.Nombre == "" {
+		return errors.New("el nombre de la zona es obligatorio")
+	}
+	if err := s.repo.CreateZona(ctx, zona); err != nil {
+		return fmt.Errorf("no se pudo registrar la zona (¿ya existe?): %w", err)
+	}
+	s.auditService.Log(ctx, "CREAR_ZONA_VIATICO", "viatico", zona.ID, "", zona.Nombre, "", "")
+	return nil
+}
+
+func (s *ViaticoService) DeleteZona(ctx context.Context, id string) error {
+	if n, err := s.repo.CountCategoriasByZona(ctx, id); err != nil {
+		return err
+	} else if n > 0 {
+		return errors.New("la zona tiene escalas registradas; elimínelas primero")
+	}
+	if err := s.repo.DeleteZona(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ELIMINAR_ZONA_VIATICO", "viatico", id, "", "", "", "")
+	return nil
+}
+
+// SaveCategoria registra la escala o, si ya existe para la categoría, zona y ámbito, actualiza su monto.
+func (s *ViaticoService) SaveCategoria(ctx context.Context, req dtos.CreateCategoriaViaticoRequest) error {
+	cat := models.CategoriaViatico{
+		Codigo:        utils.StrToInt(req.Codigo, -1),
+		Nombre:        strings.TrimSpace(req.Nombre),
+		ZonaViaticoID: req.ZonaViaticoID,
+		AmbitoCodigo:  req.AmbitoCodigo,
+		Monto:         utils.ParseFloat(req.Monto),
+		Moneda:        req.Moneda,
+	}
+	if cat.Codigo < 0 || cat.Nombre == "" || cat.ZonaViaticoID == "" || cat.AmbitoCodigo == "" {
+		return errors.New("categoría, nombre, zona y ámbito son obligatorios")
+	}
+	if cat.Monto <= 0 {
+		return errors.New("el monto diario debe ser mayor a cero")
+	}
+	if !models.IsMonedaValida(cat.Moneda) {
+		return fmt.Errorf("moneda no válida: %s", cat.Moneda)
+	}
+
+	existing, err := s.repo.FindCategoria(ctx, cat.Codigo, cat.ZonaViaticoID, cat.AmbitoCodigo)
+	if err == nil {
+		anterior := fmt.Sprintf("%s %.2f", existing.Moneda, existing.Monto)
+		existing.Nombre, existing.Monto, existing.Moneda = cat.Nombre, cat.Monto, cat.Moneda
+		if err := s.repo.UpdateCategoria(ctx, existing); err != nil {
+			return err
+		}
+		s.auditService.Log(ctx, "EDITAR_ESCALA_VIATICO", "viatico", existing.ID, anterior, fmt.Sprintf("%s %.2f", cat.Moneda, cat.Monto), "", "")
+		return nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	if err := s.repo.CreateCategoria(ctx, &cat); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "CREAR_ESCALA_VIATICO", "viatico", cat.ID, "", fmt.Sprintf("Cat. %d %s: %s %.2f", cat.Codigo, cat.AmbitoCodigo, cat.Moneda, cat.Monto), "", "")
+	return nil
+}
+
+func (s *ViaticoService) DeleteCategoria(ctx context.Context, id string) error {
+	cat, err := s.repo.FindCategoriaByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteCategoria(ctx, id); err != nil {
+		return err
+	}
+	s.auditService.Log(ctx, "ELIMINAR_ESCALA_VIATICO", "viatico", id, fmt.Sprintf("Cat. %d %s: %s %.2f", cat.Codigo, cat.AmbitoCodigo, cat.Moneda, cat.Monto), "", "", "")
+	return nil
+}
+
+// Calcular liquida los viáticos del descargo oficial: días de la comisión por la escala de la
+// categoría del beneficiario en la zona y ámbito del viaje, convertidos a bolivianos y con la
+// retención RC-IVA. Recalcular reemplaza la liquidación anterior.
+func (s *ViaticoService) Calcular(ctx context.Context, descargoID, zonaID string, actor *models.Usuario) (*models.Viatico, error) {
+	descargo, err := s.descargoRepo.FindByID(ctx, descargoID)
+	if err != nil {
+		return nil, err
+	}
+	sol := descargo.Solicitud
+	if sol == nil || !sol.IsOficial() {
+		return nil, errors.New("solo los descargos de viajes oficiales liquidan viáticos")
+	}
+	if !descargo.PermiteLiquidarViatico() {
+		return nil, fmt.Errorf("el descargo está %s; los viáticos solo se liquidan hasta su revisión", descargo.GetEstadoLabel())
+	}
+	if descargo.Oficial == nil {
+		return nil, errors.New("registre el informe PV-06 con las fechas de salida y retorno")
+	}
+	dias := models.DiasViatico(descargo.Oficial.FechaSalida, descargo.Oficial.FechaRetorno)
+	if dias == 0 {
+		return nil, errors.New("las fechas de salida y retorno del informe no son válidas")
+	}
+	if sol.Usuario.Cargo == nil {
+		return nil, errors.New("el beneficiario no tiene cargo asignado; no se puede determinar su categoría de viático")
+	}
+
+	categoria := sol.Usuario.Cargo.Categoria
+	escala, err := s.repo.FindCategoria(ctx, categoria, zonaID, sol.AmbitoViajeCodigo)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("no hay escala de viático para la categoría %d en la zona elegida (%s)", categoria, sol.GetAmbitoViajeNombre())
+	} else if err != nil {
+		return nil, err
+	}
+
+	tc, err := s.tipoCambioService.GetTasa(ctx, escala.Moneda, descargo.Oficial.FechaSalida)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &models.Viatico{
+		DescargoID:         descargo.ID,
+		UsuarioID:          sol.UsuarioID,
+		CategoriaViaticoID: escala.ID,
+		Categoria:          categoria,
+		CategoriaNombre:    escala.Nombre,
+		AmbitoCodigo:       sol.AmbitoViajeCodigo,
+		FechaSalida:        descargo.Oficial.FechaSalida,
+		FechaRetorno:       descargo.Oficial.FechaRetorno,
+		Dias:               dias,
+		MontoDiario:        escala.Monto,
+		Moneda:             escala.Moneda,
+		TipoCambio:         tc,
+		TasaRCIVA:          s.GetTasaRCIVA(ctx),
+	}
+	if escala.ZonaViatico != nil {
+		v.ZonaNombre = escala.ZonaViatico.Nombre
+	}
+	v.Liquidar()
+
+	anterior := ""
+	if existing, err := s.repo.FindByDescargoID(ctx, descargo.ID); err == nil {
+		anterior = fmt.Sprintf("%d días, Bs %.2f", existing.Dias, existing.Liquido)
+		v.BaseModel = existing.BaseModel
+		v.UpdatedBy = &actor.ID
+		if err := s.repo.Update(ctx, v); err != nil {
+			return nil, err
+		}
+	} else if errors.Is(err, gorm.ErrRecordNotFound) {
+		v.CreatedBy = &actor.ID
+		if err := s.repo.Create(ctx, v); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+
+	s.auditService.Log(ctx, "CALCULAR_VIATICO", "descargo", descargo.ID, anterior, fmt.Sprintf("%d días, Bs %.2f", v.Dias, v.Liquido), "", "")
+	return v, nil
+}
+
+// GetPlanilla lista las liquidaciones de las comisiones que retornaron en el rango.
+func (s *ViaticoService) GetPlanilla(ctx context.Context, desde, hasta time.Time) ([]models.Viatico, error) {
+	return s.repo.FindByRetorno(ctx, desde, hasta)
+}
+
+// GeneratePlanillaExcel arma la planilla de viáticos para pago por sueldos.
+func (s *ViaticoService) GeneratePlanillaExcel(ctx context.Context, desde, hasta time.Time) (*excelize.File, error) {
+	viaticos, err := s.GetPlanilla(ctx, desde, hasta)
+	if err != nil {
+		return nil, err
+	}
+
+	f := excelize.NewFile()
+	sheet := "Planilla Viáticos"
+	f.SetSheetName("Sheet1", sheet)
+
+	headerStyle, _ := f.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true, Color: "FFFFFF"},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"0F7654"}, Pattern: 1},
+	})
+	totalStyle, _ := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+
+	headers := []string{"N°", "CI", "BENEFICIARIO", "CARGO", "SOLICITUD", "DESCARGO", "CATEGORÍA", "ZONA", "ÁMBITO",
+		"SALIDA", "RETORNO", "DÍAS", "MONTO DIARIO", "MONEDA", "T/C", "TOTAL (BS)", "RC-IVA (BS)", "LÍQUIDO PAGABLE (BS)"}
+	for i, h := range headers {
+		cell, _ := excelize.CoordinatesToCellName(i+1, 1)
+		f.SetCellValue(sheet, cell, h)
+		f.SetCellStyle(sheet, cell, cell, headerStyle)
+	}
+
+	var total, retencion, liquido float64
+	row := 2
+	for i, v := range viaticos {
+		nombre, ci, cargo := "", "", ""
+		if v.Usuario != nil {
+			nombre, ci = v.Usuario.GetNombreCompleto(), v.Usuario.CI
+			if v.Usuario.Cargo != nil {
+				cargo = v.Usuario.Cargo.Descripcion
+			}
+		}
+		solicitud, descargo := "", ""
+		if v.Descargo != nil {
+			descargo = v.Descargo.Codigo
+			if v.Descargo.Solicitud != nil {
+				solicitud = v.Descargo.Solicitud.Codigo
+			}
+		}
+		valores := []any{i + 1, ci, nombre, cargo, solicitud, descargo, fmt.Sprintf("%d - %s", v.Categoria, v.CategoriaNombre), v.ZonaNombre, v.AmbitoCodigo,
+			v.FechaSalida.Format("02/01/2006"), v.FechaRetorno.Format("02/01/2006"), v.Dias, v.MontoDiario, v.GetMonedaSimbolo(), v.TipoCambio,
+			v.Total, v.Retencion, v.Liquido}
+		for col, val := range valores {
+			cell, _ := excelize.CoordinatesToCellName(col+1, row)
+			f.SetCellValue(sheet, cell, val)
+		}
+		total += v.Total
+		retencion += v.Retencion
+		liquido += v.Liquido
+		row++
+	}
+
+	f.SetCellValue(sheet, fmt.Sprintf("O%d", row), "TOTALES")
+	f.SetCellValue(sheet, fmt.Sprintf("P%d", row), total)
+	f.SetCellValue(sheet, fmt.Sprintf("Q%d", row), retencion)
+	f.SetCellValue(sheet, fmt.Sprintf("R%d", row), liquido)
+	f.SetCellStyle(sheet, fmt.Sprintf("O%d", row), fmt.Sprintf("R%d", row), totalStyle)
+
+	f.SetColWidth(sheet, "A", "B", 12)
+	f.SetColWidth(sheet, "C", "D", 35)
+	f.SetColWidth(sheet, "E", "I", 16)
+	f.SetColWidth(sheet, "J", "R", 14)
+
+	return f, nil
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/aztec"
+	"github.com/makiuchi-d/gozxing/datamatrix"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+var ErrSinCodigoBarras = errors.New("no se encontró un código de barras legible")
+
+// DecodeBarcodes busca códigos 2D en un PDF o en una imagen (PNG/JPEG) y retorna el texto
+// de cada uno, sin repetir. Se prueban los formatos admitidos por IATA para el pase de
+// abordar: PDF417, QR, Aztec y DataMatrix.
+func DecodeBarcodes(data []byte) ([]string, error) {
+	var imgs []image.Image
+	if bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		var err error
+		if imgs, err = ExtractPDFImages(data); err != nil {
+			return nil, err
+		}
+	} else {
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.New("el archivo no es un PDF ni una imagen legible")
+		}
+		imgs = append(imgs, img)
+	}
+
+	var textos []string
+	vistos := map[string]bool{}
+	for _, img := range imgs {
+		for _, texto := range decodeBarcodesImage(img) {
+			if texto != "" && !vistos[texto] {
+				vistos[texto] = true
+				textos = append(textos, texto)
+			}
+		}
+	}
+	if len(textos) == 0 {
+		return nil, ErrSinCodigoBarras
+	}
+	return textos, nil
+}
+
+func decodeBarcodesImage(img image.Image) []string {
+	var textos []string
+	if texto, err := DecodePDF417(img); err == nil {
+		textos = append(textos, texto)
+	}
+
+	bmp, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return textos
+	}
+	hints := map[gozxing.DecodeHintType]interface{}{gozxing.DecodeHintType_TRY_HARDER: true}
+	for _, reader := range []gozxing.Reader{qrcode.NewQRCodeReader(), aztec.NewAztecReader(), datamatrix.NewDataMatrixReader()} {
+		if res, err := reader.Decode(bmp, hints); err == nil {
+			textos = append(textos, res.GetText())
+		}
+	}
+	return textos
+}
@@ -5,34 +5,57 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"sistema-pasajes/internal/appcontext"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-// SaveUploadedFile gestiona la persistencia de archivos subidos, retornando la ruta relativa.
-func SaveUploadedFile(c *gin.Context, file *multipart.FileHeader, uploadDir string, prefix string) (string, error) {
+// SaveUploadedFile valida el archivo subido contra la regla del campo (tipo por contenido,
+// tamaño, antivirus), normaliza las imágenes y lo guarda, retornando la ruta relativa. Si el
+// archivo no cumple la regla devuelve *UploadError.
+func SaveUploadedFile(c *gin.Context, file *multipart.FileHeader, uploadDir string, prefix string, rule UploadRule) (string, error) {
 	if file == nil {
 		return "", nil
 	}
 
+	data, ext, err := processUpload(c.Request.Context(), file, rule)
+	if err != nil {
+		return "", err
+	}
+
 	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
 		if err := os.MkdirAll(uploadDir, 0755); err != nil {
 			return "", err
 		}
 	}
 
-	ext := filepath.Ext(file.Filename)
 	fileName := fmt.Sprintf("%s%d%s", prefix, time.Now().UnixNano(), ext)
 	filePath := filepath.Join(uploadDir, fileName)
 
-	if err := c.SaveUploadedFile(file, filePath); err != nil {
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
 		return "", err
 	}
+	appcontext.SetUploadedFile(c, filePath, file.Filename)
 
 	return filePath, nil
 }
 
+// SaveFormFile guarda el archivo del campo si se envió uno. Un archivo rechazado queda
+// registrado en UploadErrors bajo el nombre del campo y devuelve la ruta vacía.
+func SaveFormFile(c *gin.Context, field string, uploadDir string, prefix string, rule UploadRule) string {
+	file, err := c.FormFile(field)
+	if err != nil {
+		return ""
+	}
+	path, err := SaveUploadedFile(c, file, uploadDir, prefix, rule)
+	if err != nil {
+		AddUploadError(c, field, err)
+		return ""
+	}
+	return path
+}
+
 // ExtractDescargoFiles procesa de forma masiva los archivos de pases de abordaje para un descargo.
 func ExtractDescargoFiles(c *gin.Context, tramoIDs []string) []string {
 	var paths []string
@@ -41,11 +64,8 @@ func ExtractDescargoFiles(c *gin.Context, tramoIDs []string) []string {
 		path := c.PostForm("tramo_archivo_existente_" + idRow)
 
 		// 2. Intentar capturar el archivo nuevo subido para esta fila
-		if file, err := c.FormFile("tramo_archivo_" + idRow); err == nil {
-			savedPath, err := SaveUploadedFile(c, file, "uploads/pases_abordo", "pase_descargo_"+idRow+"_")
-			if err == nil {
-				path = savedPath
-			}
+		if savedPath := SaveFormFile(c, "tramo_archivo_"+idRow, "uploads/pases_abordo", "pase_descargo_"+idRow+"_", UploadPDF); savedPath != "" {
+			path = savedPath
 		}
 		paths = append(paths, path)
 	}
@@ -65,10 +85,12 @@ func ExtractDescargoAnexos(c *gin.Context, id string) []string {
 	paths = append(paths, existentes...)
 
 	for _, fileHeader := range newAnexos {
-		savedPath, err := SaveUploadedFile(c, fileHeader, "uploads/anexos", "anexo_edit_"+id+"_")
-		if err == nil {
-			paths = append(paths, savedPath)
+		savedPath, err := SaveUploadedFile(c, fileHeader, "uploads/anexos", "anexo_edit_"+id+"_", UploadPhoto)
+		if err != nil {
+			AddUploadError(c, "anexos[]", err)
+			continue
 		}
+		paths = append(paths, savedPath)
 	}
 	return paths
 }
@@ -81,11 +103,8 @@ func ExtractTerrestreFiles(c *gin.Context, itemIDs []string) []string {
 		path := c.PostForm("terrestre_archivo_existente_" + idRow)
 
 		// 2. Intentar capturar el archivo nuevo subido para esta fila
-		if file, err := c.FormFile("terrestre_archivo_" + idRow); err == nil {
-			savedPath, err := SaveUploadedFile(c, file, "uploads/terrestre", "terrestre_"+idRow+"_")
-			if err == nil {
-				path = savedPath
-			}
+		if savedPath := SaveFormFile(c, "terrestre_archivo_"+idRow, "uploads/terrestre", "terrestre_"+idRow+"_", UploadPDF); savedPath != "" {
+			path = savedPath
 		}
 		paths = append(paths, path)
 	}
@@ -100,11 +119,8 @@ func ExtractPasajeBoletas(c *gin.Context, pasajeIDs []string) []string {
 		path := c.PostForm("liquidacion_archivo_existente_" + pid)
 
 		// 2. Intentar capturar el archivo nuevo subido para esta fila
-		if file, err := c.FormFile("liquidacion_archivo_" + pid); err == nil {
-			savedPath, err := SaveUploadedFile(c, file, "uploads/descargos/pagos", "boleta_"+pid+"_")
-			if err == nil {
-				path = savedPath
-			}
+		if savedPath := SaveFormFile(c, "liquidacion_archivo_"+pid, "uploads/descargos/pagos", "boleta_"+pid+"_", UploadPDF); savedPath != "" {
+			path = savedPath
 		}
 		paths = append(paths, path)
 	}
@@ -115,11 +131,25 @@ func ExtractPasajeBoletas(c *gin.Context, pasajeIDs []string) []string {
 func ExtractMemorandumFile(c *gin.Context, id string) string {
 	path := c.PostForm("archivo_memorandum_existente")
 
-	if file, err := c.FormFile("archivo_memorandum"); err == nil {
-		savedPath, err := SaveUploadedFile(c, file, "uploads/memorandums", "memo_"+id+"_")
-		if err == nil {
-			path = savedPath
-		}
+	if savedPath := SaveFormFile(c, "archivo_memorandum", "uploads/memorandums", "memo_"+id+"_", UploadPDF); savedPath != "" {
+		path = savedPath
+	}
+	return path
+}
+
+// ExtractBoletaDepositoFile procesa la boleta de depósito del saldo de anticipo de un descargo oficial.
+func ExtractBoletaDepositoFile(c *gin.Context, id string) string {
+	path := c.PostForm("archivo_boleta_deposito_existente")
+
+	if savedPath := SaveFormFile(c, "archivo_boleta_deposito", "uploads/descargos/pagos", "anticipo_"+id+"_", UploadDocument); savedPath != "" {
+		path = savedPath
 	}
 	return path
 }
+
+// ReadUploadedFile valida el archivo subido contra la regla igual que SaveUploadedFile, pero
+// devuelve el contenido sin guardarlo, para archivos que solo se procesan en la petición.
+func ReadUploadedFile(c *gin.Context, file *multipart.FileHeader, rule UploadRule) ([]byte, error) {
+	data, _, err := processUpload(c.Request.Context(), file, rule)
+	return data, err
+}
@@ -0,0 +1,468 @@
+package utils
+
+import (
+	"errors"
+	"image"
+	"math"
+	"math/big"
+	"strings"
+)
+
+var ErrPDF417NoEncontrado = errors.New("no se encontró un código PDF417 legible")
+
+// pdf417Indice asocia cada patrón de barras con su cluster (0, 1, 2) y su valor.
+var pdf417Indice = func() map[int][2]int {
+	m := make(map[int][2]int, 3*929)
+	for c := range pdf417Patrones {
+		for v, p := range pdf417Patrones[c] {
+			m[p] = [2]int{c, v}
+		}
+	}
+	return m
+}()
+
+var (
+	pdf417Inicio = []int{8, 1, 1, 1, 1, 1, 1, 3}
+	pdf417Fin    = []int{7, 1, 1, 3, 1, 1, 1, 2}
+)
+
+const (
+	pdf417MixedChars = "0123456789&\r\t,:#-.$/+%*=^"
+	pdf417PunctChars = ";<>@[\\]_`~!\r\t,:\n-.$/\"|*()?{}'"
+)
+
+// DecodePDF417 lee un código PDF417 de una imagen nítida y sin inclinación, como la
+// incrustada en un pase de abordar en PDF o una captura de pantalla. Prueba las cuatro
+// orientaciones; no corrige errores, solo los detecta: si algún codeword no se lee, falla.
+func DecodePDF417(img image.Image) (string, error) {
+	bm := newPDF417Bitmap(img)
+	if bm == nil {
+		return "", ErrPDF417NoEncontrado
+	}
+	for i := 0; i < 4; i++ {
+		if text, ok := bm.decode(); ok {
+			return text, nil
+		}
+		bm = bm.rotate()
+	}
+	return "", ErrPDF417NoEncontrado
+}
+
+type pdf417Bitmap struct {
+	w, h  int
+	black []bool
+}
+
+// newPDF417Bitmap binariza la imagen con el umbral de Otsu.
+func newPDF417Bitmap(img image.Image) *pdf417Bitmap {
+	r := img.Bounds()
+	w, h := r.Dx(), r.Dy()
+	if w < 17*5 || h < 3 {
+		return nil
+	}
+
+	lum := make([]uint8, w*h)
+	var hist [256]int
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			cr, cg, cb, ca := img.At(r.Min.X+x, r.Min.Y+y).RGBA()
+			// Los píxeles transparentes se toman como fondo blanco
+			l := (299*cr + 587*cg + 114*cb) / 1000
+			l = (l*ca + 0xffff*(0xffff-ca)) / 0xffff
+			v := uint8(l >> 8)
+			lum[y*w+x] = v
+			hist[v]++
+		}
+	}
+
+	total := w * h
+	var sum float64
+	for i, c := range hist {
+		sum += float64(i * c)
+	}
+	var sumB, best float64
+	var wB, threshold int
+	for i, c := range hist {
+		wB += c
+		if wB == 0 {
+			continue
+		}
+		wF := total - wB
+		if wF == 0 {
+			break
+		}
+		sumB += float64(i * c)
+		mB, mF := sumB/float64(wB), (sum-sumB)/float64(wF)
+		if between := float64(wB) * float64(wF) * (mB - mF) * (mB - mF); between > best {
+			best, threshold = between, i
+		}
+	}
+
+	bm := &pdf417Bitmap{w: w, h: h, black: make([]bool, total)}
+	for i, v := range lum {
+		bm.black[i] = int(v) <= threshold
+	}
+	return bm
+}
+
+// rotate gira el bitmap 90° en sentido horario.
+func (b *pdf417Bitmap) rotate() *pdf417Bitmap {
+	r := &pdf417Bitmap{w: b.h, h: b.w, black: make([]bool, len(b.black))}
+	for y := 0; y < b.h; y++ {
+		for x := 0; x < b.w; x++ {
+			r.black[x*r.w+(b.h-1-y)] = b.black[y*b.w+x]
+		}
+	}
+	return r
+}
+
+type pdf417Line struct {
+	row int
+	cws []int
+}
+
+// decode lee cada línea de píxeles y arma la matriz de codewords por votación: cada fila
+// del símbolo ocupa varias líneas, y basta con que una la lea completa.
+func (b *pdf417Bitmap) decode() (string, bool) {
+	var lines []pdf417Line
+	colVotes := map[int]int{}
+	for y := 0; y < b.h; y++ {
+		cluster, cws, ok := pdf417ScanLine(b.black[y*b.w : (y+1)*b.w])
+		if !ok || cws[0] < 0 {
+			continue
+		}
+		lines = append(lines, pdf417Line{row: 3*(cws[0]/30) + cluster, cws: cws})
+		colVotes[len(cws)-2]++
+	}
+	cols := 0
+	for c, n := range colVotes {
+		if n > colVotes[cols] {
+			cols = c
+		}
+	}
+	if cols == 0 {
+		return "", false
+	}
+
+	votes := map[[2]int]map[int]int{}
+	rows := 0
+	for _, l := range lines {
+		if len(l.cws)-2 != cols {
+			continue
+		}
+		rows = max(rows, l.row+1)
+		for c, v := range l.cws[1 : len(l.cws)-1] {
+			if v < 0 {
+				continue
+			}
+			k := [2]int{l.row, c}
+			if votes[k] == nil {
+				votes[k] = map[int]int{}
+			}
+			votes[k][v]++
+		}
+	}
+
+	cws := make([]int, 0, rows*cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			best, bestN := -1, 0
+			for v, n := range votes[[2]int{r, c}] {
+				if n > bestN {
+					best, bestN = v, n
+				}
+			}
+			if best < 0 {
+				return "", false
+			}
+			cws = append(cws, best)
+		}
+	}
+
+	// El primer codeword es la cantidad de codewords de datos; el resto es corrección de errores
+	n := len(cws)
+	if n < 2 || cws[0] < 1 || cws[0] >= n {
+		return "", false
+	}
+	ec := n - cws[0]
+	if ec < 2 || ec&(ec-1) != 0 || !pdf417Verify(cws, ec) {
+		return "", false
+	}
+	text := pdf417Text(cws[:cws[0]])
+	return text, text != ""
+}
+
+// pdf417ScanLine busca el patrón de inicio y lee los codewords de la línea hasta el patrón
+// de fin. Retorna el cluster y los codewords (indicadores de fila incluidos; -1 si no se
+// reconoció el patrón).
+func pdf417ScanLine(line []bool) (int, []int, bool) {
+	var runs []int
+	for x := 0; x < len(line); x++ {
+		if len(runs) == 0 {
+			if line[x] {
+				runs = append(runs, 1)
+			}
+			continue
+		}
+		if line[x] == (len(runs)%2 == 1) {
+			runs[len(runs)-1]++
+		} else {
+			runs = append(runs, 1)
+		}
+	}
+
+	for i := 0; i+8 <= len(runs); i += 2 {
+		if !pdf417Matches(runs[i:i+8], pdf417Inicio) {
+			continue
+		}
+		cluster := -1
+		var cws []int
+		for j := i + 8; j+8 <= len(runs); j += 8 {
+			if pdf417Matches(runs[j:j+8], pdf417Fin) {
+				if cluster >= 0 && len(cws) >= 3 {
+					return cluster, cws, true
+				}
+				break
+			}
+			e, ok := pdf417Indice[pdf417Pattern(runs[j:j+8])]
+			if !ok || (cluster >= 0 && e[0] != cluster) {
+				cws = append(cws, -1)
+				continue
+			}
+			cluster = e[0]
+			cws = append(cws, e[1])
+		}
+	}
+	return 0, nil, false
+}
+
+// pdf417Modules reparte las 8 barras y espacios de un codeword en 17 módulos a partir de
+// sus anchos en píxeles.
+func pdf417Modules(runs []int) ([8]int, bool) {
+	var mods [8]int
+	total := 0
+	for _, r := range runs {
+		total += r
+	}
+	acc, prev := 0, 0
+	for k, r := range runs {
+		acc += r
+		b := int(math.Round(float64(acc) * 17 / float64(total)))
+		mods[k] = b - prev
+		prev = b
+		if mods[k] < 1 || mods[k] > 8 {
+			return mods, false
+		}
+	}
+	return mods, true
+}
+
+func pdf417Matches(runs, expected []int) bool {
+	mods, ok := pdf417Modules(runs)
+	if !ok {
+		return false
+	}
+	for k := range expected {
+		if mods[k] != expected[k] {
+			return false
+		}
+	}
+	return true
+}
+
+func pdf417Pattern(runs []int) int {
+	mods, ok := pdf417Modules(runs)
+	if !ok {
+		return -1
+	}
+	p := 0
+	for k, m := range mods {
+		for ; m > 0; m-- {
+			p <<= 1
+			if k%2 == 0 {
+				p |= 1
+			}
+		}
+	}
+	return p
+}
+
+// pdf417Verify comprueba que los síndromes Reed-Solomon sobre GF(929) sean cero.
+func pdf417Verify(cws []int, ec int) bool {
+	a := 1
+	for i := 1; i <= ec; i++ {
+		a = a * 3 % 929
+		s := 0
+		for _, c := range cws {
+			s = (s*a + c) % 929
+		}
+		if s != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// pdf417Text interpreta los codewords de datos (sin el de longitud) según los modos de
+// compactación de texto, bytes y numérico.
+func pdf417Text(cws []int) string {
+	var sb strings.Builder
+	i := 1
+	for i < len(cws) {
+		switch code := cws[i]; code {
+		case 900:
+			i = pdf417TextCompaction(cws, i+1, &sb)
+		case 901, 924:
+			i = pdf417ByteCompaction(code, cws, i+1, &sb)
+		case 902:
+			i = pdf417NumericCompaction(cws, i+1, &sb)
+		case 913:
+			if i+1 < len(cws) {
+				sb.WriteByte(byte(cws[i+1]))
+			}
+			i += 2
+		case 925, 927:
+			i += 2
+		case 926:
+			i += 3
+		case 922, 923, 928:
+			// Bloques de control de macro PDF417: no contienen datos
+			return sb.String()
+		default:
+			i = pdf417TextCompaction(cws, i, &sb)
+		}
+	}
+	return sb.String()
+}
+
+const (
+	pdf417Alpha = iota
+	pdf417Lower
+	pdf417Mixed
+	pdf417Punct
+	pdf417AlphaShift
+	pdf417PunctShift
+)
+
+func pdf417TextCompaction(cws []int, i int, sb *strings.Builder) int {
+	mode, prior := pdf417Alpha, pdf417Alpha
+	emit := func(v int) {
+		switch mode {
+		case pdf417Alpha, pdf417Lower:
+			switch {
+			case v < 26 && mode == pdf417Alpha:
+				sb.WriteByte(byte('A' + v))
+			case v < 26:
+				sb.WriteByte(byte('a' + v))
+			case v == 26:
+				sb.WriteByte(' ')
+			case v == 27 && mode == pdf417Alpha:
+				mode = pdf417Lower
+			case v == 27:
+				prior, mode = mode, pdf417AlphaShift
+			case v == 28:
+				mode = pdf417Mixed
+			case v == 29:
+				prior, mode = mode, pdf417PunctShift
+			}
+		case pdf417Mixed:
+			switch {
+			case v < 25:
+				sb.WriteByte(pdf417MixedChars[v])
+			case v == 25:
+				mode = pdf417Punct
+			case v == 26:
+				sb.WriteByte(' ')
+			case v == 27:
+				mode = pdf417Lower
+			case v == 28:
+				mode = pdf417Alpha
+			case v == 29:
+				prior, mode = mode, pdf417PunctShift
+			}
+		case pdf417Punct:
+			if v < 29 {
+				sb.WriteByte(pdf417PunctChars[v])
+			} else {
+				mode = pdf417Alpha
+			}
+		case pdf417AlphaShift:
+			mode = prior
+			if v < 26 {
+				sb.WriteByte(byte('A' + v))
+			} else if v == 26 {
+				sb.WriteByte(' ')
+			}
+		case pdf417PunctShift:
+			mode = prior
+			if v < 29 {
+				sb.WriteByte(pdf417PunctChars[v])
+			} else {
+				mode = pdf417Alpha
+			}
+		}
+	}
+
+	for i < len(cws) {
+		c := cws[i]
+		switch {
+		case c < 900:
+			emit(c / 30)
+			emit(c % 30)
+			i++
+		case c == 900:
+			mode = pdf417Alpha
+			i++
+		case c == 913:
+			if i+1 < len(cws) {
+				sb.WriteByte(byte(cws[i+1]))
+			}
+			i += 2
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// pdf417ByteCompaction: cada grupo de 5 codewords representa 6 bytes; con el modo 901 los
+// bytes sobrantes van uno por codeword.
+func pdf417ByteCompaction(mode int, cws []int, i int, sb *strings.Builder) int {
+	var vals []int
+	for i < len(cws) && cws[i] < 900 {
+		vals = append(vals, cws[i])
+		i++
+	}
+	k := 0
+	for len(vals)-k > 5 || (mode == 924 && len(vals)-k == 5) {
+		var v uint64
+		for _, c := range vals[k : k+5] {
+			v = v*900 + uint64(c)
+		}
+		for s := 40; s >= 0; s -= 8 {
+			sb.WriteByte(byte(v >> s))
+		}
+		k += 5
+	}
+	for _, c := range vals[k:] {
+		sb.WriteByte(byte(c))
+	}
+	return i
+}
+
+// pdf417NumericCompaction: grupos de hasta 15 codewords en base 900 cuyo valor decimal
+// lleva un 1 inicial de relleno.
+func pdf417NumericCompaction(cws []int, i int, sb *strings.Builder) int {
+	for i < len(cws) && cws[i] < 900 {
+		n := new(big.Int)
+		for k := 0; k < 15 && i < len(cws) && cws[i] < 900; k++ {
+			n.Mul(n, big.NewInt(900))
+			n.Add(n, big.NewInt(int64(cws[i])))
+			i++
+		}
+		if s := n.String(); len(s) > 1 {
+			sb.WriteString(s[1:])
+		}
+	}
+	return i
+}
@@ -0,0 +1,435 @@
+package utils
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExtractPDFImages obtiene las imágenes de un PDF para buscar códigos de barras: las
+// incrustadas (JPEG o mapas de bits FlateDecode) y, por página, un render de los
+// rectángulos rellenos, que es como muchas aerolíneas dibujan el PDF417 del pase.
+func ExtractPDFImages(data []byte) ([]image.Image, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		return nil, ErrNoEsPDF
+	}
+
+	doc := parsePDF(data)
+	var nums []int
+	for num, obj := range doc.objs {
+		if obj.raw != nil && pdfHasName(obj.dict, "Subtype", "Image") {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+
+	var imgs []image.Image
+	for _, num := range nums {
+		if img := doc.decodeImage(doc.objs[num]); img != nil {
+			imgs = append(imgs, img)
+		}
+	}
+	for _, page := range doc.pages() {
+		r := &pdfRectRaster{}
+		r.run(doc, doc.pageContent(page.dict), page.resources, [6]float64{1, 0, 0, 1, 0, 0}, 0)
+		if img := r.render(); img != nil {
+			imgs = append(imgs, img)
+		}
+	}
+	if doc.err != nil {
+		return nil, doc.err
+	}
+	return imgs, nil
+}
+
+func (d *pdfDocument) decodeImage(obj *pdfObject) image.Image {
+	if strings.Contains(obj.dict, "/DCTDecode") {
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(obj.raw))
+		if err != nil || cfg.Width*cfg.Height > 25_000_000 {
+			return nil
+		}
+		img, err := jpeg.Decode(bytes.NewReader(d.streamData(obj)))
+		if err != nil {
+			return nil
+		}
+		return img
+	}
+	if strings.Contains(obj.dict, "/Filter") && !strings.Contains(obj.dict, "/FlateDecode") {
+		// CCITT, JBIG2 y JPEG 2000 no se soportan
+		return nil
+	}
+
+	w, _ := strconv.Atoi(d.resolve(pdfDictRaw(obj.dict, "Width")))
+	h, _ := strconv.Atoi(d.resolve(pdfDictRaw(obj.dict, "Height")))
+	if w <= 0 || h <= 0 || w*h > 25_000_000 {
+		return nil
+	}
+	stream := d.streamData(obj)
+	mask := d.resolve(pdfDictRaw(obj.dict, "ImageMask")) == "true"
+	bpc, _ := strconv.Atoi(d.resolve(pdfDictRaw(obj.dict, "BitsPerComponent")))
+	if mask {
+		bpc = 1
+	} else if bpc == 0 {
+		bpc = 8
+	}
+	if bpc != 1 && bpc != 2 && bpc != 4 && bpc != 8 {
+		return nil
+	}
+
+	comps := 1
+	var palette []uint8
+	cs := d.resolve(pdfDictRaw(obj.dict, "ColorSpace"))
+	switch {
+	case mask:
+	case strings.Contains(cs, "/Indexed"):
+		palette = d.indexedPalette(cs)
+	case strings.Contains(cs, "RGB"):
+		comps = 3
+	case strings.Contains(cs, "CMYK"):
+		comps = 4
+	case strings.Contains(cs, "Gray"):
+	default:
+		// ICCBased u otros: se deduce de la longitud de los datos
+		rowBytes := (w*bpc + 7) / 8
+		if n := len(stream) / (rowBytes * h); n == 3 || n == 4 {
+			comps = n
+		}
+	}
+
+	rowBytes := (w*comps*bpc + 7) / 8
+	raw := stream
+	if parms := d.resolve(pdfDictRaw(obj.dict, "DecodeParms")); parms != "" {
+		if pred, _ := strconv.Atoi(d.resolve(pdfDictRaw(parms, "Predictor"))); pred >= 10 {
+			raw = pdfUnpredictPNG(raw, rowBytes, max(1, comps*bpc/8))
+		}
+	}
+	if len(raw) < rowBytes*h {
+		return nil
+	}
+
+	invert := strings.HasPrefix(strings.TrimSpace(strings.TrimPrefix(d.resolve(pdfDictRaw(obj.dict, "Decode")), "[")), "1")
+	maxVal := (1 << bpc) - 1
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		row := raw[y*rowBytes : (y+1)*rowBytes]
+		sample := func(i int) int {
+			bit := i * bpc
+			return int(row[bit/8]>>(8-bpc-bit%8)) & maxVal
+		}
+		for x := 0; x < w; x++ {
+			var g int
+			switch {
+			case palette != nil:
+				if idx := sample(x); idx < len(palette) {
+					g = int(palette[idx])
+				}
+			case comps == 3:
+				r, gr, b := sample(3*x), sample(3*x+1), sample(3*x+2)
+				g = (299*r + 587*gr + 114*b) * 255 / (1000 * maxVal)
+			case comps == 4:
+				c, m, yy, k := sample(4*x), sample(4*x+1), sample(4*x+2), sample(4*x+3)
+				g = 255 - min(255, ((30*c+59*m+11*yy)/100+k)*255/maxVal)
+			default:
+				g = sample(x) * 255 / maxVal
+			}
+			if invert {
+				g = 255 - g
+			}
+			img.Pix[y*img.Stride+x] = uint8(g)
+		}
+	}
+	return img
+}
+
+// indexedPalette convierte la tabla de un espacio [/Indexed base hival lookup] a grises.
+func (d *pdfDocument) indexedPalette(cs string) []uint8 {
+	lex := &pdfLexer{data: []byte(strings.TrimSpace(cs))}
+	tok, ok := lex.next()
+	if !ok || tok.kind != pdfTokArray || len(tok.array) < 4 {
+		return nil
+	}
+	base := d.resolve(tok.array[1].text)
+	comps := 3
+	switch {
+	case strings.Contains(base, "Gray"):
+		comps = 1
+	case strings.Contains(base, "CMYK"):
+		comps = 4
+	}
+
+	var lookup []byte
+	last := tok.array[len(tok.array)-1]
+	if last.kind == pdfTokString {
+		lookup = last.str
+	} else if refs := rePDFRef.FindAllStringSubmatch(cs, -1); len(refs) > 0 && last.text == "R" {
+		// La tabla es un stream indirecto: la última referencia del arreglo
+		num, _ := strconv.Atoi(refs[len(refs)-1][1])
+		if o, ok := d.objs[num]; ok {
+			lookup = d.streamData(o)
+		}
+	}
+
+	palette := make([]uint8, 0, len(lookup)/comps)
+	for i := 0; i+comps <= len(lookup); i += comps {
+		c := lookup[i : i+comps]
+		switch comps {
+		case 1:
+			palette = append(palette, c[0])
+		case 3:
+			palette = append(palette, uint8((299*int(c[0])+587*int(c[1])+114*int(c[2]))/1000))
+		default:
+			palette = append(palette, uint8(255-min(255, (30*int(c[0])+59*int(c[1])+11*int(c[2]))/100+int(c[3]))))
+		}
+	}
+	return palette
+}
+
+// pdfUnpredictPNG revierte los filtros PNG por fila (DecodeParms /Predictor >= 10).
+func pdfUnpredictPNG(data []byte, rowBytes, bpp int) []byte {
+	out := make([]byte, 0, len(data))
+	prev := make([]byte, rowBytes)
+	for pos := 0; pos+rowBytes+1 <= len(data); pos += rowBytes + 1 {
+		filter := data[pos]
+		row := append([]byte(nil), data[pos+1:pos+1+rowBytes]...)
+		for i := range row {
+			var a, b, c int
+			if i >= bpp {
+				a, c = int(row[i-bpp]), int(prev[i-bpp])
+			}
+			b = int(prev[i])
+			switch filter {
+			case 1:
+				row[i] += byte(a)
+			case 2:
+				row[i] += byte(b)
+			case 3:
+				row[i] += byte((a + b) / 2)
+			case 4:
+				p := a + b - c
+				pa, pb, pc := pdfAbs(p-a), pdfAbs(p-b), pdfAbs(p-c)
+				switch {
+				case pa <= pb && pa <= pc:
+					row[i] += byte(a)
+				case pb <= pc:
+					row[i] += byte(b)
+				default:
+					row[i] += byte(c)
+				}
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out
+}
+
+func pdfAbs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+type pdfRect struct {
+	x0, y0, x1, y1 float64
+	gray           uint8
+}
+
+// pdfRectRaster acumula los rectángulos rellenos de un content stream (operador re o
+// subtrayectos de cuatro lados) con su color de relleno.
+type pdfRectRaster struct {
+	rects []pdfRect
+	path  []pdfRect
+	sub   [][2]float64
+}
+
+func (r *pdfRectRaster) closeSub() {
+	if n := len(r.sub); n >= 4 && n <= 5 {
+		rect := pdfRect{x0: math.Inf(1), y0: math.Inf(1), x1: math.Inf(-1), y1: math.Inf(-1)}
+		for _, p := range r.sub {
+			rect.x0, rect.x1 = math.Min(rect.x0, p[0]), math.Max(rect.x1, p[0])
+			rect.y0, rect.y1 = math.Min(rect.y0, p[1]), math.Max(rect.y1, p[1])
+		}
+		r.path = append(r.path, rect)
+	}
+	r.sub = r.sub[:0]
+}
+
+func (r *pdfRectRaster) run(d *pdfDocument, content []byte, resources string, ctm [6]float64, depth int) {
+	if depth > 5 || len(content) == 0 {
+		return
+	}
+	xobjects := pdfDictEntries(d.resolve(pdfDictRaw(resources, "XObject")))
+
+	type state struct {
+		ctm  [6]float64
+		gray uint8
+	}
+	st := state{ctm: ctm}
+	var stack []state
+	apply := func(x, y float64) [2]float64 {
+		m := st.ctm
+		return [2]float64{m[0]*x + m[2]*y + m[4], m[1]*x + m[3]*y + m[5]}
+	}
+
+	lex := &pdfLexer{data: content}
+	var nums []float64
+	var operands []pdfToken
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != pdfTokOperator {
+			operands = append(operands, tok)
+			if tok.kind == pdfTokNumber {
+				nums = append(nums, tok.num)
+			}
+			continue
+		}
+
+		switch tok.text {
+		case "q":
+			stack = append(stack, st)
+		case "Q":
+			if len(stack) > 0 {
+				st, stack = stack[len(stack)-1], stack[:len(stack)-1]
+			}
+		case "cm":
+			if len(nums) >= 6 {
+				st.ctm = pdfMulMatrix(nums[len(nums)-6:], st.ctm)
+			}
+		case "g":
+			if len(nums) >= 1 {
+				st.gray = uint8(255 * math.Min(1, nums[len(nums)-1]))
+			}
+		case "rg", "k", "sc", "scn":
+			st.gray = pdfFillGray(nums)
+		case "re":
+			if len(nums) >= 4 {
+				x, y, w, h := nums[len(nums)-4], nums[len(nums)-3], nums[len(nums)-2], nums[len(nums)-1]
+				r.closeSub()
+				r.sub = append(r.sub, apply(x, y), apply(x+w, y), apply(x+w, y+h), apply(x, y+h))
+				r.closeSub()
+			}
+		case "m":
+			r.closeSub()
+			if len(nums) >= 2 {
+				r.sub = append(r.sub, apply(nums[len(nums)-2], nums[len(nums)-1]))
+			}
+		case "l":
+			if len(nums) >= 2 {
+				r.sub = append(r.sub, apply(nums[len(nums)-2], nums[len(nums)-1]))
+			}
+		case "h":
+			r.closeSub()
+		case "f", "F", "f*", "B", "B*", "b", "b*":
+			r.closeSub()
+			for _, p := range r.path {
+				p.gray = st.gray
+				r.rects = append(r.rects, p)
+			}
+			r.path = r.path[:0]
+		case "n", "S", "s":
+			r.sub, r.path = r.sub[:0], r.path[:0]
+		case "Do":
+			if len(operands) >= 1 {
+				if m := rePDFRefOnly.FindStringSubmatch(strings.TrimSpace(xobjects[operands[len(operands)-1].text])); m != nil {
+					num, _ := strconv.Atoi(m[1])
+					if obj, ok := d.objs[num]; ok && pdfHasName(obj.dict, "Subtype", "Form") {
+						res := resources
+						if raw := pdfDictRaw(obj.dict, "Resources"); raw != "" {
+							res = d.resolve(raw)
+						}
+						m := st.ctm
+						if raw := pdfDictRaw(obj.dict, "Matrix"); raw != "" {
+							var vals []float64
+							for _, f := range strings.Fields(strings.Trim(raw, "[]")) {
+								if v, err := strconv.ParseFloat(f, 64); err == nil {
+									vals = append(vals, v)
+								}
+							}
+							if len(vals) == 6 {
+								m = pdfMulMatrix(vals, m)
+							}
+						}
+						r.run(d, d.streamData(obj), res, m, depth+1)
+					}
+				}
+			}
+		}
+		nums, operands = nums[:0], operands[:0]
+	}
+}
+
+func pdfMulMatrix(a []float64, b [6]float64) [6]float64 {
+	return [6]float64{
+		a[0]*b[0] + a[1]*b[2], a[0]*b[1] + a[1]*b[3],
+		a[2]*b[0] + a[3]*b[2], a[2]*b[1] + a[3]*b[3],
+		a[4]*b[0] + a[5]*b[2] + b[4], a[4]*b[1] + a[5]*b[3] + b[5],
+	}
+}
+
+func pdfFillGray(nums []float64) uint8 {
+	switch len(nums) {
+	case 1:
+		return uint8(255 * math.Min(1, nums[0]))
+	case 3:
+		return uint8(255 * math.Min(1, 0.299*nums[0]+0.587*nums[1]+0.114*nums[2]))
+	case 4:
+		return uint8(255 * (1 - math.Min(1, 0.3*nums[0]+0.59*nums[1]+0.11*nums[2]+nums[3])))
+	}
+	return 0
+}
+
+// render dibuja los rectángulos en una imagen con resolución suficiente para que las
+// barras más finas midan unos 3 píxeles. Sin suficientes rectángulos oscuros no hay código.
+func (r *pdfRectRaster) render() image.Image {
+	var widths []float64
+	minX, minY, maxX, maxY := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, rc := range r.rects {
+		if rc.gray >= 128 {
+			continue
+		}
+		if w := math.Min(rc.x1-rc.x0, rc.y1-rc.y0); w > 0 {
+			widths = append(widths, w)
+		}
+		minX, minY = math.Min(minX, rc.x0), math.Min(minY, rc.y0)
+		maxX, maxY = math.Max(maxX, rc.x1), math.Max(maxY, rc.y1)
+	}
+	if len(widths) < 40 {
+		return nil
+	}
+	sort.Float64s(widths)
+
+	scale := 3 / widths[len(widths)/10]
+	if dim := math.Max(maxX-minX, maxY-minY) * scale; dim > 4000 {
+		scale *= 4000 / dim
+	}
+	const margin = 10
+	w := int((maxX-minX)*scale) + 2*margin
+	h := int((maxY-minY)*scale) + 2*margin
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 255
+	}
+	for _, rc := range r.rects {
+		x0 := int(math.Round((rc.x0-minX)*scale)) + margin
+		x1 := int(math.Round((rc.x1-minX)*scale)) + margin
+		// El eje Y del PDF crece hacia arriba
+		y0 := h - margin - int(math.Round((rc.y1-minY)*scale))
+		y1 := h - margin - int(math.Round((rc.y0-minY)*scale))
+		fill := image.Rect(x0, y0, x1, y1).Intersect(img.Rect)
+		for y := fill.Min.Y; y < fill.Max.Y; y++ {
+			for x := fill.Min.X; x < fill.Max.X; x++ {
+				img.SetGray(x, y, color.Gray{Y: rc.gray})
+			}
+		}
+	}
+	return img
+}
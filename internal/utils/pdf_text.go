@@ -0,0 +1,931 @@
+package utils
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+var (
+	ErrNoEsPDF            = errors.New("el archivo no es un PDF válido")
+	ErrPDFSinTexto        = errors.New("el PDF no contiene texto extraíble (¿es un documento escaneado?)")
+	ErrPDFDemasiadoGrande = errors.New("el contenido comprimido del PDF supera el tamaño máximo permitido")
+)
+
+// Límites de descompresión: un stream FlateDecode pequeño puede inflarse a gigabytes.
+const (
+	pdfMaxStream      = 32 << 20  // bytes descomprimidos por stream
+	pdfMaxInflated    = 128 << 20 // bytes descomprimidos por documento
+	pdfMaxCMapEntries = 1 << 17   // códigos por CMap ToUnicode
+)
+
+// ExtractPDFText obtiene el texto de un PDF generado digitalmente (billetes electrónicos,
+// facturas). Soporta streams FlateDecode, object streams, fuentes con ToUnicode y Form
+// XObjects; no hace OCR, por lo que un PDF escaneado devuelve ErrPDFSinTexto.
+func ExtractPDFText(data []byte) (string, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, " \t\r\n"), []byte("%PDF")) {
+		return "", ErrNoEsPDF
+	}
+
+	doc := parsePDF(data)
+	var sb strings.Builder
+	for _, page := range doc.pages() {
+		w := &pdfTextWriter{}
+		doc.runContent(w, doc.pageContent(page.dict), page.resources, 0)
+		if text := strings.TrimSpace(w.sb.String()); text != "" {
+			sb.WriteString(text)
+			sb.WriteString("\n")
+		}
+	}
+
+	if doc.err != nil {
+		return "", doc.err
+	}
+	text := strings.TrimSpace(sb.String())
+	if text == "" {
+		return "", ErrPDFSinTexto
+	}
+	return text, nil
+}
+
+var (
+	rePDFObj       = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	rePDFRef       = regexp.MustCompile(`(\d+)\s+\d+\s+R`)
+	rePDFRefOnly   = regexp.MustCompile(`^(\d+)\s+\d+\s+R$`)
+	rePDFRefPrefix = regexp.MustCompile(`^\d+\s+\d+\s+R`)
+	rePDFCMapToken = regexp.MustCompile(`<[0-9A-Fa-f\s]*>|\[|\]`)
+)
+
+// pdfObject guarda el stream tal como viene en el archivo; se descomprime al usarlo (ver
+// pdfDocument.streamData).
+type pdfObject struct {
+	dict    string
+	raw     []byte
+	flate   bool
+	stream  []byte
+	decoded bool
+}
+
+type pdfDocument struct {
+	objs     map[int]*pdfObject
+	cmaps    map[int]*pdfCMap
+	inflated int
+	err      error
+}
+
+type pdfPage struct {
+	dict      string
+	resources string
+}
+
+func parsePDF(data []byte) *pdfDocument {
+	doc := &pdfDocument{objs: map[int]*pdfObject{}, cmaps: map[int]*pdfCMap{}}
+
+	locs := rePDFObj.FindAllSubmatchIndex(data, -1)
+	for _, loc := range locs {
+		num, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		body := data[loc[1]:]
+		end := bytes.Index(body, []byte("endobj"))
+		if end < 0 {
+			end = len(body)
+		}
+		// Un stream puede contener la palabra endobj; se busca después de endstream
+		if si := bytes.Index(body[:end], []byte("stream")); si >= 0 {
+			if es := bytes.Index(body[si:], []byte("endstream")); es >= 0 {
+				if eo := bytes.Index(body[si+es:], []byte("endobj")); eo >= 0 {
+					end = si + es + eo
+				}
+			}
+		}
+		doc.objs[num] = parsePDFObject(body[:end])
+	}
+
+	// Objetos comprimidos dentro de object streams (PDF 1.5+)
+	for _, obj := range doc.objs {
+		if !pdfHasName(obj.dict, "Type", "ObjStm") {
+			continue
+		}
+		stream := doc.streamData(obj)
+		n, _ := strconv.Atoi(pdfDictRaw(obj.dict, "N"))
+		first, _ := strconv.Atoi(pdfDictRaw(obj.dict, "First"))
+		if first <= 0 || first > len(stream) {
+			continue
+		}
+		header := strings.Fields(string(stream[:first]))
+		type entry struct{ num, off int }
+		var entries []entry
+		for i := 0; i+1 < len(header) && len(entries) < n; i += 2 {
+			num, err1 := strconv.Atoi(header[i])
+			off, err2 := strconv.Atoi(header[i+1])
+			if err1 == nil && err2 == nil {
+				entries = append(entries, entry{num, off})
+			}
+		}
+		for i, e := range entries {
+			start := first + e.off
+			end := len(stream)
+			if i+1 < len(entries) {
+				end = first + entries[i+1].off
+			}
+			if start < 0 || start > end || end > len(stream) {
+				continue
+			}
+			if _, ok := doc.objs[e.num]; !ok {
+				doc.objs[e.num] = &pdfObject{dict: string(stream[start:end])}
+			}
+		}
+	}
+
+	return doc
+}
+
+func parsePDFObject(body []byte) *pdfObject {
+	si := bytes.Index(body, []byte("stream"))
+	if si < 0 {
+		return &pdfObject{dict: string(body)}
+	}
+
+	obj := &pdfObject{dict: string(body[:si])}
+	raw := body[si+len("stream"):]
+	raw = bytes.TrimPrefix(raw, []byte("\r"))
+	raw = bytes.TrimPrefix(raw, []byte("\n"))
+	if length, err := strconv.Atoi(pdfDictRaw(obj.dict, "Length")); err == nil && length > 0 && length <= len(raw) {
+		raw = raw[:length]
+	} else if es := bytes.LastIndex(raw, []byte("endstream")); es >= 0 {
+		raw = bytes.TrimRight(raw[:es], "\r\n")
+	}
+
+	switch {
+	case strings.Contains(obj.dict, "/FlateDecode"):
+		obj.raw, obj.flate = raw, true
+	case strings.Contains(obj.dict, "/DCTDecode") && !strings.Contains(obj.dict, "/Filter ["):
+		// JPEG: se conserva tal cual para ExtractPDFImages
+		obj.raw = raw
+	case strings.Contains(obj.dict, "/Filter"):
+		// Imágenes u otros filtros que no contienen texto
+	default:
+		obj.raw = raw
+	}
+	return obj
+}
+
+// streamData devuelve el stream del objeto, descomprimiéndolo la primera vez que se usa.
+// Un stream que supera pdfMaxStream o agota pdfMaxInflated se descarta y deja d.err.
+func (d *pdfDocument) streamData(obj *pdfObject) []byte {
+	if obj.decoded || obj.raw == nil {
+		return obj.stream
+	}
+	obj.decoded = true
+	if !obj.flate {
+		obj.stream = obj.raw
+		return obj.stream
+	}
+
+	limite := min(pdfMaxStream, pdfMaxInflated-d.inflated)
+	r, err := zlib.NewReader(bytes.NewReader(obj.raw))
+	if err != nil {
+		return nil
+	}
+	// Se conserva lo descomprimido aunque el stream esté truncado
+	decoded, _ := io.ReadAll(io.LimitReader(r, int64(limite)+1))
+	if len(decoded) > limite {
+		d.err = ErrPDFDemasiadoGrande
+		return nil
+	}
+	d.inflated += len(decoded)
+	obj.stream = decoded
+	return obj.stream
+}
+
+// resolve devuelve el valor directo de una referencia indirecta "N 0 R".
+func (d *pdfDocument) resolve(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if m := rePDFRefOnly.FindStringSubmatch(raw); m != nil {
+		num, _ := strconv.Atoi(m[1])
+		if obj, ok := d.objs[num]; ok {
+			return strings.TrimSpace(obj.dict)
+		}
+		return ""
+	}
+	return raw
+}
+
+func (d *pdfDocument) pages() []pdfPage {
+	var pages []pdfPage
+	for _, obj := range d.objs {
+		if !pdfHasName(obj.dict, "Type", "Catalog") {
+			continue
+		}
+		if root := rePDFRefOnly.FindStringSubmatch(pdfDictRaw(obj.dict, "Pages")); root != nil {
+			num, _ := strconv.Atoi(root[1])
+			d.walkPages(num, "", map[int]bool{}, &pages)
+		}
+		if len(pages) > 0 {
+			return pages
+		}
+	}
+
+	// Sin catálogo legible: páginas en orden de número de objeto
+	var nums []int
+	for num, obj := range d.objs {
+		if pdfHasName(obj.dict, "Type", "Page") {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		dict := d.objs[num].dict
+		pages = append(pages, pdfPage{dict: dict, resources: d.resolve(pdfDictRaw(dict, "Resources"))})
+	}
+	return pages
+}
+
+func (d *pdfDocument) walkPages(num int, inherited string, visited map[int]bool, pages *[]pdfPage) {
+	obj, ok := d.objs[num]
+	if !ok || visited[num] {
+		return
+	}
+	visited[num] = true
+
+	resources := inherited
+	if raw := pdfDictRaw(obj.dict, "Resources"); raw != "" {
+		resources = d.resolve(raw)
+	}
+
+	if pdfHasName(obj.dict, "Type", "Page") {
+		*pages = append(*pages, pdfPage{dict: obj.dict, resources: resources})
+		return
+	}
+	for _, m := range rePDFRef.FindAllStringSubmatch(d.resolve(pdfDictRaw(obj.dict, "Kids")), -1) {
+		kid, _ := strconv.Atoi(m[1])
+		d.walkPages(kid, resources, visited, pages)
+	}
+}
+
+func (d *pdfDocument) pageContent(page string) []byte {
+	var content []byte
+	for _, m := range rePDFRef.FindAllStringSubmatch(pdfDictRaw(page, "Contents"), -1) {
+		num, _ := strconv.Atoi(m[1])
+		obj, ok := d.objs[num]
+		if !ok {
+			continue
+		}
+		if obj.raw != nil {
+			content = append(content, d.streamData(obj)...)
+			content = append(content, '\n')
+			continue
+		}
+		// Contents puede apuntar a un arreglo indirecto de streams
+		for _, inner := range rePDFRef.FindAllStringSubmatch(obj.dict, -1) {
+			n, _ := strconv.Atoi(inner[1])
+			if o, ok := d.objs[n]; ok && o.raw != nil {
+				content = append(content, d.streamData(o)...)
+				content = append(content, '\n')
+			}
+		}
+	}
+	return content
+}
+
+type pdfFont struct {
+	cmap    *pdfCMap
+	twoByte bool
+}
+
+func (d *pdfDocument) fonts(resources string) map[string]*pdfFont {
+	fonts := map[string]*pdfFont{}
+	for name, raw := range pdfDictEntries(d.resolve(pdfDictRaw(resources, "Font"))) {
+		dict := d.resolve(raw)
+		font := &pdfFont{twoByte: pdfHasName(dict, "Subtype", "Type0")}
+		if m := rePDFRefOnly.FindStringSubmatch(pdfDictRaw(dict, "ToUnicode")); m != nil {
+			num, _ := strconv.Atoi(m[1])
+			font.cmap = d.cmap(num)
+		}
+		fonts[name] = font
+	}
+	return fonts
+}
+
+func (d *pdfDocument) cmap(num int) *pdfCMap {
+	if cm, ok := d.cmaps[num]; ok {
+		return cm
+	}
+	var cm *pdfCMap
+	if obj, ok := d.objs[num]; ok && obj.raw != nil {
+		cm = parsePDFCMap(d.streamData(obj))
+	}
+	d.cmaps[num] = cm
+	return cm
+}
+
+// runContent interpreta los operadores de texto de un content stream.
+func (d *pdfDocument) runContent(w *pdfTextWriter, content []byte, resources string, depth int) {
+	if depth > 5 || len(content) == 0 {
+		return
+	}
+
+	fonts := d.fonts(resources)
+	xobjects := pdfDictEntries(d.resolve(pdfDictRaw(resources, "XObject")))
+	var font *pdfFont
+	var lineX, lineY, leading float64
+
+	lex := &pdfLexer{data: content}
+	var operands []pdfToken
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != pdfTokOperator {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch tok.text {
+		case "BT":
+			lineX, lineY = 0, 0
+		case "Tf":
+			if len(operands) >= 2 {
+				font = fonts[operands[len(operands)-2].text]
+			}
+		case "TL":
+			if len(operands) >= 1 {
+				leading = operands[len(operands)-1].num
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				lineX += operands[len(operands)-2].num
+				lineY += operands[len(operands)-1].num
+				if tok.text == "TD" {
+					leading = -operands[len(operands)-1].num
+				}
+				w.moveTo(lineX, lineY)
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				lineX = operands[len(operands)-2].num
+				lineY = operands[len(operands)-1].num
+				w.moveTo(lineX, lineY)
+			}
+		case "T*":
+			lineY -= leading
+			w.newLine(lineX, lineY)
+		case "Tj":
+			if len(operands) >= 1 {
+				w.show(decodePDFString(operands[len(operands)-1].str, font))
+			}
+		case "'", "\"":
+			lineY -= leading
+			w.newLine(lineX, lineY)
+			if len(operands) >= 1 {
+				w.show(decodePDFString(operands[len(operands)-1].str, font))
+			}
+		case "TJ":
+			if len(operands) >= 1 {
+				var sb strings.Builder
+				for _, el := range operands[len(operands)-1].array {
+					if el.kind == pdfTokString {
+						sb.WriteString(decodePDFString(el.str, font))
+					} else if el.kind == pdfTokNumber && el.num < -250 {
+						// Un desplazamiento grande dentro de TJ equivale a un espacio
+						sb.WriteString(" ")
+					}
+				}
+				w.show(sb.String())
+			}
+		case "Do":
+			if len(operands) >= 1 {
+				if m := rePDFRefOnly.FindStringSubmatch(strings.TrimSpace(xobjects[operands[len(operands)-1].text])); m != nil {
+					num, _ := strconv.Atoi(m[1])
+					if obj, ok := d.objs[num]; ok && pdfHasName(obj.dict, "Subtype", "Form") {
+						res := resources
+						if raw := pdfDictRaw(obj.dict, "Resources"); raw != "" {
+							res = d.resolve(raw)
+						}
+						d.runContent(w, d.streamData(obj), res, depth+1)
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+}
+
+// pdfTextWriter arma líneas de texto a partir de la posición vertical de cada fragmento.
+type pdfTextWriter struct {
+	sb      strings.Builder
+	x, y    float64
+	lastY   float64
+	moved   bool
+	newline bool
+	started bool
+}
+
+func (w *pdfTextWriter) moveTo(x, y float64) {
+	w.x, w.y = x, y
+	w.moved = true
+}
+
+func (w *pdfTextWriter) newLine(x, y float64) {
+	w.moveTo(x, y)
+	w.newline = true
+}
+
+func (w *pdfTextWriter) show(text string) {
+	if strings.TrimSpace(text) == "" {
+		if text != "" {
+			w.moved = true
+		}
+		return
+	}
+	if w.started {
+		if w.newline || math.Abs(w.y-w.lastY) > 1 {
+			w.sb.WriteString("\n")
+		} else if w.moved {
+			w.sb.WriteString(" ")
+		}
+	}
+	w.sb.WriteString(text)
+	w.lastY = w.y
+	w.moved, w.newline, w.started = false, false, true
+}
+
+type pdfCMap struct {
+	codeLen int
+	m       map[string]string
+}
+
+func parsePDFCMap(data []byte) *pdfCMap {
+	cm := &pdfCMap{codeLen: 1, m: map[string]string{}}
+	s := string(data)
+
+	if block := pdfBetween(s, "begincodespacerange", "endcodespacerange"); block != "" {
+		if toks := rePDFCMapToken.FindAllString(block, 1); len(toks) > 0 {
+			if n := len(pdfHexBytes(toks[0])); n > 0 {
+				cm.codeLen = n
+			}
+		}
+	}
+
+	for _, block := range pdfAllBetween(s, "beginbfchar", "endbfchar") {
+		toks := rePDFCMapToken.FindAllString(block, -1)
+		for i := 0; i+1 < len(toks) && len(cm.m) < pdfMaxCMapEntries; i += 2 {
+			cm.m[string(pdfHexBytes(toks[i]))] = pdfUTF16(pdfHexBytes(toks[i+1]))
+		}
+	}
+
+	for _, block := range pdfAllBetween(s, "beginbfrange", "endbfrange") {
+		toks := rePDFCMapToken.FindAllString(block, -1)
+		for i := 0; i+2 < len(toks) && len(cm.m) < pdfMaxCMapEntries; {
+			lo, hi := pdfHexBytes(toks[i]), pdfHexBytes(toks[i+1])
+			if len(lo) == 0 || len(lo) > 4 {
+				break
+			}
+			from, to := pdfBytesToInt(lo), pdfBytesToInt(hi)
+			// Los extremos vienen del archivo: el rango se recorta a lo que aún cabe en el CMap.
+			if to < from || to-from > 0xFFFF {
+				break
+			}
+			to = min(to, from+pdfMaxCMapEntries-len(cm.m)-1)
+			if toks[i+2] == "[" {
+				j := i + 3
+				for code := from; j < len(toks) && toks[j] != "]"; j, code = j+1, code+1 {
+					cm.m[string(pdfIntToBytes(code, len(lo)))] = pdfUTF16(pdfHexBytes(toks[j]))
+				}
+				i = j + 1
+				continue
+			}
+			dst := pdfHexBytes(toks[i+2])
+			if len(dst) > 8 {
+				break
+			}
+			base := pdfBytesToInt(dst)
+			for code := from; code <= to; code++ {
+				cm.m[string(pdfIntToBytes(code, len(lo)))] = pdfUTF16(pdfIntToBytes(base+code-from, len(dst)))
+			}
+			i += 3
+		}
+	}
+	return cm
+}
+
+var pdfWinAnsi = charmap.Windows1252.NewDecoder()
+
+func decodePDFString(raw []byte, font *pdfFont) string {
+	if font == nil || font.cmap == nil {
+		if font != nil && font.twoByte {
+			// CID sin ToUnicode: los códigos son glifos, no caracteres
+			return ""
+		}
+		out, err := pdfWinAnsi.Bytes(raw)
+		if err != nil {
+			return string(raw)
+		}
+		return string(out)
+	}
+
+	n := font.cmap.codeLen
+	if font.twoByte && n < 2 {
+		n = 2
+	}
+	var sb strings.Builder
+	for i := 0; i+n <= len(raw); i += n {
+		if s, ok := font.cmap.m[string(raw[i:i+n])]; ok {
+			sb.WriteString(s)
+		} else if n == 1 {
+			out, _ := pdfWinAnsi.Bytes(raw[i : i+1])
+			sb.Write(out)
+		}
+	}
+	return sb.String()
+}
+
+const (
+	pdfTokOperator = iota
+	pdfTokNumber
+	pdfTokString
+	pdfTokName
+	pdfTokArray
+	pdfTokOther
+)
+
+type pdfToken struct {
+	kind  int
+	text  string
+	num   float64
+	str   []byte
+	array []pdfToken
+}
+
+type pdfLexer struct {
+	data []byte
+	pos  int
+}
+
+func pdfIsWhite(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0
+}
+
+func pdfIsDelim(c byte) bool {
+	return strings.IndexByte("()<>[]{}/%", c) >= 0
+}
+
+func (l *pdfLexer) next() (pdfToken, bool) {
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		switch {
+		case pdfIsWhite(c):
+			l.pos++
+		case c == '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		case c == '(':
+			return pdfToken{kind: pdfTokString, str: l.literal()}, true
+		case c == '<' && l.pos+1 < len(l.data) && l.data[l.pos+1] == '<':
+			l.skipDict()
+			return pdfToken{kind: pdfTokOther}, true
+		case c == '<':
+			end := bytes.IndexByte(l.data[l.pos:], '>')
+			if end < 0 {
+				l.pos = len(l.data)
+				return pdfToken{}, false
+			}
+			hex := l.data[l.pos : l.pos+end+1]
+			l.pos += end + 1
+			return pdfToken{kind: pdfTokString, str: pdfHexBytes(string(hex))}, true
+		case c == '[':
+			l.pos++
+			var arr []pdfToken
+			for {
+				tok, ok := l.next()
+				if !ok || (tok.kind == pdfTokOther && tok.text == "]") {
+					break
+				}
+				arr = append(arr, tok)
+			}
+			return pdfToken{kind: pdfTokArray, array: arr}, true
+		case c == ']':
+			l.pos++
+			return pdfToken{kind: pdfTokOther, text: "]"}, true
+		case c == '/':
+			start := l.pos
+			l.pos++
+			for l.pos < len(l.data) && !pdfIsWhite(l.data[l.pos]) && !pdfIsDelim(l.data[l.pos]) {
+				l.pos++
+			}
+			return pdfToken{kind: pdfTokName, text: string(l.data[start:l.pos])}, true
+		case c == '{' || c == '}' || c == ')' || c == '>':
+			l.pos++
+		default:
+			start := l.pos
+			for l.pos < len(l.data) && !pdfIsWhite(l.data[l.pos]) && !pdfIsDelim(l.data[l.pos]) {
+				l.pos++
+			}
+			word := string(l.data[start:l.pos])
+			if num, err := strconv.ParseFloat(word, 64); err == nil {
+				return pdfToken{kind: pdfTokNumber, text: word, num: num}, true
+			}
+			if word == "BI" {
+				l.skipInlineImage()
+				continue
+			}
+			return pdfToken{kind: pdfTokOperator, text: word}, true
+		}
+	}
+	return pdfToken{}, false
+}
+
+func (l *pdfLexer) literal() []byte {
+	var out []byte
+	depth := 0
+	l.pos++ // (
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			if depth == 0 {
+				return out
+			}
+			depth--
+			out = append(out, c)
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for k := 0; k < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; k++ {
+						v = v*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (l *pdfLexer) skipDict() {
+	depth := 0
+	for l.pos+1 < len(l.data) {
+		switch {
+		case l.data[l.pos] == '<' && l.data[l.pos+1] == '<':
+			depth++
+			l.pos += 2
+		case l.data[l.pos] == '>' && l.data[l.pos+1] == '>':
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return
+			}
+		case l.data[l.pos] == '(':
+			l.literal()
+		default:
+			l.pos++
+		}
+	}
+	l.pos = len(l.data)
+}
+
+func (l *pdfLexer) skipInlineImage() {
+	for l.pos+2 < len(l.data) {
+		if pdfIsWhite(l.data[l.pos]) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I' &&
+			(l.pos+3 == len(l.data) || pdfIsWhite(l.data[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
+
+// pdfDictRaw devuelve el valor sin interpretar de /key dentro de un diccionario.
+func pdfDictRaw(dict, key string) string {
+	needle := "/" + key
+	for from := 0; ; {
+		idx := strings.Index(dict[from:], needle)
+		if idx < 0 {
+			return ""
+		}
+		pos := from + idx + len(needle)
+		from = pos
+		if pos < len(dict) && !pdfIsWhite(dict[pos]) && !pdfIsDelim(dict[pos]) {
+			continue // /Font vs /FontDescriptor
+		}
+		return pdfReadValue(dict, pos)
+	}
+}
+
+func pdfReadValue(s string, pos int) string {
+	for pos < len(s) && pdfIsWhite(s[pos]) {
+		pos++
+	}
+	if pos >= len(s) {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(s[pos:], "<<"):
+		return pdfBalanced(s, pos, "<<", ">>")
+	case s[pos] == '[':
+		return pdfBalanced(s, pos, "[", "]")
+	case s[pos] == '(':
+		return pdfBalanced(s, pos, "(", ")")
+	}
+	if m := rePDFRefPrefix.FindString(s[pos:]); m != "" {
+		return m
+	}
+	end := pos + 1
+	for end < len(s) && !pdfIsWhite(s[end]) && !pdfIsDelim(s[end]) {
+		end++
+	}
+	return s[pos:end]
+}
+
+func pdfBalanced(s string, pos int, open, close string) string {
+	depth := 0
+	for i := pos; i < len(s); {
+		switch {
+		case strings.HasPrefix(s[i:], open):
+			depth++
+			i += len(open)
+		case strings.HasPrefix(s[i:], close):
+			depth--
+			i += len(close)
+			if depth == 0 {
+				return s[pos:i]
+			}
+		default:
+			i++
+		}
+	}
+	return s[pos:]
+}
+
+// pdfDictEntries lista las claves de primer nivel de un diccionario << /A ... /B ... >>.
+func pdfDictEntries(dict string) map[string]string {
+	entries := map[string]string{}
+	dict = strings.TrimSpace(dict)
+	if !strings.HasPrefix(dict, "<<") {
+		return entries
+	}
+	inner := strings.TrimSuffix(strings.TrimPrefix(dict, "<<"), ">>")
+	for pos := 0; pos < len(inner); {
+		if inner[pos] != '/' {
+			pos++
+			continue
+		}
+		end := pos + 1
+		for end < len(inner) && !pdfIsWhite(inner[end]) && !pdfIsDelim(inner[end]) {
+			end++
+		}
+		name := inner[pos:end]
+		value := pdfReadValue(inner, end)
+		entries[name] = value
+		next := strings.Index(inner[end:], value)
+		if value == "" || next < 0 {
+			pos = end
+			continue
+		}
+		pos = end + next + len(value)
+	}
+	return entries
+}
+
+// pdfHasName indica si alguna entrada /key del diccionario vale /value.
+func pdfHasName(dict, key, value string) bool {
+	needle := "/" + key
+	for from := 0; ; {
+		idx := strings.Index(dict[from:], needle)
+		if idx < 0 {
+			return false
+		}
+		pos := from + idx + len(needle)
+		from = pos
+		if pos < len(dict) && !pdfIsWhite(dict[pos]) && !pdfIsDelim(dict[pos]) {
+			continue
+		}
+		if pdfReadValue(dict, pos) == "/"+value {
+			return true
+		}
+	}
+}
+
+func pdfBetween(s, start, end string) string {
+	if blocks := pdfAllBetween(s, start, end); len(blocks) > 0 {
+		return blocks[0]
+	}
+	return ""
+}
+
+func pdfAllBetween(s, start, end string) []string {
+	var blocks []string
+	for {
+		i := strings.Index(s, start)
+		if i < 0 {
+			return blocks
+		}
+		s = s[i+len(start):]
+		j := strings.Index(s, end)
+		if j < 0 {
+			return blocks
+		}
+		blocks = append(blocks, s[:j])
+		s = s[j+len(end):]
+	}
+}
+
+func pdfHexBytes(tok string) []byte {
+	var out []byte
+	var hi byte
+	half := false
+	for i := 0; i < len(tok); i++ {
+		c := tok[i]
+		var v byte
+		switch {
+		case c >= '0' && c <= '9':
+			v = c - '0'
+		case c >= 'a' && c <= 'f':
+			v = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			v = c - 'A' + 10
+		default:
+			continue
+		}
+		if half {
+			out = append(out, hi<<4|v)
+		} else {
+			hi = v
+		}
+		half = !half
+	}
+	if half {
+		out = append(out, hi<<4)
+	}
+	return out
+}
+
+func pdfUTF16(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	units := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		units = append(units, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func pdfBytesToInt(b []byte) int {
+	v := 0
+	for _, c := range b {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+func pdfIntToBytes(v, n int) []byte {
+	out := make([]byte, n)
+	for i := n - 1; i >= 0; i-- {
+		out[i] = byte(v)
+		v >>= 8
+	}
+	return out
+}
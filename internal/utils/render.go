@@ -67,6 +67,7 @@ func Render(c *gin.Context, templateName string, data gin.H) {
 	if len(errors) > 0 {
 		data["ErrorMessage"] = errors[0]
 	}
+	data["UploadErrors"] = uploadErrorsForView(c, session)
 	session.Save()
 
 	c.HTML(http.StatusOK, templateName, data)
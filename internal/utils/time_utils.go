@@ -1,22 +1,14 @@
 package utils
 
 import (
+	"sistema-pasajes/internal/models"
 	"time"
 )
 
-// CalcularFechaLimiteDescargo retorna la fecha 8 días hábiles posterior a la de retorno.
-func CalcularFechaLimiteDescargo(fechaRetorno time.Time) time.Time {
-	dias := 0
-	fecha := fechaRetorno
-
-	for dias < 8 {
-		fecha = fecha.AddDate(0, 0, 1)
-		weekday := fecha.Weekday()
-		if weekday != time.Saturday && weekday != time.Sunday {
-			dias++
-		}
-	}
-	return fecha
+// CalcularFechaLimiteDescargo retorna la fecha 8 días hábiles posterior a la de retorno,
+// descontando los feriados nacionales y los del departamento del beneficiario.
+func CalcularFechaLimiteDescargo(fechaRetorno time.Time, departamento string) time.Time {
+	return models.SumarDiasHabiles(fechaRetorno, models.PlazoDescargoDiasHabiles, departamento)
 }
 
 // TranslateMonth retorna el mapping ES del month name.
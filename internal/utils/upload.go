@@ -0,0 +1,218 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mimePDF  = "application/pdf"
+	mimeJPEG = "image/jpeg"
+	mimePNG  = "image/png"
+	mimeText = "text/plain"
+	mimeZip  = "application/zip" // xlsx
+)
+
+var mimeLabels = map[string]string{
+	mimePDF:  "PDF",
+	mimeJPEG: "JPG",
+	mimePNG:  "PNG",
+	mimeText: "CSV/TXT",
+	mimeZip:  "XLSX",
+}
+
+// Cómo se guardan las imágenes aceptadas por una regla.
+const (
+	ImagesAsJPEG = "jpeg"
+	ImagesAsPDF  = "pdf"
+)
+
+// UploadRule define qué acepta un campo de archivo: tipos MIME detectados por el contenido (no
+// por la extensión), tamaño máximo y en qué se convierten las imágenes.
+type UploadRule struct {
+	Types    []string
+	MaxBytes int64
+	ImagesAs string
+}
+
+var (
+	// UploadPDF para boletos, memorándums y comprobantes que deben ser PDF.
+	UploadPDF = UploadRule{Types: []string{mimePDF}, MaxBytes: 10 << 20}
+	// UploadDocument acepta PDF o fotografías, que se guardan como PDF de una hoja.
+	UploadDocument = UploadRule{Types: []string{mimePDF, mimeJPEG, mimePNG}, MaxBytes: 10 << 20, ImagesAs: ImagesAsPDF}
+	// UploadPhoto para el anexo fotográfico; las imágenes se guardan como JPEG comprimido.
+	UploadPhoto = UploadRule{Types: []string{mimeJPEG, mimePNG}, MaxBytes: 10 << 20, ImagesAs: ImagesAsJPEG}
+	// UploadStatement para extractos bancarios en CSV o xlsx.
+	UploadStatement = UploadRule{Types: []string{mimeText, mimeZip}, MaxBytes: 5 << 20}
+)
+
+func (r UploadRule) accepted() string {
+	labels := make([]string, 0, len(r.Types))
+	for _, t := range r.Types {
+		labels = append(labels, mimeLabels[t])
+	}
+	return strings.Join(labels, ", ")
+}
+
+// UploadError es el rechazo de un archivo subido, con el motivo para mostrar junto al campo.
+type UploadError struct {
+	FileName string
+	Reason   string
+}
+
+func (e *UploadError) Error() string {
+	return fmt.Sprintf("«%s»: %s", e.FileName, e.Reason)
+}
+
+// processUpload valida el archivo contra la regla, lo analiza con el escáner de malware y
+// normaliza las imágenes. Devuelve el contenido a guardar y su extensión.
+func processUpload(ctx context.Context, file *multipart.FileHeader, rule UploadRule) ([]byte, string, error) {
+	reject := func(format string, args ...any) ([]byte, string, error) {
+		return nil, "", &UploadError{FileName: file.Filename, Reason: fmt.Sprintf(format, args...)}
+	}
+	tooBig := fmt.Sprintf("supera el tamaño máximo de %d MB", rule.MaxBytes>>20)
+
+	if file.Size > rule.MaxBytes {
+		return reject("%s", tooBig)
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, "", err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(io.LimitReader(f, rule.MaxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > rule.MaxBytes {
+		return reject("%s", tooBig)
+	}
+	if len(data) == 0 {
+		return reject("el archivo está vacío")
+	}
+
+	mime, _, _ := strings.Cut(http.DetectContentType(data), ";")
+	if !slices.Contains(rule.Types, mime) {
+		if detected, ok := mimeLabels[mime]; ok {
+			return reject("el contenido es %s y este campo acepta %s", detected, rule.accepted())
+		}
+		return reject("el contenido no es un formato reconocido (%s); este campo acepta %s", mime, rule.accepted())
+	}
+
+	if err := getMalwareScanner().Scan(ctx, data); err != nil {
+		var malware *MalwareError
+		if errors.As(err, &malware) {
+			slog.Warn("Archivo subido rechazado por el antivirus", "archivo", file.Filename, "firma", malware.Signature)
+			return reject("%s", malware.Error())
+		}
+		slog.Error("No se pudo analizar el archivo subido", "archivo", file.Filename, "error", err.Error())
+		return reject("no se pudo verificar con el antivirus; intente nuevamente más tarde")
+	}
+
+	switch mime {
+	case mimeJPEG, mimePNG:
+		img, err := normalizeImage(data)
+		if err != nil {
+			return reject("%s", err.Error())
+		}
+		if rule.ImagesAs == ImagesAsPDF {
+			data, err = imageToPDF(img)
+			return data, ".pdf", err
+		}
+		data, err = encodeJPEG(img)
+		return data, ".jpg", err
+	case mimePDF:
+		return data, ".pdf", nil
+	case mimeZip:
+		return data, ".xlsx", nil
+	}
+	if ext := strings.ToLower(filepath.Ext(file.Filename)); ext == ".csv" {
+		return data, ext, nil
+	}
+	return data, ".txt", nil
+}
+
+const uploadErrorsKey = "upload_errors"
+
+// AddUploadError registra el rechazo de un archivo del campo para mostrarlo en el formulario.
+func AddUploadError(c *gin.Context, field string, err error) {
+	msg := err.Error()
+	var rejected *UploadError
+	if !errors.As(err, &rejected) {
+		slog.Error("Error guardando archivo subido", "campo", field, "error", err.Error())
+		msg = "no se pudo guardar el archivo"
+	}
+	errs := UploadErrors(c)
+	if prev, ok := errs[field]; ok {
+		errs[field] = prev + "; " + msg
+	} else {
+		errs[field] = msg
+	}
+	c.Set(uploadErrorsKey, errs)
+}
+
+// UploadErrors devuelve los archivos rechazados en la petición, por nombre de campo.
+func UploadErrors(c *gin.Context) map[string]string {
+	if v, ok := c.Get(uploadErrorsKey); ok {
+		if errs, ok := v.(map[string]string); ok {
+			return errs
+		}
+	}
+	return map[string]string{}
+}
+
+// UploadErrorSummary resume en un mensaje los archivos rechazados en la petición; vacío si no hubo.
+func UploadErrorSummary(c *gin.Context) string {
+	errs := UploadErrors(c)
+	if len(errs) == 0 {
+		return ""
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, field := range slices.Sorted(maps.Keys(errs)) {
+		msgs = append(msgs, errs[field])
+	}
+	return "Archivo rechazado: " + strings.Join(msgs, "; ")
+}
+
+// FlashUploadErrors conserva los rechazos por campo para la página a la que se redirige tras
+// guardar el formulario. Devuelve true si hubo archivos rechazados.
+func FlashUploadErrors(c *gin.Context) bool {
+	errs := UploadErrors(c)
+	if len(errs) == 0 {
+		return false
+	}
+	if data, err := json.Marshal(errs); err == nil {
+		session := sessions.Default(c)
+		session.AddFlash(string(data), uploadErrorsKey)
+		session.Save()
+	}
+	return true
+}
+
+// uploadErrorsForView junta los rechazos de esta petición con los que llegaron por flash tras
+// una redirección.
+func uploadErrorsForView(c *gin.Context, session sessions.Session) map[string]string {
+	errs := map[string]string{}
+	for _, f := range session.Flashes(uploadErrorsKey) {
+		if s, ok := f.(string); ok {
+			json.Unmarshal([]byte(s), &errs)
+		}
+	}
+	for field, msg := range UploadErrors(c) {
+		errs[field] = msg
+	}
+	return errs
+}
@@ -0,0 +1,208 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+const (
+	maxImageSide   = 2000       // px del lado mayor tras normalizar
+	maxImagePixels = 50_000_000 // px declarados por la cabecera; evita bombas de descompresión
+	jpegQuality    = 80
+)
+
+// normalizeImage decodifica la imagen, aplica la orientación EXIF y la reduce. Al volver a
+// codificarla se descartan los metadatos (EXIF, GPS) del archivo original. Las dimensiones se
+// leen de la cabecera antes de decodificar para no reservar memoria para imágenes enormes.
+func normalizeImage(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("la imagen está dañada o no se puede leer")
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxImagePixels {
+		return nil, fmt.Errorf("la imagen mide %d×%d píxeles y supera el máximo de %d MP", cfg.Width, cfg.Height, maxImagePixels/1_000_000)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("la imagen está dañada o no se puede leer")
+	}
+	// Se reduce antes de rotar para no copiar la imagen a tamaño completo.
+	img = fitImage(img, maxImageSide)
+	return applyOrientation(img, jpegOrientation(data)), nil
+}
+
+func encodeJPEG(img image.Image) ([]byte, error) {
+	// JPEG no tiene transparencia: se aplana sobre blanco.
+	flat := image.NewRGBA(img.Bounds())
+	draw.Draw(flat, flat.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, flat.Bounds(), img, img.Bounds().Min, draw.Over)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flat, &jpeg.Options{Quality: jpegQuality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// imageToPDF coloca la imagen en una hoja A4 (horizontal si es apaisada) con márgenes de 10 mm.
+func imageToPDF(img image.Image) ([]byte, error) {
+	jpg, err := encodeJPEG(img)
+	if err != nil {
+		return nil, err
+	}
+	b := img.Bounds()
+	orientation := "P"
+	if b.Dx() > b.Dy() {
+		orientation = "L"
+	}
+
+	pdf := gofpdf.New(orientation, "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.AddPage()
+	pdf.RegisterImageOptionsReader("upload", gofpdf.ImageOptions{ImageType: "JPG"}, bytes.NewReader(jpg))
+
+	pageW, pageH := pdf.GetPageSize()
+	maxW, maxH := pageW-20, pageH-20
+	w, h := maxW, maxW*float64(b.Dy())/float64(b.Dx())
+	if h > maxH {
+		w, h = maxH*float64(b.Dx())/float64(b.Dy()), maxH
+	}
+	pdf.ImageOptions("upload", (pageW-w)/2, (pageH-h)/2, w, h, false, gofpdf.ImageOptions{ImageType: "JPG"}, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// jpegOrientation lee la etiqueta Orientation (0x0112) del bloque EXIF de un JPEG; 1 si no hay.
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return 1
+		}
+		marker := data[i+1]
+		size := int(binary.BigEndian.Uint16(data[i+2:]))
+		if marker == 0xDA || size < 2 || i+2+size > len(data) {
+			return 1
+		}
+		seg := data[i+4 : i+2+size]
+		if marker == 0xE1 && len(seg) > 14 && string(seg[:6]) == "Exif\x00\x00" {
+			return exifOrientation(seg[6:])
+		}
+		i += 2 + size
+	}
+	return 1
+}
+
+func exifOrientation(tiff []byte) int {
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+	n := int(order.Uint16(tiff[ifd:]))
+	for k := 0; k < n; k++ {
+		entry := ifd + 2 + k*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) == 0x0112 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 1
+		}
+	}
+	return 1
+}
+
+// applyOrientation endereza la imagen según la orientación EXIF (1 a 8).
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+			dst.Set(dx, dy, img.At(b.Min.X+x, b.Min.Y+y))
+		}
+	}
+	return dst
+}
+
+// fitImage reduce la imagen para que su lado mayor no supere maxSide, promediando los píxeles
+// de origen que caen en cada píxel de destino.
+func fitImage(img image.Image, maxSide int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w <= maxSide && h <= maxSide {
+		return img
+	}
+	dw, dh := maxSide, h*maxSide/w
+	if h > w {
+		dw, dh = w*maxSide/h, maxSide
+	}
+	dw, dh = max(dw, 1), max(dh, 1)
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		y0, y1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := 0; x < dw; x++ {
+			x0, x1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := img.At(b.Min.X+sx, b.Min.Y+sy).RGBA()
+					r, g, bl, a, n = r+uint64(cr), g+uint64(cg), bl+uint64(cb), a+uint64(ca), n+1
+				}
+			}
+			dst.SetRGBA64(x, y, color.RGBA64{uint16(r / n), uint16(g / n), uint16(bl / n), uint16(a / n)})
+		}
+	}
+	return dst
+}
@@ -0,0 +1,142 @@
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// MalwareScanner analiza el contenido de un archivo subido antes de guardarlo. Devuelve
+// *MalwareError si el archivo está infectado y otro error si no pudo analizarlo.
+type MalwareScanner interface {
+	Scan(ctx context.Context, data []byte) error
+}
+
+// MalwareError indica que el escáner encontró una firma de malware.
+type MalwareError struct {
+	Signature string
+}
+
+func (e *MalwareError) Error() string {
+	return "el archivo contiene malware (" + e.Signature + ")"
+}
+
+var (
+	scannerMu sync.RWMutex
+	scanner   MalwareScanner
+)
+
+// SetMalwareScanner reemplaza el escáner usado por las subidas; nil vuelve al configurado.
+func SetMalwareScanner(s MalwareScanner) {
+	scannerMu.Lock()
+	defer scannerMu.Unlock()
+	scanner = s
+}
+
+// getMalwareScanner usa clamd si CLAMD_ADDRESS está configurado (tcp://host:3310 o
+// unix:///ruta/clamd.sock); si no, el escáner local que solo reconoce la firma de prueba EICAR.
+func getMalwareScanner() MalwareScanner {
+	scannerMu.RLock()
+	s := scanner
+	scannerMu.RUnlock()
+	if s != nil {
+		return s
+	}
+	if addr := viper.GetString("CLAMD_ADDRESS"); addr != "" {
+		return NewClamdScanner(addr)
+	}
+	return StubScanner{}
+}
+
+// ClamdScanner envía el archivo a clamd con el comando INSTREAM.
+type ClamdScanner struct {
+	Network   string
+	Address   string
+	Timeout   time.Duration
+	ChunkSize int
+}
+
+func NewClamdScanner(addr string) *ClamdScanner {
+	network, address := "tcp", addr
+	if rest, ok := strings.CutPrefix(addr, "unix://"); ok {
+		network, address = "unix", rest
+	} else if rest, ok := strings.CutPrefix(addr, "tcp://"); ok {
+		address = rest
+	}
+	return &ClamdScanner{Network: network, Address: address, Timeout: 30 * time.Second, ChunkSize: 64 << 10}
+}
+
+func (s *ClamdScanner) Scan(ctx context.Context, data []byte) error {
+	d := net.Dialer{Timeout: s.Timeout}
+	conn, err := d.DialContext(ctx, s.Network, s.Address)
+	if err != nil {
+		return fmt.Errorf("no se pudo conectar con el antivirus: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(s.Timeout))
+	}
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return fmt.Errorf("error enviando el archivo al antivirus: %w", err)
+	}
+	size := make([]byte, 4)
+	for start := 0; start < len(data); start += s.ChunkSize {
+		end := min(start+s.ChunkSize, len(data))
+		binary.BigEndian.PutUint32(size, uint32(end-start))
+		if _, err := conn.Write(size); err != nil {
+			return fmt.Errorf("error enviando el archivo al antivirus: %w", err)
+		}
+		if _, err := conn.Write(data[start:end]); err != nil {
+			return fmt.Errorf("error enviando el archivo al antivirus: %w", err)
+		}
+	}
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := conn.Write(size); err != nil {
+		return fmt.Errorf("error enviando el archivo al antivirus: %w", err)
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return fmt.Errorf("el antivirus no respondió: %w", err)
+	}
+	return parseClamdReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamdReply interpreta "stream: OK", "stream: <firma> FOUND" o "<mensaje> ERROR".
+func parseClamdReply(reply string) error {
+	reply = strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case reply == "OK":
+		return nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return &MalwareError{Signature: strings.TrimSuffix(reply, " FOUND")}
+	case strings.HasSuffix(reply, " ERROR"):
+		return errors.New("el antivirus no pudo analizar el archivo: " + strings.TrimSuffix(reply, " ERROR"))
+	}
+	return errors.New("respuesta inesperada del antivirus: " + reply)
+}
+
+// StubScanner es el escáner local para desarrollo: solo detecta el archivo de prueba EICAR.
+type StubScanner struct{}
+
+// La firma se arma en tiempo de ejecución para que el propio binario no la contenga completa.
+var eicar = append([]byte(`X5O!P%@AP[4\PZX54(P^)7CC)7}$`), "EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*"...)
+
+func (StubScanner) Scan(_ context.Context, data []byte) error {
+	if bytes.Contains(data, eicar) {
+		return &MalwareError{Signature: "Eicar-Test-Signature"}
+	}
+	return nil
+}
@@ -43,6 +43,10 @@ document.addEventListener("alpine:init", function () {
       loading: false,
       processingImage: false,
       fares: config.fares || {},
+      item_id: config.item_id || "",
+      extrayendo: false,
+      eticket: null,
+      eticketAvisos: [],
 
       parseDate(str) {
         if (!str) return new Date(NaN);
@@ -91,13 +95,64 @@ document.addEventListener("alpine:init", function () {
             return;
           }
           this.fileName = file.name;
+          await this.extraerBillete(file, el.closest("form"));
         } else {
           this.fileName = file.name;
         }
       },
 
+      /**
+       * Lee el billete electrónico en el servidor y precarga los campos del formulario.
+       */
+      async extraerBillete(file, form) {
+        if (!form) return;
+        const formData = new FormData();
+        formData.append("archivo", file);
+        formData.append("solicitud_item_id", this.item_id);
+        const csrfToken = form.querySelector('input[name="_csrf"]')?.value;
+        if (csrfToken) formData.append("_csrf", csrfToken);
+
+        this.extrayendo = true;
+        this.eticket = null;
+        this.eticketAvisos = [];
+        try {
+          const response = await fetch("/pasajes/eticket/extraer", { method: "POST", body: formData });
+          const data = await response.json();
+          if (!response.ok) {
+            this.eticketAvisos = [data.error || "No se pudieron leer los datos del billete."];
+            return;
+          }
+
+          const setValue = (name, value) => {
+            const input = form.querySelector(`[name="${name}"]`);
+            if (!input || !value) return;
+            input.value = value;
+            input.dispatchEvent(new Event("input", { bubbles: true }));
+          };
+          setValue("numero_billete", data.numero_billete);
+          setValue("numero_vuelo", data.numero_vuelo);
+          setValue("moneda", data.moneda);
+          if (data.costo) this.costo = data.costo;
+          if (data.fecha_vuelo) {
+            const input = form.querySelector('[name="fecha_vuelo"]');
+            if (input && input._dp_instance) {
+              input._dp_instance.selectDate(this.parseDate(data.fecha_vuelo));
+            } else {
+              setValue("fecha_vuelo", data.fecha_vuelo);
+            }
+          }
+
+          this.eticket = data;
+          this.eticketAvisos = data.avisos || [];
+        } catch (err) {
+          console.error("Error extracting e-ticket:", err);
+        } finally {
+          this.extrayendo = false;
+        }
+      },
+
       get canSave() {
-        if (this.loading || this.processingImage) return false;
+        if (this.loading || this.processingImage || this.extrayendo) return false;
         // If editing, file is not mandatory. If creating, it is.
         return this.id ? true : !!this.fileName;
       },
@@ -958,6 +1013,20 @@ window.handleSingleUpload = async function (event, alpineData = null) {
           }
         }
       }
+    } else if (response.status === 422) {
+      // Archivo rechazado por el servidor (tipo, tamaño o antivirus): se descarta la selección
+      const data = await response.json().catch(() => ({}));
+      input.value = "";
+      if (alpineData) {
+        alpineData.fileName = "";
+        alpineData.localUrl = "";
+      }
+      Swal.fire({
+        icon: "error",
+        title: "Archivo rechazado",
+        text: data.error || "El archivo no es válido para este campo.",
+        confirmButtonColor: "#ef4444",
+      });
     } else {
       console.error("Error en la subida automática:", response.statusText);
     }